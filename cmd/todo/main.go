@@ -2,63 +2,258 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"flag"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
+	"todo/internal/config"
+	"todo/internal/metrics"
 	"todo/internal/server"
+	"todo/internal/storage"
+	"todo/internal/storage/postgres"
 	"todo/internal/storage/sqlite"
+	"todo/internal/tracing"
 	"todo/internal/util"
+	"todo/internal/version"
 )
 
+// tlsVersions maps --tls-min-version values to crypto/tls constants.
+var tlsVersions = map[string]uint16{
+	"TLS10": tls.VersionTLS10,
+	"TLS11": tls.VersionTLS11,
+	"TLS12": tls.VersionTLS12,
+	"TLS13": tls.VersionTLS13,
+}
+
 func main() {
-	addrFlag := flag.String("addr", util.EnvOrDefault("TODO_ADDR", ":8080"), "HTTP listen address")
-	dbFlag := flag.String("db", util.EnvOrDefault("TODO_DB_PATH", "data/todo.db"), "Path to sqlite database file")
-	staticFlag := flag.String("static", util.EnvOrDefault("TODO_STATIC_DIR", "web/dist"), "Directory with built frontend")
-	flag.Parse()
+	cfg, err := config.Load(util.EnvOrDefault("TODO_CONFIG", ""), os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
 
-	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
-	logger.Info("ToDo application v.1.0.0")
+	var logLevel slog.Level
+	if err := logLevel.UnmarshalText([]byte(cfg.LogLevel)); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid log level %q: use debug, info, warn or error\n", cfg.LogLevel)
+		os.Exit(2)
+	}
+	handlerOpts := &slog.HandlerOptions{Level: logLevel}
+	var handler slog.Handler
+	switch strings.ToLower(cfg.LogFormat) {
+	case "text":
+		handler = slog.NewTextHandler(os.Stdout, handlerOpts)
+	case "json":
+		handler = slog.NewJSONHandler(os.Stdout, handlerOpts)
+	default:
+		fmt.Fprintf(os.Stderr, "invalid log format %q: use text or json\n", cfg.LogFormat)
+		os.Exit(2)
+	}
+	logger := slog.New(handler)
+	slog.SetDefault(logger)
+	logger.Info("ToDo application v." + version.Version)
 	logger.Info("Created by Xenon007 https://github.com/xenon007/todo")
 	logger.Info("Used: Golang, Gin, SQLite, TypeScript, Vite, Vue3 and PAYED Admin Premium Template")
 	logger.Info("Premium template not included in source repo")
 
-	store, err := sqlite.Open(*dbFlag, logger)
+	timezone, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		logger.Error("invalid timezone", slog.String("timezone", cfg.Timezone), slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	shutdownTimeout, err := parseShutdownTimeout(cfg.ShutdownTimeout)
+	if err != nil {
+		logger.Error("invalid shutdown timeout", slog.String("shutdown_timeout", cfg.ShutdownTimeout))
+		os.Exit(1)
+	}
+
+	tlsConfig, err := serverTLSConfig(cfg)
+	if err != nil {
+		logger.Error("invalid TLS settings", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	useTLS := tlsConfig != nil
+
+	if !server.SupportedAPIVersion(cfg.APIVersionPrefix) {
+		logger.Error("unsupported API version", slog.String("api_version_prefix", cfg.APIVersionPrefix))
+		os.Exit(1)
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.TracingEnabled)
 	if err != nil {
-		logger.Error("unable to open database", slog.String("error", err.Error()))
+		logger.Error("unable to set up tracing", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	pool := storage.PoolConfig{MaxOpenConns: cfg.DBMaxOpenConns, MaxIdleConns: cfg.DBMaxIdleConns}
+	for _, d := range []struct {
+		name, value string
+		dst         *time.Duration
+	}{
+		{"db_conn_max_lifetime", cfg.DBConnMaxLifetime, &pool.ConnMaxLifetime},
+		{"db_conn_max_idle_time", cfg.DBConnMaxIdleTime, &pool.ConnMaxIdleTime},
+	} {
+		if *d.dst, err = time.ParseDuration(d.value); err != nil || *d.dst < 0 {
+			logger.Error("invalid connection pool duration", slog.String(d.name, d.value))
+			os.Exit(1)
+		}
+	}
+
+	var observe func(string, time.Duration)
+	if cfg.MetricsAddr != "" {
+		observe = metrics.ObserveStoreQuery
+	}
+	store, err := openStore(cfg, logger, pool, observe)
+	if err != nil {
+		logger.Error("unable to open database", slog.String("driver", cfg.DBDriver), slog.String("error", err.Error()))
 		os.Exit(1)
 	}
 	defer store.Close()
+	store.SetMaxRevisions(cfg.MaxRevisions)
+	store.SetLengthLimits(cfg.MaxTitleLength, cfg.MaxDescriptionLength, cfg.MaxProjectNameLength)
 
-	srv := server.New(store, logger, *staticFlag)
+	srv := server.New(store, logger, server.Options{
+		StaticDir:    cfg.StaticDir,
+		CORSOrigins:  cfg.CORSOrigins,
+		MaxBodyBytes: cfg.MaxBodyBytes,
+		JWTSecret:    cfg.JWTSecret,
+		RateLimit:    cfg.RateLimit,
+		RateBurst:    cfg.RateBurst,
+		Config:       cfg.Redacted(),
+		APIVersion:   cfg.APIVersionPrefix,
+	})
+	srv.SetMaxActivity(cfg.ActivityLimit)
+	srv.SetTimezone(timezone)
 
 	httpServer := &http.Server{
-		Addr:    *addrFlag,
-		Handler: srv.Engine(),
+		Addr:      cfg.Addr,
+		Handler:   srv.Engine(),
+		TLSConfig: tlsConfig,
 	}
+	httpServer.RegisterOnShutdown(srv.Shutdown)
 
 	go func() {
-		logger.Info("starting server", slog.String("addr", httpServer.Addr))
-		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Info("starting server", slog.String("addr", httpServer.Addr), slog.Bool("tls", useTLS))
+		var err error
+		if useTLS {
+			err = httpServer.ListenAndServeTLS(cfg.TLSCert, cfg.TLSKey)
+		} else {
+			err = httpServer.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
 			logger.Error("server stopped unexpectedly", slog.String("error", err.Error()))
 		}
 	}()
 
+	// Metrics live on their own listener so they can stay off the public port.
+	var metricsServer *http.Server
+	if cfg.MetricsAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("GET /metrics", metrics.Handler())
+		metricsServer = &http.Server{Addr: cfg.MetricsAddr, Handler: mux}
+		go func() {
+			logger.Info("starting metrics server", slog.String("addr", metricsServer.Addr))
+			if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Error("metrics server stopped unexpectedly", slog.String("error", err.Error()))
+			}
+		}()
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	shutdownStarted := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer cancel()
 
 	if err := httpServer.Shutdown(ctx); err != nil {
-		logger.Error("failed to shutdown server", slog.String("error", err.Error()))
+		logger.Error("failed to shutdown server",
+			slog.String("error", err.Error()),
+			slog.Duration("elapsed", time.Since(shutdownStarted)),
+			slog.Duration("timeout", shutdownTimeout))
+	}
+
+	if metricsServer != nil {
+		if err := metricsServer.Shutdown(ctx); err != nil {
+			logger.Error("failed to shutdown metrics server", slog.String("error", err.Error()))
+		}
+	}
+
+	if err := shutdownTracing(ctx); err != nil {
+		logger.Error("failed to flush traces", slog.String("error", err.Error()))
 	}
 
 	logger.Info("server stopped")
 }
+
+// parseShutdownTimeout reads a --shutdown-timeout value, which must be a
+// positive duration such as 30s.
+func parseShutdownTimeout(raw string) (time.Duration, error) {
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("shutdown timeout must be positive, got %s", raw)
+	}
+	return d, nil
+}
+
+// backend is the store the server runs on, whichever driver opened it.
+type backend interface {
+	server.StorageBackend
+	Close() error
+	SetMaxRevisions(n int)
+	SetLengthLimits(title, description, projectName int)
+}
+
+// openStore opens the database at cfg.DBPath with the driver cfg.DBDriver
+// names. A non-nil observe is told how long each query took.
+func openStore(cfg *config.Config, logger *slog.Logger, pool storage.PoolConfig, observe func(string, time.Duration)) (backend, error) {
+	switch cfg.DBDriver {
+	case "sqlite3":
+		store, err := sqlite.Open(cfg.DBPath, logger, pool)
+		if err != nil {
+			return nil, err
+		}
+		store.SetQueryObserver(observe)
+		return store, nil
+	case "postgres":
+		store, err := postgres.Open(cfg.DBPath, logger, pool)
+		if err != nil {
+			return nil, err
+		}
+		store.SetQueryObserver(observe)
+		return store, nil
+	default:
+		return nil, fmt.Errorf("invalid database driver %q: use sqlite3 or postgres", cfg.DBDriver)
+	}
+}
+
+// serverTLSConfig checks the TLS settings of cfg and returns the
+// configuration to serve HTTPS with, or nil to serve plain HTTP.
+func serverTLSConfig(cfg *config.Config) (*tls.Config, error) {
+	minVersion, ok := tlsVersions[strings.ToUpper(cfg.TLSMinVersion)]
+	if !ok {
+		return nil, fmt.Errorf("invalid TLS version %q: use TLS10, TLS11, TLS12 or TLS13", cfg.TLSMinVersion)
+	}
+	if cfg.TLSCert == "" && cfg.TLSKey == "" {
+		return nil, nil
+	}
+	if cfg.TLSCert == "" || cfg.TLSKey == "" {
+		return nil, errors.New("--tls-cert and --tls-key must be given together")
+	}
+	return &tls.Config{MinVersion: minVersion}, nil
+}
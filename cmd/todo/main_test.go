@@ -0,0 +1,153 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"todo/internal/config"
+	"todo/internal/storage/sqlite"
+	"todo/internal/util"
+)
+
+func TestServeTLS(t *testing.T) {
+	certPEM, keyPEM, err := util.GenerateSelfSignedCert("127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, keyPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := config.Load("", []string{"--tls-cert", certFile, "--tls-key", keyFile, "--tls-min-version", "tls13"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tlsConfig, err := serverTLSConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpServer := &http.Server{
+		Handler:   http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		TLSConfig: tlsConfig,
+	}
+	go httpServer.ServeTLS(ln, cfg.TLSCert, cfg.TLSKey)
+	defer httpServer.Close()
+
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(certPEM)
+	get := func(maxVersion uint16) error {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots, MaxVersion: maxVersion}}}
+		resp, err := client.Get("https://" + ln.Addr().String() + "/")
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		return nil
+	}
+	if err := get(0); err != nil {
+		t.Fatalf("HTTPS request: %v", err)
+	}
+	if err := get(tls.VersionTLS12); err == nil {
+		t.Fatal("TLS 1.2 client connected despite --tls-min-version tls13")
+	}
+}
+
+func TestServerTLSConfigErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"cert without key", []string{"--tls-cert", "cert.pem"}},
+		{"key without cert", []string{"--tls-key", "key.pem"}},
+		{"unknown version", []string{"--tls-min-version", "SSL3"}},
+	}
+	for _, tt := range tests {
+		cfg, err := config.Load("", tt.args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := serverTLSConfig(cfg); err == nil {
+			t.Errorf("%s: serverTLSConfig succeeded, want an error", tt.name)
+		}
+	}
+
+	cfg, err := config.Load("", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tlsConfig, err := serverTLSConfig(cfg); tlsConfig != nil || err != nil {
+		t.Fatalf("no certificate: got %v, %v; want plain HTTP", tlsConfig, err)
+	}
+}
+
+func TestShutdownTimeoutFlag(t *testing.T) {
+	tests := []struct {
+		name string
+		env  string
+		args []string
+		want time.Duration
+	}{
+		{"default", "", nil, 5 * time.Second},
+		{"env", "10s", nil, 10 * time.Second},
+		{"flag", "", []string{"--shutdown-timeout", "30s"}, 30 * time.Second},
+		{"flag over env", "10s", []string{"-shutdown-timeout=1m"}, time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TODO_SHUTDOWN_TIMEOUT", tt.env)
+			cfg, err := config.Load("", tt.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := parseShutdownTimeout(cfg.ShutdownTimeout)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Fatalf("shutdown timeout = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	for _, raw := range []string{"5", "soon", "0s", "-1s"} {
+		if _, err := parseShutdownTimeout(raw); err == nil {
+			t.Errorf("parseShutdownTimeout(%q) succeeded, want an error", raw)
+		}
+	}
+}
+
+func TestOpenStoreDriver(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg, err := config.Load("", []string{"--db", filepath.Join(t.TempDir(), "todo.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := openStore(cfg, logger, sqlite.DefaultPoolConfig(), nil)
+	if err != nil {
+		t.Fatalf("default driver: %v", err)
+	}
+	store.Close()
+
+	cfg.DBDriver = "mysql"
+	if _, err := openStore(cfg, logger, sqlite.DefaultPoolConfig(), nil); err == nil || !strings.Contains(err.Error(), "sqlite3 or postgres") {
+		t.Fatalf("unknown driver: got %v, want an invalid driver error", err)
+	}
+}
@@ -0,0 +1,264 @@
+// Package config gathers the server settings from defaults, an optional YAML
+// or TOML file, TODO_* environment variables and command-line flags, in
+// increasing order of precedence.
+package config
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+
+	"todo/internal/defaults"
+	"todo/internal/util"
+)
+
+// Config mirrors every command-line flag. File keys use the snake_case names
+// given in the tags.
+type Config struct {
+	Addr              string   `yaml:"addr" toml:"addr" json:"addr"`
+	DBDriver          string   `yaml:"db_driver" toml:"db_driver" json:"db_driver"`
+	DBPath            string   `yaml:"db_path" toml:"db_path" json:"db_path"`
+	DBMaxOpenConns    int      `yaml:"db_max_open_conns" toml:"db_max_open_conns" json:"db_max_open_conns"`
+	DBMaxIdleConns    int      `yaml:"db_max_idle_conns" toml:"db_max_idle_conns" json:"db_max_idle_conns"`
+	DBConnMaxLifetime string   `yaml:"db_conn_max_lifetime" toml:"db_conn_max_lifetime" json:"db_conn_max_lifetime"`
+	DBConnMaxIdleTime string   `yaml:"db_conn_max_idle_time" toml:"db_conn_max_idle_time" json:"db_conn_max_idle_time"`
+	StaticDir         string   `yaml:"static_dir" toml:"static_dir" json:"static_dir"`
+	CORSOrigins       []string `yaml:"cors_origins" toml:"cors_origins" json:"cors_origins"`
+	MaxRevisions      int      `yaml:"max_revisions" toml:"max_revisions" json:"max_revisions"`
+	ActivityLimit     int      `yaml:"activity_limit" toml:"activity_limit" json:"activity_limit"`
+	MaxBodyBytes      int64    `yaml:"max_body_bytes" toml:"max_body_bytes" json:"max_body_bytes"`
+	JWTSecret         string   `yaml:"jwt_secret" toml:"jwt_secret" json:"jwt_secret"`
+	RateLimit         int      `yaml:"rate_limit" toml:"rate_limit" json:"rate_limit"`
+	RateBurst         int      `yaml:"rate_burst" toml:"rate_burst" json:"rate_burst"`
+	MetricsAddr       string   `yaml:"metrics_addr" toml:"metrics_addr" json:"metrics_addr"`
+	TracingEnabled    bool     `yaml:"tracing_enabled" toml:"tracing_enabled" json:"tracing_enabled"`
+	TLSCert           string   `yaml:"tls_cert" toml:"tls_cert" json:"tls_cert"`
+	TLSKey            string   `yaml:"tls_key" toml:"tls_key" json:"tls_key"`
+	TLSMinVersion     string   `yaml:"tls_min_version" toml:"tls_min_version" json:"tls_min_version"`
+	ShutdownTimeout   string   `yaml:"shutdown_timeout" toml:"shutdown_timeout" json:"shutdown_timeout"`
+	LogLevel          string   `yaml:"log_level" toml:"log_level" json:"log_level"`
+	LogFormat         string   `yaml:"log_format" toml:"log_format" json:"log_format"`
+	Timezone          string   `yaml:"timezone" toml:"timezone" json:"timezone"`
+	APIVersionPrefix  string   `yaml:"api_version_prefix" toml:"api_version_prefix" json:"api_version_prefix"`
+	Validation        `yaml:",inline"`
+
+	// File is the config file the values were read from, if any.
+	File string `yaml:"-" toml:"-" json:"file"`
+}
+
+// Default returns the settings used when nothing else is configured.
+func Default() *Config {
+	return &Config{
+		Addr:              ":8080",
+		DBDriver:          "sqlite3",
+		DBPath:            "data/todo.db",
+		DBMaxOpenConns:    defaults.DBMaxOpenConns,
+		DBMaxIdleConns:    defaults.DBMaxIdleConns,
+		DBConnMaxLifetime: "0",
+		DBConnMaxIdleTime: "0",
+		StaticDir:         "web/dist",
+		MaxRevisions:      defaults.MaxRevisions,
+		ActivityLimit:     defaults.MaxActivity,
+		MaxBodyBytes:      defaults.MaxBodyBytes,
+		RateLimit:         defaults.RateLimit,
+		RateBurst:         defaults.RateBurst,
+		TLSMinVersion:     "TLS12",
+		ShutdownTimeout:   "5s",
+		LogLevel:          "info",
+		LogFormat:         "text",
+		Timezone:          "Local",
+		APIVersionPrefix:  defaults.APIVersion,
+		Validation:        defaultValidation(),
+	}
+}
+
+// Load builds the configuration for the command-line args, which exclude the
+// program name. The file named by a --config flag in args takes the place of
+// path; an empty path means no file. Values from the file override the
+// defaults, TODO_* environment variables override the file and flags override
+// everything.
+func Load(path string, args []string) (*Config, error) {
+	if p, ok := configFlag(args); ok {
+		path = p
+	}
+	cfg := Default()
+	if path != "" {
+		if err := cfg.readFile(path); err != nil {
+			return nil, err
+		}
+		cfg.File = path
+	}
+	cfg.applyEnv()
+
+	fs := flag.NewFlagSet(filepath.Base(os.Args[0]), flag.ContinueOnError)
+	fs.String("config", path, "YAML (.yaml, .yml) or TOML (.toml) config file; flags and TODO_* variables override it")
+	cfg.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Redacted returns a copy that is safe to show to users, with secrets masked.
+func (c *Config) Redacted() *Config {
+	out := *c
+	out.CORSOrigins = append([]string(nil), c.CORSOrigins...)
+	if out.JWTSecret != "" {
+		out.JWTSecret = "[redacted]"
+	}
+	if out.DBDriver == "postgres" {
+		out.DBPath = redactDSN(out.DBPath)
+	}
+	return &out
+}
+
+// redactDSN masks the password of a postgres:// connection URL. Key/value
+// connection strings are masked whole, since the password may sit anywhere.
+func redactDSN(dsn string) string {
+	if u, err := url.Parse(dsn); err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql") {
+		return u.Redacted()
+	}
+	if dsn == "" {
+		return dsn
+	}
+	return "[redacted]"
+}
+
+// readFile decodes the file at path, choosing the format by extension.
+// Unknown keys are rejected so typos do not go unnoticed.
+func (c *Config) readFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		// An empty file decodes to io.EOF and leaves the defaults alone.
+		if err := dec.Decode(c); err != nil && len(bytes.TrimSpace(data)) > 0 {
+			return fmt.Errorf("parse config %s: %w", path, err)
+		}
+	case ".toml":
+		dec := toml.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(c); err != nil {
+			return fmt.Errorf("parse config %s: %w", path, err)
+		}
+	default:
+		return fmt.Errorf("config %s: unsupported format, use .yaml, .yml or .toml", path)
+	}
+	return nil
+}
+
+// applyEnv overrides values with the TODO_* environment variables that are set.
+func (c *Config) applyEnv() {
+	c.Addr = util.EnvOrDefault("TODO_ADDR", c.Addr)
+	c.DBDriver = util.EnvOrDefault("TODO_DB_DRIVER", c.DBDriver)
+	c.DBPath = util.EnvOrDefault("TODO_DB_PATH", c.DBPath)
+	c.DBMaxOpenConns = util.EnvIntOrDefault("TODO_DB_MAX_OPEN_CONNS", c.DBMaxOpenConns)
+	c.DBMaxIdleConns = util.EnvIntOrDefault("TODO_DB_MAX_IDLE_CONNS", c.DBMaxIdleConns)
+	c.DBConnMaxLifetime = util.EnvOrDefault("TODO_DB_CONN_MAX_LIFETIME", c.DBConnMaxLifetime)
+	c.DBConnMaxIdleTime = util.EnvOrDefault("TODO_DB_CONN_MAX_IDLE_TIME", c.DBConnMaxIdleTime)
+	c.StaticDir = util.EnvOrDefault("TODO_STATIC_DIR", c.StaticDir)
+	if origins := os.Getenv("TODO_CORS_ORIGINS"); origins != "" {
+		c.CORSOrigins = splitList(origins)
+	}
+	c.MaxRevisions = util.EnvIntOrDefault("TODO_MAX_REVISIONS", c.MaxRevisions)
+	c.ActivityLimit = util.EnvIntOrDefault("TODO_ACTIVITY_LIMIT", c.ActivityLimit)
+	c.MaxBodyBytes = util.EnvInt64OrDefault("TODO_MAX_BODY_BYTES", c.MaxBodyBytes)
+	c.JWTSecret = util.EnvOrDefault("TODO_JWT_SECRET", c.JWTSecret)
+	c.RateLimit = util.EnvIntOrDefault("TODO_RATE_LIMIT", c.RateLimit)
+	c.RateBurst = util.EnvIntOrDefault("TODO_RATE_BURST", c.RateBurst)
+	c.MetricsAddr = util.EnvOrDefault("TODO_METRICS_ADDR", c.MetricsAddr)
+	c.TracingEnabled = util.EnvBoolOrDefault("TODO_TRACING_ENABLED", c.TracingEnabled)
+	c.TLSCert = util.EnvOrDefault("TODO_TLS_CERT", c.TLSCert)
+	c.TLSKey = util.EnvOrDefault("TODO_TLS_KEY", c.TLSKey)
+	c.TLSMinVersion = util.EnvOrDefault("TODO_TLS_MIN_VERSION", c.TLSMinVersion)
+	c.ShutdownTimeout = util.EnvOrDefault("TODO_SHUTDOWN_TIMEOUT", c.ShutdownTimeout)
+	c.LogLevel = util.EnvOrDefault("TODO_LOG_LEVEL", c.LogLevel)
+	c.LogFormat = util.EnvOrDefault("TODO_LOG_FORMAT", c.LogFormat)
+	c.Timezone = util.EnvOrDefault("TODO_TIMEZONE", c.Timezone)
+	c.APIVersionPrefix = util.EnvOrDefault("TODO_API_VERSION_PREFIX", c.APIVersionPrefix)
+	c.Validation.applyEnv()
+}
+
+// register binds every field to its flag, using the current value as default.
+func (c *Config) register(fs *flag.FlagSet) {
+	fs.StringVar(&c.Addr, "addr", c.Addr, "HTTP listen address")
+	fs.StringVar(&c.DBDriver, "db-driver", c.DBDriver, "Database driver: sqlite3 or postgres")
+	fs.StringVar(&c.DBPath, "db", c.DBPath, "Path to sqlite database file, or the connection string with --db-driver postgres")
+	fs.IntVar(&c.DBMaxOpenConns, "db-max-open-conns", c.DBMaxOpenConns, "Maximum open database connections")
+	fs.IntVar(&c.DBMaxIdleConns, "db-max-idle-conns", c.DBMaxIdleConns, "Maximum idle database connections kept in the pool")
+	fs.StringVar(&c.DBConnMaxLifetime, "db-conn-max-lifetime", c.DBConnMaxLifetime, "Close database connections after this long, e.g. 1h; 0 keeps them forever")
+	fs.StringVar(&c.DBConnMaxIdleTime, "db-conn-max-idle-time", c.DBConnMaxIdleTime, "Close database connections idle this long, e.g. 5m; 0 keeps them forever")
+	fs.StringVar(&c.StaticDir, "static", c.StaticDir, "Directory with built frontend")
+	fs.Var((*listValue)(&c.CORSOrigins), "cors-origins", "Comma-separated origins allowed to call the API cross-origin")
+	fs.IntVar(&c.MaxRevisions, "max-revisions", c.MaxRevisions, "Title/description revisions kept per task")
+	fs.IntVar(&c.ActivityLimit, "activity-limit", c.ActivityLimit, "Maximum entries returned by activity feeds")
+	fs.Int64Var(&c.MaxBodyBytes, "max-body-bytes", c.MaxBodyBytes, "Maximum request body size in bytes")
+	fs.StringVar(&c.JWTSecret, "jwt-secret", c.JWTSecret, "HS256 secret; when set, API calls require a bearer token from /api/auth/login")
+	fs.IntVar(&c.RateLimit, "rate-limit", c.RateLimit, "API requests per second allowed per client IP; 0 disables limiting")
+	fs.IntVar(&c.RateBurst, "rate-burst", c.RateBurst, "Requests a client IP may burst above the rate limit")
+	fs.StringVar(&c.MetricsAddr, "metrics-addr", c.MetricsAddr, "Listen address for Prometheus /metrics; empty disables it")
+	fs.BoolVar(&c.TracingEnabled, "tracing-enabled", c.TracingEnabled, "Export OpenTelemetry traces over OTLP/HTTP, configured by the OTEL_* environment variables")
+	fs.StringVar(&c.TLSCert, "tls-cert", c.TLSCert, "PEM certificate file; serve HTTPS when set together with --tls-key")
+	fs.StringVar(&c.TLSKey, "tls-key", c.TLSKey, "PEM private key file for --tls-cert")
+	fs.StringVar(&c.TLSMinVersion, "tls-min-version", c.TLSMinVersion, "Minimum TLS version: TLS10, TLS11, TLS12 or TLS13")
+	fs.StringVar(&c.ShutdownTimeout, "shutdown-timeout", c.ShutdownTimeout, "How long to wait for open requests and event streams on shutdown, e.g. 30s")
+	fs.StringVar(&c.LogLevel, "log-level", c.LogLevel, "Log level: debug, info, warn or error")
+	fs.StringVar(&c.LogFormat, "log-format", c.LogFormat, "Log format: text or json")
+	fs.StringVar(&c.Timezone, "timezone", c.Timezone, "IANA time zone for natural-language due dates")
+	fs.StringVar(&c.APIVersionPrefix, "api-version-prefix", c.APIVersionPrefix, "Canonical API version, served under /api as well as /api/<version>")
+	c.Validation.register(fs)
+}
+
+// configFlag finds the value of a --config or -config flag in args without
+// parsing the rest, so the file can be read before the other flags.
+func configFlag(args []string) (string, bool) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return "", false
+		}
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		name := strings.TrimLeft(arg, "-")
+		if value, ok := strings.CutPrefix(name, "config="); ok {
+			return value, true
+		}
+		if name == "config" && i+1 < len(args) {
+			return args[i+1], true
+		}
+	}
+	return "", false
+}
+
+// listValue is a comma-separated flag that replaces the whole list when set.
+type listValue []string
+
+func (l *listValue) String() string { return strings.Join(*l, ",") }
+
+func (l *listValue) Set(raw string) error {
+	*l = splitList(raw)
+	return nil
+}
+
+// splitList splits a comma-separated list, dropping blank entries.
+func splitList(raw string) []string {
+	var out []string
+	for _, part := range strings.Split(raw, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := writeFile(t, "todo.yaml", "addr: \":9000\"\nrate_limit: 5\nlog_level: debug\ntimezone: UTC\n")
+	t.Setenv("TODO_RATE_LIMIT", "6")
+	t.Setenv("TODO_LOG_LEVEL", "warn")
+
+	cfg, err := Load(path, []string{"--log-level", "error"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{"default", cfg.DBPath, Default().DBPath},
+		{"default driver", cfg.DBDriver, "sqlite3"},
+		{"file over default", cfg.Addr, ":9000"},
+		{"file only", cfg.Timezone, "UTC"},
+		{"env over file", cfg.RateLimit, 6},
+		{"flag over env", cfg.LogLevel, "error"},
+		{"source", cfg.File, path},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestLoadConfigFlagReplacesPath(t *testing.T) {
+	ignored := writeFile(t, "ignored.yaml", "addr: \":1\"\n")
+	path := writeFile(t, "todo.toml", "addr = \":2\"\n")
+
+	cfg, err := Load(ignored, []string{"--config=" + path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Addr != ":2" || cfg.File != path {
+		t.Fatalf("addr = %q from %q, want :2 from %q", cfg.Addr, cfg.File, path)
+	}
+}
+
+func TestLoadRejectsUnknownKeys(t *testing.T) {
+	path := writeFile(t, "todo.yaml", "adr: \":9000\"\n")
+	if _, err := Load(path, nil); err == nil {
+		t.Fatal("Load accepted an unknown key")
+	}
+}
+
+func TestRedactedMasksSecrets(t *testing.T) {
+	tests := []struct {
+		driver, dsn, want string
+	}{
+		{"sqlite3", "data/todo.db", "data/todo.db"},
+		{"postgres", "postgres://todo:hunter2@db:5432/todo?sslmode=disable", "postgres://todo:xxxxx@db:5432/todo?sslmode=disable"},
+		{"postgres", "host=db user=todo password=hunter2", "[redacted]"},
+	}
+	for _, tt := range tests {
+		cfg := Default()
+		cfg.JWTSecret = "secret"
+		cfg.DBDriver, cfg.DBPath = tt.driver, tt.dsn
+		got := cfg.Redacted()
+		if got.DBPath != tt.want || got.JWTSecret != "[redacted]" {
+			t.Errorf("%s %q: redacted to %q with secret %q", tt.driver, tt.dsn, got.DBPath, got.JWTSecret)
+		}
+		if cfg.DBPath != tt.dsn {
+			t.Errorf("%s %q: Redacted changed the original to %q", tt.driver, tt.dsn, cfg.DBPath)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"flag"
+
+	"todo/internal/defaults"
+	"todo/internal/util"
+)
+
+// Validation holds the text length limits, in characters, that the store
+// enforces on user input.
+type Validation struct {
+	MaxTitleLength       int `yaml:"max_title_length" toml:"max_title_length" json:"max_title_length"`
+	MaxDescriptionLength int `yaml:"max_description_length" toml:"max_description_length" json:"max_description_length"`
+	MaxProjectNameLength int `yaml:"max_project_name_length" toml:"max_project_name_length" json:"max_project_name_length"`
+}
+
+// defaultValidation returns the store's built-in limits.
+func defaultValidation() Validation {
+	return Validation{
+		MaxTitleLength:       defaults.MaxTitleLength,
+		MaxDescriptionLength: defaults.MaxDescriptionLength,
+		MaxProjectNameLength: defaults.MaxProjectNameLength,
+	}
+}
+
+func (v *Validation) applyEnv() {
+	v.MaxTitleLength = util.EnvIntOrDefault("TODO_MAX_TITLE_LEN", v.MaxTitleLength)
+	v.MaxDescriptionLength = util.EnvIntOrDefault("TODO_MAX_DESC_LEN", v.MaxDescriptionLength)
+	v.MaxProjectNameLength = util.EnvIntOrDefault("TODO_MAX_PROJECT_NAME_LEN", v.MaxProjectNameLength)
+}
+
+func (v *Validation) register(fs *flag.FlagSet) {
+	fs.IntVar(&v.MaxTitleLength, "max-title-len", v.MaxTitleLength, "Maximum task title length in characters")
+	fs.IntVar(&v.MaxDescriptionLength, "max-desc-len", v.MaxDescriptionLength, "Maximum task description length in characters")
+	fs.IntVar(&v.MaxProjectNameLength, "max-project-name-len", v.MaxProjectNameLength, "Maximum project name length in characters")
+}
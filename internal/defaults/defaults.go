@@ -0,0 +1,35 @@
+// Package defaults holds the built-in settings shared by the config package
+// and the packages it configures, so that config need not import them.
+package defaults
+
+// Database connection pool: one connection that is never recycled, which
+// serializes writes the way SQLite without WAL expects.
+const (
+	DBMaxOpenConns = 1
+	DBMaxIdleConns = 1
+)
+
+// MaxRevisions is the number of title and description revisions kept per task.
+const MaxRevisions = 50
+
+// Text length limits, in characters.
+const (
+	MaxTitleLength       = 500
+	MaxDescriptionLength = 50000
+	MaxProjectNameLength = 200
+)
+
+// MaxActivity caps how many entries an activity feed returns.
+const MaxActivity = 200
+
+// MaxBodyBytes caps API request bodies at 1 MB.
+const MaxBodyBytes int64 = 1 << 20
+
+// Rate limit per client IP.
+const (
+	RateLimit = 60
+	RateBurst = 20
+)
+
+// APIVersion is the canonical API version.
+const APIVersion = "v1"
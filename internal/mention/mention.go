@@ -0,0 +1,113 @@
+// Package mention finds @name mentions in Markdown task descriptions.
+//
+// A mention is an @ at the start of a word followed by letters, digits, '_',
+// '-' or '.'. An @ preceded by a word character, as in an email address, is
+// not a mention, and nothing inside fenced (``` or ~~~) or inline code spans
+// is considered.
+package mention
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxLength caps the length of a mentioned name; longer runs are ignored.
+const MaxLength = 64
+
+// Parse returns the names mentioned in text without the leading @, in order
+// of first appearance. Duplicates differing only in case are dropped.
+func Parse(text string) []string {
+	var (
+		names []string
+		seen  = make(map[string]bool)
+		fence string
+	)
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if f := fenceMarker(trimmed); f != "" {
+			fence = f
+			continue
+		}
+		for _, name := range scanLine(line) {
+			key := strings.ToLower(name)
+			if !seen[key] {
+				seen[key] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// fenceMarker returns the run of backticks or tildes opening a fenced code
+// block on line, or "" if line does not open one.
+func fenceMarker(line string) string {
+	for _, c := range []byte{'`', '~'} {
+		n := 0
+		for n < len(line) && line[n] == c {
+			n++
+		}
+		if n >= 3 {
+			return line[:n]
+		}
+	}
+	return ""
+}
+
+// scanLine returns the mentions on a single line outside inline code spans.
+func scanLine(line string) []string {
+	var names []string
+	prev := ' '
+	for i := 0; i < len(line); {
+		r, size := utf8.DecodeRuneInString(line[i:])
+		switch {
+		case r == '`':
+			// Skip to the closing run of the same length; an unmatched run is
+			// literal text.
+			n := 0
+			for i+n < len(line) && line[i+n] == '`' {
+				n++
+			}
+			run := line[i : i+n]
+			if end := strings.Index(line[i+n:], run); end >= 0 {
+				i += n + end + n
+			} else {
+				i += n
+			}
+			prev = '`'
+			continue
+		case r == '@' && !isNameRune(prev) && prev != '@':
+			name := line[i+size:]
+			end := 0
+			for end < len(name) {
+				nr, nsize := utf8.DecodeRuneInString(name[end:])
+				if !isNameRune(nr) {
+					break
+				}
+				end += nsize
+			}
+			// Trailing punctuation ends the sentence rather than the name.
+			name = strings.TrimRight(name[:end], ".-")
+			if name != "" && utf8.RuneCountInString(name) <= MaxLength {
+				names = append(names, name)
+			}
+			i += size + end
+			prev = 'x'
+			continue
+		}
+		prev = r
+		i += size
+	}
+	return names
+}
+
+func isNameRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.'
+}
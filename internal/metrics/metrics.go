@@ -0,0 +1,51 @@
+// Package metrics defines the Prometheus metrics exported by the server.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests served, by method, route and status code.",
+	}, []string{"method", "path", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency by method and route.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "path"})
+
+	storeDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "store_query_duration_seconds",
+		Help:    "Database query latency by store operation.",
+		Buckets: []float64{.0001, .0005, .001, .005, .01, .05, .1, .5, 1},
+	}, []string{"operation"})
+)
+
+func init() {
+	prometheus.MustRegister(httpRequests, httpDuration, storeDuration)
+}
+
+// ObserveHTTPRequest records one served request. path should be the route
+// template, not the raw URL, to keep label cardinality bounded.
+func ObserveHTTPRequest(method, path string, status int, d time.Duration) {
+	httpRequests.WithLabelValues(method, path, strconv.Itoa(status)).Inc()
+	httpDuration.WithLabelValues(method, path).Observe(d.Seconds())
+}
+
+// ObserveStoreQuery records the latency of one database query.
+func ObserveStoreQuery(operation string, d time.Duration) {
+	storeDuration.WithLabelValues(operation).Observe(d.Seconds())
+}
+
+// Handler serves the registered metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -1,29 +1,749 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // Project describes a scrum project that groups multiple tasks.
 type Project struct {
+	ID          int64      `json:"id"`
+	Name        string     `json:"name"`
+	Color       string     `json:"color"`
+	Description string     `json:"description"`
+	Deadline    *time.Time `json:"deadline"`
+	Position    int64      `json:"position"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+}
+
+// TaskLink is an external reference such as a pull request or ticket.
+type TaskLink struct {
+	URL   string `json:"url"`
+	Title string `json:"title"`
+}
+
+// TaskStatus is a board column of a project. Tasks store the status name;
+// entering a terminal status marks a task completed. A WIPLimit above zero
+// caps the number of tasks in the column.
+type TaskStatus struct {
+	ID           int64     `json:"id"`
+	ProjectID    int64     `json:"project_id"`
+	Name         string    `json:"name"`
+	Title        string    `json:"title"`
+	Color        string    `json:"color"`
+	DisplayOrder int       `json:"display_order"`
+	IsTerminal   bool      `json:"is_terminal"`
+	WIPLimit     int       `json:"wip_limit"`
+	WIPMode      string    `json:"wip_mode"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// WIP modes of a status column: enforce refuses tasks beyond the limit, warn
+// accepts them and flags the response.
+const (
+	WIPModeEnforce = "enforce"
+	WIPModeWarn    = "warn"
+)
+
+// ProjectWithCounts is a project together with its task counts.
+// StatusCounts has an entry for each of the project's statuses, and
+// DoneCount counts the tasks in terminal statuses.
+type ProjectWithCounts struct {
+	Project
+	StatusCounts map[string]int `json:"status_counts"`
+	DoneCount    int            `json:"done_count"`
+	TotalCount   int            `json:"total_count"`
+}
+
+// Board is a project with its tasks grouped by status column in board order.
+// Statuses lists the columns in display order, and each has an entry in
+// Columns, empty or not.
+type Board struct {
+	Project  Project           `json:"project"`
+	Statuses []TaskStatus      `json:"statuses"`
+	Columns  map[string][]Task `json:"columns"`
+	// Counts holds the live tasks of every column, snoozed ones included,
+	// as counted against its WIP limit.
+	Counts map[string]int `json:"counts"`
+}
+
+// Task represents a single card in the scrum board.
+type Task struct {
+	ID             int64             `json:"id"`
+	ProjectID      int64             `json:"project_id"`
+	Number         int64             `json:"number"`
+	ParentID       *int64            `json:"parent_id"`
+	SprintID       *int64            `json:"sprint_id"`
+	MilestoneID    *int64            `json:"milestone_id"`
+	Title          string            `json:"title"`
+	Description    string            `json:"description"`
+	Status         string            `json:"status"`
+	Priority       string            `json:"priority"`
+	Assignee       string            `json:"assignee"`
+	Color          string            `json:"color"`
+	StoryPoints    int               `json:"story_points"`
+	CoverURL       string            `json:"cover_url"`
+	DueDate        *time.Time        `json:"due_date"`
+	SnoozedUntil   *time.Time        `json:"snoozed_until"`
+	Position       int64             `json:"position"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+	CompletedAt    *time.Time        `json:"completed_at"`
+	DeletedAt      *time.Time        `json:"deleted_at,omitempty"`
+	Version        int               `json:"version"`
+	Labels         []int64           `json:"labels"`
+	CommentCount   int               `json:"comment_count"`
+	ChecklistTotal int               `json:"checklist_total"`
+	ChecklistDone  int               `json:"checklist_done"`
+	ChecklistPct   float64           `json:"checklist_pct"`
+	TotalMinutes   int               `json:"total_minutes"`
+	BlockerIDs     []int64           `json:"blocker_ids"`
+	BlockingIDs    []int64           `json:"blocking_ids"`
+	Watchers       []string          `json:"watchers"`
+	Mentions       []string          `json:"mentions"`
+	Fields         map[string]string `json:"fields"`
+	Links          []TaskLink        `json:"links"`
+	Reactions      map[string]int    `json:"reactions"`
+	// TimeInStatus sums the seconds spent in each status. It is only filled
+	// for single-task responses.
+	TimeInStatus map[string]float64 `json:"time_in_status,omitempty"`
+}
+
+// TimeEntry records a period of work on a task; EndedAt is nil while the
+// timer is running.
+type TimeEntry struct {
+	ID        int64      `json:"id"`
+	TaskID    int64      `json:"task_id"`
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at"`
+	Note      string     `json:"note"`
+}
+
+// ChecklistItem is a single step of a task's checklist.
+type ChecklistItem struct {
+	ID        int64     `json:"id"`
+	TaskID    int64     `json:"task_id"`
+	Text      string    `json:"text"`
+	Done      bool      `json:"done"`
+	Position  int64     `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Comment is a message left on a task.
+type Comment struct {
+	ID        int64     `json:"id"`
+	TaskID    int64     `json:"task_id"`
+	Author    string    `json:"author"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TaskFilter narrows a project task listing. Zero values disable a criterion;
+// a SprintID of 0 selects the backlog (tasks outside any sprint) and a
+// MilestoneID of 0 tasks outside any milestone. Snoozed tasks are left out
+// unless IncludeSnoozed is set.
+type TaskFilter struct {
+	Assignee       *string
+	Statuses       []string
+	Priorities     []string
+	LabelIDs       []int64
+	SprintID       *int64
+	MilestoneID    *int64
+	IncludeSnoozed bool
+	// Sort names a column to order by, prefixed with "-" for descending;
+	// empty keeps board order.
+	Sort string
+}
+
+// Sprint is a time-boxed iteration of a project.
+type Sprint struct {
+	ID        int64      `json:"id"`
+	ProjectID int64      `json:"project_id"`
+	Name      string     `json:"name"`
+	Goal      string     `json:"goal"`
+	StartsAt  *time.Time `json:"starts_at"`
+	EndsAt    *time.Time `json:"ends_at"`
+	Status    string     `json:"status"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// SprintVelocity compares the story points planned for a sprint with those
+// delivered.
+type SprintVelocity struct {
+	SprintID  int64  `json:"sprint_id"`
+	Name      string `json:"name"`
+	Planned   int    `json:"planned"`
+	Completed int    `json:"completed"`
+}
+
+// BurndownPoint is one day of a sprint burndown chart with the story points
+// and tasks still open at the end of that day.
+type BurndownPoint struct {
+	Date            string  `json:"date"`
+	RemainingPoints int     `json:"remaining_points"`
+	RemainingTasks  int     `json:"remaining_tasks"`
+	Ideal           float64 `json:"ideal"`
+}
+
+// SprintBurndown is the burndown of a sprint up to today, with the sprint
+// dates so the chart's axis can be drawn from the one response.
+type SprintBurndown struct {
+	SprintID int64           `json:"sprint_id"`
+	StartsAt time.Time       `json:"starts_at"`
+	EndsAt   time.Time       `json:"ends_at"`
+	Days     []BurndownPoint `json:"days"`
+}
+
+// Milestone is a checkpoint of a project that tasks can be attached to.
+type Milestone struct {
+	ID          int64      `json:"id"`
+	ProjectID   int64      `json:"project_id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	DueDate     *time.Time `json:"due_date"`
+	Status      string     `json:"status"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// MilestoneProgress counts the live tasks of a milestone and how many of them
+// are completed; Pct is 0 for a milestone without tasks.
+type MilestoneProgress struct {
+	TotalTasks int     `json:"total_tasks"`
+	Done       int     `json:"done"`
+	Pct        float64 `json:"pct"`
+}
+
+// ValidMilestoneStatuses enumerates the states of a milestone.
+var ValidMilestoneStatuses = map[string]struct{}{
+	"open":   {},
+	"closed": {},
+}
+
+// ValidSprintStatuses enumerates the lifecycle states of a sprint.
+var ValidSprintStatuses = map[string]struct{}{
+	"planning": {},
+	"active":   {},
+	"closed":   {},
+}
+
+// TaskRevision is a previous title and description of a task.
+type TaskRevision struct {
+	ID          int64     `json:"id"`
+	TaskID      int64     `json:"task_id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// StatusLogEntry is one stay of a task in a status column. LeftAt is nil for
+// the column the task is in now, whose duration runs up to the request time.
+type StatusLogEntry struct {
+	Status          string     `json:"status"`
+	EnteredAt       time.Time  `json:"entered_at"`
+	LeftAt          *time.Time `json:"left_at"`
+	DurationSeconds float64    `json:"duration_seconds"`
+}
+
+// AuditEntry records one write request. EntityID and ActorID are nil when the
+// request names no entity or comes from no user; Payload holds the request
+// body with secrets redacted, or null.
+type AuditEntry struct {
+	ID         int64           `json:"id"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   *int64          `json:"entity_id"`
+	ActorID    *int64          `json:"actor_id"`
+	ActorIP    string          `json:"actor_ip"`
+	Payload    json.RawMessage `json:"payload"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// AuditFilter narrows the audit log; zero fields match everything.
+type AuditFilter struct {
+	EntityType string
+	EntityID   int64
+	ActorID    int64
+	Limit      int
+}
+
+// ActivityEntry records one field change of a task.
+type ActivityEntry struct {
+	ID        int64     `json:"id"`
+	TaskID    int64     `json:"task_id"`
+	ProjectID int64     `json:"project_id,omitempty"`
+	TaskTitle string    `json:"task_title,omitempty"`
+	Field     string    `json:"field"`
+	OldValue  string    `json:"old_value"`
+	NewValue  string    `json:"new_value"`
+	ChangedBy string    `json:"changed_by"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// ProjectEvent is one entry of a project's activity feed. Type is one of
+// task.created, task.renamed, task.moved, task.completed, task.deleted,
+// task.restored, project.renamed or project.recolored. TaskID and TaskTitle
+// describe the task at the time of the event, so they outlive it, and are
+// empty for project events. OldValue and NewValue hold the changed title,
+// status, name or color.
+type ProjectEvent struct {
+	ID        int64     `json:"id"`
+	ProjectID int64     `json:"project_id"`
+	Type      string    `json:"type"`
+	TaskID    *int64    `json:"task_id"`
+	TaskTitle string    `json:"task_title"`
+	OldValue  string    `json:"old_value"`
+	NewValue  string    `json:"new_value"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Webhook delivers board events to an external URL. A nil ProjectID
+// subscribes to every project and empty Events to every event.
+type Webhook struct {
+	ID        int64     `json:"id"`
+	ProjectID *int64    `json:"project_id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"-"`
+	HasSecret bool      `json:"has_secret"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Subscribed reports whether the webhook wants the given event.
+func (w Webhook) Subscribed(event string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidWebhookEvents enumerates the events a webhook can subscribe to.
+var ValidWebhookEvents = map[string]struct{}{
+	"task.created":    {},
+	"task.updated":    {},
+	"task.deleted":    {},
+	"project.created": {},
+	"project.updated": {},
+	"project.deleted": {},
+}
+
+// WebhookEvent is the JSON body POSTed to webhooks.
+type WebhookEvent struct {
+	Event     string    `json:"event"`
+	ProjectID int64     `json:"project_id"`
+	Data      any       `json:"data"`
+	SentAt    time.Time `json:"sent_at"`
+}
+
+// WebhookDelivery tracks the delivery of one event to a webhook. A non-nil
+// NextRetryAt means another attempt is scheduled.
+type WebhookDelivery struct {
+	ID          int64      `json:"id"`
+	WebhookID   int64      `json:"webhook_id"`
+	Event       string     `json:"event"`
+	StatusCode  int        `json:"status_code"`
+	Error       string     `json:"error"`
+	RetryCount  int        `json:"retry_count"`
+	NextRetryAt *time.Time `json:"next_retry_at"`
+	// DeliveredAt is the time of the latest attempt.
+	DeliveredAt time.Time `json:"delivered_at"`
+}
+
+// UndoResult describes an operation reversed by undo along with the tasks or
+// project it touched.
+type UndoResult struct {
+	Operation   string    `json:"operation"`
+	EntityID    int64     `json:"entity_id"`
+	Description string    `json:"description"`
+	PerformedAt time.Time `json:"performed_at"`
+	TaskIDs     []int64   `json:"-"`
+	Tasks       []Task    `json:"tasks,omitempty"`
+	Project     *Project  `json:"project,omitempty"`
+}
+
+// APIKey is a long-lived credential for integrations. Only a hash of the key
+// is stored; the plaintext is shown once when the key is created. UserID is
+// the user the key acts as, nil for keys without an owner.
+type APIKey struct {
+	ID         int64      `json:"id"`
+	UserID     *int64     `json:"user_id"`
+	Name       string     `json:"name"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+}
+
+// Label is a colored tag scoped to a project that can be attached to tasks.
+type Label struct {
 	ID        int64     `json:"id"`
+	ProjectID int64     `json:"project_id"`
 	Name      string    `json:"name"`
 	Color     string    `json:"color"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
-// Task represents a single card in the scrum board.
-type Task struct {
+// TaskTemplate is a reusable blueprint for creating tasks. Templates without
+// a project can be instantiated in any project.
+type TaskTemplate struct {
 	ID          int64     `json:"id"`
-	ProjectID   int64     `json:"project_id"`
+	ProjectID   *int64    `json:"project_id"`
 	Title       string    `json:"title"`
 	Description string    `json:"description"`
 	Status      string    `json:"status"`
-	Position    int64     `json:"position"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
-// ValidTaskStatuses enumerates the statuses supported by the board columns.
+// FilterDefinition is the stored form of a saved filter. Its fields mirror
+// the query parameters of the project task list.
+type FilterDefinition struct {
+	Statuses       []string `json:"statuses,omitempty"`
+	Assignee       *string  `json:"assignee,omitempty"`
+	LabelIDs       []int64  `json:"label_ids,omitempty"`
+	SprintID       *int64   `json:"sprint_id,omitempty"`
+	IncludeSnoozed bool     `json:"include_snoozed,omitempty"`
+	Sort           string   `json:"sort,omitempty"`
+}
+
+// TaskFilter converts the definition into the filter used to list tasks.
+func (d FilterDefinition) TaskFilter() TaskFilter {
+	return TaskFilter{
+		Assignee:       d.Assignee,
+		Statuses:       d.Statuses,
+		LabelIDs:       d.LabelIDs,
+		SprintID:       d.SprintID,
+		IncludeSnoozed: d.IncludeSnoozed,
+		Sort:           d.Sort,
+	}
+}
+
+// SavedFilter is a named task filter. A nil ProjectID makes it usable in
+// every project.
+type SavedFilter struct {
+	ID         int64            `json:"id"`
+	ProjectID  *int64           `json:"project_id"`
+	Name       string           `json:"name"`
+	Definition FilterDefinition `json:"definition"`
+	CreatedAt  time.Time        `json:"created_at"`
+	UpdatedAt  time.Time        `json:"updated_at"`
+}
+
+// ProjectExportVersion is the format version of project exports. It changes
+// whenever the layout of ProjectExport does, and imports of other versions are
+// rejected.
+const ProjectExportVersion = 1
+
+// ProjectExport is a self-contained copy of a project and its tasks for backup
+// and restore. IDs are those of the exporting database and are remapped on
+// import. Exports without statuses import with the default ones.
+type ProjectExport struct {
+	Version    int            `json:"version"`
+	ExportedAt time.Time      `json:"exported_at"`
+	Project    Project        `json:"project"`
+	Statuses   []TaskStatus   `json:"statuses"`
+	Labels     []Label        `json:"labels"`
+	Tasks      []ExportedTask `json:"tasks"`
+}
+
+// ExportedTask is a task in a project export with its comments and checklist.
+type ExportedTask struct {
+	Task
+	Comments  []Comment       `json:"comments"`
+	Checklist []ChecklistItem `json:"checklist"`
+}
+
+// TaskImportRow is one task read from an import file. Row is its row number
+// in the file, counting the header as row 1. Err is set when the row could not
+// be read into a task; it is then reported like a validation failure.
+type TaskImportRow struct {
+	Row  int
+	Task Task
+	Err  error
+}
+
+// TaskImportError explains why a row of an import file was skipped.
+type TaskImportError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+// TaskImportResult summarizes a bulk task import.
+type TaskImportResult struct {
+	Imported int               `json:"imported"`
+	Skipped  int               `json:"skipped"`
+	Errors   []TaskImportError `json:"errors"`
+}
+
+// TaskSearchResult is a task matched by search together with its project.
+// Snippet is HTML-escaped text around the match with the matched words
+// wrapped in <mark>.
+type TaskSearchResult struct {
+	Task
+	ProjectName  string `json:"project_name"`
+	ProjectColor string `json:"project_color"`
+	Snippet      string `json:"snippet"`
+}
+
+// GlobalTaskFilter narrows the cross-project task list. Zero values match
+// everything; Limit and Offset page through the result.
+type GlobalTaskFilter struct {
+	Statuses       []string
+	ProjectID      *int64
+	Query          string
+	IncludeSnoozed bool
+	Limit          int
+	Offset         int
+}
+
+// ProjectTask is a task annotated with the project it belongs to.
+type ProjectTask struct {
+	Task
+	ProjectName  string `json:"project_name"`
+	ProjectColor string `json:"project_color"`
+}
+
+// OverdueTask is a task past its due date with the whole days since then.
+type OverdueTask struct {
+	ProjectTask
+	DaysOverdue int `json:"days_overdue"`
+}
+
+// ProjectStats summarizes task counts per board column for a project.
+type ProjectStats struct {
+	ProjectID     int64   `json:"project_id"`
+	ProjectName   string  `json:"project_name"`
+	Todo          int64   `json:"todo"`
+	InProgress    int64   `json:"in_progress"`
+	Done          int64   `json:"done"`
+	Total         int64   `json:"total"`
+	CompletionPct float64 `json:"completion_pct"`
+}
+
+// ProjectOverview extends the column counts of a project with the figures of
+// its dashboard. Open counts tasks not in a terminal status;
+// OldestOpenAgeDays is nil when there are none. The recent counts cover the
+// last seven days.
+type ProjectOverview struct {
+	ProjectStats
+	Open               int64    `json:"open"`
+	OldestOpenAgeDays  *float64 `json:"oldest_open_age_days"`
+	CreatedLast7Days   int64    `json:"created_last_7_days"`
+	CompletedLast7Days int64    `json:"completed_last_7_days"`
+}
+
+// Add accounts count tasks with the given status and refreshes the totals.
+func (ps *ProjectStats) Add(status string, count int64) {
+	switch status {
+	case "todo":
+		ps.Todo += count
+	case "in_progress":
+		ps.InProgress += count
+	case "done":
+		ps.Done += count
+	}
+	ps.Total += count
+	if ps.Total > 0 {
+		ps.CompletionPct = float64(ps.Done) * 100 / float64(ps.Total)
+	}
+}
+
+// AdminStats summarizes the whole installation for monitoring. Trashed
+// projects and tasks are not counted.
+type AdminStats struct {
+	Projects          int64            `json:"projects"`
+	Tasks             int64            `json:"tasks"`
+	TasksByStatus     map[string]int64 `json:"tasks_by_status"`
+	DatabaseSizeBytes int64            `json:"database_size_bytes"`
+	SchemaVersion     int              `json:"schema_version"`
+}
+
+// AccuracyRow compares a task's estimate with how long it actually took.
+// ElapsedMinutes runs from first entering in_progress to completion, or up to
+// now for tasks still in flight; it is nil for tasks never started.
+type AccuracyRow struct {
+	TaskID         int64      `json:"task_id"`
+	Number         int64      `json:"number"`
+	Title          string     `json:"title"`
+	Status         string     `json:"status"`
+	StoryPoints    int        `json:"story_points"`
+	StartedAt      *time.Time `json:"started_at"`
+	CompletedAt    *time.Time `json:"completed_at"`
+	ElapsedMinutes *int       `json:"elapsed_minutes"`
+	TrackedMinutes int        `json:"tracked_minutes"`
+}
+
+// AccuracyTotals aggregates a group of AccuracyRows.
+type AccuracyTotals struct {
+	Tasks           int     `json:"tasks"`
+	StoryPoints     int     `json:"story_points"`
+	ElapsedMinutes  int     `json:"elapsed_minutes"`
+	TrackedMinutes  int     `json:"tracked_minutes"`
+	MinutesPerPoint float64 `json:"minutes_per_point"`
+}
+
+// Add accounts one row and refreshes the elapsed minutes per story point.
+func (t *AccuracyTotals) Add(r AccuracyRow) {
+	t.Tasks++
+	t.StoryPoints += r.StoryPoints
+	if r.ElapsedMinutes != nil {
+		t.ElapsedMinutes += *r.ElapsedMinutes
+	}
+	t.TrackedMinutes += r.TrackedMinutes
+	if t.StoryPoints > 0 {
+		t.MinutesPerPoint = float64(t.ElapsedMinutes) / float64(t.StoryPoints)
+	}
+}
+
+// AccuracyReport is the estimate versus actual report of a project.
+type AccuracyReport struct {
+	ProjectID       int64          `json:"project_id"`
+	Completed       []AccuracyRow  `json:"completed"`
+	CompletedTotals AccuracyTotals `json:"completed_totals"`
+	InFlight        []AccuracyRow  `json:"in_flight"`
+	InFlightTotals  AccuracyTotals `json:"in_flight_totals"`
+}
+
+// CycleTimeRow is how long one completed task took, in minutes. Lead time
+// runs from creation and cycle time from first entering in_progress to the
+// final completion; CycleMinutes is nil for tasks that never were in
+// progress.
+type CycleTimeRow struct {
+	TaskID       int64      `json:"task_id"`
+	Number       int64      `json:"number"`
+	Title        string     `json:"title"`
+	CreatedAt    time.Time  `json:"created_at"`
+	StartedAt    *time.Time `json:"started_at"`
+	CompletedAt  time.Time  `json:"completed_at"`
+	LeadMinutes  float64    `json:"lead_minutes"`
+	CycleMinutes *float64   `json:"cycle_minutes"`
+}
+
+// DurationSummary describes a set of durations in minutes. The figures are 0
+// when Count is.
+type DurationSummary struct {
+	Count  int     `json:"count"`
+	Mean   float64 `json:"mean"`
+	Median float64 `json:"median"`
+	P85    float64 `json:"p85"`
+}
+
+// CycleTimeReport summarizes the lead and cycle times of the tasks of a
+// project completed since Since.
+type CycleTimeReport struct {
+	ProjectID int64           `json:"project_id"`
+	Since     time.Time       `json:"since"`
+	LeadTime  DurationSummary `json:"lead_time"`
+	CycleTime DurationSummary `json:"cycle_time"`
+	Tasks     []CycleTimeRow  `json:"tasks"`
+}
+
+// VelocityPeriod is the work completed in one sprint or calendar week.
+// RollingPoints and RollingTasks average the period with the ones just
+// before it. SprintID is nil for weeks.
+type VelocityPeriod struct {
+	SprintID        *int64    `json:"sprint_id"`
+	Label           string    `json:"label"`
+	Start           time.Time `json:"start"`
+	End             time.Time `json:"end"`
+	CompletedPoints int       `json:"completed_points"`
+	CompletedTasks  int       `json:"completed_tasks"`
+	RollingPoints   float64   `json:"rolling_points"`
+	RollingTasks    float64   `json:"rolling_tasks"`
+}
+
+// VelocityReport lists the completed work of a project's recent periods in
+// chronological order. Basis is "sprint" when the project has closed sprints
+// and "week" otherwise.
+type VelocityReport struct {
+	ProjectID     int64            `json:"project_id"`
+	Basis         string           `json:"basis"`
+	RollingWindow int              `json:"rolling_window"`
+	Periods       []VelocityPeriod `json:"periods"`
+}
+
+// Trash groups soft-deleted projects and tasks awaiting restore or purge.
+type Trash struct {
+	Projects []Project `json:"projects"`
+	Tasks    []Task    `json:"tasks"`
+}
+
+// User is an account allowed to work with the board.
+type User struct {
+	ID          int64      `json:"id"`
+	Username    string     `json:"username"`
+	Email       string     `json:"email"`
+	Role        string     `json:"role"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	LastLoginAt *time.Time `json:"last_login_at"`
+}
+
+// Project roles, from least to most privileged: viewers may read a project,
+// members may also change its tasks and admins may manage the project itself.
+const (
+	ProjectRoleViewer = "viewer"
+	ProjectRoleMember = "member"
+	ProjectRoleAdmin  = "admin"
+)
+
+// ValidProjectRoles ranks the project roles; a higher rank grants more.
+var ValidProjectRoles = map[string]int{
+	ProjectRoleViewer: 1,
+	ProjectRoleMember: 2,
+	ProjectRoleAdmin:  3,
+}
+
+// ProjectMember is the role of a user in a project.
+type ProjectMember struct {
+	ProjectID int64     `json:"project_id"`
+	UserID    int64     `json:"user_id"`
+	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ValidUserRoles enumerates the roles a user may hold.
+var ValidUserRoles = map[string]struct{}{
+	"admin":  {},
+	"member": {},
+}
+
+// SetupStatus reports whether the first-run wizard still has to be completed.
+type SetupStatus struct {
+	Required bool  `json:"setup_required"`
+	Users    int64 `json:"users"`
+	Projects int64 `json:"projects"`
+}
+
+// DefaultTaskPriority is assigned to tasks created without a priority.
+const DefaultTaskPriority = "medium"
+
+// ValidTaskPriorities maps each task priority to its rank; higher is more
+// urgent.
+var ValidTaskPriorities = map[string]int{
+	"low":    1,
+	"medium": 2,
+	"high":   3,
+	"urgent": 4,
+}
+
+// ValidTaskStatuses enumerates the statuses every project starts with.
+//
+// Deprecated: statuses are defined per project; use the store's
+// ListStatuses. The map remains as the fallback for statuses not tied to a
+// project.
 var ValidTaskStatuses = map[string]struct{}{
 	"todo":        {},
 	"in_progress": {},
@@ -0,0 +1,147 @@
+// Package quickadd parses the inline syntax of the quick-add box, for example
+// `Fix login bug #Backend !high @friday`.
+//
+// Words prefixed with # name the project, ! the priority and @ the due date;
+// everything else forms the title. Double quotes group words into a single
+// token, so `#"Web App"` and `@"next friday 17:00"` work, and a quoted word
+// without a prefix such as `"#1"` is kept literally in the title.
+package quickadd
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Token is one whitespace separated word of the input. Prefix is '#', '!' or
+// '@' for directives and 0 for title text.
+type Token struct {
+	Prefix rune
+	Text   string
+	Quoted bool
+}
+
+// Result is what Parse extracted from the input.
+type Result struct {
+	Title    string `json:"title"`
+	Project  string `json:"project"`
+	Priority string `json:"priority"`
+	Due      string `json:"due"`
+}
+
+const quote = '"'
+
+// Tokenize splits input into tokens, honoring double quotes. A backslash
+// escapes a quote inside a quoted section.
+func Tokenize(input string) ([]Token, error) {
+	var (
+		tokens []Token
+		text   strings.Builder
+		tok    Token
+		inWord bool
+	)
+	flush := func() {
+		if inWord {
+			tok.Text = text.String()
+			tokens = append(tokens, tok)
+		}
+		text.Reset()
+		tok = Token{}
+		inWord = false
+	}
+
+	for i := 0; i < len(input); {
+		r, size := utf8.DecodeRuneInString(input[i:])
+		if r == utf8.RuneError && size == 1 {
+			return nil, fmt.Errorf("input is not valid UTF-8")
+		}
+		switch {
+		case unicode.IsSpace(r):
+			flush()
+			i += size
+		case r == quote:
+			end, quoted, err := readQuoted(input, i+size)
+			if err != nil {
+				return nil, err
+			}
+			inWord = true
+			tok.Quoted = true
+			text.WriteString(quoted)
+			i = end
+		case !inWord && (r == '#' || r == '!' || r == '@'):
+			inWord = true
+			tok.Prefix = r
+			i += size
+		default:
+			inWord = true
+			text.WriteRune(r)
+			i += size
+		}
+	}
+	flush()
+	return tokens, nil
+}
+
+// readQuoted reads up to the closing quote starting at offset i and returns
+// the offset just past it.
+func readQuoted(input string, i int) (int, string, error) {
+	var b strings.Builder
+	for i < len(input) {
+		r, size := utf8.DecodeRuneInString(input[i:])
+		switch {
+		case r == '\\' && i+size < len(input) && input[i+size] == quote:
+			b.WriteRune(quote)
+			i += size + 1
+		case r == quote:
+			return i + size, b.String(), nil
+		default:
+			b.WriteRune(r)
+			i += size
+		}
+	}
+	return 0, "", fmt.Errorf("unterminated quote")
+}
+
+// Parse tokenizes input and sorts the tokens into title, project, priority and
+// due date. Each directive may appear at most once.
+func Parse(input string) (Result, error) {
+	tokens, err := Tokenize(input)
+	if err != nil {
+		return Result{}, err
+	}
+
+	var (
+		res   Result
+		title []string
+	)
+	for _, t := range tokens {
+		if t.Prefix != 0 && t.Text == "" {
+			// A lone "#" or "!" is ordinary text.
+			title = append(title, string(t.Prefix))
+			continue
+		}
+		var dest *string
+		switch t.Prefix {
+		case '#':
+			dest = &res.Project
+		case '!':
+			dest = &res.Priority
+		case '@':
+			dest = &res.Due
+		default:
+			title = append(title, t.Text)
+			continue
+		}
+		if *dest != "" {
+			return Result{}, fmt.Errorf("%c given more than once", t.Prefix)
+		}
+		*dest = strings.TrimSpace(t.Text)
+	}
+	res.Title = strings.Join(title, " ")
+	res.Priority = strings.ToLower(res.Priority)
+	if res.Title == "" {
+		return Result{}, fmt.Errorf("title is required")
+	}
+	return res, nil
+}
@@ -0,0 +1,71 @@
+package quickadd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []Token
+	}{
+		{"", nil},
+		{"  fix   bug ", []Token{{Text: "fix"}, {Text: "bug"}}},
+		{"#Backend !high @friday", []Token{{Prefix: '#', Text: "Backend"}, {Prefix: '!', Text: "high"}, {Prefix: '@', Text: "friday"}}},
+		{`#"Web App"`, []Token{{Prefix: '#', Text: "Web App", Quoted: true}}},
+		{`"#1" a#b`, []Token{{Text: "#1", Quoted: true}, {Text: "a#b"}}},
+		{`"say \"hi\""`, []Token{{Text: `say "hi"`, Quoted: true}}},
+		{`pre"quoted part"post`, []Token{{Text: "prequoted partpost", Quoted: true}}},
+		{"日本語　タスク", []Token{{Text: "日本語"}, {Text: "タスク"}}},
+	}
+	for _, tt := range tests {
+		got, err := Tokenize(tt.input)
+		if err != nil {
+			t.Errorf("Tokenize(%q): %v", tt.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Tokenize(%q) = %+v, want %+v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestTokenizeErrors(t *testing.T) {
+	for _, input := range []string{`"unterminated`, `#"Web App`, `ends with \"`, "bad \xff byte"} {
+		if _, err := Tokenize(input); err == nil {
+			t.Errorf("Tokenize(%q) succeeded, want an error", input)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Result
+	}{
+		{"Fix login bug #Backend !high @friday", Result{Title: "Fix login bug", Project: "Backend", Priority: "high", Due: "friday"}},
+		{`@"next friday 17:00" Ship it #"Web App"`, Result{Title: "Ship it", Project: "Web App", Due: "next friday 17:00"}},
+		{`Fix "#1" crash`, Result{Title: "Fix #1 crash"}},
+		{"a # b !", Result{Title: "a # b !"}},
+		{"Überprüfe die tâche 日本語 #Équipe !HIGH", Result{Title: "Überprüfe die tâche 日本語", Project: "Équipe", Priority: "high"}},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.input)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, input := range []string{"", "#Backend !high", "a #one #two", "a !low !high", `a "open`} {
+		if _, err := Parse(input); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", input)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"todo/internal/storage"
+)
+
+// captureChangedBy attributes changes made by the request to the name given
+// in the X-Changed-By header.
+func (s *Server) captureChangedBy(c *gin.Context) {
+	if name := c.GetHeader("X-Changed-By"); name != "" {
+		c.Request = c.Request.WithContext(storage.WithChangedBy(c.Request.Context(), name))
+	}
+	c.Next()
+}
+
+// handleListTaskActivity returns the change history of a task.
+func (s *Server) handleListTaskActivity(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	entries, err := s.store.ListTaskActivity(c.Request.Context(), id)
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"activity": entries})
+}
+
+// handleListProjectActivity returns the activity feed of a project, newest
+// first. ?since (RFC 3339) drops older events and ?before takes the
+// next_before of the previous page.
+func (s *Server) handleListProjectActivity(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	limit, ok := s.activityLimit(c)
+	if !ok {
+		return
+	}
+	var since *time.Time
+	if raw := c.Query("since"); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			s.respondError(c, http.StatusBadRequest, fmt.Errorf("since must be an RFC 3339 timestamp"))
+			return
+		}
+		since = &t
+	}
+	var before int64
+	if raw := c.Query("before"); raw != "" {
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || n < 1 {
+			s.respondError(c, http.StatusBadRequest, fmt.Errorf("before must be a positive integer"))
+			return
+		}
+		before = n
+	}
+
+	events, err := s.store.ListProjectEvents(c.Request.Context(), id, since, before, limit)
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	var next *int64
+	if len(events) == limit {
+		next = &events[len(events)-1].ID
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"activity": events, "next_before": next})
+}
+
+// handleListActivity returns the recent activity across all projects.
+func (s *Server) handleListActivity(c *gin.Context) {
+	limit, ok := s.activityLimit(c)
+	if !ok {
+		return
+	}
+	entries, err := s.store.ListActivity(c.Request.Context(), limit)
+	if err != nil {
+		s.respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"activity": entries})
+}
+
+// activityLimit parses ?limit (default 50), capped at the configured maximum.
+func (s *Server) activityLimit(c *gin.Context) (int, bool) {
+	limit := 50
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			s.respondError(c, http.StatusBadRequest, fmt.Errorf("limit must be a positive integer"))
+			return 0, false
+		}
+		limit = n
+	}
+	if limit > s.maxActivity {
+		limit = s.maxActivity
+	}
+	return limit, true
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handleGetDBVersion reports the highest applied schema migration.
+func (s *Server) handleGetDBVersion(c *gin.Context) {
+	version, err := s.store.MigrationVersion(c.Request.Context())
+	if err != nil {
+		s.respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"migration_version": version})
+}
+
+// handleGetDBStats reports connection pool usage and the database file size.
+func (s *Server) handleGetDBStats(c *gin.Context) {
+	size, err := s.store.DatabaseSize(c.Request.Context())
+	if err != nil {
+		s.respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	stats := s.store.DBStats()
+	respondSuccess(c, http.StatusOK, gin.H{
+		"open_connections":      stats.OpenConnections,
+		"in_use":                stats.InUse,
+		"idle":                  stats.Idle,
+		"wait_count":            stats.WaitCount,
+		"wait_duration_seconds": stats.WaitDuration.Seconds(),
+		"size_bytes":            size,
+	})
+}
+
+// handleGetAdminStats returns installation-wide counts, the database file
+// size and the schema version.
+func (s *Server) handleGetAdminStats(c *gin.Context) {
+	stats, err := s.store.GetAdminStats(c.Request.Context())
+	if err != nil {
+		s.respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"stats": stats})
+}
+
+// handleVacuumDB compacts the database and reports how long it took. Other
+// queries wait for the vacuum to finish.
+func (s *Server) handleVacuumDB(c *gin.Context) {
+	start := time.Now()
+	if err := s.store.VacuumDB(c.Request.Context()); err != nil {
+		s.respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"elapsed_seconds": time.Since(start).Seconds()})
+}
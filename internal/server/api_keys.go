@@ -0,0 +1,78 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"todo/internal/storage"
+)
+
+type apiKeyRequest struct {
+	Name      string     `json:"name"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// keyOwner returns the user whose keys the caller may manage, or nil when
+// the caller may manage every key: admins and anyone while authentication
+// is disabled.
+func (s *Server) keyOwner(c *gin.Context) *int64 {
+	if s.jwtSecret == "" {
+		return nil
+	}
+	id, role, _ := currentUser(c)
+	if role == "admin" {
+		return nil
+	}
+	return &id
+}
+
+// handleListAPIKeys returns the metadata of the API keys the caller may
+// manage; hashes and plaintexts are never exposed.
+func (s *Server) handleListAPIKeys(c *gin.Context) {
+	keys, err := s.store.ListAPIKeys(c.Request.Context(), s.keyOwner(c))
+	if err != nil {
+		s.respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"api_keys": keys})
+}
+
+// handleCreateAPIKey issues a new key acting as the caller. The plaintext is
+// only part of this response.
+func (s *Server) handleCreateAPIKey(c *gin.Context) {
+	var req apiKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	var owner *int64
+	if id, _, ok := currentUser(c); ok {
+		owner = &id
+	}
+	key, plaintext, err := s.store.CreateAPIKey(c.Request.Context(), owner, req.Name, req.ExpiresAt)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, storage.ErrValidation) {
+			status = http.StatusBadRequest
+		}
+		s.respondError(c, status, err)
+		return
+	}
+	respondSuccess(c, http.StatusCreated, gin.H{"api_key": key, "key": plaintext})
+}
+
+// handleRevokeAPIKey deletes an API key the caller may manage.
+func (s *Server) handleRevokeAPIKey(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	if err := s.store.RevokeAPIKey(c.Request.Context(), id, s.keyOwner(c)); err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"status": "revoked"})
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"todo/internal/models"
+)
+
+// createKey issues an API key as the caller behind auth and returns its
+// plaintext.
+func createKey(t *testing.T, srv *Server, auth string) string {
+	t.Helper()
+	w := do(t, srv, http.MethodPost, "/api/api-keys", `{"name":"ci"}`, "Authorization", auth)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create key: %d %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Key string `json:"key"`
+	}
+	decode(t, w, &resp)
+	return resp.Key
+}
+
+func TestAPIKeyActsAsOwner(t *testing.T) {
+	srv, store := newTestServer(t, Options{JWTSecret: testSecret})
+	if _, err := store.CreateUser(context.Background(), "bob", testPassword, "", "member"); err != nil {
+		t.Fatal(err)
+	}
+	adminKey := createKey(t, srv, login(t, srv, testAdmin, testPassword))
+	bobKey := createKey(t, srv, login(t, srv, "bob", testPassword))
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		key    string
+		want   int
+	}{
+		{"member key outside project", http.MethodGet, "/api/projects/1/tasks", bobKey, http.StatusForbidden},
+		{"member key deletes project", http.MethodDelete, "/api/projects/1", bobKey, http.StatusForbidden},
+		{"member key admin route", http.MethodGet, "/api/users", bobKey, http.StatusForbidden},
+		{"admin key project", http.MethodGet, "/api/projects/1/tasks", adminKey, http.StatusOK},
+		{"admin key admin route", http.MethodGet, "/api/users", adminKey, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := do(t, srv, tt.method, tt.path, "", "X-API-Key", tt.key)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAPIKeysAreManagedByTheirOwner(t *testing.T) {
+	srv, store := newTestServer(t, Options{JWTSecret: testSecret})
+	if _, err := store.CreateUser(context.Background(), "bob", testPassword, "", "member"); err != nil {
+		t.Fatal(err)
+	}
+	admin := login(t, srv, testAdmin, testPassword)
+	bob := login(t, srv, "bob", testPassword)
+	createKey(t, srv, admin)
+	createKey(t, srv, bob)
+
+	var list struct {
+		APIKeys []models.APIKey `json:"api_keys"`
+	}
+	decode(t, do(t, srv, http.MethodGet, "/api/api-keys", "", "Authorization", bob), &list)
+	if len(list.APIKeys) != 1 || list.APIKeys[0].UserID == nil || *list.APIKeys[0].UserID != 2 {
+		t.Fatalf("bob lists %+v, want only his key", list.APIKeys)
+	}
+	bobKeyID := list.APIKeys[0].ID
+
+	decode(t, do(t, srv, http.MethodGet, "/api/api-keys", "", "Authorization", admin), &list)
+	if len(list.APIKeys) != 2 {
+		t.Fatalf("admin lists %d keys, want 2", len(list.APIKeys))
+	}
+	adminKeyID := list.APIKeys[1].ID
+
+	if w := do(t, srv, http.MethodDelete, "/api/api-keys/"+itoa(adminKeyID), "", "Authorization", bob); w.Code != http.StatusNotFound {
+		t.Fatalf("bob revokes admin key = %d, want 404", w.Code)
+	}
+	if w := do(t, srv, http.MethodDelete, "/api/api-keys/"+itoa(bobKeyID), "", "Authorization", bob); w.Code != http.StatusOK {
+		t.Fatalf("bob revokes his key = %d, want 200: %s", w.Code, w.Body.String())
+	}
+}
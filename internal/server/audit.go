@@ -0,0 +1,158 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"todo/internal/models"
+)
+
+// maxAuditPayload bounds the request body kept in an audit entry; larger
+// bodies are recorded by size only.
+const maxAuditPayload = 64 << 10
+
+// auditRedacted are body fields never written to the audit log.
+var auditRedacted = map[string]struct{}{
+	"password": {},
+	"secret":   {},
+}
+
+// auditEntities names the entity types of route prefixes that are not a
+// plain plural.
+var auditEntities = map[string]string{
+	"statuses":     "status",
+	"time-entries": "time_entry",
+	"api-keys":     "api_key",
+	"checklist":    "checklist_item",
+}
+
+// auditWrites records every write request in the audit log before its
+// handler runs, so failed and interrupted writes are recorded too. A request
+// whose entry cannot be written is refused.
+func (s *Server) auditWrites(c *gin.Context) {
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		c.Next()
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			s.respondError(c, http.StatusRequestEntityTooLarge, fmt.Errorf("request body must be at most %d bytes", tooLarge.Limit))
+		} else {
+			s.respondError(c, http.StatusBadRequest, err)
+		}
+		c.Abort()
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	route := apiRoute(c.FullPath())
+	entry := models.AuditEntry{
+		Action:     c.Request.Method + " " + route,
+		EntityType: auditEntityType(route),
+		ActorIP:    c.ClientIP(),
+		Payload:    auditPayload(body),
+	}
+	if raw := c.Param("id"); raw != "" {
+		if id, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			entry.EntityID = &id
+		}
+	}
+	if id, _, ok := currentUser(c); ok {
+		entry.ActorID = &id
+	}
+	if err := s.store.WriteAudit(c.Request.Context(), entry); err != nil {
+		s.respondError(c, http.StatusInternalServerError, err)
+		c.Abort()
+		return
+	}
+	c.Next()
+}
+
+// auditEntityType derives the entity type from the first route segment, or
+// the second under /trash: "/projects/:id/tasks" is a project.
+func auditEntityType(route string) string {
+	segments := strings.Split(strings.Trim(route, "/"), "/")
+	if segments[0] == "trash" && len(segments) > 1 && !strings.HasPrefix(segments[1], ":") {
+		segments = segments[1:]
+	}
+	if name, ok := auditEntities[segments[0]]; ok {
+		return name
+	}
+	return strings.TrimSuffix(segments[0], "s")
+}
+
+// auditPayload returns a JSON body with its redacted fields masked. Bodies
+// that are empty or not JSON give nil.
+func auditPayload(body []byte) json.RawMessage {
+	if len(body) > maxAuditPayload {
+		return json.RawMessage(fmt.Sprintf(`{"truncated":true,"bytes":%d}`, len(body)))
+	}
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return nil
+	}
+	out, err := json.Marshal(redactAudit(v))
+	if err != nil {
+		return nil
+	}
+	return out
+}
+
+func redactAudit(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, field := range v {
+			if _, ok := auditRedacted[strings.ToLower(k)]; ok {
+				v[k] = "[redacted]"
+				continue
+			}
+			v[k] = redactAudit(field)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redactAudit(item)
+		}
+	}
+	return v
+}
+
+// handleListAudit returns audit entries, newest first, optionally narrowed by
+// ?entity_type, ?entity_id and ?actor_id. ?limit defaults to 50.
+func (s *Server) handleListAudit(c *gin.Context) {
+	limit, ok := s.activityLimit(c)
+	if !ok {
+		return
+	}
+	filter := models.AuditFilter{EntityType: c.Query("entity_type"), Limit: limit}
+	for name, dst := range map[string]*int64{"entity_id": &filter.EntityID, "actor_id": &filter.ActorID} {
+		raw := c.Query(name)
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || n < 1 {
+			s.respondError(c, http.StatusBadRequest, fmt.Errorf("%s must be a positive integer", name))
+			return
+		}
+		*dst = n
+	}
+
+	entries, err := s.store.ListAudit(c.Request.Context(), filter)
+	if err != nil {
+		s.respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"audit": entries})
+}
@@ -0,0 +1,39 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"todo/internal/models"
+)
+
+func TestDeleteProjectWritesAudit(t *testing.T) {
+	srv, _ := newTestServer(t, Options{JWTSecret: testSecret})
+	admin := login(t, srv, testAdmin, testPassword)
+
+	// The entry is written before the handler runs, so a delete that fails
+	// is recorded as well.
+	if w := do(t, srv, http.MethodDelete, "/api/projects/999", "", "Authorization", admin); w.Code != http.StatusBadRequest {
+		t.Fatalf("delete unknown project = %d, want 400", w.Code)
+	}
+	if w := do(t, srv, http.MethodDelete, "/api/projects/1", "", "Authorization", admin); w.Code != http.StatusOK {
+		t.Fatalf("delete project = %d, want 200: %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Audit []models.AuditEntry `json:"audit"`
+	}
+	decode(t, do(t, srv, http.MethodGet, "/api/admin/audit?entity_type=project", "", "Authorization", admin), &resp)
+	if len(resp.Audit) != 2 {
+		t.Fatalf("audit entries = %+v, want 2", resp.Audit)
+	}
+	for i, wantID := range []int64{1, 999} {
+		e := resp.Audit[i]
+		if e.Action != "DELETE /projects/:id" || e.EntityID == nil || *e.EntityID != wantID {
+			t.Errorf("entry %d = %+v, want DELETE of project %d", i, e, wantID)
+		}
+		if e.ActorID == nil || *e.ActorID != 1 || e.ActorIP == "" {
+			t.Errorf("entry %d actor = %v %q, want user 1 with an IP", i, e.ActorID, e.ActorIP)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+
+	"todo/internal/storage"
+)
+
+// tokenTTL is how long a login token stays valid.
+const tokenTTL = 24 * time.Hour
+
+// authClaims are the JWT claims issued by handleLogin; the subject is the
+// user id.
+type authClaims struct {
+	Role string `json:"role"`
+	jwt.RegisteredClaims
+}
+
+type loginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// handleLogin exchanges a username and password for a signed HS256 token.
+func (s *Server) handleLogin(c *gin.Context) {
+	var req loginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	user, err := s.store.Authenticate(c.Request.Context(), req.Username, req.Password)
+	if errors.Is(err, storage.ErrInvalidCredentials) {
+		s.respondError(c, http.StatusUnauthorized, err)
+		return
+	}
+	if err != nil {
+		s.respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	now := time.Now()
+	expires := now.Add(tokenTTL)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, authClaims{
+		Role: user.Role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.FormatInt(user.ID, 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expires),
+		},
+	}).SignedString([]byte(s.jwtSecret))
+	if err != nil {
+		s.respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"token": token, "expires_at": expires.UTC(), "user": user})
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"todo/internal/storage"
+)
+
+// signToken returns a bearer header for admin (id 1) signed with secret
+// and expiring at exp; a zero exp leaves the claim out.
+func signToken(t *testing.T, secret string, exp time.Time) string {
+	t.Helper()
+	claims := authClaims{Role: "admin", RegisteredClaims: jwt.RegisteredClaims{Subject: "1"}}
+	if !exp.IsZero() {
+		claims.ExpiresAt = jwt.NewNumericDate(exp)
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return "Bearer " + token
+}
+
+func TestJWTMiddleware(t *testing.T) {
+	srv, _ := newTestServer(t, Options{JWTSecret: testSecret})
+	tests := []struct {
+		name string
+		auth string
+		want int
+	}{
+		{"valid login token", login(t, srv, testAdmin, testPassword), http.StatusOK},
+		{"valid signed token", signToken(t, testSecret, time.Now().Add(time.Hour)), http.StatusOK},
+		{"missing header", "", http.StatusUnauthorized},
+		{"not bearer", "Basic YWRtaW46c2VjcmV0MTIz", http.StatusUnauthorized},
+		{"expired", signToken(t, testSecret, time.Now().Add(-time.Minute)), http.StatusUnauthorized},
+		{"no expiry", signToken(t, testSecret, time.Time{}), http.StatusUnauthorized},
+		{"wrong secret", signToken(t, "other", time.Now().Add(time.Hour)), http.StatusUnauthorized},
+		{"garbage", "Bearer not.a.token", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := do(t, srv, http.MethodGet, "/api/projects", "", "Authorization", tt.auth)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+
+	if w := do(t, srv, http.MethodGet, "/api/healthz", ""); w.Code != http.StatusOK {
+		t.Fatalf("healthz without token = %d, want 200", w.Code)
+	}
+	if w := do(t, srv, http.MethodPost, "/api/auth/login", `{"username":"admin","password":"wrong"}`); w.Code != http.StatusUnauthorized {
+		t.Fatalf("login with a wrong password = %d, want 401", w.Code)
+	}
+}
+
+func TestJWTUsesStoredUser(t *testing.T) {
+	srv, store := newTestServer(t, Options{JWTSecret: testSecret})
+	ctx := context.Background()
+	bob, err := store.CreateUser(ctx, "bob", testPassword, "", "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := login(t, srv, "bob", testPassword)
+	if w := do(t, srv, http.MethodGet, "/api/users", "", "Authorization", token); w.Code != http.StatusOK {
+		t.Fatalf("admin lists users = %d, want 200", w.Code)
+	}
+
+	member := "member"
+	if _, err := store.UpdateUser(ctx, bob.ID, storage.UserUpdate{Role: &member}); err != nil {
+		t.Fatal(err)
+	}
+	if w := do(t, srv, http.MethodGet, "/api/users", "", "Authorization", token); w.Code != http.StatusForbidden {
+		t.Fatalf("demoted user lists users = %d, want 403", w.Code)
+	}
+
+	if err := store.DeleteUser(ctx, bob.ID); err != nil {
+		t.Fatal(err)
+	}
+	if w := do(t, srv, http.MethodGet, "/api/projects", "", "Authorization", token); w.Code != http.StatusUnauthorized {
+		t.Fatalf("deleted user = %d, want 401", w.Code)
+	}
+}
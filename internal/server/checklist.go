@@ -0,0 +1,106 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type checklistRequest struct {
+	Text *string `json:"text"`
+	Done *bool   `json:"done"`
+}
+
+type checklistOrderRequest struct {
+	IDs []int64 `json:"ids"`
+}
+
+// handleListChecklist returns the checklist of a task.
+func (s *Server) handleListChecklist(c *gin.Context) {
+	taskID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	items, err := s.store.ListChecklistItems(c.Request.Context(), taskID)
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"items": items})
+}
+
+// handleCreateChecklistItem appends an item to a task checklist.
+func (s *Server) handleCreateChecklistItem(c *gin.Context) {
+	taskID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req checklistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	item, err := s.store.CreateChecklistItem(c.Request.Context(), taskID, getString(req.Text))
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	respondSuccess(c, http.StatusCreated, gin.H{"item": item})
+}
+
+// handleReorderChecklist sets the order of all items of a task checklist.
+func (s *Server) handleReorderChecklist(c *gin.Context) {
+	taskID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req checklistOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	items, err := s.store.ReorderChecklistItems(c.Request.Context(), taskID, req.IDs)
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"items": items})
+}
+
+// handleUpdateChecklistItem renames or toggles a checklist item.
+func (s *Server) handleUpdateChecklistItem(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req checklistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	item, err := s.store.UpdateChecklistItem(c.Request.Context(), id, req.Text, req.Done)
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"item": item})
+}
+
+// handleDeleteChecklistItem removes a checklist item.
+func (s *Server) handleDeleteChecklistItem(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	if err := s.store.DeleteChecklistItem(c.Request.Context(), id); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"status": "deleted"})
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"todo/internal/storage"
+)
+
+// handleGetBoard returns a project and its tasks grouped by column, which is
+// everything the board needs on load.
+func (s *Server) handleGetBoard(c *gin.Context) {
+	projectID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	board, err := s.store.GetBoard(c.Request.Context(), projectID)
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, board)
+}
+
+// handleGetTaskCounts returns how many tasks each column of a project holds.
+func (s *Server) handleGetTaskCounts(c *gin.Context) {
+	projectID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	counts, err := s.store.CountTasksByStatus(c.Request.Context(), projectID)
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"counts": counts, "total": total})
+}
+
+type clearColumnRequest struct {
+	// Permanent deletes the tasks instead of moving them to the trash.
+	Permanent bool `json:"permanent"`
+}
+
+// handleCompleteColumn moves every task of a status column to the project's
+// terminal column.
+func (s *Server) handleCompleteColumn(c *gin.Context) {
+	projectID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	tasks, err := s.store.CompleteColumn(c.Request.Context(), projectID, c.Param("status"))
+	if err != nil {
+		if errors.Is(err, storage.ErrValidation) {
+			s.respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"affected": len(tasks), "tasks": tasks})
+}
+
+// handleClearDoneColumn trashes or deletes every task in the done column.
+func (s *Server) handleClearDoneColumn(c *gin.Context) {
+	projectID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	// The body is optional; without one the tasks go to the trash.
+	var req clearColumnRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	affected, err := s.store.ClearDoneColumn(c.Request.Context(), projectID, req.Permanent)
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"affected": affected})
+}
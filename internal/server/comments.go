@@ -0,0 +1,60 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type commentRequest struct {
+	Author string `json:"author"`
+	Body   string `json:"body"`
+}
+
+// handleListComments returns the discussion of a task.
+func (s *Server) handleListComments(c *gin.Context) {
+	taskID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	comments, err := s.store.ListComments(c.Request.Context(), taskID)
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"comments": comments})
+}
+
+// handleCreateComment adds a comment to a task.
+func (s *Server) handleCreateComment(c *gin.Context) {
+	taskID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req commentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	comment, err := s.store.CreateComment(c.Request.Context(), taskID, req.Author, req.Body)
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	respondSuccess(c, http.StatusCreated, gin.H{"comment": comment})
+}
+
+// handleDeleteComment permanently removes a comment.
+func (s *Server) handleDeleteComment(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	if err := s.store.DeleteComment(c.Request.Context(), id); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"status": "deleted"})
+}
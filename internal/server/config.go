@@ -0,0 +1,27 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requireAdmin limits a route to users with the admin role when JWT
+// authentication is enabled. API keys carry no role and are refused.
+func (s *Server) requireAdmin(c *gin.Context) {
+	if s.jwtSecret == "" {
+		c.Next()
+		return
+	}
+	if _, role, ok := currentUser(c); !ok || role != "admin" {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin role required"})
+		return
+	}
+	c.Next()
+}
+
+// handleGetConfig returns the active server configuration with secrets
+// redacted.
+func (s *Server) handleGetConfig(c *gin.Context) {
+	respondSuccess(c, http.StatusOK, gin.H{"config": s.config})
+}
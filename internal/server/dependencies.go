@@ -0,0 +1,86 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"todo/internal/storage"
+)
+
+type dependencyRequest struct {
+	BlockerID int64 `json:"blocker_id"`
+}
+
+// handleListDependencies returns the tasks blocking and blocked by a task.
+func (s *Server) handleListDependencies(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	blockers, err := s.store.ListBlockers(c.Request.Context(), id)
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	blocking, err := s.store.ListBlocking(c.Request.Context(), id)
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"blocked_by": blockers, "blocking": blocking})
+}
+
+// handleAddDependency marks the task as blocked by another task.
+func (s *Server) handleAddDependency(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dependencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	if req.BlockerID == 0 {
+		s.respondError(c, http.StatusBadRequest, fmt.Errorf("blocker_id is required"))
+		return
+	}
+
+	err := s.store.AddDependency(c.Request.Context(), req.BlockerID, id)
+	if errors.Is(err, storage.ErrConflict) {
+		s.respondError(c, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	task, err := s.store.GetTask(c.Request.Context(), id)
+	if err != nil {
+		s.respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	respondSuccess(c, http.StatusCreated, gin.H{"task": task})
+}
+
+// handleRemoveDependency clears a blocked-by relationship.
+func (s *Server) handleRemoveDependency(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	blockerID, ok := parseID(c, "blockerID")
+	if !ok {
+		return
+	}
+	if err := s.store.RemoveDependency(c.Request.Context(), blockerID, id); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"status": "deleted"})
+}
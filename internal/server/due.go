@@ -0,0 +1,96 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"todo/internal/storage"
+)
+
+// handleListOverdueTasks returns unfinished tasks past their due date across
+// all projects together with their count. ?sort=project groups them by
+// project and ?count_only=true skips the task list for badges.
+func (s *Server) handleListOverdueTasks(c *gin.Context) {
+	now := time.Now().In(s.timezone)
+	if raw := c.Query("count_only"); raw != "" {
+		countOnly, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid count_only"})
+			return
+		}
+		if countOnly {
+			count, err := s.store.CountOverdueTasks(c.Request.Context(), now)
+			if err != nil {
+				s.respondError(c, http.StatusInternalServerError, err)
+				return
+			}
+			respondSuccess(c, http.StatusOK, gin.H{"count": count})
+			return
+		}
+	}
+
+	var byProject bool
+	switch sort := c.Query("sort"); sort {
+	case "", "due_date":
+	case "project":
+		byProject = true
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid sort %q", sort), "valid_sorts": []string{"due_date", "project"}})
+		return
+	}
+
+	tasks, err := s.store.ListAllOverdueTasks(c.Request.Context(), now, byProject)
+	if err != nil {
+		s.respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"tasks": tasks, "count": len(tasks)})
+}
+
+// handleListProjectOverdueTasks returns the unfinished tasks of a project past
+// their due date, most overdue first.
+func (s *Server) handleListProjectOverdueTasks(c *gin.Context) {
+	projectID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	tasks, err := s.store.ListOverdueTasks(c.Request.Context(), projectID, time.Now().In(s.timezone))
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"tasks": tasks, "count": len(tasks)})
+}
+
+// defaultUpcomingDays is the ?days horizon of the upcoming listing.
+const defaultUpcomingDays = 7
+
+// handleListUpcomingTasks returns unfinished tasks due within the next ?days
+// days (default 7); days=0 means due later today.
+func (s *Server) handleListUpcomingTasks(c *gin.Context) {
+	days := defaultUpcomingDays
+	if raw := c.Query("days"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil {
+			s.respondError(c, http.StatusBadRequest, fmt.Errorf("days must be an integer"))
+			return
+		}
+		days = v
+	}
+
+	tasks, err := s.store.ListUpcomingTasks(c.Request.Context(), time.Now().In(s.timezone), days)
+	if err != nil {
+		if errors.Is(err, storage.ErrValidation) {
+			s.respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		s.respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"tasks": tasks, "count": len(tasks), "days": days})
+}
@@ -0,0 +1,251 @@
+package server
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"todo/internal/models"
+	"todo/internal/storage"
+	"todo/internal/when"
+)
+
+// handleExportProject downloads a project with its tasks as a JSON file that
+// POST /api/projects/import accepts.
+func (s *Server) handleExportProject(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	export, err := s.store.ExportProject(c.Request.Context(), id)
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="project-%d.json"`, id))
+	respondSuccess(c, http.StatusOK, export)
+}
+
+// handleImportProject recreates an exported project as a new project with
+// the caller, if any, as its admin.
+func (s *Server) handleImportProject(c *gin.Context) {
+	var export models.ProjectExport
+	if err := c.ShouldBindJSON(&export); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	project, err := s.store.ImportProject(c.Request.Context(), &export)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrValidation):
+			s.respondError(c, http.StatusUnprocessableEntity, err)
+		case errors.Is(err, storage.ErrConflict):
+			s.respondError(c, http.StatusConflict, err)
+		default:
+			s.respondError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	if !s.assignProjectCreator(c, project.ID) {
+		return
+	}
+	respondSuccess(c, http.StatusCreated, gin.H{"project": project})
+}
+
+// taskCSVHeader is the first row of the task CSV export.
+var taskCSVHeader = []string{"id", "title", "description", "status", "priority", "assignee", "story_points", "due_date", "completed_at", "created_at", "updated_at"}
+
+// handleExportTasksCSV downloads the tasks of a project as CSV. It accepts the
+// filters of GET /api/projects/:id/tasks.
+func (s *Server) handleExportTasksCSV(c *gin.Context) {
+	projectID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	filter, ok := s.taskFilter(c)
+	if !ok {
+		return
+	}
+	if _, err := s.store.GetProject(c.Request.Context(), projectID); err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	tasks, err := s.store.ListTasksFiltered(c.Request.Context(), projectID, filter)
+	if err != nil {
+		if errors.Is(err, storage.ErrValidation) {
+			s.respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		s.respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="project-%d-tasks.csv"`, projectID))
+	c.Status(http.StatusOK)
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write(taskCSVHeader)
+	for _, t := range tasks {
+		_ = w.Write([]string{
+			strconv.FormatInt(t.ID, 10),
+			t.Title,
+			t.Description,
+			t.Status,
+			t.Priority,
+			t.Assignee,
+			strconv.Itoa(t.StoryPoints),
+			csvTime(t.DueDate),
+			csvTime(t.CompletedAt),
+			csvTime(&t.CreatedAt),
+			csvTime(&t.UpdatedAt),
+		})
+	}
+	w.Flush()
+	// The status is already sent, so a failed write can only be logged.
+	if err := w.Error(); err != nil {
+		s.logger.LogAttrs(c.Request.Context(), slog.LevelWarn, "csv export failed",
+			slog.String("request_id", requestIDFromContext(c)),
+			slog.String("error", err.Error()))
+	}
+}
+
+// csvTime formats a timestamp as RFC 3339 in UTC; nil is an empty cell.
+func csvTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// handleImportTasksCSV adds the tasks of an uploaded CSV file, sent as the
+// multipart field "file", to a project. The header row names the columns:
+// title is required, and description, status, priority, assignee,
+// story_points and due_date are optional; other columns are ignored. Bad rows
+// are skipped and reported unless ?strict=true, which rejects the whole file.
+func (s *Server) handleImportTasksCSV(c *gin.Context) {
+	projectID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	strict := false
+	if raw := c.Query("strict"); raw != "" {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			s.respondError(c, http.StatusBadRequest, fmt.Errorf("invalid strict"))
+			return
+		}
+		strict = v
+	}
+	header, err := c.FormFile("file")
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, fmt.Errorf("file: %w", err))
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	defer file.Close()
+
+	rows, err := s.readTaskCSV(csv.NewReader(file))
+	if err != nil {
+		s.respondError(c, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	result, err := s.store.ImportTasks(c.Request.Context(), projectID, rows, strict)
+	if err != nil {
+		if errors.Is(err, storage.ErrValidation) {
+			s.respondError(c, http.StatusUnprocessableEntity, err)
+			return
+		}
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, result)
+}
+
+// readTaskCSV turns the records of a task CSV file into import rows. Rows
+// whose cells cannot be parsed carry the error for the store to report.
+func (s *Server) readTaskCSV(r *csv.Reader) ([]models.TaskImportRow, error) {
+	r.FieldsPerRecord = -1
+	names, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	columns := make(map[string]int, len(names))
+	for i, name := range names {
+		if i == 0 {
+			// Spreadsheet programs often start UTF-8 files with a byte order mark.
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, fmt.Errorf("header must contain a title column")
+	}
+
+	var rows []models.TaskImportRow
+	for line := 2; ; line++ {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", line, err)
+		}
+		if line-1 > storage.MaxTaskImportRows {
+			return nil, fmt.Errorf("at most %d rows can be imported at once", storage.MaxTaskImportRows)
+		}
+		cell := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		task, err := s.csvTask(cell)
+		rows = append(rows, models.TaskImportRow{Row: line, Task: task, Err: err})
+	}
+	return rows, nil
+}
+
+// csvTask builds a task from the cells of one CSV row. Only the number and
+// date cells are checked here; the store validates the rest.
+func (s *Server) csvTask(cell func(name string) string) (models.Task, error) {
+	task := models.Task{
+		Title:       cell("title"),
+		Description: cell("description"),
+		Status:      cell("status"),
+		Priority:    cell("priority"),
+		Assignee:    cell("assignee"),
+	}
+	if raw := cell("story_points"); raw != "" {
+		points, err := strconv.Atoi(raw)
+		if err != nil {
+			return task, fmt.Errorf("story_points must be an integer")
+		}
+		task.StoryPoints = points
+	}
+	if raw := cell("due_date"); raw != "" {
+		due, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			if due, err = when.Parse(raw, time.Now(), s.timezone); err != nil {
+				return task, fmt.Errorf("due_date: %w", err)
+			}
+		}
+		task.DueDate = &due
+	}
+	return task, nil
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+	"encoding/csv"
+	"net/http"
+	"strings"
+	"testing"
+
+	"todo/internal/models"
+)
+
+func TestExportTasksCSV(t *testing.T) {
+	srv, store := newTestServer(t, Options{})
+	ctx := context.Background()
+	fixture := []models.Task{
+		{ProjectID: 1, Title: "Plain", Priority: "high"},
+		{ProjectID: 1, Title: `Quotes "and", commas`, Description: "line one\nline two", Priority: "high"},
+		{ProjectID: 1, Title: "Low one", Priority: "low", Status: "done"},
+	}
+	for _, task := range fixture {
+		if _, err := store.CreateTask(ctx, task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	export := func(query string) [][]string {
+		t.Helper()
+		w := do(t, srv, http.MethodGet, "/api/projects/1/tasks/export.csv"+query, "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("export%s = %d: %s", query, w.Code, w.Body.String())
+		}
+		if got := w.Header().Get("Content-Disposition"); got != `attachment; filename="project-1-tasks.csv"` {
+			t.Fatalf("Content-Disposition = %q", got)
+		}
+		records, err := csv.NewReader(w.Body).ReadAll()
+		if err != nil {
+			t.Fatalf("parse csv: %v", err)
+		}
+		return records
+	}
+
+	records := export("")
+	if got, want := strings.Join(records[0], ","), strings.Join(taskCSVHeader, ","); got != want {
+		t.Fatalf("header = %s, want %s", got, want)
+	}
+	if len(records)-1 != len(fixture) {
+		t.Fatalf("got %d rows, want %d", len(records)-1, len(fixture))
+	}
+	found := false
+	for _, row := range records[1:] {
+		if row[1] == fixture[1].Title && row[2] == fixture[1].Description {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("no row round-trips %q with its multi-line description: %v", fixture[1].Title, records)
+	}
+
+	if rows := export("?priority=high")[1:]; len(rows) != 2 {
+		t.Fatalf("priority=high exported %d rows, want 2", len(rows))
+	}
+	if rows := export("?status=done")[1:]; len(rows) != 1 || rows[0][1] != "Low one" {
+		t.Fatalf("status=done exported %v, want only Low one", rows)
+	}
+}
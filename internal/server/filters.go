@@ -0,0 +1,149 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+type filterRequest struct {
+	ProjectID  *int64                  `json:"project_id"`
+	Name       string                  `json:"name"`
+	Definition models.FilterDefinition `json:"definition"`
+}
+
+func (r filterRequest) toModel() models.SavedFilter {
+	return models.SavedFilter{
+		ProjectID:  r.ProjectID,
+		Name:       r.Name,
+		Definition: r.Definition,
+	}
+}
+
+// handleListFilters returns global saved filters and those of ?project_id.
+func (s *Server) handleListFilters(c *gin.Context) {
+	var projectID *int64
+	if raw := c.Query("project_id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid identifier"})
+			return
+		}
+		projectID = &id
+	}
+
+	filters, err := s.store.ListSavedFilters(c.Request.Context(), projectID)
+	if err != nil {
+		s.respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"filters": filters})
+}
+
+// handleGetFilter returns a single saved filter.
+func (s *Server) handleGetFilter(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	f, err := s.store.GetSavedFilter(c.Request.Context(), id)
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"filter": f})
+}
+
+// handleCreateFilter validates and stores a new saved filter.
+func (s *Server) handleCreateFilter(c *gin.Context) {
+	var req filterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	f, err := s.store.CreateSavedFilter(c.Request.Context(), req.toModel())
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	respondSuccess(c, http.StatusCreated, gin.H{"filter": f})
+}
+
+// handleUpdateFilter replaces a saved filter.
+func (s *Server) handleUpdateFilter(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req filterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	f, err := s.store.UpdateSavedFilter(c.Request.Context(), id, req.toModel())
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"filter": f})
+}
+
+// handleDeleteFilter removes a saved filter.
+func (s *Server) handleDeleteFilter(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	if err := s.store.DeleteSavedFilter(c.Request.Context(), id); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"status": "deleted"})
+}
+
+// handleListFilterTasks runs a saved filter. Scoped filters run in their own
+// project; global ones need ?project_id.
+func (s *Server) handleListFilterTasks(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	f, err := s.store.GetSavedFilter(c.Request.Context(), id)
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+
+	var projectID int64
+	if raw := c.Query("project_id"); raw != "" {
+		if projectID, err = strconv.ParseInt(raw, 10, 64); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid identifier"})
+			return
+		}
+	} else if f.ProjectID != nil {
+		projectID = *f.ProjectID
+	} else {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "project_id is required for global filters"})
+		return
+	}
+	if !s.checkProjectRole(c, projectID, models.ProjectRoleViewer) {
+		return
+	}
+
+	tasks, err := s.store.ListSavedFilterTasks(c.Request.Context(), id, projectID)
+	if err != nil {
+		if errors.Is(err, storage.ErrValidation) {
+			s.respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"filter": f, "tasks": tasks})
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+// defaultGlobalTaskPage is the page size of GET /api/tasks without ?limit.
+const defaultGlobalTaskPage = 50
+
+// defaultRecentWindow is how far back GET /api/tasks/recent looks without
+// ?since.
+const defaultRecentWindow = 24 * time.Hour
+
+// handleListAllTasks lists tasks across projects, filtered by ?status,
+// ?project_id and ?q and paged with ?limit and ?offset.
+func (s *Server) handleListAllTasks(c *gin.Context) {
+	filter := models.GlobalTaskFilter{Query: c.Query("q"), Limit: defaultGlobalTaskPage}
+	filter.Statuses = statusQuery(c)
+	if raw := c.Query("project_id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project_id"})
+			return
+		}
+		filter.ProjectID = &id
+	}
+	if raw := c.Query("include_snoozed"); raw != "" {
+		include, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid include_snoozed"})
+			return
+		}
+		filter.IncludeSnoozed = include
+	}
+	for name, dst := range map[string]*int{"limit": &filter.Limit, "offset": &filter.Offset} {
+		if raw := c.Query(name); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil {
+				s.respondError(c, http.StatusBadRequest, fmt.Errorf("%s must be an integer", name))
+				return
+			}
+			*dst = n
+		}
+	}
+
+	tasks, total, err := s.store.ListAllTasks(c.Request.Context(), filter)
+	if err != nil {
+		if errors.Is(err, storage.ErrValidation) {
+			s.respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		s.respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{
+		"tasks":  tasks,
+		"total":  total,
+		"limit":  filter.Limit,
+		"offset": filter.Offset,
+	})
+}
+
+// handleListRecentTasks lists tasks of every project updated after ?since
+// (RFC3339, default the last 24 hours), newest first and at most ?limit.
+func (s *Server) handleListRecentTasks(c *gin.Context) {
+	since := time.Now().Add(-defaultRecentWindow)
+	if raw := c.Query("since"); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			s.respondError(c, http.StatusBadRequest, fmt.Errorf("since must be an RFC3339 timestamp"))
+			return
+		}
+		since = t
+	}
+	limit := defaultGlobalTaskPage
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			s.respondError(c, http.StatusBadRequest, fmt.Errorf("limit must be an integer"))
+			return
+		}
+		limit = n
+	}
+
+	tasks, err := s.store.ListRecentTasks(c.Request.Context(), since, limit)
+	if err != nil {
+		if errors.Is(err, storage.ErrValidation) {
+			s.respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		s.respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"tasks": tasks, "since": since.UTC().Format(time.RFC3339)})
+}
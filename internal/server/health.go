@@ -0,0 +1,83 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"todo/internal/version"
+)
+
+// healthTimeout bounds how long a probe waits on a dependency.
+const healthTimeout = 2 * time.Second
+
+// healthCheck is the outcome of probing one dependency.
+type healthCheck struct {
+	Name  string `json:"name"`
+	Error string `json:"error"`
+}
+
+// handleHealth reports liveness together with the database state, uptime and
+// build version. It responds 503 when the database does not answer.
+func (s *Server) handleHealth(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), healthTimeout)
+	defer cancel()
+
+	status, code := "ok", http.StatusOK
+	dbErr := s.store.Ping(ctx)
+	if dbErr != nil {
+		s.logger.Warn("health check failed", "error", dbErr)
+		status, code = "unavailable", http.StatusServiceUnavailable
+	}
+	c.JSON(code, gin.H{
+		"status":         status,
+		"db_ok":          dbErr == nil,
+		"uptime_seconds": time.Since(s.started).Seconds(),
+		"version":        version.Version,
+	})
+}
+
+// handleReady checks every dependency the server needs to take traffic and
+// responds 503 listing the failing ones. It backs readiness probes.
+func (s *Server) handleReady(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), healthTimeout)
+	defer cancel()
+
+	failing := []healthCheck{}
+	if err := s.store.Ping(ctx); err != nil {
+		failing = append(failing, healthCheck{Name: "database", Error: err.Error()})
+	}
+	if s.staticDir != "" {
+		if err := checkWritableDir(s.staticDir); err != nil {
+			failing = append(failing, healthCheck{Name: "static_dir", Error: err.Error()})
+		}
+	}
+
+	if len(failing) > 0 {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "failing": failing})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "failing": failing})
+}
+
+// checkWritableDir verifies that dir exists and a file can be created in it.
+func checkWritableDir(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+	f, err := os.CreateTemp(dir, ".readyz-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	_ = f.Close()
+	return os.Remove(name)
+}
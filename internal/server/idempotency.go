@@ -0,0 +1,144 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"todo/internal/models"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotencyReplayedHeader = "Idempotency-Replayed"
+	// maxIdempotencyKeyLength bounds client supplied keys.
+	maxIdempotencyKeyLength = 255
+	// idempotencyTTL is how long a response stays available for replay.
+	idempotencyTTL = 24 * time.Hour
+	// idempotencyPruneInterval is how often expired keys are deleted.
+	idempotencyPruneInterval = time.Hour
+)
+
+// idempotentRoutes are the route prefixes whose POST handlers honour
+// Idempotency-Key.
+var idempotentRoutes = []string{"/projects", "/tasks"}
+
+// idempotency replays the stored response of a POST to a project or task
+// route when the Idempotency-Key header repeats a key seen within
+// idempotencyTTL, marking it with Idempotency-Replayed: true. Keys belong to
+// the calling user or API key and to the request path, so they never replay
+// a response across callers or endpoints. A retry arriving while the first
+// request still runs gets 409; server errors are not stored, so they can be
+// retried. A background goroutine deletes expired keys until Shutdown.
+func (s *Server) idempotency() gin.HandlerFunc {
+	s.every(idempotencyPruneInterval, func(time.Time) {
+		if _, err := s.store.PruneIdempotencyKeys(context.Background(), idempotencyTTL); err != nil {
+			s.logger.Error("prune idempotency keys", slog.String("error", err.Error()))
+		}
+	})
+
+	return func(c *gin.Context) {
+		raw := strings.TrimSpace(c.GetHeader(idempotencyKeyHeader))
+		if raw == "" || c.Request.Method != http.MethodPost || !idempotentRoute(c.FullPath()) {
+			c.Next()
+			return
+		}
+		if len(raw) > maxIdempotencyKeyLength {
+			s.respondError(c, http.StatusBadRequest, fmt.Errorf("%s must be at most %d characters", idempotencyKeyHeader, maxIdempotencyKeyLength))
+			c.Abort()
+			return
+		}
+		key := idempotencyScope(c) + " " + apiRoute(c.Request.URL.Path) + " " + raw
+
+		claimed, status, body, err := s.store.ClaimIdempotencyKey(c.Request.Context(), key, idempotencyTTL)
+		if err != nil {
+			s.respondError(c, http.StatusInternalServerError, err)
+			c.Abort()
+			return
+		}
+		if !claimed {
+			if status == 0 {
+				s.respondError(c, http.StatusConflict, fmt.Errorf("a request with this %s is still in progress", idempotencyKeyHeader))
+				c.Abort()
+				return
+			}
+			c.Header(idempotencyReplayedHeader, "true")
+			c.Data(status, "application/json; charset=utf-8", []byte(body))
+			c.Abort()
+			return
+		}
+
+		// The outcome is stored even when the client has gone away, so its
+		// retry finds it.
+		ctx := context.WithoutCancel(c.Request.Context())
+		w := &recordingResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		saved := false
+		defer func() {
+			c.Writer = w.ResponseWriter
+			if saved {
+				return
+			}
+			if err := s.store.ReleaseIdempotencyKey(ctx, key); err != nil {
+				s.logger.Error("release idempotency key", slog.String("request_id", requestIDFromContext(c)), slog.String("error", err.Error()))
+			}
+		}()
+		c.Next()
+
+		if w.Status() >= http.StatusInternalServerError {
+			return
+		}
+		if err := s.store.SaveIdempotentResponse(ctx, key, w.Status(), string(w.body)); err != nil {
+			s.logger.Error("save idempotent response", slog.String("request_id", requestIDFromContext(c)), slog.String("error", err.Error()))
+			return
+		}
+		saved = true
+	}
+}
+
+// idempotentRoute reports whether a route template falls under
+// idempotentRoutes.
+func idempotentRoute(route string) bool {
+	route = apiRoute(route)
+	for _, prefix := range idempotentRoutes {
+		if route == prefix || strings.HasPrefix(route, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// idempotencyScope names the caller a key belongs to: the authenticated
+// user, else the API key, else nobody.
+func idempotencyScope(c *gin.Context) string {
+	if id, _, ok := currentUser(c); ok {
+		return "user:" + strconv.FormatInt(id, 10)
+	}
+	if v, ok := c.Get("api_key"); ok {
+		if key, ok := v.(models.APIKey); ok {
+			return "key:" + strconv.FormatInt(key.ID, 10)
+		}
+	}
+	return "-"
+}
+
+// recordingResponseWriter keeps a copy of the body it writes through.
+type recordingResponseWriter struct {
+	gin.ResponseWriter
+	body []byte
+}
+
+func (w *recordingResponseWriter) Write(p []byte) (int, error) {
+	w.body = append(w.body, p...)
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *recordingResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
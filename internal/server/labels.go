@@ -0,0 +1,117 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type labelRequest struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// handleListLabels returns the labels of a project.
+func (s *Server) handleListLabels(c *gin.Context) {
+	projectID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	labels, err := s.store.ListLabels(c.Request.Context(), projectID)
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"labels": labels})
+}
+
+// handleCreateLabel defines a new label within a project.
+func (s *Server) handleCreateLabel(c *gin.Context) {
+	projectID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req labelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	label, err := s.store.CreateLabel(c.Request.Context(), projectID, req.Name, req.Color)
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	respondSuccess(c, http.StatusCreated, gin.H{"label": label})
+}
+
+// handleUpdateLabel renames or recolors a label.
+func (s *Server) handleUpdateLabel(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req labelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	label, err := s.store.UpdateLabel(c.Request.Context(), id, req.Name, req.Color)
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"label": label})
+}
+
+// handleDeleteLabel removes a label from the project and its tasks.
+func (s *Server) handleDeleteLabel(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	if err := s.store.DeleteLabel(c.Request.Context(), id); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"status": "deleted"})
+}
+
+// handleAddTaskLabel attaches a label to a task.
+func (s *Server) handleAddTaskLabel(c *gin.Context) {
+	taskID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	labelID, ok := parseID(c, "labelID")
+	if !ok {
+		return
+	}
+	task, err := s.store.AddTaskLabel(c.Request.Context(), taskID, labelID)
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"task": task})
+}
+
+// handleRemoveTaskLabel detaches a label from a task.
+func (s *Server) handleRemoveTaskLabel(c *gin.Context) {
+	taskID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	labelID, ok := parseID(c, "labelID")
+	if !ok {
+		return
+	}
+	task, err := s.store.RemoveTaskLabel(c.Request.Context(), taskID, labelID)
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"task": task})
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"todo/internal/models"
+)
+
+//go:embed templates/project.md.tmpl
+var projectMarkdownSource string
+
+var projectMarkdown = template.Must(template.New("project.md").Funcs(template.FuncMap{
+	"date": markdownDate,
+	"join": strings.Join,
+	// indent keeps continuation lines inside their list item.
+	"indent": func(s string) string { return strings.ReplaceAll(s, "\n", "\n  ") },
+}).Parse(projectMarkdownSource))
+
+type markdownBoard struct {
+	Project         models.Project
+	ExportedAt      time.Time
+	IncludeComments bool
+	Columns         []markdownColumn
+}
+
+type markdownColumn struct {
+	Title string
+	Tasks []markdownTask
+}
+
+type markdownTask struct {
+	models.ExportedTask
+	LabelNames   []string
+	ParentNumber int64
+}
+
+// handleExportProjectMarkdown renders a project board as a Markdown document.
+// Comments are included unless ?include_comments=false.
+func (s *Server) handleExportProjectMarkdown(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	includeComments := true
+	if raw := c.Query("include_comments"); raw != "" {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			s.respondError(c, http.StatusBadRequest, fmt.Errorf("invalid include_comments"))
+			return
+		}
+		includeComments = v
+	}
+	export, err := s.store.ExportProject(c.Request.Context(), id)
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := projectMarkdown.Execute(&buf, newMarkdownBoard(export, includeComments)); err != nil {
+		s.respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="project-%d.md"`, id))
+	c.Data(http.StatusOK, "text/markdown; charset=utf-8", buf.Bytes())
+}
+
+// newMarkdownBoard groups the exported tasks by status column, in display
+// order, and resolves label and parent references for the template.
+func newMarkdownBoard(export *models.ProjectExport, includeComments bool) markdownBoard {
+	labels := make(map[int64]string, len(export.Labels))
+	for _, l := range export.Labels {
+		labels[l.ID] = l.Name
+	}
+	numbers := make(map[int64]int64, len(export.Tasks))
+	for _, t := range export.Tasks {
+		numbers[t.ID] = t.Number
+	}
+
+	board := markdownBoard{Project: export.Project, ExportedAt: export.ExportedAt, IncludeComments: includeComments}
+	for _, st := range export.Statuses {
+		column := markdownColumn{Title: st.Title}
+		if column.Title == "" {
+			column.Title = st.Name
+		}
+		for _, t := range export.Tasks {
+			if t.Status != st.Name {
+				continue
+			}
+			task := markdownTask{ExportedTask: t}
+			for _, id := range t.Labels {
+				task.LabelNames = append(task.LabelNames, labels[id])
+			}
+			if t.ParentID != nil {
+				task.ParentNumber = numbers[*t.ParentID]
+			}
+			column.Tasks = append(column.Tasks, task)
+		}
+		board.Columns = append(board.Columns, column)
+	}
+	return board
+}
+
+// markdownDate formats a time or *time.Time in UTC for the Markdown export.
+func markdownDate(v any) string {
+	var t time.Time
+	switch v := v.(type) {
+	case time.Time:
+		t = v
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		t = *v
+	}
+	return t.UTC().Format("2006-01-02 15:04 MST")
+}
@@ -0,0 +1,188 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+type memberRequest struct {
+	UserID int64  `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+// projectOf resolves the project owning the resource of the given kind named
+// by the :id parameter, for requireProjectRole. Unknown resources end the
+// request with 404.
+func (s *Server) projectOf(kind string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !s.projectRolesApply(c) {
+			c.Next()
+			return
+		}
+		id, ok := parseID(c, "id")
+		if !ok {
+			c.Abort()
+			return
+		}
+		projectID, err := s.store.ResourceProjectID(c.Request.Context(), kind, id)
+		if err != nil {
+			s.respondError(c, http.StatusNotFound, err)
+			c.Abort()
+			return
+		}
+		c.Set("project_id", projectID)
+		c.Next()
+	}
+}
+
+// requireProjectRole limits a route to members of the project holding at
+// least minRole. The project is the one resolved by projectOf or else the :id
+// parameter. Checks apply only with JWT authentication; users with the admin
+// role, and their API keys, are let through.
+func (s *Server) requireProjectRole(minRole string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !s.projectRolesApply(c) {
+			c.Next()
+			return
+		}
+		projectID := c.GetInt64("project_id")
+		if _, ok := c.Get("project_id"); !ok {
+			id, ok := parseID(c, "id")
+			if !ok {
+				c.Abort()
+				return
+			}
+			projectID = id
+		}
+		if !s.checkProjectRole(c, projectID, minRole) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// checkProjectRole reports whether the caller holds at least minRole in a
+// project, for handlers that only learn the project from the request body.
+// It reports false after aborting with an error.
+func (s *Server) checkProjectRole(c *gin.Context, projectID int64, minRole string) bool {
+	if !s.projectRolesApply(c) {
+		return true
+	}
+	userID, _, _ := currentUser(c)
+	role, err := s.store.ProjectRole(c.Request.Context(), projectID, userID)
+	if err != nil {
+		s.respondError(c, http.StatusInternalServerError, err)
+		c.Abort()
+		return false
+	}
+	if role == "" {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "not a member of this project"})
+		return false
+	}
+	if models.ValidProjectRoles[role] < models.ValidProjectRoles[minRole] {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("project role %s required", minRole)})
+		return false
+	}
+	return true
+}
+
+// scopeProjects limits the listings spanning projects, such as GET /tasks or
+// /search, to the projects the caller is a member of when project roles
+// apply.
+func (s *Server) scopeProjects(c *gin.Context) {
+	if s.projectRolesApply(c) {
+		userID, _, _ := currentUser(c)
+		c.Request = c.Request.WithContext(storage.WithProjectMember(c.Request.Context(), userID))
+	}
+	c.Next()
+}
+
+// projectRolesApply reports whether the caller is subject to project roles:
+// with authentication enabled, everyone but admins is, including API keys,
+// which act as their owner. Keys without an owner belong to no project.
+func (s *Server) projectRolesApply(c *gin.Context) bool {
+	if s.jwtSecret == "" {
+		return false
+	}
+	_, role, _ := currentUser(c)
+	return role != "admin"
+}
+
+// assignProjectCreator makes the caller an admin of a project they just
+// created. It reports false after responding with an error.
+func (s *Server) assignProjectCreator(c *gin.Context, projectID int64) bool {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return true
+	}
+	if _, err := s.store.AddProjectMember(c.Request.Context(), projectID, userID, models.ProjectRoleAdmin); err != nil {
+		s.respondError(c, http.StatusInternalServerError, err)
+		return false
+	}
+	return true
+}
+
+// handleListProjectMembers returns the members of a project and their roles.
+func (s *Server) handleListProjectMembers(c *gin.Context) {
+	projectID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	members, err := s.store.ListProjectMembers(c.Request.Context(), projectID)
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"members": members})
+}
+
+// handleAddProjectMember adds a user to a project or changes their role; the
+// role defaults to member.
+func (s *Server) handleAddProjectMember(c *gin.Context) {
+	projectID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	var req memberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	if req.Role == "" {
+		req.Role = models.ProjectRoleMember
+	}
+	member, err := s.store.AddProjectMember(c.Request.Context(), projectID, req.UserID, req.Role)
+	if err != nil {
+		s.respondStatusError(c, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"member": member})
+}
+
+// handleRemoveProjectMember takes a user out of a project.
+func (s *Server) handleRemoveProjectMember(c *gin.Context) {
+	projectID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := parseID(c, "userID")
+	if !ok {
+		return
+	}
+	err := s.store.RemoveProjectMember(c.Request.Context(), projectID, userID)
+	if errors.Is(err, storage.ErrConflict) {
+		s.respondError(c, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"status": "removed"})
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"todo/internal/models"
+)
+
+func TestNonMemberCannotReachOtherProjects(t *testing.T) {
+	srv, store := newTestServer(t, Options{JWTSecret: testSecret})
+	ctx := context.Background()
+	task, err := store.CreateTask(ctx, models.Task{ProjectID: 1, Title: "secret plan"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.CreateUser(ctx, "bob", testPassword, "", "member"); err != nil {
+		t.Fatal(err)
+	}
+	bob := login(t, srv, "bob", testPassword)
+
+	var list struct {
+		Tasks []models.ProjectTask `json:"tasks"`
+		Total int                  `json:"total"`
+	}
+	decode(t, do(t, srv, http.MethodGet, "/api/tasks", "", "Authorization", bob), &list)
+	if list.Total != 0 || len(list.Tasks) != 0 {
+		t.Fatalf("GET /tasks = %d tasks, want none", list.Total)
+	}
+	var search struct {
+		Projects []models.Project          `json:"projects"`
+		Tasks    []models.TaskSearchResult `json:"tasks"`
+	}
+	decode(t, do(t, srv, http.MethodGet, "/api/search?q=secret", "", "Authorization", bob), &search)
+	if len(search.Tasks) != 0 {
+		t.Fatalf("search found %d tasks, want none", len(search.Tasks))
+	}
+	decode(t, do(t, srv, http.MethodGet, "/api/search?q=main", "", "Authorization", bob), &search)
+	if len(search.Projects) != 0 {
+		t.Fatalf("search found %d projects, want none", len(search.Projects))
+	}
+
+	writes := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		{"bulk move", http.MethodPatch, "/api/tasks/bulk", `{"ids":[1],"status":"done"}`},
+		{"quick add", http.MethodPost, "/api/quick", `{"text":"sneaky","project_id":1}`},
+		{"quick add by name", http.MethodPost, "/api/quick", `{"text":"sneaky #Main"}`},
+		{"duplicate project", http.MethodPost, "/api/projects/1/duplicate", `{}`},
+	}
+	for _, tt := range writes {
+		t.Run(tt.name, func(t *testing.T) {
+			w := do(t, srv, tt.method, tt.path, tt.body, "Authorization", bob)
+			if w.Code != http.StatusForbidden {
+				t.Fatalf("status = %d, want 403: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+
+	// As a viewer bob sees the project but still cannot copy it or write to it.
+	if _, err := store.AddProjectMember(ctx, 1, 2, models.ProjectRoleViewer); err != nil {
+		t.Fatal(err)
+	}
+	decode(t, do(t, srv, http.MethodGet, "/api/tasks", "", "Authorization", bob), &list)
+	if list.Total != 1 || list.Tasks[0].ID != task.ID {
+		t.Fatalf("GET /tasks as viewer = %+v, want task %d", list, task.ID)
+	}
+	if w := do(t, srv, http.MethodPost, "/api/projects/1/duplicate", `{}`, "Authorization", bob); w.Code != http.StatusForbidden {
+		t.Fatalf("viewer duplicate = %d, want 403", w.Code)
+	}
+}
+
+func TestDuplicateTaskChecksTargetProject(t *testing.T) {
+	srv, store := newTestServer(t, Options{JWTSecret: testSecret})
+	ctx := context.Background()
+	if _, err := store.CreateUser(ctx, "bob", testPassword, "", "member"); err != nil {
+		t.Fatal(err)
+	}
+	bob := login(t, srv, "bob", testPassword)
+	w := do(t, srv, http.MethodPost, "/api/projects", `{"name":"Bob's"}`, "Authorization", bob)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create project: %d %s", w.Code, w.Body.String())
+	}
+	var created struct {
+		Project models.Project `json:"project"`
+	}
+	decode(t, w, &created)
+	task, err := store.CreateTask(ctx, models.Task{ProjectID: created.Project.ID, Title: "mine"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := "/api/tasks/" + itoa(task.ID) + "/duplicate"
+	if w := do(t, srv, http.MethodPost, path, `{"project_id":1}`, "Authorization", bob); w.Code != http.StatusForbidden {
+		t.Fatalf("duplicate into foreign project = %d, want 403: %s", w.Code, w.Body.String())
+	}
+	if w := do(t, srv, http.MethodPost, path, `{}`, "Authorization", bob); w.Code != http.StatusCreated {
+		t.Fatalf("duplicate in place = %d, want 201: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,571 @@
+package server
+
+import (
+	"compress/gzip"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
+
+	"todo/internal/metrics"
+	"todo/internal/storage"
+)
+
+// apiKeyHeader carries an API key created through /api/api-keys.
+const apiKeyHeader = "X-API-Key"
+
+// requestIDHeader carries the correlation id of a request.
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds client supplied ids before they reach the logs.
+const maxRequestIDLength = 128
+
+const (
+	corsAllowMethods = "GET, POST, PUT, PATCH, DELETE, OPTIONS"
+	corsAllowHeaders = "Content-Type, Authorization, Idempotency-Key, X-API-Key, X-Changed-By, X-Request-ID, X-Session-ID"
+	corsMaxAge       = "600"
+)
+
+// corsMiddleware allows cross-origin requests from the configured origins; an
+// entry of "*" allows any origin. With no origins only same-origin requests
+// work, since no CORS headers are sent. Preflight requests end with 204.
+func corsMiddleware(allowedOrigins []string) gin.HandlerFunc {
+	allowed := make(map[string]struct{}, len(allowedOrigins))
+	for _, o := range allowedOrigins {
+		if o = strings.TrimRight(strings.TrimSpace(o), "/"); o != "" {
+			allowed[o] = struct{}{}
+		}
+	}
+	_, allowAll := allowed["*"]
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin != "" {
+			if _, ok := allowed[origin]; ok || allowAll {
+				h := c.Writer.Header()
+				h.Set("Access-Control-Allow-Origin", origin)
+				h.Set("Access-Control-Allow-Methods", corsAllowMethods)
+				h.Set("Access-Control-Allow-Headers", corsAllowHeaders)
+				h.Set("Access-Control-Max-Age", corsMaxAge)
+				h.Add("Vary", "Origin")
+			}
+		}
+		if c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != "" {
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		c.Next()
+	}
+}
+
+// jwtMiddleware requires an "Authorization: Bearer <token>" header carrying an
+// unexpired HS256 token signed with secret and stores its *authClaims in the
+// context as "claims". The user the token was issued to is loaded and stored
+// as "user", so a deleted user's tokens stop working and a changed role
+// applies at once; tokens of unknown users are rejected with 401.
+func (s *Server) jwtMiddleware(secret string) gin.HandlerFunc {
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	key := func(*jwt.Token) (any, error) { return []byte(secret), nil }
+
+	return func(c *gin.Context) {
+		if _, ok := c.Get("api_key"); ok {
+			// Already authenticated by apiKeyMiddleware.
+			c.Next()
+			return
+		}
+		raw, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || strings.TrimSpace(raw) == "" {
+			c.Header("WWW-Authenticate", "Bearer")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing bearer token"})
+			return
+		}
+		var claims authClaims
+		if _, err := parser.ParseWithClaims(strings.TrimSpace(raw), &claims, key); err != nil {
+			c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		id, err := strconv.ParseInt(claims.Subject, 10, 64)
+		if err != nil {
+			c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "token subject is not a user id"})
+			return
+		}
+		user, err := s.store.GetUserByID(c.Request.Context(), id)
+		if errors.Is(err, storage.ErrUserNotFound) {
+			c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			s.respondError(c, http.StatusInternalServerError, err)
+			c.Abort()
+			return
+		}
+		c.Set("claims", &claims)
+		c.Set("user", user)
+		c.Next()
+	}
+}
+
+// apiKeyMiddleware authenticates requests carrying an X-API-Key header and
+// stores the models.APIKey in the context as "api_key", and its owner as
+// "api_key_user". Unknown and expired keys are rejected with 401; requests
+// without the header pass through to jwtMiddleware when it is active.
+func (s *Server) apiKeyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		raw := strings.TrimSpace(c.GetHeader(apiKeyHeader))
+		if raw == "" {
+			c.Next()
+			return
+		}
+		key, err := s.store.AuthenticateAPIKey(c.Request.Context(), raw)
+		if errors.Is(err, storage.ErrInvalidAPIKey) || errors.Is(err, storage.ErrAPIKeyExpired) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			s.respondError(c, http.StatusInternalServerError, err)
+			c.Abort()
+			return
+		}
+		if key.UserID != nil {
+			owner, err := s.store.GetUserByID(c.Request.Context(), *key.UserID)
+			if err != nil {
+				s.respondError(c, http.StatusInternalServerError, err)
+				c.Abort()
+				return
+			}
+			c.Set("api_key_user", owner)
+		}
+		c.Set("api_key", key)
+		c.Next()
+	}
+}
+
+// rateLimiterIdle is how long a client may stay quiet before its limiter is
+// dropped.
+const rateLimiterIdle = 10 * time.Minute
+
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen atomic.Int64 // unix nanoseconds
+}
+
+// rateLimitMiddleware allows each client IP rps requests per second with
+// bursts of up to burstSize, answering 429 with Retry-After beyond that. A
+// background goroutine drops limiters idle for rateLimiterIdle until
+// Shutdown.
+func (s *Server) rateLimitMiddleware(rps int, burstSize int) gin.HandlerFunc {
+	if burstSize < 1 {
+		burstSize = 1
+	}
+	var clients sync.Map // ip -> *clientLimiter
+
+	s.every(time.Minute, func(now time.Time) {
+		clients.Range(func(ip, v any) bool {
+			if now.Sub(time.Unix(0, v.(*clientLimiter).lastSeen.Load())) > rateLimiterIdle {
+				clients.Delete(ip)
+			}
+			return true
+		})
+	})
+
+	limit := strconv.Itoa(rps)
+	return func(c *gin.Context) {
+		v, ok := clients.Load(c.ClientIP())
+		if !ok {
+			v, _ = clients.LoadOrStore(c.ClientIP(), &clientLimiter{limiter: rate.NewLimiter(rate.Limit(rps), burstSize)})
+		}
+		client := v.(*clientLimiter)
+		client.lastSeen.Store(time.Now().UnixNano())
+
+		h := c.Writer.Header()
+		h.Set("X-RateLimit-Limit", limit)
+		if !client.limiter.Allow() {
+			r := client.limiter.Reserve()
+			wait := r.Delay()
+			r.Cancel()
+			h.Set("X-RateLimit-Remaining", "0")
+			h.Set("Retry-After", strconv.Itoa(max(1, int(math.Ceil(wait.Seconds())))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
+			return
+		}
+		h.Set("X-RateLimit-Remaining", strconv.Itoa(int(client.limiter.Tokens())))
+		c.Next()
+	}
+}
+
+// maxBodyMiddleware limits request bodies to maxBytes. A declared
+// Content-Length over the limit is rejected up front; otherwise reads past the
+// limit fail with *http.MaxBytesError, which respondError maps to 413.
+func maxBodyMiddleware(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > maxBytes {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error": fmt.Sprintf("request body must be at most %d bytes", maxBytes),
+			})
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		c.Next()
+	}
+}
+
+// minCompressSize is the smallest response body worth gzipping.
+const minCompressSize = 1024
+
+var gzipWriters = sync.Pool{New: func() any { return gzip.NewWriter(nil) }}
+
+// compressionMiddleware gzips responses for clients sending
+// Accept-Encoding: gzip. Bodies under minCompressSize, media that is already
+// compressed and event streams are sent as is.
+func compressionMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !strings.Contains(c.GetHeader("Accept-Encoding"), "gzip") {
+			c.Next()
+			return
+		}
+		w := &gzipResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Header("Vary", "Accept-Encoding")
+		defer w.close()
+		c.Next()
+	}
+}
+
+// gzipResponseWriter buffers the start of a body until it knows whether the
+// response is large enough to compress.
+type gzipResponseWriter struct {
+	gin.ResponseWriter
+	buf     []byte
+	gz      *gzip.Writer
+	decided bool
+}
+
+func (w *gzipResponseWriter) Write(p []byte) (int, error) {
+	if w.decided {
+		if w.gz != nil {
+			return w.gz.Write(p)
+		}
+		return w.ResponseWriter.Write(p)
+	}
+	w.buf = append(w.buf, p...)
+	if len(w.buf) < minCompressSize {
+		return len(p), nil
+	}
+	if err := w.decide(compressible(w.Header())); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (w *gzipResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Written reports buffered output too, so handlers see the response as begun.
+func (w *gzipResponseWriter) Written() bool {
+	return len(w.buf) > 0 || w.ResponseWriter.Written()
+}
+
+// Flush gives up on compression for streamed bodies that flush before
+// reaching minCompressSize.
+func (w *gzipResponseWriter) Flush() {
+	if !w.decided {
+		_ = w.decide(false)
+	}
+	if w.gz != nil {
+		_ = w.gz.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// decide writes the buffered bytes either through a gzip stream or as is.
+func (w *gzipResponseWriter) decide(compress bool) error {
+	w.decided = true
+	buf := w.buf
+	w.buf = nil
+	if !compress {
+		if len(buf) == 0 {
+			return nil
+		}
+		_, err := w.ResponseWriter.Write(buf)
+		return err
+	}
+	h := w.Header()
+	h.Set("Content-Encoding", "gzip")
+	h.Del("Content-Length")
+	w.gz = gzipWriters.Get().(*gzip.Writer)
+	w.gz.Reset(w.ResponseWriter)
+	_, err := w.gz.Write(buf)
+	return err
+}
+
+func (w *gzipResponseWriter) close() {
+	if !w.decided {
+		_ = w.decide(false)
+	}
+	if w.gz != nil {
+		_ = w.gz.Close()
+		gzipWriters.Put(w.gz)
+		w.gz = nil
+	}
+}
+
+// compressible rejects encoded bodies, media formats that are compressed
+// already and event streams, which must reach the client unbuffered.
+func compressible(h http.Header) bool {
+	if h.Get("Content-Encoding") != "" {
+		return false
+	}
+	ct := h.Get("Content-Type")
+	return !strings.HasPrefix(ct, "image/") &&
+		!strings.HasPrefix(ct, "video/") &&
+		!strings.HasPrefix(ct, "text/event-stream")
+}
+
+// cacheMiddleware adds validators to successful GET responses: an ETag
+// holding the SHA-256 of the body and, when the body carries updated_at
+// timestamps, a Last-Modified of the latest one. Requests whose
+// If-None-Match or, without it, If-Modified-Since still match get 304 with
+// no body. Responses that flush, such as event streams, are sent as is.
+func cacheMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+		w := &cacheResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+		if w.streaming {
+			return
+		}
+		if w.Status() != http.StatusOK {
+			w.release()
+			return
+		}
+
+		sum := sha256.Sum256(w.buf)
+		etag := `"` + hex.EncodeToString(sum[:]) + `"`
+		h := w.Header()
+		h.Set("ETag", etag)
+		modified, hasModified := lastUpdated(w.buf)
+		if hasModified {
+			h.Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+		}
+		if notModified(c.Request, etag, modified, hasModified) {
+			w.buf = nil
+			w.ResponseWriter.WriteHeader(http.StatusNotModified)
+		}
+		w.release()
+	}
+}
+
+// cacheResponseWriter holds back a response body so cacheMiddleware can hash
+// it before anything reaches the client.
+type cacheResponseWriter struct {
+	gin.ResponseWriter
+	buf       []byte
+	streaming bool
+}
+
+func (w *cacheResponseWriter) Write(p []byte) (int, error) {
+	if w.streaming {
+		return w.ResponseWriter.Write(p)
+	}
+	w.buf = append(w.buf, p...)
+	return len(p), nil
+}
+
+func (w *cacheResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// WriteHeaderNow is deferred with the body so a 304 can still replace the
+// status.
+func (w *cacheResponseWriter) WriteHeaderNow() {
+	if w.streaming {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+// Written reports buffered output too, so handlers see the response as begun.
+func (w *cacheResponseWriter) Written() bool {
+	return len(w.buf) > 0 || w.ResponseWriter.Written()
+}
+
+// Flush sends the response on unvalidated, since a flushing handler streams.
+func (w *cacheResponseWriter) Flush() {
+	if !w.streaming {
+		w.release()
+		w.streaming = true
+	}
+	w.ResponseWriter.Flush()
+}
+
+// release writes the status and any buffered body to the underlying writer.
+func (w *cacheResponseWriter) release() {
+	w.ResponseWriter.WriteHeaderNow()
+	if len(w.buf) > 0 {
+		_, _ = w.ResponseWriter.Write(w.buf)
+		w.buf = nil
+	}
+}
+
+// notModified evaluates the conditional headers of r against the validators
+// of the response. If-None-Match takes precedence over If-Modified-Since.
+func notModified(r *http.Request, etag string, modified time.Time, hasModified bool) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == etag {
+				return true
+			}
+		}
+		return false
+	}
+	if !hasModified {
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	return !modified.Truncate(time.Second).After(since)
+}
+
+// lastUpdated returns the latest updated_at timestamp anywhere in a JSON
+// body.
+func lastUpdated(body []byte) (time.Time, bool) {
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return time.Time{}, false
+	}
+	var latest time.Time
+	var walk func(any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			for k, field := range v {
+				if raw, ok := field.(string); ok && k == "updated_at" {
+					if t, err := time.Parse(time.RFC3339Nano, raw); err == nil && t.After(latest) {
+						latest = t
+					}
+					continue
+				}
+				walk(field)
+			}
+		case []any:
+			for _, item := range v {
+				walk(item)
+			}
+		}
+	}
+	walk(v)
+	return latest, !latest.IsZero()
+}
+
+// metricsMiddleware records the count and latency of every request, labelled
+// by route template so path parameters do not explode label cardinality.
+func metricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		path := c.FullPath()
+		if path == "" {
+			path = "unmatched"
+		}
+		metrics.ObserveHTTPRequest(c.Request.Method, path, c.Writer.Status(), time.Since(start))
+	}
+}
+
+// traceIDHeader echoes the trace a request was recorded under.
+const traceIDHeader = "X-Trace-ID"
+
+// tracingMiddleware starts a server span for every request, continuing any
+// trace propagated by the caller, and exposes its trace id in X-Trace-ID.
+// Handlers inherit the span through the request context.
+func tracingMiddleware() gin.HandlerFunc {
+	tracer := otel.Tracer("todo/server")
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		ctx, span := tracer.Start(ctx, c.Request.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", c.Request.Method),
+				attribute.String("http.route", route),
+				attribute.String("url.path", c.Request.URL.Path),
+				attribute.String("client.address", c.ClientIP()),
+				attribute.String("user_agent.original", c.Request.UserAgent()),
+			))
+		defer span.End()
+
+		if sc := span.SpanContext(); sc.HasTraceID() {
+			c.Header(traceIDHeader, sc.TraceID().String())
+		}
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(
+			attribute.Int("http.response.status_code", status),
+			attribute.String("request_id", requestIDFromContext(c)),
+		)
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
+
+// requestIDMiddleware reuses the caller's X-Request-ID or generates a UUID v4,
+// echoes it on the response and stores it in the context as "request_id".
+func requestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := strings.TrimSpace(c.GetHeader(requestIDHeader))
+		if id == "" || len(id) > maxRequestIDLength {
+			id = newUUID()
+		}
+		c.Set("request_id", id)
+		c.Header(requestIDHeader, id)
+		c.Next()
+	}
+}
+
+// requestIDFromContext returns the id assigned by requestIDMiddleware.
+func requestIDFromContext(c *gin.Context) string {
+	return c.GetString("request_id")
+}
+
+// newUUID returns a random RFC 4122 version 4 UUID.
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,157 @@
+package server
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"todo/internal/models"
+	"todo/internal/storage/sqlite"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestRequestID(t *testing.T) {
+	srv, _ := newTestServer(t, Options{})
+
+	if w := do(t, srv, http.MethodGet, "/api/healthz", "", "X-Request-ID", "abc-123"); w.Header().Get("X-Request-ID") != "abc-123" {
+		t.Fatalf("X-Request-ID = %q, want the one sent", w.Header().Get("X-Request-ID"))
+	}
+	for _, sent := range []string{"", strings.Repeat("x", maxRequestIDLength+1)} {
+		w := do(t, srv, http.MethodGet, "/api/healthz", "", "X-Request-ID", sent)
+		if got := w.Header().Get("X-Request-ID"); !uuidPattern.MatchString(got) {
+			t.Fatalf("sent %d chars: X-Request-ID = %q, want a generated UUID", len(sent), got)
+		}
+	}
+}
+
+// BenchmarkListTasksCompression lists a 200-task project with and without
+// gzip and reports the response size of each.
+func BenchmarkListTasksCompression(b *testing.B) {
+	srv, store := newTestServer(b, Options{})
+	for i := 0; i < 200; i++ {
+		task := models.Task{ProjectID: 1, Title: "Task " + strconv.Itoa(i), Description: "Write the release notes for the next sprint."}
+		if _, err := store.CreateTask(context.Background(), task); err != nil {
+			b.Fatal(err)
+		}
+	}
+	for _, bc := range []struct {
+		name     string
+		encoding string
+	}{
+		{"identity", ""},
+		{"gzip", "gzip"},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			var size int
+			for i := 0; i < b.N; i++ {
+				w := do(b, srv, http.MethodGet, "/api/projects/1/tasks", "", "Accept-Encoding", bc.encoding)
+				if w.Code != http.StatusOK {
+					b.Fatalf("status = %d", w.Code)
+				}
+				if got := w.Header().Get("Content-Encoding"); got != bc.encoding {
+					b.Fatalf("Content-Encoding = %q, want %q", got, bc.encoding)
+				}
+				size = w.Body.Len()
+			}
+			b.ReportMetric(float64(size), "resp-bytes")
+		})
+	}
+}
+
+func TestOversizedBodyIsRejected(t *testing.T) {
+	srv, _ := newTestServer(t, Options{MaxBodyBytes: 64})
+	// One byte over the limit.
+	body := `{"name":"` + strings.Repeat("a", 54) + `"}`
+
+	if w := do(t, srv, http.MethodPost, "/api/projects", body); w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("declared length: status = %d, want 413: %s", w.Code, w.Body.String())
+	}
+
+	// Without a Content-Length the limit is only hit while reading the body.
+	req := httptest.NewRequest(http.MethodPost, "/api/projects", io.MultiReader(strings.NewReader(body)))
+	req.ContentLength = -1
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	srv.Engine().ServeHTTP(w, req)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("streamed body: status = %d, want 413: %s", w.Code, w.Body.String())
+	}
+
+	if w := do(t, srv, http.MethodPost, "/api/projects", `{"name":"ok"}`); w.Code != http.StatusCreated {
+		t.Fatalf("body under the limit: status = %d, want 201: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestCacheValidators(t *testing.T) {
+	srv, store := newTestServer(t, Options{})
+	task, err := store.CreateTask(context.Background(), models.Task{ProjectID: 1, Title: "cached"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const path = "/api/projects/1/tasks"
+
+	w := do(t, srv, http.MethodGet, path, "")
+	etag, modified := w.Header().Get("ETag"), w.Header().Get("Last-Modified")
+	if w.Code != http.StatusOK || etag == "" || modified == "" {
+		t.Fatalf("GET = %d, ETag %q, Last-Modified %q; want 200 with both", w.Code, etag, modified)
+	}
+	if _, err := http.ParseTime(modified); err != nil {
+		t.Fatalf("Last-Modified %q: %v", modified, err)
+	}
+
+	for _, header := range []string{"If-None-Match", "If-Modified-Since"} {
+		value := etag
+		if header == "If-Modified-Since" {
+			value = modified
+		}
+		w := do(t, srv, http.MethodGet, path, "", header, value)
+		if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+			t.Fatalf("%s: status = %d with %d body bytes, want 304 and none", header, w.Code, w.Body.Len())
+		}
+	}
+
+	if w := do(t, srv, http.MethodPut, "/api/tasks/"+itoa(task.ID), `{"title":"changed"}`); w.Code != http.StatusOK {
+		t.Fatalf("update task = %d: %s", w.Code, w.Body.String())
+	}
+	w = do(t, srv, http.MethodGet, path, "", "If-None-Match", etag)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET after write = %d, want 200", w.Code)
+	}
+	if got := w.Header().Get("ETag"); got == "" || got == etag {
+		t.Fatalf("ETag after write = %q, want a new tag", got)
+	}
+}
+
+func TestShutdownStopsBackgroundWork(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	before := runtime.NumGoroutine()
+	store, err := sqlite.Open(filepath.Join(t.TempDir(), "todo.db"), logger, sqlite.DefaultPoolConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := New(store, logger, Options{RateLimit: DefaultRateLimit, RateBurst: DefaultRateBurst})
+	srv.Shutdown()
+	srv.Shutdown()
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The database's own goroutines exit shortly after Close returns.
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Fatalf("%d goroutines left running after shutdown", n-before)
+	}
+}
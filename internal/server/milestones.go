@@ -0,0 +1,135 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"todo/internal/models"
+)
+
+type milestoneRequest struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	DueDate     string `json:"due_date"`
+	Status      string `json:"status"`
+}
+
+// toModel converts the request, parsing a YYYY-MM-DD due date.
+func (r milestoneRequest) toModel() (models.Milestone, error) {
+	m := models.Milestone{Title: r.Title, Description: r.Description, Status: r.Status}
+	var err error
+	if m.DueDate, err = parseDate(r.DueDate); err != nil {
+		return m, fmt.Errorf("due_date: %w", err)
+	}
+	return m, nil
+}
+
+// handleListMilestones returns the milestones of a project.
+func (s *Server) handleListMilestones(c *gin.Context) {
+	projectID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	milestones, err := s.store.ListMilestones(c.Request.Context(), projectID)
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"milestones": milestones})
+}
+
+// handleCreateMilestone adds a milestone to a project.
+func (s *Server) handleCreateMilestone(c *gin.Context) {
+	projectID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req milestoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	m, err := req.toModel()
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	m.ProjectID = projectID
+
+	milestone, err := s.store.CreateMilestone(c.Request.Context(), m)
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	respondSuccess(c, http.StatusCreated, gin.H{"milestone": milestone})
+}
+
+// handleGetMilestone returns a single milestone.
+func (s *Server) handleGetMilestone(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	milestone, err := s.store.GetMilestone(c.Request.Context(), id)
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"milestone": milestone})
+}
+
+// handleUpdateMilestone edits a milestone.
+func (s *Server) handleUpdateMilestone(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req milestoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	m, err := req.toModel()
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	milestone, err := s.store.UpdateMilestone(c.Request.Context(), id, m)
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"milestone": milestone})
+}
+
+// handleDeleteMilestone removes a milestone and detaches its tasks.
+func (s *Server) handleDeleteMilestone(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	if err := s.store.DeleteMilestone(c.Request.Context(), id); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"status": "deleted"})
+}
+
+// handleMilestoneProgress reports how many of a milestone's tasks are done.
+func (s *Server) handleMilestoneProgress(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	progress, err := s.store.GetMilestoneProgress(c.Request.Context(), id)
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, progress)
+}
@@ -1,18 +1,52 @@
 package server
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+
+	"todo/internal/models"
+	"todo/internal/storage"
 )
 
+// defaultDueSoonDays is the ?days window of the due-soon listing.
+const defaultDueSoonDays = 7
+
 type projectRequest struct {
-	Name  string `json:"name"`
-	Color string `json:"color"`
+	Name        string  `json:"name"`
+	Color       string  `json:"color"`
+	Description string  `json:"description"`
+	Deadline    *string `json:"deadline"`
+}
+
+// project converts the request into a model, parsing the deadline. It writes
+// the error response itself and returns false when the deadline is invalid.
+func (s *Server) project(c *gin.Context, req projectRequest) (models.Project, bool) {
+	deadline, ok := s.parseTimeInput(c, req.Deadline)
+	if !ok {
+		return models.Project{}, false
+	}
+	return models.Project{Name: req.Name, Color: req.Color, Description: req.Description, Deadline: deadline}, true
 }
 
-// handleListProjects returns all available projects.
+// handleListProjects returns all available projects; ?include_counts=true adds
+// task counts per column.
 func (s *Server) handleListProjects(c *gin.Context) {
+	if c.Query("include_counts") == "true" {
+		projects, err := s.store.ListProjectsWithTaskCounts(c.Request.Context())
+		if err != nil {
+			s.respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+		respondSuccess(c, http.StatusOK, gin.H{"projects": projects})
+		return
+	}
+
 	projects, err := s.store.ListProjects(c.Request.Context())
 	if err != nil {
 		s.respondError(c, http.StatusInternalServerError, err)
@@ -21,7 +55,8 @@ func (s *Server) handleListProjects(c *gin.Context) {
 	respondSuccess(c, http.StatusOK, gin.H{"projects": projects})
 }
 
-// handleCreateProject creates a new project entity.
+// handleCreateProject creates a new project entity with the caller, if any,
+// as its admin.
 func (s *Server) handleCreateProject(c *gin.Context) {
 	var req projectRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -29,15 +64,30 @@ func (s *Server) handleCreateProject(c *gin.Context) {
 		return
 	}
 
-	project, err := s.store.CreateProject(c.Request.Context(), req.Name, req.Color)
+	p, ok := s.project(c, req)
+	if !ok {
+		return
+	}
+	project, err := s.store.CreateProject(c.Request.Context(), p)
 	if err != nil {
+		if errors.Is(err, storage.ErrValidation) {
+			s.respondError(c, http.StatusUnprocessableEntity, err)
+			return
+		}
+		if errors.Is(err, storage.ErrConflict) {
+			s.respondError(c, http.StatusConflict, err)
+			return
+		}
 		s.respondError(c, http.StatusBadRequest, err)
 		return
 	}
+	if !s.assignProjectCreator(c, project.ID) {
+		return
+	}
 	respondSuccess(c, http.StatusCreated, gin.H{"project": project})
 }
 
-// handleUpdateProject renames or recolors an existing project.
+// handleUpdateProject replaces the details of an existing project.
 func (s *Server) handleUpdateProject(c *gin.Context) {
 	id, ok := parseID(c, "id")
 	if !ok {
@@ -50,15 +100,27 @@ func (s *Server) handleUpdateProject(c *gin.Context) {
 		return
 	}
 
-	project, err := s.store.UpdateProject(c.Request.Context(), id, req.Name, req.Color)
+	p, ok := s.project(c, req)
+	if !ok {
+		return
+	}
+	project, err := s.store.UpdateProject(c.Request.Context(), id, p)
 	if err != nil {
+		if errors.Is(err, storage.ErrValidation) {
+			s.respondError(c, http.StatusUnprocessableEntity, err)
+			return
+		}
+		if errors.Is(err, storage.ErrConflict) {
+			s.respondError(c, http.StatusConflict, err)
+			return
+		}
 		s.respondError(c, http.StatusBadRequest, err)
 		return
 	}
 	respondSuccess(c, http.StatusOK, gin.H{"project": project})
 }
 
-// handleDeleteProject removes a project and all related tasks.
+// handleDeleteProject moves a project and all related tasks to the trash.
 func (s *Server) handleDeleteProject(c *gin.Context) {
 	id, ok := parseID(c, "id")
 	if !ok {
@@ -70,3 +132,93 @@ func (s *Server) handleDeleteProject(c *gin.Context) {
 	}
 	respondSuccess(c, http.StatusOK, gin.H{"status": "deleted"})
 }
+
+type duplicateProjectRequest struct {
+	Name           string `json:"name"`
+	IncludeTasks   bool   `json:"include_tasks"`
+	IncludeDone    bool   `json:"include_done"`
+	FailOnConflict bool   `json:"fail_on_conflict"`
+}
+
+// handleDuplicateProject copies a project, optionally with its open tasks or
+// all of them, with the caller, if any, as admin of the copy. A taken name
+// gets " (copy)" appended unless fail_on_conflict asks for 409.
+func (s *Server) handleDuplicateProject(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	var req duplicateProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	project, err := s.store.DuplicateProject(c.Request.Context(), id, storage.DuplicateOptions{
+		Name:         req.Name,
+		IncludeTasks: req.IncludeTasks,
+		IncludeDone:  req.IncludeDone,
+		Rename:       !req.FailOnConflict,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrValidation):
+			s.respondError(c, http.StatusUnprocessableEntity, err)
+		case errors.Is(err, storage.ErrConflict):
+			s.respondError(c, http.StatusConflict, err)
+		default:
+			s.respondError(c, http.StatusNotFound, err)
+		}
+		return
+	}
+	if !s.assignProjectCreator(c, project.ID) {
+		return
+	}
+	respondSuccess(c, http.StatusCreated, gin.H{"project": project})
+}
+
+type projectOrderRequest struct {
+	IDs []int64 `json:"ids"`
+}
+
+// handleReorderProjects sets the manual order of projects: the listed ones
+// first, the rest after them. When project roles apply, callers can only
+// name and move the projects they are members of.
+func (s *Server) handleReorderProjects(c *gin.Context) {
+	var req projectOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	projects, err := s.store.ReorderProjects(c.Request.Context(), req.IDs)
+	if err != nil {
+		if errors.Is(err, storage.ErrValidation) {
+			s.respondError(c, http.StatusUnprocessableEntity, err)
+			return
+		}
+		s.respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"projects": projects})
+}
+
+// handleListProjectsDueSoon returns projects whose deadline falls within the
+// next ?days days (default 7).
+func (s *Server) handleListProjectsDueSoon(c *gin.Context) {
+	days := defaultDueSoonDays
+	if raw := c.Query("days"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v <= 0 {
+			s.respondError(c, http.StatusBadRequest, fmt.Errorf("days must be a positive integer"))
+			return
+		}
+		days = v
+	}
+
+	projects, err := s.store.ListProjectsDueSoon(c.Request.Context(), time.Duration(days)*24*time.Hour)
+	if err != nil {
+		s.respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"projects": projects})
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+
+	"todo/internal/models"
+)
+
+func TestProjectColorIsValidated(t *testing.T) {
+	srv, _ := newTestServer(t, Options{})
+	if w := do(t, srv, http.MethodPost, "/api/projects", `{"name":"A","color":"#gg0000"}`); w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("create with bad color = %d, want 422: %s", w.Code, w.Body.String())
+	}
+	if w := do(t, srv, http.MethodPut, "/api/projects/1", `{"name":"Main","color":"#12345"}`); w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("update with bad color = %d, want 422: %s", w.Code, w.Body.String())
+	}
+	if w := do(t, srv, http.MethodPost, "/api/projects", `{"name":"A","color":"#123456"}`); w.Code != http.StatusCreated {
+		t.Fatalf("create with good color = %d, want 201: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestReorderProjectsIsScopedToMembers(t *testing.T) {
+	srv, store := newTestServer(t, Options{JWTSecret: testSecret})
+	ctx := context.Background()
+	if _, err := store.CreateProject(ctx, models.Project{Name: "Second"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.CreateUser(ctx, "bob", testPassword, "", "member"); err != nil {
+		t.Fatal(err)
+	}
+	bob := login(t, srv, "bob", testPassword)
+	for _, name := range []string{"Bob A", "Bob B"} {
+		if w := do(t, srv, http.MethodPost, "/api/projects", `{"name":"`+name+`"}`, "Authorization", bob); w.Code != http.StatusCreated {
+			t.Fatalf("create %s = %d: %s", name, w.Code, w.Body.String())
+		}
+	}
+	order := func() []int64 {
+		t.Helper()
+		projects, err := store.ListProjects(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids := make([]int64, len(projects))
+		for i, p := range projects {
+			ids[i] = p.ID
+		}
+		return ids
+	}
+
+	if w := do(t, srv, http.MethodPut, "/api/projects/positions", `{"ids":[2,1]}`, "Authorization", bob); w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("non-member reorder = %d, want 422: %s", w.Code, w.Body.String())
+	}
+	if got := order(); !slices.Equal(got, []int64{1, 2, 3, 4}) {
+		t.Fatalf("order after refused reorder = %v, want unchanged", got)
+	}
+
+	w := do(t, srv, http.MethodPut, "/api/projects/positions", `{"ids":[4]}`, "Authorization", bob)
+	if w.Code != http.StatusOK {
+		t.Fatalf("member reorder = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Projects []models.Project `json:"projects"`
+	}
+	decode(t, w, &resp)
+	if len(resp.Projects) != 2 || resp.Projects[0].ID != 4 || resp.Projects[1].ID != 3 {
+		t.Fatalf("member sees %+v, want projects 4 and 3", resp.Projects)
+	}
+	if got := order(); !slices.Equal(got, []int64{1, 2, 4, 3}) {
+		t.Fatalf("order = %v, want bob's projects swapped in place", got)
+	}
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"todo/internal/models"
+	"todo/internal/quickadd"
+)
+
+type quickAddRequest struct {
+	Text string `json:"text"`
+	// ProjectID is used when the text names no #project.
+	ProjectID *int64 `json:"project_id"`
+	// CreateMissing creates an unknown #project instead of failing.
+	CreateMissing bool `json:"create_missing"`
+}
+
+// quickAddParsed reports how the quick-add text was interpreted.
+type quickAddParsed struct {
+	quickadd.Result
+	ProjectID      int64      `json:"project_id"`
+	ProjectCreated bool       `json:"project_created"`
+	DueDate        *time.Time `json:"due_date"`
+}
+
+// handleQuickAdd creates a task from one line of inline syntax such as
+// "Fix login bug #Backend !high @friday".
+func (s *Server) handleQuickAdd(c *gin.Context) {
+	var req quickAddRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	res, err := quickadd.Parse(req.Text)
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	parsed := quickAddParsed{Result: res}
+
+	ctx := c.Request.Context()
+	switch {
+	case res.Project != "":
+		project, err := s.store.FindProjectByName(ctx, res.Project)
+		if err != nil {
+			if !req.CreateMissing {
+				s.respondError(c, http.StatusBadRequest, err)
+				return
+			}
+			if project, err = s.store.CreateProject(ctx, models.Project{Name: res.Project}); err != nil {
+				s.respondError(c, http.StatusBadRequest, err)
+				return
+			}
+			if !s.assignProjectCreator(c, project.ID) {
+				return
+			}
+			parsed.ProjectCreated = true
+		} else if !s.checkProjectRole(c, project.ID, models.ProjectRoleMember) {
+			return
+		}
+		parsed.ProjectID = project.ID
+	case req.ProjectID != nil:
+		if !s.checkProjectRole(c, *req.ProjectID, models.ProjectRoleMember) {
+			return
+		}
+		parsed.ProjectID = *req.ProjectID
+	default:
+		s.respondError(c, http.StatusBadRequest, fmt.Errorf("name a #project or pass project_id"))
+		return
+	}
+
+	if res.Priority != "" {
+		if _, ok := models.ValidTaskPriorities[res.Priority]; !ok {
+			s.respondError(c, http.StatusBadRequest, fmt.Errorf("unknown priority %q", res.Priority))
+			return
+		}
+	}
+	dueDate, ok := s.parseTimeInput(c, &res.Due)
+	if !ok {
+		return
+	}
+	parsed.DueDate = dueDate
+
+	task, err := s.store.CreateTask(ctx, models.Task{
+		ProjectID: parsed.ProjectID,
+		Title:     res.Title,
+		Priority:  res.Priority,
+		DueDate:   dueDate,
+	})
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	respondSuccess(c, http.StatusCreated, gin.H{"task": task, "parsed": parsed})
+}
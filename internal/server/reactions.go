@@ -0,0 +1,73 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"todo/internal/storage"
+)
+
+// reactionRequest names the emoji and who reacts; author defaults to the
+// X-Changed-By header.
+type reactionRequest struct {
+	Emoji  string `json:"emoji"`
+	Author string `json:"author"`
+}
+
+func (s *Server) bindReaction(c *gin.Context) (reactionRequest, bool) {
+	var req reactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return req, false
+	}
+	if req.Author == "" {
+		req.Author = c.GetHeader("X-Changed-By")
+	}
+	return req, true
+}
+
+// handleAddReaction adds an emoji reaction to a task.
+func (s *Server) handleAddReaction(c *gin.Context) {
+	taskID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	req, ok := s.bindReaction(c)
+	if !ok {
+		return
+	}
+	err := s.store.AddReaction(c.Request.Context(), taskID, req.Emoji, req.Author)
+	if errors.Is(err, storage.ErrValidation) {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	s.respondTask(c, taskID)
+}
+
+// handleRemoveReaction withdraws an emoji reaction from a task.
+func (s *Server) handleRemoveReaction(c *gin.Context) {
+	taskID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	req, ok := s.bindReaction(c)
+	if !ok {
+		return
+	}
+	err := s.store.RemoveReaction(c.Request.Context(), taskID, req.Emoji, req.Author)
+	if errors.Is(err, storage.ErrValidation) {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	s.respondTask(c, taskID)
+}
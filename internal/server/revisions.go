@@ -0,0 +1,39 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handleListTaskRevisions returns previous titles and descriptions of a task.
+func (s *Server) handleListTaskRevisions(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	revisions, err := s.store.ListTaskRevisions(c.Request.Context(), id)
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"revisions": revisions})
+}
+
+// handleRestoreTaskRevision brings back the title and description of a revision.
+func (s *Server) handleRestoreTaskRevision(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	revisionID, ok := parseID(c, "rev")
+	if !ok {
+		return
+	}
+	task, err := s.store.RestoreTaskRevision(c.Request.Context(), id, revisionID)
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"task": task})
+}
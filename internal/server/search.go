@@ -0,0 +1,82 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+
+	"todo/internal/models"
+)
+
+// defaultSearchLimit caps each kind of result of GET /api/search without
+// ?limit.
+const defaultSearchLimit = 20
+
+// minSearchLength is the shortest query GET /api/search looks up; shorter
+// ones return no results.
+const minSearchLength = 2
+
+// handleSearch looks up projects by name and tasks by title or description,
+// optionally restricting tasks to ?project_id. Each list holds at most ?limit
+// entries.
+func (s *Server) handleSearch(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		s.respondError(c, http.StatusBadRequest, fmt.Errorf("search query must not be empty"))
+		return
+	}
+	var projectID *int64
+	if raw := c.Query("project_id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid identifier"})
+			return
+		}
+		projectID = &id
+	}
+	limit := defaultSearchLimit
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			s.respondError(c, http.StatusBadRequest, fmt.Errorf("limit must be an integer"))
+			return
+		}
+		limit = n
+	}
+	if utf8.RuneCountInString(query) < minSearchLength {
+		respondSuccess(c, http.StatusOK, gin.H{"projects": []models.Project{}, "tasks": []models.TaskSearchResult{}})
+		return
+	}
+
+	projects, err := s.store.SearchProjects(c.Request.Context(), query, limit)
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	tasks, err := s.store.SearchTasks(c.Request.Context(), projectID, query, limit)
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"projects": projects, "tasks": tasks})
+}
+
+// handleSearchProjectTasks runs a ranked full-text search over the titles and
+// descriptions of a project's tasks.
+func (s *Server) handleSearchProjectTasks(c *gin.Context) {
+	projectID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	results, err := s.store.SearchProjectTasks(c.Request.Context(), projectID, c.Query("q"))
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"tasks": results})
+}
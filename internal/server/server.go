@@ -1,76 +1,415 @@
 package server
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
-	"todo/internal/storage/sqlite"
+	"todo/internal/defaults"
+	"todo/internal/models"
 )
 
 // Server provides HTTP handlers for the Scrum board backend.
 type Server struct {
 	engine    *gin.Engine
-	store     *sqlite.Store
+	store     StorageBackend
 	logger    *slog.Logger
 	staticDir string
+	setupDone atomic.Bool
+	events    *Broadcaster
+	started   time.Time
+	// stop ends the background goroutines of the middlewares on Shutdown.
+	stop     chan struct{}
+	stopOnce sync.Once
+	workers  sync.WaitGroup
+
+	maxActivity int
+	// timezone anchors natural-language due dates such as "tomorrow".
+	timezone *time.Location
+	// maxBodyBytes caps API request bodies.
+	maxBodyBytes int64
+	// jwtSecret enables bearer token authentication when non-empty.
+	jwtSecret string
+	// rateLimit and rateBurst configure per-IP rate limiting.
+	rateLimit int
+	rateBurst int
+	// config is returned by handleGetConfig.
+	config any
+	// apiVersion is the canonical API version, served under plain /api.
+	apiVersion string
+}
+
+// webhookWorkers is the number of concurrent webhook deliveries.
+const webhookWorkers = 4
+
+// DefaultMaxActivity caps how many entries an activity feed returns.
+const DefaultMaxActivity = defaults.MaxActivity
+
+// DefaultMaxBodyBytes caps API request bodies at 1 MB.
+const DefaultMaxBodyBytes = defaults.MaxBodyBytes
+
+// Default rate limit per client IP.
+const (
+	DefaultRateLimit = defaults.RateLimit
+	DefaultRateBurst = defaults.RateBurst
+)
+
+// Options configures the middleware and routes set up by New.
+type Options struct {
+	// StaticDir holds the built frontend; empty serves the API only.
+	StaticDir string
+	// CORSOrigins lists the origins allowed to call the API cross-origin.
+	CORSOrigins []string
+	// MaxBodyBytes caps API request bodies; values below one use the default.
+	MaxBodyBytes int64
+	// JWTSecret, when set, requires a bearer token on every API route except
+	// health, setup and login.
+	JWTSecret string
+	// RateLimit is the sustained requests per second allowed per client IP
+	// with bursts of up to RateBurst; a RateLimit below one disables limiting.
+	RateLimit int
+	RateBurst int
+	// Config is the active configuration, already stripped of secrets, served
+	// to admins by GET /api/config.
+	Config any
+	// APIVersion is the canonical API version reported by API-Version and
+	// GET /api/versions; empty means DefaultAPIVersion. It must be a
+	// supported version, see SupportedAPIVersion.
+	APIVersion string
 }
 
 // New constructs the HTTP server with routes and middleware configured.
-func New(store *sqlite.Store, logger *slog.Logger, staticDir string) *Server {
+func New(store StorageBackend, logger *slog.Logger, opts Options) *Server {
 	if logger == nil {
 		logger = slog.Default()
 	}
 
+	if opts.APIVersion == "" {
+		opts.APIVersion = DefaultAPIVersion
+	}
+
 	gin.SetMode(gin.ReleaseMode)
 	router := gin.New()
 	router.Use(gin.Recovery())
+	router.Use(requestIDMiddleware())
+	router.Use(tracingMiddleware())
+	router.Use(metricsMiddleware())
 	router.Use(gin.LoggerWithWriter(gin.DefaultWriter, "/api"))
+	router.Use(corsMiddleware(opts.CORSOrigins))
+	router.Use(apiVersionMiddleware(opts.APIVersion))
 
 	srv := &Server{
 		engine:    router,
 		store:     store,
 		logger:    logger,
-		staticDir: staticDir,
+		staticDir: opts.StaticDir,
+		events:    NewBroadcaster(),
+		started:   time.Now(),
+		stop:      make(chan struct{}),
+
+		maxActivity: DefaultMaxActivity,
+		timezone:    time.Local,
+	}
+	srv.maxBodyBytes = opts.MaxBodyBytes
+	if srv.maxBodyBytes < 1 {
+		srv.maxBodyBytes = DefaultMaxBodyBytes
 	}
+	srv.jwtSecret = opts.JWTSecret
+	srv.rateLimit, srv.rateBurst = opts.RateLimit, opts.RateBurst
+	srv.config = opts.Config
+	srv.apiVersion = opts.APIVersion
 
+	store.SetEventListener(func(event string, projectID int64, data any) {
+		srv.events.Publish(Event{Type: event, ProjectID: projectID, Payload: data})
+	})
+	store.StartWebhookWorkers(webhookWorkers)
 	srv.registerRoutes()
 	return srv
 }
 
+// SetMaxActivity changes the cap on activity feed sizes; values below one are
+// ignored.
+func (s *Server) SetMaxActivity(n int) {
+	if n > 0 {
+		s.maxActivity = n
+	}
+}
+
+// SetTimezone sets the zone natural-language due dates are resolved in.
+func (s *Server) SetTimezone(loc *time.Location) {
+	if loc != nil {
+		s.timezone = loc
+	}
+}
+
+// Shutdown ends open event streams so the HTTP server can drain, and stops
+// the background pruning of the middlewares. Calling it again does nothing.
+func (s *Server) Shutdown() {
+	s.stopOnce.Do(func() {
+		s.events.Close()
+		close(s.stop)
+		s.workers.Wait()
+	})
+}
+
+// every runs fn each interval on a goroutine until Shutdown.
+func (s *Server) every(interval time.Duration, fn func(now time.Time)) {
+	s.workers.Add(1)
+	go func() {
+		defer s.workers.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stop:
+				return
+			case now := <-ticker.C:
+				fn(now)
+			}
+		}
+	}()
+}
+
 // Engine exposes the underlying Gin engine.
 func (s *Server) Engine() *gin.Engine {
 	return s.engine
 }
 
-// registerRoutes wires all API and static handlers together.
+// registerRoutes wires all API and static handlers together. The API is
+// served under /api and, for every registered version, /api/<version>.
 func (s *Server) registerRoutes() {
-	api := s.engine.Group("/api")
+	// Middlewares holding state are shared, so limits and idempotency keys
+	// apply across the prefixes.
+	var common []gin.HandlerFunc
+	if s.rateLimit > 0 {
+		common = append(common, s.rateLimitMiddleware(s.rateLimit, s.rateBurst))
+	}
+	common = append(common, maxBodyMiddleware(s.maxBodyBytes), compressionMiddleware(), cacheMiddleware())
+	idempotency := s.idempotency()
+
+	s.registerAPI(s.engine.Group("/api", common...), idempotency)
+	for _, v := range apiVersions {
+		s.registerAPI(s.engine.Group("/api/"+v.Name, common...), idempotency)
+	}
+	s.mountStatic()
+}
+
+// registerAPI registers the API routes on one prefix.
+func (s *Server) registerAPI(api *gin.RouterGroup, idempotency gin.HandlerFunc) {
 	{
+		api.GET("/versions", s.handleListVersions)
 		api.GET("/healthz", s.handleHealth)
+		api.GET("/readyz", s.handleReady)
+		api.GET("/setup/status", s.handleSetupStatus)
+		api.POST("/setup", s.handleSetup)
+	}
 
-		projects := api.Group("/projects")
+	// Setup stays open so the first user can be created and log in. API keys
+	// work with or without JWT; only JWT makes credentials mandatory.
+	authed := api.Group("", s.apiKeyMiddleware())
+	if s.jwtSecret != "" {
+		api.POST("/auth/login", s.handleLogin)
+		authed.Use(s.jwtMiddleware(s.jwtSecret))
+	}
+
+	guarded := authed.Group("", s.requireSetup, s.captureChangedBy, s.captureSession, s.scopeProjects, s.auditWrites, idempotency)
+	{
+		// Project roles only apply with JWT authentication; the *Project
+		// middlewares resolve the project of the resource named by :id.
+		projectViewer := s.requireProjectRole(models.ProjectRoleViewer)
+		projectMember := s.requireProjectRole(models.ProjectRoleMember)
+		projectAdmin := s.requireProjectRole(models.ProjectRoleAdmin)
+		taskProject := s.projectOf("task")
+		labelProject := s.projectOf("label")
+		statusProject := s.projectOf("status")
+		commentProject := s.projectOf("comment")
+		checklistProject := s.projectOf("checklist")
+		timeEntryProject := s.projectOf("time-entry")
+		sprintProject := s.projectOf("sprint")
+		milestoneProject := s.projectOf("milestone")
+		webhookProject := s.projectOf("webhook")
+
+		projects := guarded.Group("/projects")
 		{
 			projects.GET("", s.handleListProjects)
 			projects.POST("", s.handleCreateProject)
-			projects.PUT(":id", s.handleUpdateProject)
-			projects.DELETE(":id", s.handleDeleteProject)
-			projects.GET(":id/tasks", s.handleListTasks)
-			projects.POST(":id/tasks", s.handleCreateTask)
+			projects.GET("due-soon", s.handleListProjectsDueSoon)
+			projects.POST("import", s.handleImportProject)
+			projects.PUT("positions", s.handleReorderProjects)
+			projects.PUT(":id", projectAdmin, s.handleUpdateProject)
+			projects.DELETE(":id", projectAdmin, s.handleDeleteProject)
+			projects.POST(":id/duplicate", projectMember, s.handleDuplicateProject)
+			projects.GET(":id/tasks", projectViewer, s.handleListTasks)
+			projects.POST(":id/tasks", projectMember, s.handleCreateTask)
+			projects.GET(":id/tasks/number/:n", projectViewer, s.handleGetTaskByNumber)
+			projects.GET(":id/tasks/search", projectViewer, s.handleSearchProjectTasks)
+			projects.GET(":id/tasks/export.csv", projectViewer, s.handleExportTasksCSV)
+			projects.POST(":id/tasks/import", projectMember, s.handleImportTasksCSV)
+			projects.POST(":id/tasks/from-template/:templateID", projectMember, s.handleCreateTaskFromTemplate)
+			projects.GET(":id/board", projectViewer, s.handleGetBoard)
+			projects.GET(":id/overdue-tasks", projectViewer, s.handleListProjectOverdueTasks)
+			projects.GET(":id/task-counts", projectViewer, s.handleGetTaskCounts)
+			projects.GET(":id/export", projectViewer, s.handleExportProject)
+			projects.GET(":id/export.md", projectViewer, s.handleExportProjectMarkdown)
+			projects.GET(":id/columns", projectViewer, s.handleListStatuses)
+			projects.POST(":id/columns", projectAdmin, s.handleCreateStatus)
+			projects.PUT(":id/columns/:status", projectAdmin, s.handleUpdateColumn)
+			projects.DELETE(":id/columns/:status", projectAdmin, s.handleDeleteColumn)
+			projects.POST(":id/columns/:status/complete", projectMember, s.handleCompleteColumn)
+			projects.POST(":id/columns/done/clear", projectMember, s.handleClearDoneColumn)
+			projects.GET(":id/assignees", projectViewer, s.handleListAssignees)
+			projects.GET(":id/sprints", projectViewer, s.handleListSprints)
+			projects.POST(":id/sprints", projectMember, s.handleCreateSprint)
+			projects.GET(":id/milestones", projectViewer, s.handleListMilestones)
+			projects.POST(":id/milestones", projectMember, s.handleCreateMilestone)
+			projects.GET(":id/velocity", projectViewer, s.handleProjectVelocity)
+			projects.GET(":id/stats", projectViewer, s.handleGetProjectStats)
+			projects.GET(":id/throughput", projectViewer, s.handleGetThroughput)
+			projects.GET(":id/report/accuracy", projectViewer, s.handleGetAccuracyReport)
+			projects.GET(":id/report/cycle-time", projectViewer, s.handleGetCycleTimeReport)
+			projects.GET(":id/report/velocity", projectViewer, s.handleGetVelocityReport)
+			projects.GET(":id/activity", projectViewer, s.handleListProjectActivity)
+			projects.GET(":id/events", projectViewer, s.handleProjectSSE)
+			projects.GET(":id/webhooks", projectAdmin, s.handleListWebhooks)
+			projects.POST(":id/webhooks", projectAdmin, s.handleCreateWebhook)
+			projects.GET(":id/labels", projectViewer, s.handleListLabels)
+			projects.POST(":id/labels", projectMember, s.handleCreateLabel)
+			// Deprecated alias of :id/columns.
+			statusesAlias := deprecatedAlias(statusesDeprecated, "/statuses", "/columns")
+			projects.GET(":id/statuses", statusesAlias, projectViewer, s.handleListStatuses)
+			projects.POST(":id/statuses", statusesAlias, projectAdmin, s.handleCreateStatus)
+			projects.GET(":id/members", projectAdmin, s.handleListProjectMembers)
+			projects.POST(":id/members", projectAdmin, s.handleAddProjectMember)
+			projects.DELETE(":id/members/:userID", projectAdmin, s.handleRemoveProjectMember)
 		}
 
-		api.PUT("/tasks/:id", s.handleUpdateTask)
-		api.DELETE("/tasks/:id", s.handleDeleteTask)
-	}
+		guarded.GET("/tasks", s.handleListAllTasks)
+		guarded.GET("/tasks/overdue", s.handleListOverdueTasks)
+		guarded.GET("/overdue-tasks", s.handleListOverdueTasks)
+		guarded.GET("/tasks/upcoming", s.handleListUpcomingTasks)
+		guarded.GET("/tasks/recent", s.handleListRecentTasks)
+		guarded.PATCH("/tasks/bulk", s.handleBulkUpdateStatus)
+		guarded.PUT("/tasks/:id", taskProject, projectMember, s.handleUpdateTask)
+		guarded.DELETE("/tasks/:id", taskProject, projectMember, s.handleDeleteTask)
+		guarded.POST("/tasks/:id/move", taskProject, projectMember, s.handleMoveTask)
+		guarded.POST("/tasks/:id/duplicate", taskProject, projectMember, s.handleDuplicateTask)
+		guarded.GET("/tasks/:id/activity", taskProject, projectViewer, s.handleListTaskActivity)
+		guarded.GET("/tasks/:id/status-log", taskProject, projectViewer, s.handleListStatusLog)
+		guarded.GET("/tasks/:id/revisions", taskProject, projectViewer, s.handleListTaskRevisions)
+		guarded.POST("/tasks/:id/revisions/:rev/restore", taskProject, projectMember, s.handleRestoreTaskRevision)
+		guarded.POST("/tasks/:id/snooze", taskProject, projectMember, s.handleSnoozeTask)
+		guarded.DELETE("/tasks/:id/snooze", taskProject, projectMember, s.handleUnsnoozeTask)
+		guarded.PUT("/tasks/:id/watchers/:name", taskProject, projectMember, s.handleAddWatcher)
+		guarded.DELETE("/tasks/:id/watchers/:name", taskProject, projectMember, s.handleRemoveWatcher)
+		guarded.POST("/tasks/:id/reactions", taskProject, projectMember, s.handleAddReaction)
+		guarded.DELETE("/tasks/:id/reactions", taskProject, projectMember, s.handleRemoveReaction)
+		guarded.GET("/tasks/:id/subtasks", taskProject, projectViewer, s.handleListSubTasks)
+		guarded.POST("/tasks/:id/labels/:labelID", taskProject, projectMember, s.handleAddTaskLabel)
+		guarded.DELETE("/tasks/:id/labels/:labelID", taskProject, projectMember, s.handleRemoveTaskLabel)
+		guarded.GET("/tasks/:id/comments", taskProject, projectViewer, s.handleListComments)
+		guarded.POST("/tasks/:id/comments", taskProject, projectMember, s.handleCreateComment)
+		guarded.GET("/tasks/:id/checklist", taskProject, projectViewer, s.handleListChecklist)
+		guarded.POST("/tasks/:id/checklist", taskProject, projectMember, s.handleCreateChecklistItem)
+		guarded.PUT("/tasks/:id/checklist/order", taskProject, projectMember, s.handleReorderChecklist)
+		guarded.POST("/tasks/:id/timer/start", taskProject, projectMember, s.handleStartTimer)
+		guarded.POST("/tasks/:id/timer/stop", taskProject, projectMember, s.handleStopTimer)
+		guarded.GET("/tasks/:id/time", taskProject, projectViewer, s.handleListTimeEntries)
+		guarded.GET("/tasks/:id/dependencies", taskProject, projectViewer, s.handleListDependencies)
+		guarded.POST("/tasks/:id/dependencies", taskProject, projectMember, s.handleAddDependency)
+		guarded.DELETE("/tasks/:id/dependencies/:blockerID", taskProject, projectMember, s.handleRemoveDependency)
 
-	s.mountStatic()
-}
+		guarded.PUT("/labels/:id", labelProject, projectMember, s.handleUpdateLabel)
+		guarded.DELETE("/labels/:id", labelProject, projectMember, s.handleDeleteLabel)
+		guarded.PUT("/statuses/:id", statusProject, projectAdmin, s.handleUpdateStatus)
+		guarded.DELETE("/statuses/:id", statusProject, projectAdmin, s.handleDeleteStatus)
+
+		guarded.DELETE("/comments/:id", commentProject, projectMember, s.handleDeleteComment)
+
+		guarded.PUT("/checklist/:id", checklistProject, projectMember, s.handleUpdateChecklistItem)
+		guarded.DELETE("/checklist/:id", checklistProject, projectMember, s.handleDeleteChecklistItem)
+
+		guarded.DELETE("/time-entries/:id", timeEntryProject, projectMember, s.handleDeleteTimeEntry)
+
+		sprints := guarded.Group("/sprints")
+		{
+			sprints.GET(":id", sprintProject, projectViewer, s.handleGetSprint)
+			sprints.PUT(":id", sprintProject, projectMember, s.handleUpdateSprint)
+			sprints.DELETE(":id", sprintProject, projectMember, s.handleDeleteSprint)
+			sprints.POST(":id/close", sprintProject, projectMember, s.handleCloseSprint)
+			sprints.GET(":id/velocity", sprintProject, projectViewer, s.handleSprintVelocity)
+			sprints.GET(":id/burndown", sprintProject, projectViewer, s.handleSprintBurndown)
+		}
+
+		milestones := guarded.Group("/milestones")
+		{
+			milestones.GET(":id", milestoneProject, projectViewer, s.handleGetMilestone)
+			milestones.PUT(":id", milestoneProject, projectMember, s.handleUpdateMilestone)
+			milestones.DELETE(":id", milestoneProject, projectMember, s.handleDeleteMilestone)
+			milestones.GET(":id/progress", milestoneProject, projectViewer, s.handleMilestoneProgress)
+		}
 
-// handleHealth provides a basic readiness endpoint.
-func (s *Server) handleHealth(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+		webhooks := guarded.Group("/webhooks")
+		{
+			webhooks.PUT(":id", webhookProject, projectAdmin, s.handleUpdateWebhook)
+			webhooks.DELETE(":id", webhookProject, projectAdmin, s.handleDeleteWebhook)
+			webhooks.GET(":id/deliveries", webhookProject, projectAdmin, s.handleListWebhookDeliveries)
+			webhooks.POST(":id/test", webhookProject, projectAdmin, s.handleTestWebhook)
+		}
+
+		filters := guarded.Group("/filters")
+		{
+			filters.GET("", s.handleListFilters)
+			filters.POST("", s.handleCreateFilter)
+			filters.GET(":id", s.handleGetFilter)
+			filters.PUT(":id", s.handleUpdateFilter)
+			filters.DELETE(":id", s.handleDeleteFilter)
+			filters.GET(":id/tasks", s.handleListFilterTasks)
+		}
+
+		templates := guarded.Group("/templates")
+		{
+			templates.GET("", s.handleListTemplates)
+			templates.POST("", s.handleCreateTemplate)
+			templates.GET(":id", s.handleGetTemplate)
+			templates.PUT(":id", s.handleUpdateTemplate)
+			templates.DELETE(":id", s.handleDeleteTemplate)
+		}
+
+		guarded.GET("/search", s.handleSearch)
+		guarded.GET("/stats", s.handleGetDashboardStats)
+		guarded.GET("/activity", s.handleListActivity)
+		guarded.GET("/events", s.handleSSE)
+		guarded.POST("/quick", s.handleQuickAdd)
+		guarded.POST("/undo", s.handleUndo)
+		guarded.GET("/config", s.requireAdmin, s.handleGetConfig)
+		guarded.GET("/admin/stats", s.requireAdmin, s.handleGetAdminStats)
+		guarded.GET("/admin/audit", s.requireAdmin, s.handleListAudit)
+		guarded.GET("/admin/db/version", s.requireAdmin, s.handleGetDBVersion)
+		guarded.GET("/admin/db/stats", s.requireAdmin, s.handleGetDBStats)
+		guarded.POST("/admin/db/vacuum", s.requireAdmin, s.handleVacuumDB)
+		guarded.GET("/users/me", s.handleGetCurrentUser)
+		guarded.GET("/users", s.requireAdmin, s.handleListUsers)
+		guarded.POST("/users", s.requireAdmin, s.handleCreateUser)
+		guarded.PUT("/users/:id", s.handleUpdateUser)
+		guarded.DELETE("/users/:id", s.requireAdmin, s.handleDeleteUser)
+		guarded.GET("/api-keys", s.handleListAPIKeys)
+		guarded.POST("/api-keys", s.handleCreateAPIKey)
+		guarded.DELETE("/api-keys/:id", s.handleRevokeAPIKey)
+
+		trash := guarded.Group("/trash")
+		{
+			trash.GET("", s.handleListTrash)
+			trash.POST("/tasks/:id/restore", taskProject, projectMember, s.handleRestoreTask)
+			trash.POST("/projects/:id/restore", projectAdmin, s.handleRestoreProject)
+			trash.POST("/purge", s.requireAdmin, s.handlePurgeTrash)
+		}
+	}
 }
 
 // parseID converts a path parameter to int64 with error handling.
@@ -84,10 +423,23 @@ func parseID(c *gin.Context, name string) (int64, bool) {
 	return id, true
 }
 
-// respondError logs the error and returns a JSON payload.
+// respondError logs the error and returns a JSON payload. Server errors are
+// logged at error level and client errors at warn level.
 func (s *Server) respondError(c *gin.Context, status int, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		status = http.StatusRequestEntityTooLarge
+	}
 	if err != nil {
-		s.logger.Error("request failed", slog.String("path", c.FullPath()), slog.String("error", err.Error()))
+		level := slog.LevelWarn
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		s.logger.LogAttrs(c.Request.Context(), level, "request failed",
+			slog.String("request_id", requestIDFromContext(c)),
+			slog.String("path", c.FullPath()),
+			slog.Int("status", status),
+			slog.String("error", err.Error()))
 	}
 	c.JSON(status, gin.H{"error": err.Error()})
 }
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"todo/internal/storage"
+	"todo/internal/storage/sqlite"
+)
+
+const (
+	testAdmin    = "admin"
+	testPassword = "secret123"
+	testSecret   = "s3cr3t"
+)
+
+// newTestServer returns a server over a fresh database whose setup created
+// testAdmin and the project "Main" with id 1.
+func newTestServer(t testing.TB, opts Options) (*Server, *sqlite.Store) {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	store, err := sqlite.Open(filepath.Join(t.TempDir(), "todo.db"), logger, sqlite.DefaultPoolConfig())
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	if _, _, err := store.CompleteSetup(context.Background(), storage.SetupInput{
+		Username:    testAdmin,
+		Password:    testPassword,
+		ProjectName: "Main",
+	}); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	gin.DefaultWriter = io.Discard
+	srv := New(store, logger, opts)
+	t.Cleanup(srv.Shutdown)
+	return srv, store
+}
+
+// do sends a request to srv and returns the recorded response. The headers
+// are given as name, value pairs.
+func do(t testing.TB, srv *Server, method, path, body string, headers ...string) *httptest.ResponseRecorder {
+	t.Helper()
+	var r io.Reader
+	if body != "" {
+		r = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, path, r)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	w := httptest.NewRecorder()
+	srv.Engine().ServeHTTP(w, req)
+	return w
+}
+
+// decode unmarshals a response body into v.
+func decode(t testing.TB, w *httptest.ResponseRecorder, v any) {
+	t.Helper()
+	if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Fatalf("decode %q: %v", w.Body.String(), err)
+	}
+}
+
+// login returns a bearer token header value for username.
+func login(t *testing.T, srv *Server, username, password string) string {
+	t.Helper()
+	w := do(t, srv, http.MethodPost, "/api/auth/login", `{"username":"`+username+`","password":"`+password+`"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("login %s: %d %s", username, w.Code, w.Body.String())
+	}
+	var resp struct {
+		Token string `json:"token"`
+	}
+	decode(t, w, &resp)
+	return "Bearer " + resp.Token
+}
+
+func itoa(id int64) string {
+	return strconv.FormatInt(id, 10)
+}
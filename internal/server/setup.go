@@ -0,0 +1,78 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"todo/internal/storage"
+)
+
+type setupRequest struct {
+	Username string            `json:"username"`
+	Password string            `json:"password"`
+	Project  projectRequest    `json:"project"`
+	Template string            `json:"template"`
+	Settings map[string]string `json:"settings"`
+}
+
+// handleSetupStatus reports whether the first-run wizard must be shown.
+func (s *Server) handleSetupStatus(c *gin.Context) {
+	status, err := s.store.SetupStatus(c.Request.Context())
+	if err != nil {
+		s.respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, status)
+}
+
+// handleSetup runs the first-run wizard and disables it afterwards.
+func (s *Server) handleSetup(c *gin.Context) {
+	var req setupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	user, project, err := s.store.CompleteSetup(c.Request.Context(), storage.SetupInput{
+		Username:     req.Username,
+		Password:     req.Password,
+		ProjectName:  req.Project.Name,
+		ProjectColor: req.Project.Color,
+		Template:     req.Template,
+		Settings:     req.Settings,
+	})
+	if errors.Is(err, storage.ErrSetupCompleted) {
+		s.setupDone.Store(true)
+		s.respondError(c, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	s.setupDone.Store(true)
+	respondSuccess(c, http.StatusCreated, gin.H{"user": user, "project": project})
+}
+
+// requireSetup rejects API calls until the first-run wizard has completed.
+func (s *Server) requireSetup(c *gin.Context) {
+	if s.setupDone.Load() {
+		c.Next()
+		return
+	}
+
+	done, err := s.store.SetupCompleted(c.Request.Context())
+	if err != nil {
+		s.respondError(c, http.StatusInternalServerError, err)
+		c.Abort()
+		return
+	}
+	if !done {
+		c.AbortWithStatusJSON(http.StatusPreconditionRequired, gin.H{"error": "setup_required"})
+		return
+	}
+	s.setupDone.Store(true)
+	c.Next()
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"todo/internal/storage"
+)
+
+type snoozeRequest struct {
+	// Until is RFC3339 or a phrase such as "next monday 9am".
+	Until *string `json:"until"`
+	// Duration is relative to now, e.g. "90m", "4h" or "3d".
+	Duration *string `json:"duration"`
+}
+
+// handleSnoozeTask hides a task from the board until a time given either as
+// until or as duration.
+func (s *Server) handleSnoozeTask(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req snoozeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	if (req.Until == nil) == (req.Duration == nil) {
+		s.respondError(c, http.StatusBadRequest, fmt.Errorf("give either until or duration"))
+		return
+	}
+
+	var until *time.Time
+	if req.Duration != nil {
+		d, err := parseSnoozeDuration(*req.Duration)
+		if err != nil {
+			s.respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		t := time.Now().Add(d)
+		until = &t
+	} else {
+		if until, ok = s.parseTimeInput(c, req.Until); !ok {
+			return
+		}
+		if until == nil {
+			s.respondError(c, http.StatusBadRequest, fmt.Errorf("until must not be empty"))
+			return
+		}
+	}
+	s.snooze(c, id, until)
+}
+
+// handleUnsnoozeTask puts a snoozed task back on the board right away.
+func (s *Server) handleUnsnoozeTask(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	s.snooze(c, id, nil)
+}
+
+func (s *Server) snooze(c *gin.Context, id int64, until *time.Time) {
+	task, err := s.store.SnoozeTask(c.Request.Context(), id, until)
+	if errors.Is(err, storage.ErrValidation) {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"task": task})
+}
+
+// parseSnoozeDuration extends time.ParseDuration with whole days ("3d").
+func parseSnoozeDuration(raw string) (time.Duration, error) {
+	raw = strings.TrimSpace(raw)
+	var (
+		d   time.Duration
+		err error
+	)
+	if days, ok := strings.CutSuffix(raw, "d"); ok {
+		n, convErr := strconv.Atoi(days)
+		d, err = time.Duration(n)*24*time.Hour, convErr
+	} else {
+		d, err = time.ParseDuration(raw)
+	}
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("duration must be positive, like 90m, 4h or 3d")
+	}
+	return d, nil
+}
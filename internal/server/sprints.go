@@ -0,0 +1,216 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+type sprintRequest struct {
+	Name     string `json:"name"`
+	Goal     string `json:"goal"`
+	StartsAt string `json:"starts_at"`
+	EndsAt   string `json:"ends_at"`
+	Status   string `json:"status"`
+}
+
+// toModel converts the request, parsing YYYY-MM-DD dates.
+func (r sprintRequest) toModel() (models.Sprint, error) {
+	sp := models.Sprint{Name: r.Name, Goal: r.Goal, Status: r.Status}
+	var err error
+	if sp.StartsAt, err = parseDate(r.StartsAt); err != nil {
+		return sp, fmt.Errorf("starts_at: %w", err)
+	}
+	if sp.EndsAt, err = parseDate(r.EndsAt); err != nil {
+		return sp, fmt.Errorf("ends_at: %w", err)
+	}
+	return sp, nil
+}
+
+// parseDate parses an optional YYYY-MM-DD value.
+func parseDate(raw string) (*time.Time, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	t, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		return nil, fmt.Errorf("expected date as YYYY-MM-DD")
+	}
+	return &t, nil
+}
+
+// handleListSprints returns the sprints of a project.
+func (s *Server) handleListSprints(c *gin.Context) {
+	projectID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	sprints, err := s.store.ListSprints(c.Request.Context(), projectID)
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"sprints": sprints})
+}
+
+// handleCreateSprint plans a new sprint in a project.
+func (s *Server) handleCreateSprint(c *gin.Context) {
+	projectID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req sprintRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	sp, err := req.toModel()
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	sp.ProjectID = projectID
+
+	sprint, err := s.store.CreateSprint(c.Request.Context(), sp)
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	respondSuccess(c, http.StatusCreated, gin.H{"sprint": sprint})
+}
+
+// handleGetSprint returns a single sprint.
+func (s *Server) handleGetSprint(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	sprint, err := s.store.GetSprint(c.Request.Context(), id)
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"sprint": sprint})
+}
+
+// handleUpdateSprint edits a sprint.
+func (s *Server) handleUpdateSprint(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req sprintRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	sp, err := req.toModel()
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	sprint, err := s.store.UpdateSprint(c.Request.Context(), id, sp)
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"sprint": sprint})
+}
+
+// handleCloseSprint closes a sprint and returns unfinished tasks to the backlog.
+func (s *Server) handleCloseSprint(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	sprint, err := s.store.CloseSprint(c.Request.Context(), id)
+	if errors.Is(err, storage.ErrConflict) {
+		s.respondError(c, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"sprint": sprint})
+}
+
+// handleSprintVelocity reports planned vs. completed story points of a sprint.
+func (s *Server) handleSprintVelocity(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	v, err := s.store.GetSprintVelocity(c.Request.Context(), id)
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"planned": v.Planned, "completed": v.Completed})
+}
+
+// handleProjectVelocity returns the velocity of the last ?last=N (default 5)
+// closed sprints of a project.
+func (s *Server) handleProjectVelocity(c *gin.Context) {
+	projectID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	last := 5
+	if raw := c.Query("last"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			s.respondError(c, http.StatusBadRequest, fmt.Errorf("last must be a positive integer"))
+			return
+		}
+		last = n
+	}
+	velocity, err := s.store.ListProjectVelocity(c.Request.Context(), projectID, last)
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, velocity)
+}
+
+// handleSprintBurndown returns the sprint dates and the daily burndown series
+// up to today.
+func (s *Server) handleSprintBurndown(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	burndown, err := s.store.GetSprintBurndown(c.Request.Context(), id)
+	if errors.Is(err, storage.ErrValidation) {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, burndown)
+}
+
+// handleDeleteSprint removes a sprint.
+func (s *Server) handleDeleteSprint(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	if err := s.store.DeleteSprint(c.Request.Context(), id); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"status": "deleted"})
+}
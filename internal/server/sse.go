@@ -0,0 +1,163 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sseKeepalive is how often an idle stream receives a comment line so
+// proxies do not close it.
+const sseKeepalive = 15 * time.Second
+
+// Event is a board change pushed to SSE subscribers.
+type Event struct {
+	Type      string `json:"type"`
+	ProjectID int64  `json:"project_id"`
+	Payload   any    `json:"payload"`
+}
+
+// Broadcaster fans events out to subscribers of a project. Subscribers of
+// project 0 receive the events of every project.
+type Broadcaster struct {
+	mu     sync.Mutex // serializes writers of subs
+	subs   sync.Map   // projectID -> []chan Event
+	closed chan struct{}
+	once   sync.Once
+}
+
+// NewBroadcaster returns a broadcaster without subscribers.
+func NewBroadcaster() *Broadcaster {
+	return &Broadcaster{closed: make(chan struct{})}
+}
+
+// Subscribe registers a channel for the events of projectID (0 for all).
+func (b *Broadcaster) Subscribe(projectID int64) chan Event {
+	ch := make(chan Event, 16)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	current, _ := b.subs.Load(projectID)
+	list, _ := current.([]chan Event)
+	b.subs.Store(projectID, append(append([]chan Event{}, list...), ch))
+	return ch
+}
+
+// Unsubscribe removes and closes a channel returned by Subscribe.
+func (b *Broadcaster) Unsubscribe(projectID int64, ch chan Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	current, _ := b.subs.Load(projectID)
+	list, _ := current.([]chan Event)
+	kept := make([]chan Event, 0, len(list))
+	for _, c := range list {
+		if c != ch {
+			kept = append(kept, c)
+		}
+	}
+	if len(kept) == 0 {
+		b.subs.Delete(projectID)
+	} else {
+		b.subs.Store(projectID, kept)
+	}
+	close(ch)
+}
+
+// Publish delivers ev to the subscribers of its project and to the global
+// subscribers. Slow subscribers miss events rather than block the caller.
+func (b *Broadcaster) Publish(ev Event) {
+	for _, key := range []int64{ev.ProjectID, 0} {
+		current, ok := b.subs.Load(key)
+		if !ok {
+			continue
+		}
+		for _, ch := range current.([]chan Event) {
+			select {
+			case ch <- ev:
+			default:
+			}
+		}
+		if ev.ProjectID == 0 {
+			break
+		}
+	}
+}
+
+// Close ends every open stream.
+func (b *Broadcaster) Close() {
+	b.once.Do(func() { close(b.closed) })
+}
+
+// handleSSE streams the events of every project the caller may view.
+func (s *Server) handleSSE(c *gin.Context) {
+	s.streamEvents(c, 0)
+}
+
+// handleProjectSSE streams the events of a single project.
+func (s *Server) handleProjectSSE(c *gin.Context) {
+	projectID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	if _, err := s.store.GetProject(c.Request.Context(), projectID); err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	s.streamEvents(c, projectID)
+}
+
+// canView reports whether the caller may see the events of a project.
+func (s *Server) canView(c *gin.Context, projectID int64) bool {
+	if !s.projectRolesApply(c) {
+		return true
+	}
+	userID, _, _ := currentUser(c)
+	role, err := s.store.ProjectRole(c.Request.Context(), projectID, userID)
+	if err != nil {
+		s.logger.Error("project role", slog.String("request_id", requestIDFromContext(c)), slog.String("error", err.Error()))
+		return false
+	}
+	return role != ""
+}
+
+// streamEvents writes events as text/event-stream until the client goes away
+// or the server shuts down.
+func (s *Server) streamEvents(c *gin.Context, projectID int64) {
+	ch := s.events.Subscribe(projectID)
+	defer s.events.Unsubscribe(projectID, ch)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	keepalive := time.NewTicker(sseKeepalive)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-s.events.closed:
+			return
+		case <-keepalive.C:
+			fmt.Fprint(c.Writer, ": keepalive\n\n")
+		case ev := <-ch:
+			if projectID == 0 && !s.canView(c, ev.ProjectID) {
+				continue
+			}
+			data, err := json.Marshal(ev)
+			if err != nil {
+				s.logger.Error("encode event", slog.String("request_id", requestIDFromContext(c)), slog.String("error", err.Error()))
+				continue
+			}
+			fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", ev.Type, data)
+		}
+		c.Writer.Flush()
+	}
+}
@@ -0,0 +1,151 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"todo/internal/storage"
+)
+
+// handleGetProjectStats returns task counts per column for a project along
+// with its open tasks and the last week's activity.
+func (s *Server) handleGetProjectStats(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	stats, err := s.store.GetProjectStats(c.Request.Context(), id)
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"stats": stats})
+}
+
+// handleGetDashboardStats returns task counts for all projects.
+func (s *Server) handleGetDashboardStats(c *gin.Context) {
+	stats, err := s.store.GetDashboardStats(c.Request.Context())
+	if err != nil {
+		s.respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"stats": stats})
+}
+
+// handleGetThroughput counts the tasks of a project completed between the
+// ?from and ?to dates (YYYY-MM-DD, both inclusive).
+func (s *Server) handleGetThroughput(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	from, err := parseDate(c.Query("from"))
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, fmt.Errorf("from: %w", err))
+		return
+	}
+	to, err := parseDate(c.Query("to"))
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, fmt.Errorf("to: %w", err))
+		return
+	}
+	if from == nil || to == nil {
+		s.respondError(c, http.StatusBadRequest, fmt.Errorf("from and to are required"))
+		return
+	}
+	if to.Before(*from) {
+		s.respondError(c, http.StatusBadRequest, fmt.Errorf("to must not be before from"))
+		return
+	}
+
+	tasks, err := s.store.ListCompletedTasksBetween(c.Request.Context(), id, *from, to.AddDate(0, 0, 1))
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{
+		"from":      from.Format("2006-01-02"),
+		"to":        to.Format("2006-01-02"),
+		"completed": len(tasks),
+	})
+}
+
+// handleGetAccuracyReport compares story point estimates with actual cycle
+// and tracked time for the tasks of a project.
+func (s *Server) handleGetAccuracyReport(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	report, err := s.store.GetAccuracyReport(c.Request.Context(), id)
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"report": report})
+}
+
+// defaultCycleTimeWindow is the ?window of the cycle time report.
+const defaultCycleTimeWindow = 30 * 24 * time.Hour
+
+// handleGetCycleTimeReport reports lead and cycle times of the tasks
+// completed within ?window (like 30d or 72h, default 30d).
+func (s *Server) handleGetCycleTimeReport(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	window := defaultCycleTimeWindow
+	if raw := c.Query("window"); raw != "" {
+		d, err := parseSnoozeDuration(raw)
+		if err != nil {
+			s.respondError(c, http.StatusBadRequest, fmt.Errorf("window: %w", err))
+			return
+		}
+		window = d
+	}
+	report, err := s.store.GetCycleTimeReport(c.Request.Context(), id, time.Now().Add(-window))
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"report": report})
+}
+
+// defaultVelocityPeriods is how many sprints or weeks GET
+// /projects/:id/report/velocity covers without ?sprints.
+const defaultVelocityPeriods = 6
+
+// handleGetVelocityReport reports the points and tasks completed in each of
+// the last ?sprints (default 6) closed sprints, or calendar weeks for
+// projects without sprints, with rolling averages.
+func (s *Server) handleGetVelocityReport(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	n := defaultVelocityPeriods
+	if raw := c.Query("sprints"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil {
+			s.respondError(c, http.StatusBadRequest, fmt.Errorf("sprints must be an integer"))
+			return
+		}
+		n = v
+	}
+	report, err := s.store.GetVelocityReport(c.Request.Context(), id, n)
+	if err != nil {
+		if errors.Is(err, storage.ErrValidation) {
+			s.respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"report": report})
+}
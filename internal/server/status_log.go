@@ -0,0 +1,22 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handleListStatusLog returns the status columns a task has passed through
+// and how long it stayed in each.
+func (s *Server) handleListStatusLog(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	entries, err := s.store.ListStatusLog(c.Request.Context(), id)
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"status_log": entries})
+}
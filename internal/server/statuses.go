@@ -0,0 +1,216 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+type statusRequest struct {
+	Name         *string `json:"name"`
+	Title        *string `json:"title"`
+	Color        *string `json:"color"`
+	DisplayOrder *int    `json:"display_order"`
+	IsTerminal   *bool   `json:"is_terminal"`
+	WIPLimit     *int    `json:"wip_limit"`
+	WIPMode      *string `json:"wip_mode"`
+}
+
+// respondStatusError maps status store errors: 400 for invalid input, 409
+// for conflicts and 404 otherwise.
+func (s *Server) respondStatusError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, storage.ErrValidation):
+		s.respondError(c, http.StatusBadRequest, err)
+	case errors.Is(err, storage.ErrConflict):
+		s.respondError(c, http.StatusConflict, err)
+	default:
+		s.respondError(c, http.StatusNotFound, err)
+	}
+}
+
+// columnID resolves the :status column of the :id project to its status id.
+func (s *Server) columnID(c *gin.Context) (int64, bool) {
+	projectID, ok := parseID(c, "id")
+	if !ok {
+		return 0, false
+	}
+	status, err := s.store.GetStatusByName(c.Request.Context(), projectID, c.Param("status"))
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return 0, false
+	}
+	return status.ID, true
+}
+
+// wipExceeded reports whether any column the tasks are in holds more tasks
+// than its WIP limit, which warn mode columns allow. It reports false as its
+// second result after responding with an error.
+func (s *Server) wipExceeded(c *gin.Context, tasks ...models.Task) (bool, bool) {
+	type column struct {
+		projectID int64
+		status    string
+	}
+	seen := map[column]bool{}
+	for _, t := range tasks {
+		col := column{t.ProjectID, t.Status}
+		if seen[col] {
+			continue
+		}
+		seen[col] = true
+		exceeded, err := s.store.WIPExceeded(c.Request.Context(), col.projectID, col.status)
+		if err != nil {
+			s.respondError(c, http.StatusInternalServerError, err)
+			return false, false
+		}
+		if exceeded {
+			return true, true
+		}
+	}
+	return false, true
+}
+
+// handleListStatuses returns the status columns of a project in display order.
+func (s *Server) handleListStatuses(c *gin.Context) {
+	projectID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	statuses, err := s.store.ListStatuses(c.Request.Context(), projectID)
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"statuses": statuses})
+}
+
+// handleCreateStatus adds a status column to a project. Without
+// display_order it goes after the existing columns.
+func (s *Server) handleCreateStatus(c *gin.Context) {
+	projectID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req statusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	in := storage.StatusInput{DisplayOrder: req.DisplayOrder}
+	if req.Name != nil {
+		in.Name = *req.Name
+	}
+	if req.Title != nil {
+		in.Title = *req.Title
+	}
+	if req.Color != nil {
+		in.Color = *req.Color
+	}
+	if req.IsTerminal != nil {
+		in.IsTerminal = *req.IsTerminal
+	}
+	if req.WIPLimit != nil {
+		in.WIPLimit = *req.WIPLimit
+	}
+	if req.WIPMode != nil {
+		in.WIPMode = *req.WIPMode
+	}
+
+	status, err := s.store.CreateStatus(c.Request.Context(), projectID, in)
+	if err != nil {
+		s.respondStatusError(c, err)
+		return
+	}
+	respondSuccess(c, http.StatusCreated, gin.H{"status": status})
+}
+
+// handleUpdateStatus edits a status by id.
+func (s *Server) handleUpdateStatus(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	s.updateStatus(c, id)
+}
+
+// handleUpdateColumn edits a status by project and name.
+func (s *Server) handleUpdateColumn(c *gin.Context) {
+	id, ok := s.columnID(c)
+	if !ok {
+		return
+	}
+	s.updateStatus(c, id)
+}
+
+// updateStatus renames, retitles, recolors or reorders a status or changes
+// its terminal flag, WIP limit or WIP mode. Renaming carries the project's
+// tasks along. A limit below the column's current count is accepted with a
+// warning.
+func (s *Server) updateStatus(c *gin.Context, id int64) {
+	var req statusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	status, err := s.store.UpdateStatus(ctx, id, storage.StatusUpdate{
+		Name:         req.Name,
+		Title:        req.Title,
+		Color:        req.Color,
+		DisplayOrder: req.DisplayOrder,
+		IsTerminal:   req.IsTerminal,
+		WIPLimit:     req.WIPLimit,
+		WIPMode:      req.WIPMode,
+	})
+	if err != nil {
+		s.respondStatusError(c, err)
+		return
+	}
+	resp := gin.H{"status": status}
+	if status.WIPLimit > 0 {
+		counts, err := s.store.CountTasksByStatus(ctx, status.ProjectID)
+		if err != nil {
+			s.respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+		if n := counts[status.Name]; n > status.WIPLimit {
+			resp["warning"] = fmt.Sprintf("status %s holds %d tasks, above its WIP limit of %d", status.Name, n, status.WIPLimit)
+		}
+	}
+	respondSuccess(c, http.StatusOK, resp)
+}
+
+// handleDeleteStatus removes a status by id.
+func (s *Server) handleDeleteStatus(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	s.deleteStatus(c, id)
+}
+
+// handleDeleteColumn removes a status by project and name.
+func (s *Server) handleDeleteColumn(c *gin.Context) {
+	id, ok := s.columnID(c)
+	if !ok {
+		return
+	}
+	s.deleteStatus(c, id)
+}
+
+// deleteStatus removes a status column. Its tasks move to the ?move_to
+// status when given; otherwise only unused columns can be deleted.
+func (s *Server) deleteStatus(c *gin.Context, id int64) {
+	if err := s.store.DeleteStatus(c.Request.Context(), id, c.Query("move_to")); err != nil {
+		s.respondStatusError(c, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"status": "deleted"})
+}
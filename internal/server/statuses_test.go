@@ -0,0 +1,102 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"todo/internal/models"
+)
+
+func TestStatusesRouteIsDeprecatedAlias(t *testing.T) {
+	srv, _ := newTestServer(t, Options{})
+
+	w := do(t, srv, http.MethodGet, "/api/projects/1/columns", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET columns = %d: %s", w.Code, w.Body.String())
+	}
+	if h := w.Header().Get("Deprecation"); h != "" {
+		t.Fatalf("columns Deprecation = %q, want none", h)
+	}
+
+	w = do(t, srv, http.MethodGet, "/api/projects/1/statuses", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET statuses = %d: %s", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Deprecation") == "" {
+		t.Fatal("statuses response has no Deprecation header")
+	}
+	if got, want := w.Header().Get("Link"), `</api/projects/1/columns>; rel="successor-version"`; got != want {
+		t.Fatalf("Link = %q, want %q", got, want)
+	}
+}
+
+func TestWIPLimit(t *testing.T) {
+	srv, store := newTestServer(t, Options{})
+	ctx := context.Background()
+	todo, err := store.GetStatusByName(ctx, 1, "todo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	statusPath := "/api/statuses/" + itoa(todo.ID)
+	if w := do(t, srv, http.MethodPut, statusPath, `{"wip_limit":2}`); w.Code != http.StatusOK {
+		t.Fatalf("set limit = %d: %s", w.Code, w.Body.String())
+	}
+	for i := 0; i < 2; i++ {
+		if w := do(t, srv, http.MethodPost, "/api/projects/1/tasks", `{"title":"t"}`); w.Code != http.StatusCreated {
+			t.Fatalf("create %d = %d: %s", i, w.Code, w.Body.String())
+		}
+	}
+	outside, err := store.CreateTask(ctx, models.Task{ProjectID: 1, Title: "elsewhere", Status: "in_progress"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A full enforcing column refuses every way in.
+	full := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		{"create", http.MethodPost, "/api/projects/1/tasks", `{"title":"one too many"}`},
+		{"update", http.MethodPut, "/api/tasks/" + itoa(outside.ID), `{"status":"todo"}`},
+		{"move", http.MethodPost, "/api/tasks/" + itoa(outside.ID) + "/move", `{"status":"todo","position":0}`},
+	}
+	for _, tt := range full {
+		w := do(t, srv, tt.method, tt.path, tt.body)
+		if w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), "WIP limit") {
+			t.Fatalf("%s into full column = %d, want 409: %s", tt.name, w.Code, w.Body.String())
+		}
+	}
+
+	// Lowering the limit below the count is allowed, with a warning.
+	w := do(t, srv, http.MethodPut, statusPath, `{"wip_limit":1}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("lower limit = %d: %s", w.Code, w.Body.String())
+	}
+	var lowered struct {
+		Warning string `json:"warning"`
+	}
+	decode(t, w, &lowered)
+	if lowered.Warning == "" {
+		t.Fatal("lowering the limit below the count gave no warning")
+	}
+
+	// In warn mode the task gets in and the response flags the overflow.
+	if w := do(t, srv, http.MethodPut, statusPath, `{"wip_mode":"warn"}`); w.Code != http.StatusOK {
+		t.Fatalf("set warn mode = %d: %s", w.Code, w.Body.String())
+	}
+	w = do(t, srv, http.MethodPost, "/api/projects/1/tasks", `{"title":"over"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create in warn mode = %d: %s", w.Code, w.Body.String())
+	}
+	var created struct {
+		WIPExceeded bool `json:"wip_exceeded"`
+	}
+	decode(t, w, &created)
+	if !created.WIPExceeded {
+		t.Fatal("create over a warning limit did not set wip_exceeded")
+	}
+}
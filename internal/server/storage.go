@@ -0,0 +1,174 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"todo/internal/models"
+	"todo/internal/storage"
+	"todo/internal/storage/postgres"
+	"todo/internal/storage/sqlite"
+)
+
+// StorageBackend is the persistence the server depends on, implemented by
+// *sqlite.Store and *postgres.Store. The errors and inputs they share with the
+// server live in package storage so that they do not depend on the backend.
+type StorageBackend interface {
+	AddDependency(ctx context.Context, blockerID, blockedID int64) error
+	AddProjectMember(ctx context.Context, projectID, userID int64, role string) (models.ProjectMember, error)
+	AddReaction(ctx context.Context, taskID int64, emoji, author string) error
+	AddTaskLabel(ctx context.Context, taskID, labelID int64) (models.Task, error)
+	AddWatcher(ctx context.Context, taskID int64, name string) error
+	Authenticate(ctx context.Context, username, password string) (models.User, error)
+	AuthenticateAPIKey(ctx context.Context, plaintext string) (models.APIKey, error)
+	ClaimIdempotencyKey(ctx context.Context, key string, ttl time.Duration) (claimed bool, status int, body string, err error)
+	ClearDoneColumn(ctx context.Context, projectID int64, permanent bool) (int64, error)
+	CloseSprint(ctx context.Context, id int64) (models.Sprint, error)
+	CompleteColumn(ctx context.Context, projectID int64, status string) ([]models.Task, error)
+	CompleteSetup(ctx context.Context, in storage.SetupInput) (models.User, models.Project, error)
+	CountOverdueTasks(ctx context.Context, now time.Time) (int, error)
+	CountTasksByStatus(ctx context.Context, projectID int64) (map[string]int, error)
+	CreateAPIKey(ctx context.Context, userID *int64, name string, expiresAt *time.Time) (models.APIKey, string, error)
+	CreateChecklistItem(ctx context.Context, taskID int64, text string) (models.ChecklistItem, error)
+	CreateComment(ctx context.Context, taskID int64, author, body string) (models.Comment, error)
+	CreateLabel(ctx context.Context, projectID int64, name, color string) (models.Label, error)
+	CreateMilestone(ctx context.Context, m models.Milestone) (models.Milestone, error)
+	CreateProject(ctx context.Context, p models.Project) (models.Project, error)
+	CreateSavedFilter(ctx context.Context, f models.SavedFilter) (models.SavedFilter, error)
+	CreateSprint(ctx context.Context, sp models.Sprint) (models.Sprint, error)
+	CreateStatus(ctx context.Context, projectID int64, in storage.StatusInput) (models.TaskStatus, error)
+	CreateTask(ctx context.Context, t models.Task) (models.Task, error)
+	CreateTaskFromTemplate(ctx context.Context, projectID, templateID int64) (models.Task, error)
+	CreateTemplate(ctx context.Context, t models.TaskTemplate) (models.TaskTemplate, error)
+	CreateUser(ctx context.Context, username, password, email, role string) (models.User, error)
+	CreateWebhook(ctx context.Context, w models.Webhook) (models.Webhook, error)
+	DBStats() sql.DBStats
+	DatabaseSize(ctx context.Context) (int64, error)
+	DeleteChecklistItem(ctx context.Context, id int64) error
+	DeleteComment(ctx context.Context, id int64) error
+	DeleteLabel(ctx context.Context, id int64) error
+	DeleteMilestone(ctx context.Context, id int64) error
+	DeleteProject(ctx context.Context, id int64) error
+	DeleteSavedFilter(ctx context.Context, id int64) error
+	DeleteSprint(ctx context.Context, id int64) error
+	DeleteStatus(ctx context.Context, id int64, moveTo string) error
+	DeleteTask(ctx context.Context, id int64) error
+	DeleteTemplate(ctx context.Context, id int64) error
+	DeleteTimeEntry(ctx context.Context, id int64) error
+	DeleteUser(ctx context.Context, id int64) error
+	DeleteWebhook(ctx context.Context, id int64) error
+	DuplicateProject(ctx context.Context, id int64, opts storage.DuplicateOptions) (models.Project, error)
+	ExportProject(ctx context.Context, projectID int64) (*models.ProjectExport, error)
+	FindProjectByName(ctx context.Context, name string) (models.Project, error)
+	GetAccuracyReport(ctx context.Context, projectID int64) (models.AccuracyReport, error)
+	GetAdminStats(ctx context.Context) (models.AdminStats, error)
+	GetBoard(ctx context.Context, projectID int64) (models.Board, error)
+	GetCycleTimeReport(ctx context.Context, projectID int64, since time.Time) (models.CycleTimeReport, error)
+	GetDashboardStats(ctx context.Context) ([]models.ProjectStats, error)
+	GetMilestone(ctx context.Context, id int64) (models.Milestone, error)
+	GetMilestoneProgress(ctx context.Context, id int64) (models.MilestoneProgress, error)
+	GetProject(ctx context.Context, id int64) (models.Project, error)
+	GetProjectStats(ctx context.Context, projectID int64) (models.ProjectOverview, error)
+	GetSavedFilter(ctx context.Context, id int64) (models.SavedFilter, error)
+	GetSprint(ctx context.Context, id int64) (models.Sprint, error)
+	GetSprintBurndown(ctx context.Context, id int64) (models.SprintBurndown, error)
+	GetSprintVelocity(ctx context.Context, id int64) (models.SprintVelocity, error)
+	GetStatusByName(ctx context.Context, projectID int64, name string) (models.TaskStatus, error)
+	GetTask(ctx context.Context, id int64) (models.Task, error)
+	GetTaskByNumber(ctx context.Context, projectID, number int64) (models.Task, error)
+	GetTemplate(ctx context.Context, id int64) (models.TaskTemplate, error)
+	GetUserByID(ctx context.Context, id int64) (models.User, error)
+	GetVelocityReport(ctx context.Context, projectID int64, n int) (models.VelocityReport, error)
+	ImportProject(ctx context.Context, export *models.ProjectExport) (models.Project, error)
+	ImportTasks(ctx context.Context, projectID int64, rows []models.TaskImportRow, strict bool) (models.TaskImportResult, error)
+	ListAPIKeys(ctx context.Context, owner *int64) ([]models.APIKey, error)
+	ListActivity(ctx context.Context, limit int) ([]models.ActivityEntry, error)
+	ListAllOverdueTasks(ctx context.Context, now time.Time, byProject bool) ([]models.OverdueTask, error)
+	ListAllTasks(ctx context.Context, filter models.GlobalTaskFilter) ([]models.ProjectTask, int, error)
+	ListAssignees(ctx context.Context, projectID int64) ([]string, error)
+	ListAudit(ctx context.Context, f models.AuditFilter) ([]models.AuditEntry, error)
+	ListBlockers(ctx context.Context, taskID int64) ([]models.Task, error)
+	ListBlocking(ctx context.Context, taskID int64) ([]models.Task, error)
+	ListChecklistItems(ctx context.Context, taskID int64) ([]models.ChecklistItem, error)
+	ListComments(ctx context.Context, taskID int64) ([]models.Comment, error)
+	ListCompletedTasksBetween(ctx context.Context, projectID int64, from, to time.Time) ([]models.Task, error)
+	ListLabels(ctx context.Context, projectID int64) ([]models.Label, error)
+	ListMilestones(ctx context.Context, projectID int64) ([]models.Milestone, error)
+	ListOverdueTasks(ctx context.Context, projectID int64, now time.Time) ([]models.OverdueTask, error)
+	ListProjectEvents(ctx context.Context, projectID int64, since *time.Time, before int64, limit int) ([]models.ProjectEvent, error)
+	ListProjectMembers(ctx context.Context, projectID int64) ([]models.ProjectMember, error)
+	ListProjectVelocity(ctx context.Context, projectID int64, n int) ([]models.SprintVelocity, error)
+	ListProjects(ctx context.Context) ([]models.Project, error)
+	ListProjectsDueSoon(ctx context.Context, within time.Duration) ([]models.Project, error)
+	ListProjectsWithTaskCounts(ctx context.Context) ([]models.ProjectWithCounts, error)
+	ListRecentTasks(ctx context.Context, since time.Time, limit int) ([]models.ProjectTask, error)
+	ListSavedFilterTasks(ctx context.Context, filterID, projectID int64) ([]models.Task, error)
+	ListSavedFilters(ctx context.Context, projectID *int64) ([]models.SavedFilter, error)
+	ListSprints(ctx context.Context, projectID int64) ([]models.Sprint, error)
+	ListStatusLog(ctx context.Context, taskID int64) ([]models.StatusLogEntry, error)
+	ListStatuses(ctx context.Context, projectID int64) ([]models.TaskStatus, error)
+	ListSubTasks(ctx context.Context, parentID int64) ([]models.Task, error)
+	ListTaskActivity(ctx context.Context, taskID int64) ([]models.ActivityEntry, error)
+	ListTaskRevisions(ctx context.Context, taskID int64) ([]models.TaskRevision, error)
+	ListTasksFiltered(ctx context.Context, projectID int64, filter models.TaskFilter) ([]models.Task, error)
+	ListTemplates(ctx context.Context, projectID *int64) ([]models.TaskTemplate, error)
+	ListTimeEntries(ctx context.Context, taskID int64) ([]models.TimeEntry, error)
+	ListTrash(ctx context.Context) (models.Trash, error)
+	ListUpcomingTasks(ctx context.Context, now time.Time, days int) ([]models.ProjectTask, error)
+	ListUsers(ctx context.Context) ([]models.User, error)
+	ListWebhookDeliveries(ctx context.Context, webhookID int64) ([]models.WebhookDelivery, error)
+	ListWebhooks(ctx context.Context, projectID int64) ([]models.Webhook, error)
+	MigrationVersion(ctx context.Context) (int, error)
+	MoveTask(ctx context.Context, id int64, status string, position int64) (models.Task, error)
+	Ping(ctx context.Context) error
+	ProjectRole(ctx context.Context, projectID, userID int64) (string, error)
+	PruneIdempotencyKeys(ctx context.Context, ttl time.Duration) (int64, error)
+	PurgeTrash(ctx context.Context, retention time.Duration) (int64, error)
+	ReleaseIdempotencyKey(ctx context.Context, key string) error
+	RemoveDependency(ctx context.Context, blockerID, blockedID int64) error
+	RemoveProjectMember(ctx context.Context, projectID, userID int64) error
+	RemoveReaction(ctx context.Context, taskID int64, emoji, author string) error
+	RemoveTaskLabel(ctx context.Context, taskID, labelID int64) (models.Task, error)
+	RemoveWatcher(ctx context.Context, taskID int64, name string) error
+	ReorderChecklistItems(ctx context.Context, taskID int64, ids []int64) ([]models.ChecklistItem, error)
+	ReorderProjects(ctx context.Context, ids []int64) ([]models.Project, error)
+	ResourceProjectID(ctx context.Context, kind string, id int64) (int64, error)
+	RestoreProject(ctx context.Context, id int64) (models.Project, error)
+	RestoreTask(ctx context.Context, id int64) (models.Task, error)
+	RestoreTaskRevision(ctx context.Context, taskID, revisionID int64) (models.Task, error)
+	RevokeAPIKey(ctx context.Context, id int64, owner *int64) error
+	SaveIdempotentResponse(ctx context.Context, key string, status int, body string) error
+	SearchProjectTasks(ctx context.Context, projectID int64, query string) ([]models.TaskSearchResult, error)
+	SearchProjects(ctx context.Context, query string, limit int) ([]models.Project, error)
+	SearchTasks(ctx context.Context, projectID *int64, query string, limit int) ([]models.TaskSearchResult, error)
+	SetEventListener(fn func(event string, projectID int64, data any))
+	SetupCompleted(ctx context.Context) (bool, error)
+	SetupStatus(ctx context.Context) (models.SetupStatus, error)
+	SnoozeTask(ctx context.Context, id int64, until *time.Time) (models.Task, error)
+	StartTimer(ctx context.Context, taskID int64, note string) (models.TimeEntry, error)
+	StartWebhookWorkers(n int)
+	StopTimer(ctx context.Context, taskID int64) (models.TimeEntry, error)
+	TestWebhook(ctx context.Context, id int64) (models.WebhookDelivery, error)
+	Undo(ctx context.Context) (models.UndoResult, error)
+	UpdateChecklistItem(ctx context.Context, id int64, text *string, done *bool) (models.ChecklistItem, error)
+	UpdateLabel(ctx context.Context, id int64, name, color string) (models.Label, error)
+	UpdateMilestone(ctx context.Context, id int64, m models.Milestone) (models.Milestone, error)
+	UpdateProject(ctx context.Context, id int64, p models.Project) (models.Project, error)
+	UpdateSavedFilter(ctx context.Context, id int64, f models.SavedFilter) (models.SavedFilter, error)
+	UpdateSprint(ctx context.Context, id int64, sp models.Sprint) (models.Sprint, error)
+	UpdateStatus(ctx context.Context, id int64, in storage.StatusUpdate) (models.TaskStatus, error)
+	UpdateTask(ctx context.Context, id int64, changes map[string]any) (models.Task, error)
+	UpdateTasksStatus(ctx context.Context, ids []int64, status string, strict bool) ([]models.Task, []int64, error)
+	UpdateTemplate(ctx context.Context, id int64, t models.TaskTemplate) (models.TaskTemplate, error)
+	UpdateUser(ctx context.Context, id int64, in storage.UserUpdate) (models.User, error)
+	UpdateWebhook(ctx context.Context, id int64, changes map[string]any) (models.Webhook, error)
+	VacuumDB(ctx context.Context) error
+	WIPExceeded(ctx context.Context, projectID int64, status string) (bool, error)
+	WriteAudit(ctx context.Context, entry models.AuditEntry) error
+}
+
+var (
+	_ StorageBackend = (*sqlite.Store)(nil)
+	_ StorageBackend = (*postgres.Store)(nil)
+)
@@ -1,35 +1,178 @@
 package server
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
 
 	"todo/internal/models"
+	"todo/internal/storage"
+	"todo/internal/when"
 )
 
+const maxAssigneeLength = 200
+
 type taskRequest struct {
 	Title       *string `json:"title"`
 	Description *string `json:"description"`
 	Status      *string `json:"status"`
+	Priority    *string `json:"priority"`
+	ParentID    *int64  `json:"parent_id"`
+	Assignee    *string `json:"assignee"`
+	Color       *string `json:"color"`
+	SprintID    *int64  `json:"sprint_id"`
+	MilestoneID *int64  `json:"milestone_id"`
+	StoryPoints *int    `json:"story_points"`
+	CoverURL    *string `json:"cover_url"`
+	// DueDate is RFC3339 or a phrase such as "next friday 17:00"; an empty
+	// string clears it.
+	DueDate *string `json:"due_date"`
+	// Fields merges custom fields; a null value removes the key.
+	Fields map[string]*string `json:"fields"`
+	// Links replaces the ordered list of external links.
+	Links *[]models.TaskLink `json:"links"`
+	// Version, when given on update, must match the task's current version.
+	Version *int `json:"version"`
 }
 
-// handleListTasks fetches tasks for a project.
+// handleListTasks fetches tasks for a project. Optional filters: ?assignee,
+// ?status and ?priority (comma separated or repeated), ?sprint_id (0 for the
+// backlog), ?milestone_id (0 for tasks outside any milestone) and repeatable
+// ?label_id, which keeps tasks carrying every label given. Snoozed tasks are
+// hidden unless ?include_snoozed=true.
 func (s *Server) handleListTasks(c *gin.Context) {
 	projectID, ok := parseID(c, "id")
 	if !ok {
 		return
 	}
+	filter, ok := s.taskFilter(c)
+	if !ok {
+		return
+	}
 
-	tasks, err := s.store.ListTasks(c.Request.Context(), projectID)
+	tasks, err := s.store.ListTasksFiltered(c.Request.Context(), projectID, filter)
 	if err != nil {
+		if errors.Is(err, storage.ErrValidation) {
+			s.respondError(c, http.StatusBadRequest, err)
+			return
+		}
 		s.respondError(c, http.StatusInternalServerError, err)
 		return
 	}
 	respondSuccess(c, http.StatusOK, gin.H{"tasks": tasks})
 }
 
+// taskFilter reads the task list query parameters, responding 400 when one is
+// invalid.
+func (s *Server) taskFilter(c *gin.Context) (models.TaskFilter, bool) {
+	var filter models.TaskFilter
+	for _, raw := range c.QueryArray("label_id") {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid label_id"})
+			return filter, false
+		}
+		filter.LabelIDs = append(filter.LabelIDs, id)
+	}
+	filter.Statuses = statusQuery(c)
+	priorities, ok := priorityQuery(c)
+	if !ok {
+		return filter, false
+	}
+	filter.Priorities = priorities
+	if assignee, ok := c.GetQuery("assignee"); ok {
+		if utf8.RuneCountInString(assignee) > maxAssigneeLength {
+			s.respondError(c, http.StatusBadRequest, fmt.Errorf("assignee must be at most %d characters", maxAssigneeLength))
+			return filter, false
+		}
+		filter.Assignee = &assignee
+	}
+	if raw := c.Query("include_snoozed"); raw != "" {
+		include, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid include_snoozed"})
+			return filter, false
+		}
+		filter.IncludeSnoozed = include
+	}
+	if raw := c.Query("sprint_id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sprint_id"})
+			return filter, false
+		}
+		filter.SprintID = &id
+	}
+	if raw := c.Query("milestone_id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid milestone_id"})
+			return filter, false
+		}
+		filter.MilestoneID = &id
+	}
+	filter.Sort = c.Query("sort")
+	return filter, true
+}
+
+// statusQuery collects ?status values, comma separated or repeated. Statuses
+// are defined per project, so the store validates them.
+func statusQuery(c *gin.Context) []string {
+	var statuses []string
+	for _, raw := range c.QueryArray("status") {
+		for _, status := range strings.Split(raw, ",") {
+			status = strings.TrimSpace(status)
+			if status == "" {
+				continue
+			}
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses
+}
+
+// priorityQuery collects ?priority values like statusQuery does and responds
+// 400 listing the valid priorities when one is unknown.
+func priorityQuery(c *gin.Context) ([]string, bool) {
+	var priorities []string
+	for _, raw := range c.QueryArray("priority") {
+		for _, priority := range strings.Split(raw, ",") {
+			priority = strings.TrimSpace(priority)
+			if priority == "" {
+				continue
+			}
+			if _, valid := models.ValidTaskPriorities[priority]; !valid {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid priority %q", priority), "valid_priorities": []string{"low", "medium", "high", "urgent"}})
+				return nil, false
+			}
+			priorities = append(priorities, priority)
+		}
+	}
+	return priorities, true
+}
+
+// handleListAssignees returns the distinct assignees of a project.
+func (s *Server) handleListAssignees(c *gin.Context) {
+	projectID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	assignees, err := s.store.ListAssignees(c.Request.Context(), projectID)
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"assignees": assignees})
+}
+
 // handleCreateTask inserts a new task into a project column.
 func (s *Server) handleCreateTask(c *gin.Context) {
 	projectID, ok := parseID(c, "id")
@@ -46,14 +189,110 @@ func (s *Server) handleCreateTask(c *gin.Context) {
 		s.respondError(c, http.StatusBadRequest, fmt.Errorf("title is required"))
 		return
 	}
+	dueDate, ok := s.parseTimeInput(c, req.DueDate)
+	if !ok {
+		return
+	}
 
 	task, err := s.store.CreateTask(c.Request.Context(), models.Task{
 		ProjectID:   projectID,
 		Title:       *req.Title,
 		Description: getString(req.Description),
 		Status:      getString(req.Status),
+		Priority:    getString(req.Priority),
+		ParentID:    req.ParentID,
+		Assignee:    getString(req.Assignee),
+		Color:       getString(req.Color),
+		SprintID:    req.SprintID,
+		MilestoneID: req.MilestoneID,
+		StoryPoints: getInt(req.StoryPoints),
+		CoverURL:    getString(req.CoverURL),
+		DueDate:     dueDate,
+		Fields:      presentFields(req.Fields),
+		Links:       getLinks(req.Links),
+	})
+	if err != nil {
+		if errors.Is(err, storage.ErrValidation) {
+			s.respondError(c, http.StatusUnprocessableEntity, err)
+			return
+		}
+		if errors.Is(err, storage.ErrConflict) {
+			s.respondError(c, http.StatusConflict, err)
+			return
+		}
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	exceeded, ok := s.wipExceeded(c, task)
+	if !ok {
+		return
+	}
+	respondSuccess(c, http.StatusCreated, gin.H{"task": task, "wip_exceeded": exceeded})
+}
+
+type duplicateRequest struct {
+	ProjectID *int64 `json:"project_id"`
+}
+
+// handleDuplicateTask copies a task to the end of its column, optionally into
+// another project, where it lands in the same status or else the project's
+// first one.
+func (s *Server) handleDuplicateTask(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req duplicateRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	source, err := s.store.GetTask(c.Request.Context(), id)
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	projectID := source.ProjectID
+	status := source.Status
+	if req.ProjectID != nil && *req.ProjectID != source.ProjectID {
+		projectID = *req.ProjectID
+		if !s.checkProjectRole(c, projectID, models.ProjectRoleMember) {
+			return
+		}
+		if _, err := s.store.GetProject(c.Request.Context(), projectID); err != nil {
+			s.respondError(c, http.StatusNotFound, err)
+			return
+		}
+		// A target project without the source's status takes the copy
+		// into its first status.
+		statuses, err := s.store.ListStatuses(c.Request.Context(), projectID)
+		if err != nil {
+			s.respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+		if !slices.ContainsFunc(statuses, func(st models.TaskStatus) bool { return st.Name == status }) {
+			status = ""
+		}
+	}
+
+	task, err := s.store.CreateTask(c.Request.Context(), models.Task{
+		ProjectID:   projectID,
+		Title:       source.Title + " (copy)",
+		Description: source.Description,
+		Status:      status,
+		Priority:    source.Priority,
 	})
 	if err != nil {
+		if errors.Is(err, storage.ErrValidation) {
+			s.respondError(c, http.StatusUnprocessableEntity, err)
+			return
+		}
+		if errors.Is(err, storage.ErrConflict) {
+			s.respondError(c, http.StatusConflict, err)
+			return
+		}
 		s.respondError(c, http.StatusBadRequest, err)
 		return
 	}
@@ -83,16 +322,191 @@ func (s *Server) handleUpdateTask(c *gin.Context) {
 	if req.Status != nil {
 		updates["status"] = *req.Status
 	}
+	if req.Priority != nil {
+		updates["priority"] = *req.Priority
+	}
+	if req.Assignee != nil {
+		updates["assignee"] = *req.Assignee
+	}
+	if req.Color != nil {
+		updates["color"] = *req.Color
+	}
+	if req.StoryPoints != nil {
+		updates["story_points"] = *req.StoryPoints
+	}
+	if req.CoverURL != nil {
+		updates["cover_url"] = *req.CoverURL
+	}
+	if req.DueDate != nil {
+		dueDate, ok := s.parseTimeInput(c, req.DueDate)
+		if !ok {
+			return
+		}
+		updates["due_date"] = dueDate
+	}
+	if req.SprintID != nil {
+		// sprint_id 0 moves the task back to the backlog.
+		updates["sprint_id"] = *req.SprintID
+	}
+	if req.MilestoneID != nil {
+		// milestone_id 0 detaches the task from its milestone.
+		updates["milestone_id"] = *req.MilestoneID
+	}
+	if req.Fields != nil {
+		updates["fields"] = req.Fields
+	}
+	if req.Links != nil {
+		updates["links"] = getLinks(req.Links)
+	}
+	if req.ParentID != nil {
+		// parent_id 0 detaches the task from its parent.
+		updates["parent_id"] = *req.ParentID
+	}
+	if req.Version != nil {
+		updates["version"] = *req.Version
+	}
 
 	task, err := s.store.UpdateTask(c.Request.Context(), id, updates)
 	if err != nil {
+		if errors.Is(err, storage.ErrValidation) {
+			s.respondError(c, http.StatusUnprocessableEntity, err)
+			return
+		}
+		if errors.Is(err, storage.ErrConflict) {
+			s.respondError(c, http.StatusConflict, err)
+			return
+		}
 		s.respondError(c, http.StatusBadRequest, err)
 		return
 	}
+	exceeded, ok := s.wipExceeded(c, task)
+	if !ok {
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"task": task, "wip_exceeded": exceeded})
+}
+
+// handleGetTaskByNumber looks up a task by its per-project number.
+func (s *Server) handleGetTaskByNumber(c *gin.Context) {
+	projectID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	number, ok := parseID(c, "n")
+	if !ok {
+		return
+	}
+	task, err := s.store.GetTaskByNumber(c.Request.Context(), projectID, number)
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
 	respondSuccess(c, http.StatusOK, gin.H{"task": task})
 }
 
-// handleDeleteTask removes a task completely.
+// handleListSubTasks returns the direct children of a task.
+func (s *Server) handleListSubTasks(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	tasks, err := s.store.ListSubTasks(c.Request.Context(), id)
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"tasks": tasks})
+}
+
+type moveRequest struct {
+	Status   string `json:"status"`
+	Position *int64 `json:"position"`
+}
+
+// handleMoveTask places a task at an explicit position inside a column.
+func (s *Server) handleMoveTask(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req moveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	if req.Position == nil {
+		s.respondError(c, http.StatusBadRequest, fmt.Errorf("position is required"))
+		return
+	}
+
+	task, err := s.store.MoveTask(c.Request.Context(), id, req.Status, *req.Position)
+	if errors.Is(err, storage.ErrConflict) {
+		s.respondError(c, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	exceeded, ok := s.wipExceeded(c, task)
+	if !ok {
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"task": task, "wip_exceeded": exceeded})
+}
+
+type bulkStatusRequest struct {
+	IDs    []int64 `json:"ids"`
+	Status string  `json:"status"`
+	Strict bool    `json:"strict"`
+}
+
+// handleBulkUpdateStatus moves a set of tasks into one column in a single
+// call. The caller must be a member of the project of every task.
+func (s *Server) handleBulkUpdateStatus(c *gin.Context) {
+	var req bulkStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	if s.projectRolesApply(c) {
+		checked := map[int64]bool{}
+		for _, id := range req.IDs {
+			// Unknown tasks are reported by UpdateTasksStatus.
+			projectID, err := s.store.ResourceProjectID(c.Request.Context(), "task", id)
+			if err != nil || checked[projectID] {
+				continue
+			}
+			if !s.checkProjectRole(c, projectID, models.ProjectRoleMember) {
+				return
+			}
+			checked[projectID] = true
+		}
+	}
+
+	tasks, invalid, err := s.store.UpdateTasksStatus(c.Request.Context(), req.IDs, req.Status, req.Strict)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrConflict):
+			s.respondError(c, http.StatusConflict, err)
+		case errors.Is(err, storage.ErrValidation):
+			s.respondError(c, http.StatusBadRequest, err)
+		case invalid != nil:
+			s.respondError(c, http.StatusUnprocessableEntity, err)
+		default:
+			s.respondError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	exceeded, ok := s.wipExceeded(c, tasks...)
+	if !ok {
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"tasks": tasks, "invalid_ids": invalid, "wip_exceeded": exceeded})
+}
+
+// handleDeleteTask moves a task to the trash.
 func (s *Server) handleDeleteTask(c *gin.Context) {
 	id, ok := parseID(c, "id")
 	if !ok {
@@ -105,9 +519,57 @@ func (s *Server) handleDeleteTask(c *gin.Context) {
 	respondSuccess(c, http.StatusOK, gin.H{"status": "deleted"})
 }
 
+// parseTimeInput accepts RFC3339 timestamps and falls back to natural-language
+// phrases resolved in the server time zone. On failure it responds with 400
+// and the parser's interpretation of the input. Nil or empty input yields nil.
+func (s *Server) parseTimeInput(c *gin.Context, raw *string) (*time.Time, bool) {
+	if raw == nil || *raw == "" {
+		return nil, true
+	}
+	if t, err := time.Parse(time.RFC3339, *raw); err == nil {
+		return &t, true
+	}
+	t, err := when.Parse(*raw, time.Now(), s.timezone)
+	if err != nil {
+		var werr *when.Error
+		if errors.As(err, &werr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "interpretation": werr.Interpretation})
+			return nil, false
+		}
+		s.respondError(c, http.StatusBadRequest, err)
+		return nil, false
+	}
+	return &t, true
+}
+
 func getString(v *string) string {
 	if v == nil {
 		return ""
 	}
 	return *v
 }
+
+func getInt(v *int) int {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+// presentFields drops the null entries of a custom field payload.
+func presentFields(fields map[string]*string) map[string]string {
+	out := make(map[string]string, len(fields))
+	for k, v := range fields {
+		if v != nil {
+			out[k] = *v
+		}
+	}
+	return out
+}
+
+func getLinks(v *[]models.TaskLink) []models.TaskLink {
+	if v == nil || *v == nil {
+		return []models.TaskLink{}
+	}
+	return *v
+}
@@ -0,0 +1,204 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+func TestDuplicateTaskIntoAnotherProject(t *testing.T) {
+	srv, store := newTestServer(t, Options{})
+	ctx := context.Background()
+	if _, err := store.CreateStatus(ctx, 1, storage.StatusInput{Name: "review"}); err != nil {
+		t.Fatal(err)
+	}
+	source, err := store.CreateTask(ctx, models.Task{ProjectID: 1, Title: "t", Status: "review"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	full, err := store.CreateProject(ctx, models.Project{Name: "Full"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Full's first status admits no more tasks.
+	todo, err := store.GetStatusByName(ctx, full.ID, "todo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	limit := 1
+	if _, err := store.UpdateStatus(ctx, todo.ID, storage.StatusUpdate{WIPLimit: &limit}); err != nil {
+		t.Fatal(err)
+	}
+	target, err := store.CreateProject(ctx, models.Project{Name: "Target"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := "/api/tasks/" + itoa(source.ID) + "/duplicate"
+
+	w := do(t, srv, http.MethodPost, path, `{"project_id":`+itoa(target.ID)+`}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("duplicate = %d, want 201: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Task models.Task `json:"task"`
+	}
+	decode(t, w, &resp)
+	if resp.Task.ProjectID != target.ID || resp.Task.Status != "todo" {
+		t.Fatalf("copy in project %d status %q, want project %d status todo", resp.Task.ProjectID, resp.Task.Status, target.ID)
+	}
+
+	if w := do(t, srv, http.MethodPost, path, `{"project_id":`+itoa(full.ID)+`}`); w.Code != http.StatusCreated {
+		t.Fatalf("first copy into full = %d, want 201: %s", w.Code, w.Body.String())
+	}
+	if w := do(t, srv, http.MethodPost, path, `{"project_id":`+itoa(full.ID)+`}`); w.Code != http.StatusConflict {
+		t.Fatalf("copy over wip limit = %d, want 409: %s", w.Code, w.Body.String())
+	}
+	if w := do(t, srv, http.MethodPost, path, `{"project_id":999}`); w.Code != http.StatusNotFound {
+		t.Fatalf("copy into missing project = %d, want 404: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestTaskCoverURL(t *testing.T) {
+	srv, store := newTestServer(t, Options{})
+	task, err := store.CreateTask(context.Background(), models.Task{ProjectID: 1, Title: "t"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := "/api/tasks/" + itoa(task.ID)
+	listedCover := func() string {
+		t.Helper()
+		var list struct {
+			Tasks []models.Task `json:"tasks"`
+		}
+		decode(t, do(t, srv, http.MethodGet, "/api/projects/1/tasks", ""), &list)
+		if len(list.Tasks) != 1 {
+			t.Fatalf("listed %d tasks, want 1", len(list.Tasks))
+		}
+		return list.Tasks[0].CoverURL
+	}
+
+	tests := []struct {
+		name string
+		body string
+		want int
+		// cover is the listed cover_url afterwards.
+		cover string
+	}{
+		{"set", `{"cover_url":"https://example.com/a.png"}`, http.StatusOK, "https://example.com/a.png"},
+		{"javascript", `{"cover_url":"javascript:alert(1)"}`, http.StatusUnprocessableEntity, "https://example.com/a.png"},
+		{"relative", `{"cover_url":"/a.png"}`, http.StatusUnprocessableEntity, "https://example.com/a.png"},
+		{"clear", `{"cover_url":""}`, http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := do(t, srv, http.MethodPut, path, tt.body); w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+			if got := listedCover(); got != tt.cover {
+				t.Fatalf("listed cover_url = %q, want %q", got, tt.cover)
+			}
+		})
+	}
+}
+
+func TestTextLengthLimits(t *testing.T) {
+	srv, _ := newTestServer(t, Options{})
+	title := func(n int) string { return strings.Repeat("a", n) }
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"title at limit", http.MethodPost, "/api/projects/1/tasks", `{"title":"` + title(storage.DefaultMaxTitleLength) + `"}`, http.StatusCreated},
+		{"title over limit", http.MethodPost, "/api/projects/1/tasks", `{"title":"` + title(storage.DefaultMaxTitleLength+1) + `"}`, http.StatusUnprocessableEntity},
+		// Limits count characters, not bytes.
+		{"multibyte title at limit", http.MethodPost, "/api/projects/1/tasks", `{"title":"` + strings.Repeat("é", storage.DefaultMaxTitleLength) + `"}`, http.StatusCreated},
+		{"description over limit", http.MethodPost, "/api/projects/1/tasks", `{"title":"t","description":"` + title(storage.DefaultMaxDescriptionLength+1) + `"}`, http.StatusUnprocessableEntity},
+		{"updated title over limit", http.MethodPut, "/api/tasks/1", `{"title":"` + title(storage.DefaultMaxTitleLength+1) + `"}`, http.StatusUnprocessableEntity},
+		{"project name over limit", http.MethodPost, "/api/projects", `{"name":"` + title(storage.DefaultMaxProjectNameLength+1) + `"}`, http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := do(t, srv, tt.method, tt.path, tt.body); w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %.200s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestBulkUpdateStatusErrors(t *testing.T) {
+	srv, store := newTestServer(t, Options{})
+	task, err := store.CreateTask(context.Background(), models.Task{ProjectID: 1, Title: "bulk"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := itoa(task.ID)
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"empty ids", `{"ids":[],"status":"done"}`, http.StatusBadRequest},
+		{"empty status", `{"ids":[` + id + `],"status":""}`, http.StatusBadRequest},
+		{"strict unknown id", `{"ids":[` + id + `,999],"status":"done","strict":true}`, http.StatusUnprocessableEntity},
+		{"lenient unknown id", `{"ids":[` + id + `,999],"status":"done"}`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := do(t, srv, http.MethodPatch, "/api/tasks/bulk", tt.body)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestConcurrentTaskUpdatesConflict(t *testing.T) {
+	srv, store := newTestServer(t, Options{})
+	task, err := store.CreateTask(context.Background(), models.Task{ProjectID: 1, Title: "shared"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := "/api/tasks/" + itoa(task.ID)
+	body := func(title string) string {
+		return `{"title":"` + title + `","version":` + itoa(int64(task.Version)) + `}`
+	}
+
+	// Clients racing with the same copy: one wins, the rest are told to reload.
+	codes := make([]int, 4)
+	var wg sync.WaitGroup
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codes[i] = do(t, srv, http.MethodPut, path, body("edit "+itoa(int64(i)))).Code
+		}(i)
+	}
+	wg.Wait()
+	ok, conflicts := 0, 0
+	for _, code := range codes {
+		switch code {
+		case http.StatusOK:
+			ok++
+		case http.StatusConflict:
+			conflicts++
+		}
+	}
+	if ok != 1 || conflicts != len(codes)-1 {
+		t.Fatalf("statuses = %v, want one 200 and %d 409s", codes, len(codes)-1)
+	}
+
+	// The loser retrying with the version it read still conflicts.
+	if w := do(t, srv, http.MethodPut, path, body("late")); w.Code != http.StatusConflict {
+		t.Fatalf("stale update = %d, want 409: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"todo/internal/models"
+)
+
+type templateRequest struct {
+	ProjectID   *int64 `json:"project_id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Status      string `json:"status"`
+}
+
+func (r templateRequest) toModel() models.TaskTemplate {
+	return models.TaskTemplate{
+		ProjectID:   r.ProjectID,
+		Title:       r.Title,
+		Description: r.Description,
+		Status:      r.Status,
+	}
+}
+
+// handleListTemplates returns global templates and those of ?project_id.
+func (s *Server) handleListTemplates(c *gin.Context) {
+	var projectID *int64
+	if raw := c.Query("project_id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid identifier"})
+			return
+		}
+		projectID = &id
+	}
+
+	templates, err := s.store.ListTemplates(c.Request.Context(), projectID)
+	if err != nil {
+		s.respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"templates": templates})
+}
+
+// handleGetTemplate returns a single template.
+func (s *Server) handleGetTemplate(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	tpl, err := s.store.GetTemplate(c.Request.Context(), id)
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"template": tpl})
+}
+
+// handleCreateTemplate stores a new task template.
+func (s *Server) handleCreateTemplate(c *gin.Context) {
+	var req templateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	tpl, err := s.store.CreateTemplate(c.Request.Context(), req.toModel())
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	respondSuccess(c, http.StatusCreated, gin.H{"template": tpl})
+}
+
+// handleUpdateTemplate replaces a template.
+func (s *Server) handleUpdateTemplate(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req templateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	tpl, err := s.store.UpdateTemplate(c.Request.Context(), id, req.toModel())
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"template": tpl})
+}
+
+// handleDeleteTemplate removes a template.
+func (s *Server) handleDeleteTemplate(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	if err := s.store.DeleteTemplate(c.Request.Context(), id); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"status": "deleted"})
+}
+
+// handleCreateTaskFromTemplate creates a task in a project from a template.
+func (s *Server) handleCreateTaskFromTemplate(c *gin.Context) {
+	projectID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	templateID, ok := parseID(c, "templateID")
+	if !ok {
+		return
+	}
+	task, err := s.store.CreateTaskFromTemplate(c.Request.Context(), projectID, templateID)
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	respondSuccess(c, http.StatusCreated, gin.H{"task": task})
+}
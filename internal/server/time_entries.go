@@ -0,0 +1,87 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"todo/internal/storage"
+)
+
+type timerRequest struct {
+	Note string `json:"note"`
+}
+
+// handleStartTimer starts tracking time on a task.
+func (s *Server) handleStartTimer(c *gin.Context) {
+	taskID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req timerRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			s.respondError(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	entry, err := s.store.StartTimer(c.Request.Context(), taskID, req.Note)
+	if errors.Is(err, storage.ErrConflict) {
+		s.respondError(c, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	respondSuccess(c, http.StatusCreated, gin.H{"entry": entry})
+}
+
+// handleStopTimer stops the running timer of a task.
+func (s *Server) handleStopTimer(c *gin.Context) {
+	taskID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	entry, err := s.store.StopTimer(c.Request.Context(), taskID)
+	if errors.Is(err, storage.ErrConflict) {
+		s.respondError(c, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"entry": entry})
+}
+
+// handleListTimeEntries returns the time log of a task.
+func (s *Server) handleListTimeEntries(c *gin.Context) {
+	taskID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	entries, err := s.store.ListTimeEntries(c.Request.Context(), taskID)
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"entries": entries})
+}
+
+// handleDeleteTimeEntry removes a time entry.
+func (s *Server) handleDeleteTimeEntry(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	if err := s.store.DeleteTimeEntry(c.Request.Context(), id); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"status": "deleted"})
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"todo/internal/storage"
+)
+
+const defaultTrashRetentionDays = 30
+
+// handleListTrash returns soft-deleted projects and tasks.
+func (s *Server) handleListTrash(c *gin.Context) {
+	trash, err := s.store.ListTrash(c.Request.Context())
+	if err != nil {
+		s.respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"trash": trash})
+}
+
+// handleRestoreTask moves a task out of the trash.
+func (s *Server) handleRestoreTask(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	task, err := s.store.RestoreTask(c.Request.Context(), id)
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"task": task})
+}
+
+// handleRestoreProject moves a project and its tasks out of the trash.
+func (s *Server) handleRestoreProject(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	project, err := s.store.RestoreProject(c.Request.Context(), id)
+	if errors.Is(err, storage.ErrConflict) {
+		s.respondError(c, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"project": project})
+}
+
+// handlePurgeTrash hard-deletes items older than ?older_than_days (default
+// 30). Emptying the whole trash with 0 also needs ?force=true.
+func (s *Server) handlePurgeTrash(c *gin.Context) {
+	days := defaultTrashRetentionDays
+	if raw := c.Query("older_than_days"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 0 {
+			s.respondError(c, http.StatusBadRequest, fmt.Errorf("older_than_days must be a non-negative integer"))
+			return
+		}
+		days = v
+	}
+	if days == 0 && c.Query("force") != "true" {
+		s.respondError(c, http.StatusBadRequest, fmt.Errorf("older_than_days=0 empties the whole trash; pass force=true to confirm"))
+		return
+	}
+
+	purged, err := s.store.PurgeTrash(c.Request.Context(), time.Duration(days)*24*time.Hour)
+	if err != nil {
+		s.respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"purged": purged})
+}
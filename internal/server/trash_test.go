@@ -0,0 +1,55 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"todo/internal/models"
+)
+
+func TestPurgeTrash(t *testing.T) {
+	srv, store := newTestServer(t, Options{JWTSecret: testSecret})
+	ctx := context.Background()
+	task, err := store.CreateTask(ctx, models.Task{ProjectID: 1, Title: "gone"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.DeleteTask(ctx, task.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.CreateUser(ctx, "bob", testPassword, "", "member"); err != nil {
+		t.Fatal(err)
+	}
+	admin := login(t, srv, testAdmin, testPassword)
+	bob := login(t, srv, "bob", testPassword)
+
+	tests := []struct {
+		name  string
+		query string
+		auth  string
+		want  int
+	}{
+		{"member", "?older_than_days=0&force=true", bob, http.StatusForbidden},
+		{"negative", "?older_than_days=-1", admin, http.StatusBadRequest},
+		{"zero without force", "?older_than_days=0", admin, http.StatusBadRequest},
+		{"default retention", "", admin, http.StatusOK},
+		{"zero with force", "?older_than_days=0&force=true", admin, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := do(t, srv, http.MethodPost, "/api/trash/purge"+tt.query, "", "Authorization", tt.auth)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+
+	trash, err := store.ListTrash(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trash.Tasks) != 0 {
+		t.Fatalf("trash still holds %d tasks after a forced purge", len(trash.Tasks))
+	}
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+// sessionHeader lets clients without authentication keep separate undo
+// histories, for example one per browser tab.
+const sessionHeader = "X-Session-ID"
+
+// captureSession scopes undo to the caller: the authenticated user when
+// tokens are on, otherwise the X-Session-ID header, falling back to
+// X-Changed-By. API keys get a history of their own. Requests with none of
+// these share one history.
+func (s *Server) captureSession(c *gin.Context) {
+	session := c.GetHeader(sessionHeader)
+	if session == "" {
+		if name := c.GetHeader("X-Changed-By"); name != "" {
+			session = "name:" + name
+		}
+	}
+	if v, ok := c.Get("api_key"); ok {
+		if key, ok := v.(models.APIKey); ok {
+			session = "key:" + strconv.FormatInt(key.ID, 10)
+		}
+	}
+	if v, ok := c.Get("claims"); ok {
+		if claims, ok := v.(*authClaims); ok {
+			session = "user:" + claims.Subject
+		}
+	}
+	c.Request = c.Request.WithContext(storage.WithSession(c.Request.Context(), session))
+	c.Next()
+}
+
+// handleUndo reverses the caller's most recent task create, delete or move,
+// or project update.
+func (s *Server) handleUndo(c *gin.Context) {
+	res, err := s.store.Undo(c.Request.Context())
+	if errors.Is(err, storage.ErrConflict) {
+		s.respondError(c, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		s.respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"undone": res})
+}
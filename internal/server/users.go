@@ -0,0 +1,138 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+type createUserRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Email    string `json:"email"`
+	Role     string `json:"role"`
+}
+
+type updateUserRequest struct {
+	Username *string `json:"username"`
+	Password *string `json:"password"`
+	Email    *string `json:"email"`
+	Role     *string `json:"role"`
+}
+
+// respondUserError maps user store errors to HTTP statuses.
+func (s *Server) respondUserError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, storage.ErrValidation):
+		s.respondError(c, http.StatusUnprocessableEntity, err)
+	case errors.Is(err, storage.ErrConflict):
+		s.respondError(c, http.StatusConflict, err)
+	default:
+		s.respondError(c, http.StatusNotFound, err)
+	}
+}
+
+// currentUser returns the id and stored role of the user the bearer token
+// was issued to, or of the owner of the API key. It is false without JWT
+// authentication or for API keys without an owner.
+func currentUser(c *gin.Context) (int64, string, bool) {
+	for _, key := range []string{"api_key_user", "user"} {
+		if v, ok := c.Get(key); ok {
+			if user, ok := v.(models.User); ok {
+				return user.ID, user.Role, true
+			}
+		}
+	}
+	return 0, "", false
+}
+
+// handleGetCurrentUser returns the user the bearer token was issued to.
+func (s *Server) handleGetCurrentUser(c *gin.Context) {
+	id, _, ok := currentUser(c)
+	if !ok {
+		s.respondError(c, http.StatusUnauthorized, fmt.Errorf("no authenticated user"))
+		return
+	}
+	user, err := s.store.GetUserByID(c.Request.Context(), id)
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"user": user})
+}
+
+// handleListUsers returns all users.
+func (s *Server) handleListUsers(c *gin.Context) {
+	users, err := s.store.ListUsers(c.Request.Context())
+	if err != nil {
+		s.respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"users": users})
+}
+
+// handleCreateUser adds a user; the role defaults to member.
+func (s *Server) handleCreateUser(c *gin.Context) {
+	var req createUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	user, err := s.store.CreateUser(c.Request.Context(), req.Username, req.Password, req.Email, req.Role)
+	if err != nil {
+		s.respondUserError(c, err)
+		return
+	}
+	respondSuccess(c, http.StatusCreated, gin.H{"user": user})
+}
+
+// handleUpdateUser edits a user. With JWT authentication members may only
+// edit themselves and not change their role.
+func (s *Server) handleUpdateUser(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	var req updateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	if s.jwtSecret != "" {
+		self, role, ok := currentUser(c)
+		if !ok || (role != "admin" && (self != id || req.Role != nil)) {
+			s.respondError(c, http.StatusForbidden, fmt.Errorf("admin role required"))
+			return
+		}
+	}
+
+	user, err := s.store.UpdateUser(c.Request.Context(), id, storage.UserUpdate{
+		Username: req.Username,
+		Email:    req.Email,
+		Password: req.Password,
+		Role:     req.Role,
+	})
+	if err != nil {
+		s.respondUserError(c, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"user": user})
+}
+
+// handleDeleteUser removes a user.
+func (s *Server) handleDeleteUser(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	if err := s.store.DeleteUser(c.Request.Context(), id); err != nil {
+		s.respondUserError(c, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"status": "deleted"})
+}
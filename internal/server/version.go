@@ -0,0 +1,128 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"todo/internal/defaults"
+)
+
+// apiVersionHeader names the API version that served a response.
+const apiVersionHeader = "API-Version"
+
+// DefaultAPIVersion is the canonical API version unless configured otherwise.
+const DefaultAPIVersion = defaults.APIVersion
+
+// apiVersion is an entry of the version registry.
+type apiVersion struct {
+	Name string
+	// Deprecated is when the version was deprecated; zero while current.
+	Deprecated time.Time
+	// Sunset is when the version is due to be removed; zero if not planned.
+	Sunset time.Time
+}
+
+// apiVersions is the version registry, oldest first. Every version is served
+// under /api/<name>/ and the canonical one under plain /api/ as well.
+//
+// A version goes through three stages:
+//
+//   - supported: it is listed here with a zero Deprecated time. Changes to it
+//     must stay backwards compatible; breaking ones go to a new version, for
+//     example v2, added at the end of the list.
+//   - deprecated: a newer version has replaced it. Set Deprecated and, once
+//     decided, Sunset; its responses then carry Deprecation and Sunset
+//     headers so clients notice before it goes away. It cannot stay the
+//     canonical version.
+//   - removed: after its sunset the entry is deleted and its routes return
+//     404.
+var apiVersions = []apiVersion{
+	{Name: "v1"},
+}
+
+// SupportedAPIVersion reports whether name is a registered API version that
+// has not been deprecated, and so may be the canonical one.
+func SupportedAPIVersion(name string) bool {
+	v, ok := lookupAPIVersion(name)
+	return ok && v.Deprecated.IsZero()
+}
+
+func lookupAPIVersion(name string) (apiVersion, bool) {
+	for _, v := range apiVersions {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return apiVersion{}, false
+}
+
+// apiVersionMiddleware sets API-Version on every response: the version
+// named by the path, or the canonical version for every other route.
+// Deprecated versions also get Deprecation and Sunset headers.
+func apiVersionMiddleware(canonical string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := canonical
+		if v, ok := requestAPIVersion(c.Request.URL.Path); ok {
+			name = v.Name
+			h := c.Writer.Header()
+			if !v.Deprecated.IsZero() {
+				h.Set("Deprecation", "@"+strconv.FormatInt(v.Deprecated.Unix(), 10))
+			}
+			if !v.Sunset.IsZero() {
+				h.Set("Sunset", v.Sunset.UTC().Format(http.TimeFormat))
+			}
+		}
+		c.Header(apiVersionHeader, name)
+		c.Next()
+	}
+}
+
+// statusesDeprecated is when /projects/:id/statuses gave way to
+// /projects/:id/columns, which names the same resource.
+var statusesDeprecated = time.Date(2026, time.October, 14, 0, 0, 0, 0, time.UTC)
+
+// deprecatedAlias marks a response as served by a deprecated route, with a
+// Link to the route replacing it: the request path with from swapped for to.
+func deprecatedAlias(since time.Time, from, to string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		h := c.Writer.Header()
+		h.Set("Deprecation", "@"+strconv.FormatInt(since.Unix(), 10))
+		h.Set("Link", "<"+strings.Replace(c.Request.URL.Path, from, to, 1)+`>; rel="successor-version"`)
+		c.Next()
+	}
+}
+
+// requestAPIVersion returns the registered version named by /api/<version>/.
+func requestAPIVersion(path string) (apiVersion, bool) {
+	rest, ok := strings.CutPrefix(path, "/api/")
+	if !ok {
+		return apiVersion{}, false
+	}
+	name, _, _ := strings.Cut(rest, "/")
+	return lookupAPIVersion(name)
+}
+
+// apiRoute strips /api and any version segment from a route template or
+// path, so "/api/v1/projects/:id" and "/api/projects/:id" both give
+// "/projects/:id".
+func apiRoute(path string) string {
+	route := strings.TrimPrefix(path, "/api")
+	if v, ok := requestAPIVersion(path); ok {
+		route = strings.TrimPrefix(route, "/"+v.Name)
+	}
+	return route
+}
+
+// handleListVersions returns the canonical API version and every version
+// still served.
+func (s *Server) handleListVersions(c *gin.Context) {
+	supported := make([]string, 0, len(apiVersions))
+	for _, v := range apiVersions {
+		supported = append(supported, v.Name)
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"current": s.apiVersion, "supported": supported})
+}
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"todo/internal/storage"
+)
+
+// handleAddWatcher subscribes a name to a task.
+func (s *Server) handleAddWatcher(c *gin.Context) {
+	taskID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	err := s.store.AddWatcher(c.Request.Context(), taskID, c.Param("name"))
+	if errors.Is(err, storage.ErrValidation) {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	s.respondTask(c, taskID)
+}
+
+// handleRemoveWatcher unsubscribes a name from a task.
+func (s *Server) handleRemoveWatcher(c *gin.Context) {
+	taskID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	if err := s.store.RemoveWatcher(c.Request.Context(), taskID, c.Param("name")); err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	s.respondTask(c, taskID)
+}
+
+// respondTask replies with the current state of a task.
+func (s *Server) respondTask(c *gin.Context, id int64) {
+	task, err := s.store.GetTask(c.Request.Context(), id)
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"task": task})
+}
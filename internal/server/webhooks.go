@@ -0,0 +1,142 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+type webhookRequest struct {
+	URL    *string  `json:"url"`
+	Events []string `json:"events"`
+	Secret *string  `json:"secret"`
+	Active *bool    `json:"active"`
+}
+
+// handleListWebhooks returns the webhooks of a project.
+func (s *Server) handleListWebhooks(c *gin.Context) {
+	projectID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	hooks, err := s.store.ListWebhooks(c.Request.Context(), projectID)
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"webhooks": hooks})
+}
+
+// handleCreateWebhook registers a webhook for a project.
+func (s *Server) handleCreateWebhook(c *gin.Context) {
+	projectID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req webhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	active := true
+	if req.Active != nil {
+		active = *req.Active
+	}
+
+	hook, err := s.store.CreateWebhook(c.Request.Context(), models.Webhook{
+		ProjectID: &projectID,
+		URL:       getString(req.URL),
+		Events:    req.Events,
+		Secret:    getString(req.Secret),
+		Active:    active,
+	})
+	if errors.Is(err, storage.ErrValidation) {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusCreated, gin.H{"webhook": hook})
+}
+
+// handleUpdateWebhook changes the url, events, secret or active flag.
+func (s *Server) handleUpdateWebhook(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req webhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	updates := map[string]any{}
+	if req.URL != nil {
+		updates["url"] = *req.URL
+	}
+	if req.Events != nil {
+		updates["events"] = req.Events
+	}
+	if req.Secret != nil {
+		updates["secret"] = *req.Secret
+	}
+	if req.Active != nil {
+		updates["active"] = *req.Active
+	}
+
+	hook, err := s.store.UpdateWebhook(c.Request.Context(), id, updates)
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"webhook": hook})
+}
+
+// handleDeleteWebhook removes a webhook.
+func (s *Server) handleDeleteWebhook(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	if err := s.store.DeleteWebhook(c.Request.Context(), id); err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"status": "deleted"})
+}
+
+// handleListWebhookDeliveries returns recent delivery attempts of a webhook.
+func (s *Server) handleListWebhookDeliveries(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	deliveries, err := s.store.ListWebhookDeliveries(c.Request.Context(), id)
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"deliveries": deliveries})
+}
+
+// handleTestWebhook queues a ping delivery to a webhook.
+func (s *Server) handleTestWebhook(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	delivery, err := s.store.TestWebhook(c.Request.Context(), id)
+	if err != nil {
+		s.respondError(c, http.StatusNotFound, err)
+		return
+	}
+	respondSuccess(c, http.StatusAccepted, gin.H{"delivery": delivery})
+}
@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"context"
+	"strings"
+)
+
+type (
+	memberKey    struct{}
+	sessionKey   struct{}
+	changedByKey struct{}
+)
+
+// WithProjectMember limits the queries spanning projects, such as
+// ListAllTasks, SearchTasks or ListActivity, to the projects userID is a
+// member of.
+func WithProjectMember(ctx context.Context, userID int64) context.Context {
+	return context.WithValue(ctx, memberKey{}, userID)
+}
+
+// ProjectMember returns the user set by WithProjectMember, if any.
+func ProjectMember(ctx context.Context) (int64, bool) {
+	userID, ok := ctx.Value(memberKey{}).(int64)
+	return userID, ok
+}
+
+// WithSession returns a context whose undoable operations are journaled under
+// session, so that Undo only reverses that session's own changes.
+func WithSession(ctx context.Context, session string) context.Context {
+	return context.WithValue(ctx, sessionKey{}, session)
+}
+
+// Session returns the session set by WithSession, or "".
+func Session(ctx context.Context) string {
+	session, _ := ctx.Value(sessionKey{}).(string)
+	return session
+}
+
+// WithChangedBy returns a context that attributes task changes made with it
+// to the given name in the activity log.
+func WithChangedBy(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, changedByKey{}, strings.TrimSpace(name))
+}
+
+// ChangedBy returns the name set by WithChangedBy, or "".
+func ChangedBy(ctx context.Context) string {
+	name, _ := ctx.Value(changedByKey{}).(string)
+	return name
+}
@@ -0,0 +1,153 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+// fieldChange is one field of a task before and after an update.
+type fieldChange struct {
+	field, oldValue, newValue string
+}
+
+// recordActivity logs every change whose value actually differs.
+func recordActivity(ctx context.Context, tx *observedTx, taskID int64, changes []fieldChange) error {
+	actor := storage.ChangedBy(ctx)
+	for _, ch := range changes {
+		if ch.oldValue == ch.newValue {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO activity_log(task_id, field, old_value, new_value, changed_by) VALUES(?, ?, ?, ?, ?)`,
+			taskID, ch.field, ch.oldValue, ch.newValue, actor); err != nil {
+			return fmt.Errorf("record activity: %w", err)
+		}
+	}
+	return nil
+}
+
+// formatID renders an optional id for the activity log; nil becomes "".
+func formatID(id *int64) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.FormatInt(*id, 10)
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// ListTaskActivity returns the change history of a task, newest first.
+func (s *Store) ListTaskActivity(ctx context.Context, taskID int64) ([]models.ActivityEntry, error) {
+	ctx, span := tracer.Start(ctx, "store.ListTaskActivity")
+	defer span.End()
+	if _, err := s.GetTask(ctx, taskID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT id, task_id, field, old_value, new_value, changed_by, changed_at FROM activity_log
+        WHERE task_id = ? ORDER BY changed_at DESC, id DESC`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("list activity: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.ActivityEntry{}
+	for rows.Next() {
+		var e models.ActivityEntry
+		if err := rows.Scan(&e.ID, &e.TaskID, &e.Field, &e.OldValue, &e.NewValue, &e.ChangedBy, &e.ChangedAt); err != nil {
+			return nil, fmt.Errorf("scan activity: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// ListProjectEvents returns the activity feed of a project, newest first.
+// Only events after since, when set, and with an id below before, when
+// positive, are returned, so the id of the last entry pages further back.
+func (s *Store) ListProjectEvents(ctx context.Context, projectID int64, since *time.Time, before int64, limit int) ([]models.ProjectEvent, error) {
+	ctx, span := tracer.Start(ctx, "store.ListProjectEvents")
+	defer span.End()
+	if _, err := s.GetProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT id, project_id, type, task_id, task_title, old_value, new_value, created_at FROM project_events WHERE project_id = ?`
+	args := []any{projectID}
+	if since != nil {
+		query += ` AND created_at >= ?`
+		args = append(args, since.UTC().Format(timestampLayout))
+	}
+	if before > 0 {
+		query += ` AND id < ?`
+		args = append(args, before)
+	}
+	rows, err := s.db.QueryContext(ctx, query+` ORDER BY id DESC LIMIT ?`, append(args, limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("list project events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []models.ProjectEvent{}
+	for rows.Next() {
+		var e models.ProjectEvent
+		if err := rows.Scan(&e.ID, &e.ProjectID, &e.Type, &e.TaskID, &e.TaskTitle, &e.OldValue, &e.NewValue, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan project event: %w", err)
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// ListActivity returns the most recent changes across all live projects.
+func (s *Store) ListActivity(ctx context.Context, limit int) ([]models.ActivityEntry, error) {
+	ctx, span := tracer.Start(ctx, "store.ListActivity")
+	defer span.End()
+	scope, args := memberScope(ctx, "p.id")
+	var arg any
+	if len(args) > 0 {
+		arg = args[0]
+	}
+	return s.listActivityFeed(ctx, `p.deleted_at IS NULL`+scope, arg, limit)
+}
+
+// listActivityFeed joins the activity log with its tasks, newest first. A nil
+// arg means the clause takes no placeholder.
+func (s *Store) listActivityFeed(ctx context.Context, clause string, arg any, limit int) ([]models.ActivityEntry, error) {
+	args := []any{}
+	if arg != nil {
+		args = append(args, arg)
+	}
+	args = append(args, limit)
+
+	rows, err := s.db.QueryContext(ctx, `SELECT a.id, a.task_id, t.project_id, t.title, a.field, a.old_value, a.new_value, a.changed_by, a.changed_at
+        FROM activity_log a
+        JOIN tasks t ON t.id = a.task_id
+        JOIN projects p ON p.id = t.project_id
+        WHERE t.deleted_at IS NULL AND `+clause+`
+        ORDER BY a.changed_at DESC, a.id DESC
+        LIMIT ?`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list activity: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.ActivityEntry{}
+	for rows.Next() {
+		var e models.ActivityEntry
+		if err := rows.Scan(&e.ID, &e.TaskID, &e.ProjectID, &e.TaskTitle, &e.Field, &e.OldValue, &e.NewValue, &e.ChangedBy, &e.ChangedAt); err != nil {
+			return nil, fmt.Errorf("scan activity: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,157 @@
+package postgres
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"todo/internal/models"
+)
+
+// apiKeyPrefix marks generated keys so they are easy to spot in configs.
+const apiKeyPrefix = "todo_"
+
+const apiKeyColumns = `id, user_id, name, created_at, last_used_at, expires_at`
+
+func scanAPIKey(row rowScanner) (models.APIKey, error) {
+	var (
+		k          models.APIKey
+		userID     sql.NullInt64
+		lastUsedAt sql.NullTime
+		expiresAt  sql.NullTime
+	)
+	if err := row.Scan(&k.ID, &userID, &k.Name, &k.CreatedAt, &lastUsedAt, &expiresAt); err != nil {
+		return models.APIKey{}, err
+	}
+	if userID.Valid {
+		k.UserID = &userID.Int64
+	}
+	if lastUsedAt.Valid {
+		k.LastUsedAt = &lastUsedAt.Time
+	}
+	if expiresAt.Valid {
+		k.ExpiresAt = &expiresAt.Time
+	}
+	return k, nil
+}
+
+func hashAPIKey(plaintext string) string {
+	sum := sha256.Sum256([]byte(plaintext))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateAPIKey generates a new key and stores its SHA-256 hash. The plaintext
+// is returned once and cannot be recovered later. A nil expiresAt never
+// expires. The key acts as userID; a nil userID leaves it without an owner,
+// which only grants access while authentication is disabled.
+func (s *Store) CreateAPIKey(ctx context.Context, userID *int64, name string, expiresAt *time.Time) (models.APIKey, string, error) {
+	ctx, span := tracer.Start(ctx, "store.CreateAPIKey")
+	defer span.End()
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return models.APIKey{}, "", fmt.Errorf("%w: name must not be empty", ErrValidation)
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return models.APIKey{}, "", fmt.Errorf("%w: expires_at must be in the future", ErrValidation)
+	}
+
+	var raw [32]byte
+	if _, err := rand.Read(raw[:]); err != nil {
+		return models.APIKey{}, "", fmt.Errorf("generate key: %w", err)
+	}
+	plaintext := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(raw[:])
+
+	var expires any
+	if expiresAt != nil {
+		expires = expiresAt.UTC().Format(timestampLayout)
+	}
+	var id int64
+	if err := s.db.QueryRowContext(ctx, `INSERT INTO api_keys(key_hash, user_id, name, expires_at) VALUES(?, ?, ?, ?) RETURNING id`, hashAPIKey(plaintext), userID, name, expires).Scan(&id); err != nil {
+		return models.APIKey{}, "", fmt.Errorf("insert api key: %w", err)
+	}
+	key, err := scanAPIKey(s.db.QueryRowContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE id = ?`, id))
+	if err != nil {
+		return models.APIKey{}, "", fmt.Errorf("get api key: %w", err)
+	}
+	return key, plaintext, nil
+}
+
+// ListAPIKeys returns the keys of owner, or all keys when owner is nil,
+// newest first.
+func (s *Store) ListAPIKeys(ctx context.Context, owner *int64) ([]models.APIKey, error) {
+	ctx, span := tracer.Start(ctx, "store.ListAPIKeys")
+	defer span.End()
+	stmt := `SELECT ` + apiKeyColumns + ` FROM api_keys`
+	var args []any
+	if owner != nil {
+		stmt += ` WHERE user_id = ?`
+		args = append(args, *owner)
+	}
+	rows, err := s.db.QueryContext(ctx, stmt+` ORDER BY id DESC`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list api keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan api key: %w", err)
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+// RevokeAPIKey deletes a key so it can no longer authenticate. With a
+// non-nil owner, keys of other users count as not found.
+func (s *Store) RevokeAPIKey(ctx context.Context, id int64, owner *int64) error {
+	ctx, span := tracer.Start(ctx, "store.RevokeAPIKey")
+	defer span.End()
+	stmt := `DELETE FROM api_keys WHERE id = ?`
+	args := []any{id}
+	if owner != nil {
+		stmt += ` AND user_id = ?`
+		args = append(args, *owner)
+	}
+	res, err := s.db.ExecContext(ctx, stmt, args...)
+	if err != nil {
+		return fmt.Errorf("revoke api key: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("api key not found")
+	}
+	return nil
+}
+
+// AuthenticateAPIKey looks up a plaintext key by its hash and records the use.
+func (s *Store) AuthenticateAPIKey(ctx context.Context, plaintext string) (models.APIKey, error) {
+	ctx, span := tracer.Start(ctx, "store.AuthenticateAPIKey")
+	defer span.End()
+	key, err := scanAPIKey(s.db.QueryRowContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = ?`, hashAPIKey(plaintext)))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.APIKey{}, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return models.APIKey{}, fmt.Errorf("get api key: %w", err)
+	}
+	if key.ExpiresAt != nil && !key.ExpiresAt.After(time.Now()) {
+		return models.APIKey{}, ErrAPIKeyExpired
+	}
+	if _, err := s.db.ExecContext(ctx, `UPDATE api_keys SET last_used_at = CURRENT_TIMESTAMP WHERE id = ?`, key.ID); err != nil {
+		return models.APIKey{}, fmt.Errorf("touch api key: %w", err)
+	}
+	return key, nil
+}
@@ -0,0 +1,45 @@
+package postgres
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp"
+	"time"
+)
+
+var (
+	hexColorPattern      = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+	shortHexColorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{3}$`)
+)
+
+// validateHexColor accepts six digit CSS hex colors such as #2563eb.
+func validateHexColor(color string) error {
+	if !hexColorPattern.MatchString(color) {
+		return fmt.Errorf("%w: color must be a hex value like #2563eb", ErrValidation)
+	}
+	return nil
+}
+
+// normalizeHexColor expands three digit shorthand such as #2ae to #22aaee
+// and returns anything else unchanged.
+func normalizeHexColor(color string) string {
+	if !shortHexColorPattern.MatchString(color) {
+		return color
+	}
+	return string([]byte{'#', color[1], color[1], color[2], color[2], color[3], color[3]})
+}
+
+// randomPaletteColor picks a palette color in six digit form.
+func randomPaletteColor() string {
+	palette := []string{
+		"#2563eb", // blue-600
+		"#7c3aed", // violet-600
+		"#dc2626", // red-600
+		"#059669", // green-600
+		"#ea580c", // orange-600
+		"#d97706", // amber-600
+		"#0ea5e9", // sky-500
+	}
+	rand.Seed(time.Now().UnixNano())
+	return normalizeHexColor(palette[rand.Intn(len(palette))])
+}
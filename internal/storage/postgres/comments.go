@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"todo/internal/models"
+)
+
+const maxCommentAuthorLength = 200
+
+// ListComments returns the comments of a task, oldest first.
+func (s *Store) ListComments(ctx context.Context, taskID int64) ([]models.Comment, error) {
+	ctx, span := tracer.Start(ctx, "store.ListComments")
+	defer span.End()
+	if _, err := s.GetTask(ctx, taskID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT id, task_id, author, body, created_at FROM comments WHERE task_id = ? ORDER BY created_at, id`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("list comments: %w", err)
+	}
+	defer rows.Close()
+
+	comments := []models.Comment{}
+	for rows.Next() {
+		var cm models.Comment
+		if err := rows.Scan(&cm.ID, &cm.TaskID, &cm.Author, &cm.Body, &cm.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan comment: %w", err)
+		}
+		comments = append(comments, cm)
+	}
+	return comments, rows.Err()
+}
+
+// CreateComment adds a comment to a task.
+func (s *Store) CreateComment(ctx context.Context, taskID int64, author, body string) (models.Comment, error) {
+	ctx, span := tracer.Start(ctx, "store.CreateComment")
+	defer span.End()
+	author = strings.TrimSpace(author)
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return models.Comment{}, fmt.Errorf("comment body must not be empty")
+	}
+	if utf8.RuneCountInString(author) > maxCommentAuthorLength {
+		return models.Comment{}, fmt.Errorf("comment author must be at most %d characters", maxCommentAuthorLength)
+	}
+	if _, err := s.GetTask(ctx, taskID); err != nil {
+		return models.Comment{}, err
+	}
+
+	var id int64
+	if err := s.db.QueryRowContext(ctx, `INSERT INTO comments(task_id, author, body) VALUES(?, ?, ?) RETURNING id`, taskID, author, body).Scan(&id); err != nil {
+		return models.Comment{}, fmt.Errorf("insert comment: %w", err)
+	}
+
+	var cm models.Comment
+	err := s.db.QueryRowContext(ctx, `SELECT id, task_id, author, body, created_at FROM comments WHERE id = ?`, id).
+		Scan(&cm.ID, &cm.TaskID, &cm.Author, &cm.Body, &cm.CreatedAt)
+	if err != nil {
+		return models.Comment{}, fmt.Errorf("get comment: %w", err)
+	}
+	return cm, nil
+}
+
+// DeleteComment permanently removes a comment.
+func (s *Store) DeleteComment(ctx context.Context, id int64) error {
+	ctx, span := tracer.Start(ctx, "store.DeleteComment")
+	defer span.End()
+	res, err := s.db.ExecContext(ctx, `DELETE FROM comments WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("delete comment: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("comment not found")
+	}
+	return nil
+}
+
+// attachCommentCounts fills the CommentCount field of each task.
+func (s *Store) attachCommentCounts(ctx context.Context, tasks []models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	index, args := taskIndex(tasks)
+	rows, err := s.db.QueryContext(ctx, `SELECT t.id, COUNT(c.id) FROM tasks t
+        LEFT JOIN comments c ON c.task_id = t.id
+        WHERE t.id IN (`+placeholders(len(args))+`)
+        GROUP BY t.id`, args...)
+	if err != nil {
+		return fmt.Errorf("count comments: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var taskID int64
+		var count int
+		if err := rows.Scan(&taskID, &count); err != nil {
+			return fmt.Errorf("scan comment count: %w", err)
+		}
+		if i, ok := index[taskID]; ok {
+			tasks[i].CommentCount = count
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,147 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"todo/internal/models"
+)
+
+// AddDependency records that blockerID blocks blockedID. Both tasks must
+// belong to the same project and the new edge must not introduce a cycle.
+func (s *Store) AddDependency(ctx context.Context, blockerID, blockedID int64) error {
+	ctx, span := tracer.Start(ctx, "store.AddDependency")
+	defer span.End()
+	if blockerID == blockedID {
+		return fmt.Errorf("%w: task cannot block itself", ErrConflict)
+	}
+	blocker, err := s.GetTask(ctx, blockerID)
+	if err != nil {
+		return err
+	}
+	blocked, err := s.GetTask(ctx, blockedID)
+	if err != nil {
+		return err
+	}
+	if blocker.ProjectID != blocked.ProjectID {
+		return fmt.Errorf("%w: dependencies must stay within one project", ErrValidation)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("add dependency: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Walk everything blockedID already blocks; reaching blockerID means the
+	// new edge would close a loop.
+	var cycles int64
+	err = tx.QueryRowContext(ctx, `WITH RECURSIVE chain(id) AS (
+            SELECT blocked_id FROM task_dependencies WHERE blocker_id = ?
+            UNION
+            SELECT d.blocked_id FROM task_dependencies d JOIN chain c ON d.blocker_id = c.id
+        )
+        SELECT COUNT(*) FROM chain WHERE id = ?`, blockedID, blockerID).Scan(&cycles)
+	if err != nil {
+		return fmt.Errorf("check dependency cycle: %w", err)
+	}
+	if cycles > 0 {
+		return fmt.Errorf("%w: dependency would create a cycle", ErrConflict)
+	}
+
+	if _, err := tx.ExecContext(ctx, `INSERT INTO task_dependencies(blocker_id, blocked_id) VALUES(?, ?) ON CONFLICT DO NOTHING`, blockerID, blockedID); err != nil {
+		return fmt.Errorf("add dependency: %w", err)
+	}
+	return tx.Commit()
+}
+
+// RemoveDependency deletes the blockerID -> blockedID edge.
+func (s *Store) RemoveDependency(ctx context.Context, blockerID, blockedID int64) error {
+	ctx, span := tracer.Start(ctx, "store.RemoveDependency")
+	defer span.End()
+	res, err := s.db.ExecContext(ctx, `DELETE FROM task_dependencies WHERE blocker_id = ? AND blocked_id = ?`, blockerID, blockedID)
+	if err != nil {
+		return fmt.Errorf("remove dependency: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("dependency not found")
+	}
+	return nil
+}
+
+// ListBlockers returns the live tasks that block taskID.
+func (s *Store) ListBlockers(ctx context.Context, taskID int64) ([]models.Task, error) {
+	ctx, span := tracer.Start(ctx, "store.ListBlockers")
+	defer span.End()
+	return s.listRelatedTasks(ctx, `SELECT blocker_id FROM task_dependencies WHERE blocked_id = ?`, taskID)
+}
+
+// ListBlocking returns the live tasks blocked by taskID.
+func (s *Store) ListBlocking(ctx context.Context, taskID int64) ([]models.Task, error) {
+	ctx, span := tracer.Start(ctx, "store.ListBlocking")
+	defer span.End()
+	return s.listRelatedTasks(ctx, `SELECT blocked_id FROM task_dependencies WHERE blocker_id = ?`, taskID)
+}
+
+func (s *Store) listRelatedTasks(ctx context.Context, idQuery string, taskID int64) ([]models.Task, error) {
+	if _, err := s.GetTask(ctx, taskID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT `+taskColumns+` FROM tasks
+        WHERE id IN (`+idQuery+`) AND deleted_at IS NULL ORDER BY project_id, status, position, id`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("list dependencies: %w", err)
+	}
+	tasks, err := scanTasks(rows)
+	if err != nil {
+		return nil, err
+	}
+	if tasks == nil {
+		tasks = []models.Task{}
+	}
+	return tasks, s.hydrateTasks(ctx, tasks)
+}
+
+// attachDependencies fills BlockerIDs and BlockingIDs of each task, ignoring
+// related tasks that are in the trash.
+func (s *Store) attachDependencies(ctx context.Context, tasks []models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	index, args := taskIndex(tasks)
+	for i := range tasks {
+		tasks[i].BlockerIDs = []int64{}
+		tasks[i].BlockingIDs = []int64{}
+	}
+
+	in := placeholders(len(args))
+	rows, err := s.db.QueryContext(ctx, `SELECT d.blocker_id, d.blocked_id FROM task_dependencies d
+        JOIN tasks b ON b.id = d.blocker_id AND b.deleted_at IS NULL
+        JOIN tasks t ON t.id = d.blocked_id AND t.deleted_at IS NULL
+        WHERE d.blocker_id IN (`+in+`) OR d.blocked_id IN (`+in+`)
+        ORDER BY d.blocker_id, d.blocked_id`, append(args, args...)...)
+	if err != nil {
+		return fmt.Errorf("load dependencies: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var blockerID, blockedID int64
+		if err := rows.Scan(&blockerID, &blockedID); err != nil {
+			return fmt.Errorf("scan dependency: %w", err)
+		}
+		if i, ok := index[blockedID]; ok {
+			tasks[i].BlockerIDs = append(tasks[i].BlockerIDs, blockerID)
+		}
+		if i, ok := index[blockerID]; ok {
+			tasks[i].BlockingIDs = append(tasks[i].BlockingIDs, blockedID)
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,125 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"todo/internal/models"
+)
+
+// unfinishedTask holds for tasks t whose status is not a terminal one of
+// their project.
+const unfinishedTask = `NOT EXISTS (SELECT 1 FROM statuses st WHERE st.project_id = t.project_id AND st.name = t.status AND st.is_terminal)`
+
+// overdueWhere selects live, unfinished tasks of live projects whose due date
+// has passed at now.
+func overdueWhere(now time.Time) (string, []any) {
+	return `t.deleted_at IS NULL AND p.deleted_at IS NULL AND ` + unfinishedTask + ` AND t.due_date < ?`,
+		[]any{now.UTC().Format(timestampLayout)}
+}
+
+// ListOverdueTasks returns the overdue tasks of a project at now, most
+// overdue first.
+func (s *Store) ListOverdueTasks(ctx context.Context, projectID int64, now time.Time) ([]models.OverdueTask, error) {
+	ctx, span := tracer.Start(ctx, "store.ListOverdueTasks")
+	defer span.End()
+	if _, err := s.GetProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+	where, args := overdueWhere(now)
+	tasks, err := s.queryProjectTasks(ctx, `WHERE t.project_id = ? AND `+where+` ORDER BY t.due_date, t.id`, append([]any{projectID}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	return overdueTasks(tasks, now), nil
+}
+
+// ListAllOverdueTasks returns every overdue task at now with its project name
+// and color, most overdue first. With byProject the tasks are grouped by
+// project name instead, most overdue first within each project.
+func (s *Store) ListAllOverdueTasks(ctx context.Context, now time.Time, byProject bool) ([]models.OverdueTask, error) {
+	ctx, span := tracer.Start(ctx, "store.ListAllOverdueTasks")
+	defer span.End()
+	where, args := overdueWhere(now)
+	scope, scopeArgs := memberScope(ctx, "t.project_id")
+	where += scope
+	args = append(args, scopeArgs...)
+	order := `t.due_date, t.id`
+	if byProject {
+		order = `lower(p.name), p.id, ` + order
+	}
+	tasks, err := s.queryProjectTasks(ctx, `WHERE `+where+` ORDER BY `+order, args...)
+	if err != nil {
+		return nil, err
+	}
+	return overdueTasks(tasks, now), nil
+}
+
+// overdueTasks adds to each task the whole days since it fell due at now.
+func overdueTasks(tasks []models.ProjectTask, now time.Time) []models.OverdueTask {
+	out := make([]models.OverdueTask, len(tasks))
+	for i, t := range tasks {
+		out[i] = models.OverdueTask{ProjectTask: t}
+		if t.DueDate != nil {
+			out[i].DaysOverdue = int(now.Sub(*t.DueDate).Hours() / 24)
+		}
+	}
+	return out
+}
+
+// CountOverdueTasks returns how many tasks ListAllOverdueTasks would return.
+func (s *Store) CountOverdueTasks(ctx context.Context, now time.Time) (int, error) {
+	ctx, span := tracer.Start(ctx, "store.CountOverdueTasks")
+	defer span.End()
+	where, args := overdueWhere(now)
+	scope, scopeArgs := memberScope(ctx, "t.project_id")
+	where += scope
+	args = append(args, scopeArgs...)
+	var count int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM tasks t JOIN projects p ON p.id = t.project_id WHERE `+where, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count overdue tasks: %w", err)
+	}
+	return count, nil
+}
+
+// MaxUpcomingDays caps the horizon of ListUpcomingTasks.
+const MaxUpcomingDays = 366
+
+// ListUpcomingTasks returns unfinished tasks of live projects due from now
+// until the end of the day days after today in now's location, so days 0
+// means due later today. Tasks are ordered by due date, then by priority with
+// the most urgent first.
+func (s *Store) ListUpcomingTasks(ctx context.Context, now time.Time, days int) ([]models.ProjectTask, error) {
+	ctx, span := tracer.Start(ctx, "store.ListUpcomingTasks")
+	defer span.End()
+	if days < 0 || days > MaxUpcomingDays {
+		return nil, fmt.Errorf("%w: days must be between 0 and %d", ErrValidation, MaxUpcomingDays)
+	}
+	y, m, d := now.AddDate(0, 0, days).Date()
+	until := time.Date(y, m, d, 23, 59, 59, 0, now.Location())
+	scope, scopeArgs := memberScope(ctx, "t.project_id")
+	return s.queryProjectTasks(ctx, `WHERE t.deleted_at IS NULL AND p.deleted_at IS NULL AND `+unfinishedTask+`
+            AND t.due_date IS NOT NULL AND t.due_date >= ? AND t.due_date <= ?`+scope+`
+        ORDER BY t.due_date, `+priorityRank("t.priority")+` DESC, t.id`,
+		append([]any{dueDateValue(&now), dueDateValue(&until)}, scopeArgs...)...)
+}
+
+// priorityRank returns an SQL expression ranking the priority in column as
+// models.ValidTaskPriorities does.
+func priorityRank(column string) string {
+	names := make([]string, 0, len(models.ValidTaskPriorities))
+	for name := range models.ValidTaskPriorities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	b.WriteString("CASE " + column)
+	for _, name := range names {
+		fmt.Fprintf(&b, " WHEN '%s' THEN %d", name, models.ValidTaskPriorities[name])
+	}
+	b.WriteString(" ELSE 0 END")
+	return b.String()
+}
@@ -0,0 +1,570 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+// ExportProject returns a project with its labels and live tasks, including
+// snoozed tasks, sub-tasks, comments and checklists, in a form ImportProject
+// accepts.
+func (s *Store) ExportProject(ctx context.Context, projectID int64) (*models.ProjectExport, error) {
+	ctx, span := tracer.Start(ctx, "store.ExportProject")
+	defer span.End()
+	project, err := s.GetProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	statuses, err := projectStatuses(ctx, s.db, projectID)
+	if err != nil {
+		return nil, err
+	}
+	labels, err := s.ListLabels(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := s.listProjectTasks(ctx, projectID, ` ORDER BY status, position, id`)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &models.ProjectExport{
+		Version:    models.ProjectExportVersion,
+		ExportedAt: time.Now().UTC(),
+		Project:    project,
+		Statuses:   statuses,
+		Labels:     labels,
+		Tasks:      make([]models.ExportedTask, len(tasks)),
+	}
+	index := make(map[int64]int, len(tasks))
+	for i, t := range tasks {
+		index[t.ID] = i
+		export.Tasks[i] = models.ExportedTask{Task: t, Comments: []models.Comment{}, Checklist: []models.ChecklistItem{}}
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT c.id, c.task_id, c.author, c.body, c.created_at FROM comments c
+        JOIN tasks t ON t.id = c.task_id WHERE t.project_id = ? AND t.deleted_at IS NULL ORDER BY c.created_at, c.id`, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("export comments: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var cm models.Comment
+		if err := rows.Scan(&cm.ID, &cm.TaskID, &cm.Author, &cm.Body, &cm.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan comment: %w", err)
+		}
+		if i, ok := index[cm.TaskID]; ok {
+			export.Tasks[i].Comments = append(export.Tasks[i].Comments, cm)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	rows, err = s.db.QueryContext(ctx, `SELECT c.id, c.task_id, c.text, c.done, c.position, c.created_at FROM checklist_items c
+        JOIN tasks t ON t.id = c.task_id WHERE t.project_id = ? AND t.deleted_at IS NULL ORDER BY c.position, c.id`, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("export checklist: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		item, err := scanChecklistItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan checklist item: %w", err)
+		}
+		if i, ok := index[item.TaskID]; ok {
+			export.Tasks[i].Checklist = append(export.Tasks[i].Checklist, item)
+		}
+	}
+	return export, rows.Err()
+}
+
+// ImportProject recreates an exported project as a new project, remapping
+// every id. Everything is inserted in one transaction, so a failed import
+// leaves nothing behind. Exports of another format version are rejected.
+func (s *Store) ImportProject(ctx context.Context, export *models.ProjectExport) (models.Project, error) {
+	ctx, span := tracer.Start(ctx, "store.ImportProject")
+	defer span.End()
+	return s.importProject(ctx, export, false)
+}
+
+// DuplicateProject copies a project with its settings, status columns and
+// labels and, when asked, its tasks with their statuses, positions,
+// sub-tasks and checklists. Tasks in terminal statuses are left out unless
+// IncludeDone is set. Comments stay with the original and every copied row
+// gets fresh timestamps. The copy is written in one transaction.
+func (s *Store) DuplicateProject(ctx context.Context, id int64, opts DuplicateOptions) (models.Project, error) {
+	ctx, span := tracer.Start(ctx, "store.DuplicateProject")
+	defer span.End()
+	export, err := s.ExportProject(ctx, id)
+	if err != nil {
+		return models.Project{}, err
+	}
+	if name := strings.TrimSpace(opts.Name); name != "" {
+		export.Project.Name = name
+	}
+	export.Project.CreatedAt = time.Time{}
+	terminal := make(map[string]bool, len(export.Statuses))
+	for _, st := range export.Statuses {
+		terminal[st.Name] = st.IsTerminal
+	}
+	tasks := export.Tasks[:0]
+	for _, t := range export.Tasks {
+		if !opts.IncludeTasks || (terminal[t.Status] && !opts.IncludeDone) {
+			continue
+		}
+		t.CreatedAt = time.Time{}
+		t.CompletedAt = nil
+		t.Comments = nil
+		for i := range t.Checklist {
+			t.Checklist[i].CreatedAt = time.Time{}
+		}
+		tasks = append(tasks, t)
+	}
+	export.Tasks = tasks
+	return s.importProject(ctx, export, opts.Rename)
+}
+
+// importProject inserts an export as a new project. A taken name fails with
+// ErrConflict, or with rename gets " (copy)" appended until it is free.
+func (s *Store) importProject(ctx context.Context, export *models.ProjectExport, rename bool) (models.Project, error) {
+	if export == nil {
+		return models.Project{}, fmt.Errorf("%w: export must not be empty", ErrValidation)
+	}
+	if export.Version != models.ProjectExportVersion {
+		return models.Project{}, fmt.Errorf("%w: unsupported export version %d; this server imports version %d", ErrValidation, export.Version, models.ProjectExportVersion)
+	}
+	p := export.Project
+	if err := s.ValidateProject(p.Name, p.Color); err != nil {
+		return models.Project{}, err
+	}
+	p.Color = normalizeHexColor(p.Color)
+	if p.Color == "" {
+		p.Color = randomPaletteColor()
+	}
+	for _, l := range export.Labels {
+		if strings.TrimSpace(l.Name) == "" {
+			return models.Project{}, fmt.Errorf("%w: label %d: name must not be empty", ErrValidation, l.ID)
+		}
+	}
+	statuses := append([]models.TaskStatus(nil), export.Statuses...)
+	if len(statuses) == 0 {
+		// Exports made before statuses were per project use the defaults.
+		statuses = append(statuses, defaultStatuses...)
+	}
+	known := make(map[string]models.TaskStatus, len(statuses))
+	for i := range statuses {
+		st := &statuses[i]
+		st.WIPLimit = max(st.WIPLimit, 0)
+		if err := normalizeStatus(st); err != nil {
+			return models.Project{}, err
+		}
+		if _, dup := known[st.Name]; dup {
+			return models.Project{}, fmt.Errorf("%w: status %q appears more than once", ErrValidation, st.Name)
+		}
+		known[st.Name] = *st
+	}
+	tasks, err := s.importOrder(export.Tasks, known)
+	if err != nil {
+		return models.Project{}, err
+	}
+
+	var projectID int64
+	err = transaction(ctx, s.db, "import project", func(tx *observedTx) error {
+		name := strings.TrimSpace(p.Name)
+		for copies := 1; ; copies++ {
+			var taken bool
+			if err := tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM projects WHERE name = ? AND deleted_at IS NULL)`, name).Scan(&taken); err != nil {
+				return fmt.Errorf("import project: %w", err)
+			}
+			if !taken {
+				break
+			}
+			if !rename {
+				return fmt.Errorf("%w: a project named %q already exists", ErrConflict, name)
+			}
+			name = strings.TrimSpace(p.Name) + " (copy)"
+			if copies > 1 {
+				name = fmt.Sprintf("%s (copy %d)", strings.TrimSpace(p.Name), copies)
+			}
+		}
+		if err := s.ValidateProject(name, ""); err != nil {
+			return err
+		}
+		if err := tx.QueryRowContext(ctx, `INSERT INTO projects(name, color, description, deadline, created_at, position) VALUES(?, ?, ?, ?, COALESCE(?, CURRENT_TIMESTAMP), `+nextProjectPosition+`) RETURNING id`,
+			name, p.Color, strings.TrimSpace(p.Description), dueDateValue(p.Deadline), importedTime(p.CreatedAt)).Scan(&projectID); err != nil {
+			return fmt.Errorf("insert project: %w", err)
+		}
+		// The insert trigger gave the project the default statuses.
+		if len(export.Statuses) > 0 {
+			if _, err := tx.ExecContext(ctx, `DELETE FROM statuses WHERE project_id = ?`, projectID); err != nil {
+				return fmt.Errorf("replace statuses: %w", err)
+			}
+			for _, st := range statuses {
+				if _, err := tx.ExecContext(ctx, `INSERT INTO statuses(project_id, name, title, color, display_order, is_terminal, wip_limit, wip_mode) VALUES(?, ?, ?, ?, ?, ?, ?, ?)`,
+					projectID, st.Name, st.Title, st.Color, st.DisplayOrder, st.IsTerminal, st.WIPLimit, st.WIPMode); err != nil {
+					return fmt.Errorf("insert status %q: %w", st.Name, err)
+				}
+			}
+		}
+
+		labelIDs := make(map[int64]int64, len(export.Labels))
+		for _, l := range export.Labels {
+			color := normalizeHexColor(l.Color)
+			if color == "" {
+				color = randomPaletteColor()
+			}
+			var id int64
+			if err := tx.QueryRowContext(ctx, `INSERT INTO labels(project_id, name, color) VALUES(?, ?, ?) RETURNING id`, projectID, strings.TrimSpace(l.Name), color).Scan(&id); err != nil {
+				return fmt.Errorf("insert label %q: %w", l.Name, err)
+			}
+			labelIDs[l.ID] = id
+		}
+
+		taskIDs := make(map[int64]int64, len(tasks))
+		for _, t := range tasks {
+			id, err := importTask(ctx, tx, projectID, t, taskIDs[derefID(t.ParentID)], known[t.Status].IsTerminal)
+			if err != nil {
+				return fmt.Errorf("task %d: %w", t.ID, err)
+			}
+			taskIDs[t.ID] = id
+			for _, labelID := range t.Labels {
+				if newID, ok := labelIDs[labelID]; ok {
+					if _, err := tx.ExecContext(ctx, `INSERT INTO task_labels(task_id, label_id) VALUES(?, ?) ON CONFLICT DO NOTHING`, id, newID); err != nil {
+						return fmt.Errorf("attach label: %w", err)
+					}
+				}
+			}
+		}
+		// Dependencies can point forward in the list, so they go in once
+		// every task has its new id.
+		for _, t := range tasks {
+			for _, blocker := range t.BlockerIDs {
+				if blockerID, ok := taskIDs[blocker]; ok {
+					if _, err := tx.ExecContext(ctx, `INSERT INTO task_dependencies(blocker_id, blocked_id) VALUES(?, ?) ON CONFLICT DO NOTHING`, blockerID, taskIDs[t.ID]); err != nil {
+						return fmt.Errorf("insert dependency: %w", err)
+					}
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return models.Project{}, err
+	}
+	project, err := s.GetProject(ctx, projectID)
+	if err == nil {
+		s.emit(ctx, "project.created", project.ID, project)
+	}
+	return project, err
+}
+
+// importOrder validates the exported tasks and orders them so every parent
+// comes before its sub-tasks. A parent missing from the export is dropped.
+func (s *Store) importOrder(tasks []models.ExportedTask, statuses map[string]models.TaskStatus) ([]models.ExportedTask, error) {
+	byID := make(map[int64]int, len(tasks))
+	numbers := make(map[int64]bool, len(tasks))
+	for i := range tasks {
+		t := &tasks[i]
+		if _, dup := byID[t.ID]; dup {
+			return nil, fmt.Errorf("%w: task id %d appears more than once", ErrValidation, t.ID)
+		}
+		byID[t.ID] = i
+		if t.Number > 0 {
+			if numbers[t.Number] {
+				return nil, fmt.Errorf("%w: task number %d appears more than once", ErrValidation, t.Number)
+			}
+			numbers[t.Number] = true
+		}
+		if err := s.validateImportedTask(&t.Task, statuses); err != nil {
+			return nil, fmt.Errorf("task %d: %w", t.ID, err)
+		}
+		for _, item := range t.Checklist {
+			if strings.TrimSpace(item.Text) == "" {
+				return nil, fmt.Errorf("%w: task %d: checklist item text must not be empty", ErrValidation, t.ID)
+			}
+		}
+		for _, cm := range t.Comments {
+			if strings.TrimSpace(cm.Body) == "" {
+				return nil, fmt.Errorf("%w: task %d: comment body must not be empty", ErrValidation, t.ID)
+			}
+		}
+	}
+
+	ordered := make([]models.ExportedTask, 0, len(tasks))
+	state := make(map[int64]int, len(tasks)) // 1 visiting, 2 done
+	var visit func(i int) error
+	visit = func(i int) error {
+		t := tasks[i]
+		switch state[t.ID] {
+		case 1:
+			return fmt.Errorf("%w: task %d is its own ancestor", ErrValidation, t.ID)
+		case 2:
+			return nil
+		}
+		state[t.ID] = 1
+		if t.ParentID != nil {
+			if parent, ok := byID[*t.ParentID]; ok {
+				if err := visit(parent); err != nil {
+					return err
+				}
+			} else {
+				t.ParentID = nil
+			}
+		}
+		state[t.ID] = 2
+		ordered = append(ordered, t)
+		return nil
+	}
+	for i := range tasks {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// validateImportedTask applies the checks CreateTask makes against the given
+// statuses, normalizing the task in place.
+func (s *Store) validateImportedTask(t *models.Task, statuses map[string]models.TaskStatus) error {
+	t.Title = strings.TrimSpace(t.Title)
+	t.Description = strings.TrimSpace(t.Description)
+	if t.Title == "" {
+		return fmt.Errorf("%w: task title must not be empty", ErrValidation)
+	}
+	if err := s.validateTaskText(t.Title, t.Description); err != nil {
+		return err
+	}
+	if _, ok := statuses[t.Status]; !ok {
+		return fmt.Errorf("%w: invalid status %q", ErrValidation, t.Status)
+	}
+	if t.Priority == "" {
+		t.Priority = models.DefaultTaskPriority
+	}
+	if err := validatePriority(t.Priority); err != nil {
+		return err
+	}
+	t.Assignee = strings.TrimSpace(t.Assignee)
+	if err := validateAssignee(t.Assignee); err != nil {
+		return err
+	}
+	t.Color = strings.TrimSpace(t.Color)
+	if t.Color != "" {
+		if err := validateHexColor(t.Color); err != nil {
+			return err
+		}
+	}
+	if err := validateStoryPoints(t.StoryPoints); err != nil {
+		return err
+	}
+	t.CoverURL = strings.TrimSpace(t.CoverURL)
+	if err := validateCoverURL(t.CoverURL); err != nil {
+		return err
+	}
+	fields := make(map[string]*string, len(t.Fields))
+	for k, v := range t.Fields {
+		fields[k] = &v
+	}
+	if _, err := mergeFields(nil, fields); err != nil {
+		return err
+	}
+	links, err := normalizeLinks(t.Links)
+	if err != nil {
+		return err
+	}
+	t.Links = links
+	return nil
+}
+
+// importTask inserts one validated task with its fields, links, watchers,
+// comments and checklist and returns its new id. A task in a terminal status
+// keeps its completion time. Sprints are not exported, so the task is left
+// outside any sprint.
+func importTask(ctx context.Context, tx *observedTx, projectID int64, t models.ExportedTask, parentID int64, terminal bool) (int64, error) {
+	var parent any
+	if parentID != 0 {
+		parent = parentID
+	}
+	var number any
+	if t.Number > 0 {
+		number = t.Number
+	}
+	var completedAt any
+	if terminal {
+		completedAt = time.Now().UTC().Format(timestampLayout)
+		if t.CompletedAt != nil {
+			completedAt = t.CompletedAt.UTC().Format(timestampLayout)
+		}
+	}
+	var id int64
+	err := tx.QueryRowContext(ctx, `INSERT INTO tasks(project_id, number, parent_id, title, description, status, priority, assignee, color, story_points, cover_url, due_date, snoozed_until, position, created_at, completed_at)
+        VALUES(?, COALESCE(?, (SELECT COALESCE(MAX(number), 0) + 1 FROM tasks WHERE project_id = ?)), ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, COALESCE(?, CURRENT_TIMESTAMP), ?)
+        RETURNING id`,
+		projectID, number, projectID, parent, t.Title, t.Description, t.Status, t.Priority, t.Assignee, t.Color, t.StoryPoints, t.CoverURL,
+		dueDateValue(t.DueDate), dueDateValue(t.SnoozedUntil), t.Position, importedTime(t.CreatedAt), completedAt).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("insert task: %w", err)
+	}
+
+	fields := make(map[string]*string, len(t.Fields))
+	for k, v := range t.Fields {
+		fields[k] = &v
+	}
+	if _, err := saveFields(ctx, tx, id, nil, fields); err != nil {
+		return 0, err
+	}
+	if err := replaceLinks(ctx, tx, id, t.Links); err != nil {
+		return 0, err
+	}
+	if err := syncMentions(ctx, tx, id, t.Description); err != nil {
+		return 0, err
+	}
+	if err := logStatus(ctx, tx, id, t.Status); err != nil {
+		return 0, err
+	}
+	for _, name := range t.Watchers {
+		if name = strings.TrimSpace(name); name != "" {
+			if _, err := tx.ExecContext(ctx, `INSERT INTO task_watchers(task_id, name) VALUES(?, ?) ON CONFLICT DO NOTHING`, id, name); err != nil {
+				return 0, fmt.Errorf("insert watcher: %w", err)
+			}
+		}
+	}
+	for _, cm := range t.Comments {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO comments(task_id, author, body, created_at) VALUES(?, ?, ?, COALESCE(?, CURRENT_TIMESTAMP))`,
+			id, strings.TrimSpace(cm.Author), strings.TrimSpace(cm.Body), importedTime(cm.CreatedAt)); err != nil {
+			return 0, fmt.Errorf("insert comment: %w", err)
+		}
+	}
+	for _, item := range t.Checklist {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO checklist_items(task_id, text, done, position, created_at) VALUES(?, ?, ?, ?, COALESCE(?, CURRENT_TIMESTAMP))`,
+			id, strings.TrimSpace(item.Text), item.Done, item.Position, importedTime(item.CreatedAt)); err != nil {
+			return 0, fmt.Errorf("insert checklist item: %w", err)
+		}
+	}
+	return id, nil
+}
+
+// importedTime binds an exported timestamp, or NULL when it is missing so the
+// column default applies.
+func importedTime(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC().Format(timestampLayout)
+}
+
+func derefID(id *int64) int64 {
+	if id == nil {
+		return 0
+	}
+	return *id
+}
+
+// MaxTaskImportRows caps the number of tasks one ImportTasks call accepts.
+const MaxTaskImportRows = storage.MaxTaskImportRows
+
+// ImportTasks adds tasks to the end of their columns in an existing project
+// within a single transaction. Invalid rows are skipped and reported, unless
+// strict is set, in which case the first one aborts the whole import.
+func (s *Store) ImportTasks(ctx context.Context, projectID int64, rows []models.TaskImportRow, strict bool) (models.TaskImportResult, error) {
+	ctx, span := tracer.Start(ctx, "store.ImportTasks")
+	defer span.End()
+	result := models.TaskImportResult{Errors: []models.TaskImportError{}}
+	if len(rows) > MaxTaskImportRows {
+		return result, fmt.Errorf("%w: at most %d rows can be imported at once", ErrValidation, MaxTaskImportRows)
+	}
+	if _, err := s.GetProject(ctx, projectID); err != nil {
+		return result, err
+	}
+	list, err := projectStatuses(ctx, s.db, projectID)
+	if err != nil {
+		return result, err
+	}
+	if len(list) == 0 {
+		return result, fmt.Errorf("%w: project has no statuses", ErrValidation)
+	}
+	statuses := make(map[string]models.TaskStatus, len(list))
+	for _, st := range list {
+		statuses[st.Name] = st
+	}
+
+	valid := make([]models.TaskImportRow, 0, len(rows))
+	for _, row := range rows {
+		if row.Task.Status == "" {
+			row.Task.Status = list[0].Name
+		}
+		var err error
+		if row.Err != nil {
+			err = fmt.Errorf("%w: %v", ErrValidation, row.Err)
+		} else {
+			err = s.validateImportedTask(&row.Task, statuses)
+		}
+		if err != nil {
+			if strict {
+				return result, fmt.Errorf("row %d: %w", row.Row, err)
+			}
+			result.Errors = append(result.Errors, models.TaskImportError{Row: row.Row, Error: err.Error()})
+			continue
+		}
+		valid = append(valid, row)
+	}
+
+	ids := make([]any, 0, len(valid))
+	err = transaction(ctx, s.db, "import tasks", func(tx *observedTx) error {
+		positions := make(map[string]int64)
+		for _, row := range valid {
+			t := row.Task
+			pos, ok := positions[t.Status]
+			if !ok {
+				var max sql.NullInt64
+				if err := tx.QueryRowContext(ctx, `SELECT MAX(position) FROM tasks WHERE project_id = ? AND status = ? AND deleted_at IS NULL`, projectID, t.Status).Scan(&max); err != nil {
+					return fmt.Errorf("select position: %w", err)
+				}
+				if max.Valid {
+					pos = max.Int64 + 1
+				}
+			}
+			positions[t.Status] = pos + 1
+			t.Position = pos
+			// Numbers always continue the project's sequence.
+			t.Number = 0
+			id, err := importTask(ctx, tx, projectID, models.ExportedTask{Task: t}, 0, statuses[t.Status].IsTerminal)
+			if err != nil {
+				return fmt.Errorf("row %d: %w", row.Row, err)
+			}
+			ids = append(ids, id)
+		}
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+	result.Imported = len(ids)
+	result.Skipped = len(rows) - len(ids)
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	loaded, err := s.db.QueryContext(ctx, `SELECT `+taskColumns+` FROM tasks WHERE id IN (`+placeholders(len(ids))+`) ORDER BY id`, ids...)
+	if err != nil {
+		return result, fmt.Errorf("load tasks: %w", err)
+	}
+	tasks, err := scanTasks(loaded)
+	if err != nil {
+		return result, err
+	}
+	if err := s.hydrateTasks(ctx, tasks); err != nil {
+		return result, err
+	}
+	for _, t := range tasks {
+		s.emit(ctx, "task.created", t.ProjectID, t)
+	}
+	return result, nil
+}
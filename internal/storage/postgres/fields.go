@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"todo/internal/models"
+)
+
+const (
+	maxTaskFields       = 50
+	maxFieldKeyLength   = 64
+	maxFieldValueLength = 2000
+)
+
+// ListTaskFields returns the custom fields of a task.
+func (s *Store) ListTaskFields(ctx context.Context, taskID int64) (map[string]string, error) {
+	ctx, span := tracer.Start(ctx, "store.ListTaskFields")
+	defer span.End()
+	task, err := s.GetTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	return task.Fields, nil
+}
+
+// mergeFields applies changes to current, where a nil value removes the key,
+// and validates the result.
+func mergeFields(current map[string]string, changes map[string]*string) (map[string]string, error) {
+	merged := make(map[string]string, len(current)+len(changes))
+	for k, v := range current {
+		merged[k] = v
+	}
+	for k, v := range changes {
+		key := strings.TrimSpace(k)
+		if key == "" {
+			return nil, fmt.Errorf("%w: field key must not be empty", ErrValidation)
+		}
+		if utf8.RuneCountInString(key) > maxFieldKeyLength {
+			return nil, fmt.Errorf("%w: field key %q must be at most %d characters", ErrValidation, key, maxFieldKeyLength)
+		}
+		if v == nil {
+			delete(merged, key)
+			continue
+		}
+		if utf8.RuneCountInString(*v) > maxFieldValueLength {
+			return nil, fmt.Errorf("%w: field %q must be at most %d characters", ErrValidation, key, maxFieldValueLength)
+		}
+		merged[key] = *v
+	}
+	if len(merged) > maxTaskFields {
+		return nil, fmt.Errorf("%w: a task may have at most %d fields", ErrValidation, maxTaskFields)
+	}
+	return merged, nil
+}
+
+// saveFields writes the custom field changes of a task inside tx and returns
+// them as activity entries keyed "fields.<key>".
+func saveFields(ctx context.Context, tx *observedTx, taskID int64, current map[string]string, changes map[string]*string) ([]fieldChange, error) {
+	keys := make([]string, 0, len(changes))
+	for k := range changes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var logged []fieldChange
+	for _, k := range keys {
+		key, v := strings.TrimSpace(k), changes[k]
+		old := current[key]
+		if v == nil {
+			if _, err := tx.ExecContext(ctx, `DELETE FROM task_fields WHERE task_id = ? AND key = ?`, taskID, key); err != nil {
+				return nil, fmt.Errorf("remove field: %w", err)
+			}
+			logged = append(logged, fieldChange{"fields." + key, old, ""})
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO task_fields(task_id, key, value) VALUES(?, ?, ?)
+            ON CONFLICT(task_id, key) DO UPDATE SET value = excluded.value`, taskID, key, *v); err != nil {
+			return nil, fmt.Errorf("save field: %w", err)
+		}
+		logged = append(logged, fieldChange{"fields." + key, old, *v})
+	}
+	return logged, nil
+}
+
+// attachFields fills the Fields map of each task using a single query.
+func (s *Store) attachFields(ctx context.Context, tasks []models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	index, args := taskIndex(tasks)
+	for i := range tasks {
+		tasks[i].Fields = map[string]string{}
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT task_id, key, value FROM task_fields WHERE task_id IN (`+placeholders(len(args))+`)`, args...)
+	if err != nil {
+		return fmt.Errorf("load task fields: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			taskID     int64
+			key, value string
+		)
+		if err := rows.Scan(&taskID, &key, &value); err != nil {
+			return fmt.Errorf("scan task field: %w", err)
+		}
+		if i, ok := index[taskID]; ok {
+			tasks[i].Fields[key] = value
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,209 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"todo/internal/models"
+)
+
+const savedFilterColumns = `id, project_id, name, definition, created_at, updated_at`
+
+func scanSavedFilter(row rowScanner) (models.SavedFilter, error) {
+	var (
+		f          models.SavedFilter
+		projectID  sql.NullInt64
+		definition string
+	)
+	if err := row.Scan(&f.ID, &projectID, &f.Name, &definition, &f.CreatedAt, &f.UpdatedAt); err != nil {
+		return models.SavedFilter{}, err
+	}
+	if projectID.Valid {
+		f.ProjectID = &projectID.Int64
+	}
+	if err := json.Unmarshal([]byte(definition), &f.Definition); err != nil {
+		return models.SavedFilter{}, fmt.Errorf("decode filter %d: %w", f.ID, err)
+	}
+	return f, nil
+}
+
+// ListSavedFilters returns global filters plus, when projectID is given, the
+// filters scoped to that project.
+func (s *Store) ListSavedFilters(ctx context.Context, projectID *int64) ([]models.SavedFilter, error) {
+	ctx, span := tracer.Start(ctx, "store.ListSavedFilters")
+	defer span.End()
+	query := `SELECT ` + savedFilterColumns + ` FROM saved_filters WHERE project_id IS NULL`
+	var args []any
+	if projectID != nil {
+		query += ` OR project_id = ?`
+		args = append(args, *projectID)
+	}
+	query += ` ORDER BY name, id`
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list filters: %w", err)
+	}
+	defer rows.Close()
+
+	filters := []models.SavedFilter{}
+	for rows.Next() {
+		f, err := scanSavedFilter(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan filter: %w", err)
+		}
+		filters = append(filters, f)
+	}
+	return filters, rows.Err()
+}
+
+// GetSavedFilter fetches a single saved filter by id.
+func (s *Store) GetSavedFilter(ctx context.Context, id int64) (models.SavedFilter, error) {
+	ctx, span := tracer.Start(ctx, "store.GetSavedFilter")
+	defer span.End()
+	f, err := scanSavedFilter(s.db.QueryRowContext(ctx, `SELECT `+savedFilterColumns+` FROM saved_filters WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.SavedFilter{}, fmt.Errorf("filter not found")
+	}
+	if err != nil {
+		return models.SavedFilter{}, fmt.Errorf("get filter: %w", err)
+	}
+	return f, nil
+}
+
+// CreateSavedFilter validates and stores a new filter.
+func (s *Store) CreateSavedFilter(ctx context.Context, f models.SavedFilter) (models.SavedFilter, error) {
+	ctx, span := tracer.Start(ctx, "store.CreateSavedFilter")
+	defer span.End()
+	definition, err := s.normalizeSavedFilter(ctx, &f)
+	if err != nil {
+		return models.SavedFilter{}, err
+	}
+
+	var id int64
+	if err := s.db.QueryRowContext(ctx, `INSERT INTO saved_filters(project_id, name, definition) VALUES(?, ?, ?) RETURNING id`, f.ProjectID, f.Name, definition).Scan(&id); err != nil {
+		return models.SavedFilter{}, fmt.Errorf("insert filter: %w", err)
+	}
+	return s.GetSavedFilter(ctx, id)
+}
+
+// UpdateSavedFilter replaces the scope, name and definition of a filter.
+func (s *Store) UpdateSavedFilter(ctx context.Context, id int64, f models.SavedFilter) (models.SavedFilter, error) {
+	ctx, span := tracer.Start(ctx, "store.UpdateSavedFilter")
+	defer span.End()
+	if _, err := s.GetSavedFilter(ctx, id); err != nil {
+		return models.SavedFilter{}, err
+	}
+	definition, err := s.normalizeSavedFilter(ctx, &f)
+	if err != nil {
+		return models.SavedFilter{}, err
+	}
+
+	if _, err := s.db.ExecContext(ctx, `UPDATE saved_filters SET project_id = ?, name = ?, definition = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`, f.ProjectID, f.Name, definition, id); err != nil {
+		return models.SavedFilter{}, fmt.Errorf("update filter: %w", err)
+	}
+	return s.GetSavedFilter(ctx, id)
+}
+
+// DeleteSavedFilter removes a saved filter.
+func (s *Store) DeleteSavedFilter(ctx context.Context, id int64) error {
+	ctx, span := tracer.Start(ctx, "store.DeleteSavedFilter")
+	defer span.End()
+	res, err := s.db.ExecContext(ctx, `DELETE FROM saved_filters WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("delete filter: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("filter not found")
+	}
+	return nil
+}
+
+// ListSavedFilterTasks runs a saved filter against the tasks of a project.
+// Filters scoped to a project only run there.
+func (s *Store) ListSavedFilterTasks(ctx context.Context, filterID, projectID int64) ([]models.Task, error) {
+	ctx, span := tracer.Start(ctx, "store.ListSavedFilterTasks")
+	defer span.End()
+	f, err := s.GetSavedFilter(ctx, filterID)
+	if err != nil {
+		return nil, err
+	}
+	if f.ProjectID != nil && *f.ProjectID != projectID {
+		return nil, fmt.Errorf("%w: filter belongs to another project", ErrValidation)
+	}
+	if _, err := s.GetProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+	return s.ListTasksFiltered(ctx, projectID, f.Definition.TaskFilter())
+}
+
+// normalizeSavedFilter checks everything ListTasksFiltered would reject, so a
+// stored filter always runs, and returns the definition encoded for storage.
+// Labels and sprints must exist and, for scoped filters, belong to the
+// project.
+func (s *Store) normalizeSavedFilter(ctx context.Context, f *models.SavedFilter) (string, error) {
+	f.Name = strings.TrimSpace(f.Name)
+	if f.Name == "" {
+		return "", fmt.Errorf("%w: filter name must not be empty", ErrValidation)
+	}
+	if f.ProjectID != nil {
+		if _, err := s.GetProject(ctx, *f.ProjectID); err != nil {
+			return "", err
+		}
+	}
+
+	d := &f.Definition
+	for _, status := range d.Statuses {
+		var err error
+		if f.ProjectID != nil {
+			_, err = lookupStatus(ctx, s.db, *f.ProjectID, status)
+		} else {
+			err = s.validateAnyStatus(ctx, status)
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	if d.Assignee != nil {
+		assignee := strings.TrimSpace(*d.Assignee)
+		if err := validateAssignee(assignee); err != nil {
+			return "", err
+		}
+		d.Assignee = &assignee
+	}
+	for _, id := range d.LabelIDs {
+		label, err := s.GetLabel(ctx, id)
+		if err != nil {
+			return "", fmt.Errorf("%w: label %d not found", ErrValidation, id)
+		}
+		if f.ProjectID != nil && label.ProjectID != *f.ProjectID {
+			return "", fmt.Errorf("%w: label %d belongs to another project", ErrValidation, id)
+		}
+	}
+	if d.SprintID != nil && *d.SprintID != 0 {
+		sprint, err := s.GetSprint(ctx, *d.SprintID)
+		if err != nil {
+			return "", fmt.Errorf("%w: sprint %d not found", ErrValidation, *d.SprintID)
+		}
+		if f.ProjectID != nil && sprint.ProjectID != *f.ProjectID {
+			return "", fmt.Errorf("%w: sprint %d belongs to another project", ErrValidation, *d.SprintID)
+		}
+	}
+	if _, err := taskOrderBy(d.Sort); err != nil {
+		return "", err
+	}
+
+	encoded, err := json.Marshal(d)
+	if err != nil {
+		return "", fmt.Errorf("encode filter: %w", err)
+	}
+	return string(encoded), nil
+}
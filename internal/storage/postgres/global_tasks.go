@@ -0,0 +1,120 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"todo/internal/models"
+)
+
+// MaxGlobalTaskPage caps how many tasks one page of ListAllTasks returns.
+const MaxGlobalTaskPage = 200
+
+// ListAllTasks returns live tasks of every live project, most recently
+// updated first, with the project name and color joined in. It also returns
+// the number of tasks matching the filter across all pages.
+func (s *Store) ListAllTasks(ctx context.Context, filter models.GlobalTaskFilter) ([]models.ProjectTask, int, error) {
+	ctx, span := tracer.Start(ctx, "store.ListAllTasks")
+	defer span.End()
+	if filter.Limit < 1 || filter.Limit > MaxGlobalTaskPage {
+		return nil, 0, fmt.Errorf("%w: limit must be between 1 and %d", ErrValidation, MaxGlobalTaskPage)
+	}
+	if filter.Offset < 0 {
+		return nil, 0, fmt.Errorf("%w: offset must not be negative", ErrValidation)
+	}
+
+	where := `t.deleted_at IS NULL AND p.deleted_at IS NULL`
+	var args []any
+	if len(filter.Statuses) > 0 {
+		for _, status := range filter.Statuses {
+			if err := s.validateAnyStatus(ctx, status); err != nil {
+				return nil, 0, err
+			}
+			args = append(args, status)
+		}
+		where += ` AND t.status IN (` + placeholders(len(filter.Statuses)) + `)`
+	}
+	if filter.ProjectID != nil {
+		where += ` AND t.project_id = ?`
+		args = append(args, *filter.ProjectID)
+	}
+	if q := strings.TrimSpace(filter.Query); q != "" {
+		pattern := "%" + escapeLike(q) + "%"
+		where += ` AND (t.title ILIKE ? ESCAPE '\' OR t.description ILIKE ? ESCAPE '\')`
+		args = append(args, pattern, pattern)
+	}
+	if !filter.IncludeSnoozed {
+		where += ` AND (t.snoozed_until IS NULL OR t.snoozed_until <= CURRENT_TIMESTAMP)`
+	}
+	scope, scopeArgs := memberScope(ctx, "t.project_id")
+	where += scope
+	args = append(args, scopeArgs...)
+
+	var total int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM tasks t JOIN projects p ON p.id = t.project_id WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count tasks: %w", err)
+	}
+
+	tasks, err := s.queryProjectTasks(ctx, `WHERE `+where+`
+        ORDER BY t.updated_at DESC, t.id DESC
+        LIMIT ? OFFSET ?`, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return tasks, total, nil
+}
+
+// ListRecentTasks returns up to limit live tasks of live projects updated
+// after since, most recent first, with the project name and color joined in.
+func (s *Store) ListRecentTasks(ctx context.Context, since time.Time, limit int) ([]models.ProjectTask, error) {
+	ctx, span := tracer.Start(ctx, "store.ListRecentTasks")
+	defer span.End()
+	if limit < 1 || limit > MaxGlobalTaskPage {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrValidation, MaxGlobalTaskPage)
+	}
+	scope, scopeArgs := memberScope(ctx, "t.project_id")
+	args := append([]any{since.UTC().Format(timestampLayout)}, scopeArgs...)
+	return s.queryProjectTasks(ctx, `WHERE t.updated_at > ? AND t.deleted_at IS NULL AND p.deleted_at IS NULL`+scope+`
+        ORDER BY t.updated_at DESC, t.id DESC
+        LIMIT ?`, append(args, limit)...)
+}
+
+// queryProjectTasks runs a task query joined with its project, followed by
+// clause, and hydrates the results.
+func (s *Store) queryProjectTasks(ctx context.Context, clause string, args ...any) ([]models.ProjectTask, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+qualify("t", taskColumns)+`, p.name, p.color
+        FROM tasks t JOIN projects p ON p.id = t.project_id
+        `+clause, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list tasks: %w", err)
+	}
+	defer rows.Close()
+
+	results := []models.ProjectTask{}
+	for rows.Next() {
+		var name, color string
+		t, err := scanTask(rows, &name, &color)
+		if err != nil {
+			return nil, fmt.Errorf("scan task: %w", err)
+		}
+		results = append(results, models.ProjectTask{Task: t, ProjectName: name, ProjectColor: color})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	tasks := make([]models.Task, len(results))
+	for i := range results {
+		tasks[i] = results[i].Task
+	}
+	if err := s.hydrateTasks(ctx, tasks); err != nil {
+		return nil, err
+	}
+	for i := range results {
+		results[i].Task = tasks[i]
+	}
+	return results, nil
+}
@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ClaimIdempotencyKey reserves key for a request about to run. When the key
+// was claimed before within ttl it returns the stored response instead;
+// a status of 0 means that request has not finished yet. Older claims are
+// replaced.
+func (s *Store) ClaimIdempotencyKey(ctx context.Context, key string, ttl time.Duration) (claimed bool, status int, body string, err error) {
+	ctx, span := tracer.Start(ctx, "store.ClaimIdempotencyKey")
+	defer span.End()
+	cutoff := time.Now().UTC().Add(-ttl).Format(timestampLayout)
+	err = transaction(ctx, s.db, "claim idempotency key", func(tx *observedTx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE key = ? AND created_at < ?`, key, cutoff); err != nil {
+			return fmt.Errorf("claim idempotency key: %w", err)
+		}
+		res, err := tx.ExecContext(ctx, `INSERT INTO idempotency_keys(key) VALUES(?) ON CONFLICT DO NOTHING`, key)
+		if err != nil {
+			return fmt.Errorf("claim idempotency key: %w", err)
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if claimed = affected == 1; claimed {
+			return nil
+		}
+		if err := tx.QueryRowContext(ctx, `SELECT response_status, response_body FROM idempotency_keys WHERE key = ?`, key).Scan(&status, &body); err != nil {
+			return fmt.Errorf("get idempotency key: %w", err)
+		}
+		return nil
+	})
+	return claimed, status, body, err
+}
+
+// SaveIdempotentResponse stores the response of the request that claimed
+// key, for retries to replay.
+func (s *Store) SaveIdempotentResponse(ctx context.Context, key string, status int, body string) error {
+	ctx, span := tracer.Start(ctx, "store.SaveIdempotentResponse")
+	defer span.End()
+	if _, err := s.db.ExecContext(ctx, `UPDATE idempotency_keys SET response_status = ?, response_body = ? WHERE key = ?`, status, body, key); err != nil {
+		return fmt.Errorf("save idempotent response: %w", err)
+	}
+	return nil
+}
+
+// ReleaseIdempotencyKey drops a claim whose request produced no response
+// worth replaying, so a retry runs again.
+func (s *Store) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	ctx, span := tracer.Start(ctx, "store.ReleaseIdempotencyKey")
+	defer span.End()
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE key = ?`, key); err != nil {
+		return fmt.Errorf("release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// PruneIdempotencyKeys removes keys claimed longer ago than ttl and returns
+// how many were deleted.
+func (s *Store) PruneIdempotencyKeys(ctx context.Context, ttl time.Duration) (int64, error) {
+	ctx, span := tracer.Start(ctx, "store.PruneIdempotencyKeys")
+	defer span.End()
+	cutoff := time.Now().UTC().Add(-ttl).Format(timestampLayout)
+	res, err := s.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE created_at < ?`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("prune idempotency keys: %w", err)
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,47 @@
+package postgres
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"todo/internal/storage"
+)
+
+// Default text length limits, in characters, unless changed with
+// SetLengthLimits.
+const (
+	DefaultMaxTitleLength       = storage.DefaultMaxTitleLength
+	DefaultMaxDescriptionLength = storage.DefaultMaxDescriptionLength
+	DefaultMaxProjectNameLength = storage.DefaultMaxProjectNameLength
+)
+
+// SetLengthLimits changes the maximum length in characters of task titles,
+// task descriptions and project names; values below one keep the current
+// limit.
+func (s *Store) SetLengthLimits(title, description, projectName int) {
+	if title > 0 {
+		s.maxTitleLength = title
+	}
+	if description > 0 {
+		s.maxDescriptionLength = description
+	}
+	if projectName > 0 {
+		s.maxProjectNameLength = projectName
+	}
+}
+
+// validateLength rejects text longer than limit characters.
+func validateLength(field, text string, limit int) error {
+	if utf8.RuneCountInString(text) > limit {
+		return fmt.Errorf("%w: %s must be at most %d characters", ErrValidation, field, limit)
+	}
+	return nil
+}
+
+// validateTaskText checks a task title and description against the limits.
+func (s *Store) validateTaskText(title, description string) error {
+	if err := validateLength("task title", title, s.maxTitleLength); err != nil {
+		return err
+	}
+	return validateLength("task description", description, s.maxDescriptionLength)
+}
@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"todo/internal/models"
+)
+
+// DBStats returns the connection pool statistics of the underlying database.
+func (s *Store) DBStats() sql.DBStats {
+	return s.db.DB.Stats()
+}
+
+// DatabaseSize returns the disk space the database takes in bytes, as
+// reported by the server.
+func (s *Store) DatabaseSize(ctx context.Context) (int64, error) {
+	ctx, span := tracer.Start(ctx, "store.DatabaseSize")
+	defer span.End()
+	var size int64
+	if err := s.db.QueryRowContext(ctx, `SELECT pg_database_size(current_database())`).Scan(&size); err != nil {
+		return 0, fmt.Errorf("read database size: %w", err)
+	}
+	return size, nil
+}
+
+// GetAdminStats counts the live projects and tasks, the latter per status
+// across all projects, and reports the database size and schema version.
+func (s *Store) GetAdminStats(ctx context.Context) (models.AdminStats, error) {
+	ctx, span := tracer.Start(ctx, "store.GetAdminStats")
+	defer span.End()
+	stats := models.AdminStats{TasksByStatus: map[string]int64{}}
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM projects WHERE deleted_at IS NULL`).Scan(&stats.Projects); err != nil {
+		return models.AdminStats{}, fmt.Errorf("count projects: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT t.status, COUNT(*) FROM tasks t
+        JOIN projects p ON p.id = t.project_id AND p.deleted_at IS NULL
+        WHERE t.deleted_at IS NULL GROUP BY t.status`)
+	if err != nil {
+		return models.AdminStats{}, fmt.Errorf("count tasks: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			status string
+			count  int64
+		)
+		if err := rows.Scan(&status, &count); err != nil {
+			return models.AdminStats{}, fmt.Errorf("scan task count: %w", err)
+		}
+		stats.TasksByStatus[status] = count
+		stats.Tasks += count
+	}
+	if err := rows.Err(); err != nil {
+		return models.AdminStats{}, err
+	}
+
+	if stats.SchemaVersion, err = s.MigrationVersion(ctx); err != nil {
+		return models.AdminStats{}, err
+	}
+	if stats.DatabaseSizeBytes, err = s.DatabaseSize(ctx); err != nil {
+		return models.AdminStats{}, err
+	}
+	return stats, nil
+}
+
+// VacuumDB reclaims the space of dead rows and refreshes the query planner
+// statistics. VACUUM cannot run inside a transaction, so it goes straight to
+// the pool.
+func (s *Store) VacuumDB(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "store.VacuumDB")
+	defer span.End()
+	if _, err := s.db.ExecContext(ctx, `VACUUM (ANALYZE)`); err != nil {
+		return fmt.Errorf("vacuum database: %w", err)
+	}
+	return nil
+}
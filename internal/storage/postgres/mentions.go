@@ -0,0 +1,66 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"todo/internal/mention"
+	"todo/internal/models"
+)
+
+// syncMentions makes the stored mentions of a task match description, keeping
+// the rows of names that are still mentioned in any case.
+func syncMentions(ctx context.Context, tx *observedTx, taskID int64, description string) error {
+	names := mention.Parse(description)
+	args := make([]any, 0, len(names)+1)
+	args = append(args, taskID)
+	for _, name := range names {
+		args = append(args, strings.ToLower(name))
+	}
+	query := `DELETE FROM task_mentions WHERE task_id = ?`
+	if len(names) > 0 {
+		query += ` AND lower(name) NOT IN (` + placeholders(len(names)) + `)`
+	}
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("clear mentions: %w", err)
+	}
+	for _, name := range names {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO task_mentions(task_id, name) VALUES(?, ?) ON CONFLICT DO NOTHING`, taskID, name); err != nil {
+			return fmt.Errorf("save mention: %w", err)
+		}
+	}
+	return nil
+}
+
+// attachMentions fills the Mentions field of each task using a single query.
+func (s *Store) attachMentions(ctx context.Context, tasks []models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	index, args := taskIndex(tasks)
+	for i := range tasks {
+		tasks[i].Mentions = []string{}
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT task_id, name FROM task_mentions WHERE task_id IN (`+placeholders(len(args))+`) ORDER BY lower(name)`, args...)
+	if err != nil {
+		return fmt.Errorf("load task mentions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			taskID int64
+			name   string
+		)
+		if err := rows.Scan(&taskID, &name); err != nil {
+			return fmt.Errorf("scan task mention: %w", err)
+		}
+		if i, ok := index[taskID]; ok {
+			tasks[i].Mentions = append(tasks[i].Mentions, name)
+		}
+	}
+	return rows.Err()
+}
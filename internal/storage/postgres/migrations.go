@@ -0,0 +1,401 @@
+package postgres
+
+// Migration is one versioned schema change. Migrations run in order of
+// Version, each in its own transaction, and are recorded in
+// schema_migrations so they are applied only once. Released migrations must
+// never change; append new ones with the next version.
+type Migration struct {
+	Version int
+	Up      string
+}
+
+// migrations lists every schema change in the order it is applied. The
+// first versions create the schema the SQLite migrations arrived at, so
+// both backends store the same data the same way.
+var migrations = []Migration{
+	{1, `CREATE TABLE projects (
+            id BIGSERIAL PRIMARY KEY,
+            name TEXT NOT NULL,
+            color TEXT NOT NULL DEFAULT '#2563eb',
+            description TEXT NOT NULL DEFAULT '',
+            deadline TIMESTAMPTZ,
+            position BIGINT NOT NULL DEFAULT 0,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            deleted_at TIMESTAMPTZ
+        );
+        CREATE TABLE users (
+            id BIGSERIAL PRIMARY KEY,
+            username TEXT NOT NULL UNIQUE,
+            password_hash TEXT NOT NULL,
+            role TEXT NOT NULL DEFAULT 'member',
+            email TEXT,
+            last_login_at TIMESTAMPTZ,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+        );
+        CREATE TABLE settings (
+            key TEXT PRIMARY KEY,
+            value TEXT NOT NULL DEFAULT '',
+            updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+        );
+        CREATE TABLE project_members (
+            project_id BIGINT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+            user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+            role TEXT NOT NULL DEFAULT 'member',
+            created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            PRIMARY KEY(project_id, user_id)
+        );
+        CREATE TABLE statuses (
+            id BIGSERIAL PRIMARY KEY,
+            project_id BIGINT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+            name TEXT NOT NULL,
+            title TEXT NOT NULL DEFAULT '',
+            color TEXT NOT NULL DEFAULT '',
+            display_order INTEGER NOT NULL DEFAULT 0,
+            is_terminal BOOLEAN NOT NULL DEFAULT FALSE,
+            wip_limit INTEGER NOT NULL DEFAULT 0,
+            wip_mode TEXT NOT NULL DEFAULT 'enforce',
+            created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            UNIQUE(project_id, name)
+        );
+        CREATE TABLE sprints (
+            id BIGSERIAL PRIMARY KEY,
+            project_id BIGINT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+            name TEXT NOT NULL,
+            goal TEXT NOT NULL DEFAULT '',
+            starts_at DATE,
+            ends_at DATE,
+            status TEXT NOT NULL DEFAULT 'planning',
+            planned_points INTEGER NOT NULL DEFAULT 0,
+            completed_points INTEGER NOT NULL DEFAULT 0,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+        );
+        CREATE TABLE milestones (
+            id BIGSERIAL PRIMARY KEY,
+            project_id BIGINT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+            title TEXT NOT NULL,
+            description TEXT NOT NULL DEFAULT '',
+            due_date DATE,
+            status TEXT NOT NULL DEFAULT 'open',
+            created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+        );
+        CREATE TABLE tasks (
+            id BIGSERIAL PRIMARY KEY,
+            project_id BIGINT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+            number BIGINT,
+            parent_id BIGINT REFERENCES tasks(id),
+            sprint_id BIGINT REFERENCES sprints(id) ON DELETE SET NULL,
+            milestone_id BIGINT REFERENCES milestones(id) ON DELETE SET NULL,
+            title TEXT NOT NULL,
+            description TEXT NOT NULL DEFAULT '',
+            status TEXT NOT NULL DEFAULT 'todo',
+            priority TEXT NOT NULL DEFAULT 'medium',
+            assignee TEXT NOT NULL DEFAULT '',
+            color TEXT NOT NULL DEFAULT '',
+            story_points INTEGER NOT NULL DEFAULT 0,
+            cover_url TEXT NOT NULL DEFAULT '',
+            due_date TIMESTAMPTZ,
+            snoozed_until TIMESTAMPTZ,
+            position BIGINT NOT NULL DEFAULT 0,
+            version INTEGER NOT NULL DEFAULT 1,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            completed_at TIMESTAMPTZ,
+            deleted_at TIMESTAMPTZ
+        );
+        CREATE TABLE labels (
+            id BIGSERIAL PRIMARY KEY,
+            project_id BIGINT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+            name TEXT NOT NULL,
+            color TEXT NOT NULL DEFAULT '#2563eb',
+            created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            UNIQUE(project_id, name)
+        );
+        CREATE TABLE task_labels (
+            task_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+            label_id BIGINT NOT NULL REFERENCES labels(id) ON DELETE CASCADE,
+            PRIMARY KEY(task_id, label_id)
+        );
+        CREATE TABLE comments (
+            id BIGSERIAL PRIMARY KEY,
+            task_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+            author TEXT NOT NULL DEFAULT '',
+            body TEXT NOT NULL,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+        );
+        CREATE TABLE checklist_items (
+            id BIGSERIAL PRIMARY KEY,
+            task_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+            text TEXT NOT NULL,
+            done BOOLEAN NOT NULL DEFAULT FALSE,
+            position BIGINT NOT NULL DEFAULT 0,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+        );
+        CREATE TABLE time_entries (
+            id BIGSERIAL PRIMARY KEY,
+            task_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+            started_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            ended_at TIMESTAMPTZ,
+            note TEXT NOT NULL DEFAULT ''
+        );
+        CREATE TABLE task_dependencies (
+            blocker_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+            blocked_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            PRIMARY KEY(blocker_id, blocked_id)
+        );
+        CREATE TABLE task_templates (
+            id BIGSERIAL PRIMARY KEY,
+            project_id BIGINT REFERENCES projects(id) ON DELETE CASCADE,
+            title TEXT NOT NULL,
+            description TEXT NOT NULL DEFAULT '',
+            status TEXT NOT NULL DEFAULT 'todo',
+            created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+        );
+        CREATE TABLE activity_log (
+            id BIGSERIAL PRIMARY KEY,
+            task_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+            field TEXT NOT NULL,
+            old_value TEXT NOT NULL DEFAULT '',
+            new_value TEXT NOT NULL DEFAULT '',
+            changed_by TEXT NOT NULL DEFAULT '',
+            changed_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+        );
+        CREATE TABLE task_watchers (
+            task_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+            name TEXT NOT NULL,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            PRIMARY KEY(task_id, name)
+        );
+        CREATE TABLE task_mentions (
+            task_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+            name TEXT NOT NULL,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+        );
+        CREATE TABLE task_fields (
+            task_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+            key TEXT NOT NULL,
+            value TEXT NOT NULL DEFAULT '',
+            PRIMARY KEY(task_id, key)
+        );
+        CREATE TABLE task_links (
+            task_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+            position INTEGER NOT NULL,
+            url TEXT NOT NULL,
+            title TEXT NOT NULL DEFAULT '',
+            PRIMARY KEY(task_id, position)
+        );
+        CREATE TABLE task_revisions (
+            id BIGSERIAL PRIMARY KEY,
+            task_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+            title TEXT NOT NULL,
+            description TEXT NOT NULL DEFAULT '',
+            created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+        );
+        CREATE TABLE task_reactions (
+            task_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+            emoji TEXT NOT NULL,
+            author TEXT NOT NULL,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            PRIMARY KEY(task_id, emoji, author)
+        );
+        -- Entries are stamped when written rather than when their
+        -- transaction began, which may be long before it got the write lock,
+        -- so that they stay in order.
+        CREATE TABLE task_status_log (
+            id BIGSERIAL PRIMARY KEY,
+            task_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+            status TEXT NOT NULL,
+            entered_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp()
+        );
+        CREATE TABLE webhooks (
+            id BIGSERIAL PRIMARY KEY,
+            project_id BIGINT REFERENCES projects(id) ON DELETE CASCADE,
+            url TEXT NOT NULL,
+            events TEXT NOT NULL DEFAULT '',
+            secret TEXT NOT NULL DEFAULT '',
+            active BOOLEAN NOT NULL DEFAULT TRUE,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+        );
+        CREATE TABLE webhook_deliveries (
+            id BIGSERIAL PRIMARY KEY,
+            webhook_id BIGINT NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+            event TEXT NOT NULL,
+            payload TEXT NOT NULL DEFAULT '',
+            status_code INTEGER NOT NULL DEFAULT 0,
+            error TEXT NOT NULL DEFAULT '',
+            retry_count INTEGER NOT NULL DEFAULT 0,
+            next_retry_at TIMESTAMPTZ,
+            delivered_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+        );
+        CREATE TABLE operations (
+            id BIGSERIAL PRIMARY KEY,
+            session TEXT NOT NULL DEFAULT '',
+            kind TEXT NOT NULL,
+            entity_id BIGINT NOT NULL,
+            data TEXT NOT NULL DEFAULT '',
+            created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+        );
+        CREATE TABLE api_keys (
+            id BIGSERIAL PRIMARY KEY,
+            user_id BIGINT REFERENCES users(id) ON DELETE CASCADE,
+            key_hash TEXT NOT NULL UNIQUE,
+            name TEXT NOT NULL,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            last_used_at TIMESTAMPTZ,
+            expires_at TIMESTAMPTZ
+        );
+        CREATE TABLE saved_filters (
+            id BIGSERIAL PRIMARY KEY,
+            project_id BIGINT REFERENCES projects(id) ON DELETE CASCADE,
+            name TEXT NOT NULL,
+            definition TEXT NOT NULL DEFAULT '{}',
+            created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+        );
+        -- project_events keeps task_id without a foreign key so events of
+        -- deleted tasks survive with the title they had.
+        CREATE TABLE project_events (
+            id BIGSERIAL PRIMARY KEY,
+            project_id BIGINT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+            type TEXT NOT NULL,
+            task_id BIGINT,
+            task_title TEXT NOT NULL DEFAULT '',
+            old_value TEXT NOT NULL DEFAULT '',
+            new_value TEXT NOT NULL DEFAULT '',
+            created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+        );
+        CREATE TABLE audit_log (
+            id BIGSERIAL PRIMARY KEY,
+            action TEXT NOT NULL,
+            entity_type TEXT NOT NULL DEFAULT '',
+            entity_id BIGINT,
+            actor_id BIGINT,
+            actor_ip TEXT NOT NULL DEFAULT '',
+            payload JSON,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+        );
+        CREATE TABLE idempotency_keys (
+            key TEXT PRIMARY KEY,
+            response_status INTEGER NOT NULL DEFAULT 0,
+            response_body TEXT NOT NULL DEFAULT '',
+            created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+        );`},
+	// Trashed projects give up their name, users without an email do not
+	// collide and mentions are unique whatever their case.
+	{2, `CREATE UNIQUE INDEX idx_projects_name ON projects(name) WHERE deleted_at IS NULL;
+        CREATE UNIQUE INDEX idx_users_email ON users(email) WHERE email IS NOT NULL;
+        CREATE UNIQUE INDEX idx_task_mentions_name ON task_mentions(task_id, lower(name));
+        CREATE UNIQUE INDEX idx_tasks_project_number ON tasks(project_id, number);
+        CREATE UNIQUE INDEX idx_time_entries_open ON time_entries(task_id) WHERE ended_at IS NULL;
+        CREATE INDEX idx_project_members_user ON project_members(user_id);
+        CREATE INDEX idx_tasks_project_status ON tasks(project_id, status);
+        CREATE INDEX idx_tasks_parent ON tasks(parent_id);
+        CREATE INDEX idx_tasks_sprint ON tasks(sprint_id);
+        CREATE INDEX idx_tasks_milestone ON tasks(milestone_id);
+        CREATE INDEX idx_tasks_updated ON tasks(updated_at);
+        CREATE INDEX idx_tasks_due_date ON tasks(due_date);
+        CREATE INDEX idx_task_labels_label ON task_labels(label_id);
+        CREATE INDEX idx_comments_task ON comments(task_id);
+        CREATE INDEX idx_checklist_items_task ON checklist_items(task_id, position);
+        CREATE INDEX idx_task_dependencies_blocked ON task_dependencies(blocked_id);
+        CREATE INDEX idx_activity_log_task ON activity_log(task_id, changed_at);
+        CREATE INDEX idx_task_revisions_task ON task_revisions(task_id, id);
+        CREATE INDEX idx_task_status_log_task ON task_status_log(task_id, id);
+        CREATE INDEX idx_webhook_deliveries_webhook ON webhook_deliveries(webhook_id, delivered_at);
+        CREATE INDEX idx_webhook_deliveries_pending ON webhook_deliveries(next_retry_at) WHERE next_retry_at IS NOT NULL;
+        CREATE INDEX idx_operations_session ON operations(session, id);
+        CREATE INDEX idx_project_events_project ON project_events(project_id, id);
+        CREATE INDEX idx_audit_log_entity ON audit_log(entity_type, entity_id);
+        CREATE INDEX idx_idempotency_keys_created ON idempotency_keys(created_at);`},
+	// Project search matches words of titles and descriptions as prefixes;
+	// see searchTasks. The expression must stay in step with taskDocument.
+	{3, `CREATE INDEX idx_tasks_search ON tasks USING GIN (to_tsvector('simple', title || ' ' || description));`},
+	// Every update of a project or task stamps updated_at; task versions
+	// change only with edits of the task itself, which bump them explicitly.
+	{4, `CREATE FUNCTION touch_updated_at() RETURNS trigger LANGUAGE plpgsql AS $$
+        BEGIN
+            NEW.updated_at := CURRENT_TIMESTAMP;
+            RETURN NEW;
+        END $$;
+        CREATE TRIGGER trg_projects_updated BEFORE UPDATE ON projects
+            FOR EACH ROW EXECUTE FUNCTION touch_updated_at();
+        CREATE TRIGGER trg_tasks_updated BEFORE UPDATE ON tasks
+            FOR EACH ROW EXECUTE FUNCTION touch_updated_at();`},
+	{5, `CREATE FUNCTION add_default_statuses() RETURNS trigger LANGUAGE plpgsql AS $$
+        BEGIN
+            INSERT INTO statuses(project_id, name, title, display_order, is_terminal) VALUES
+                (NEW.id, 'todo', 'To do', 0, FALSE),
+                (NEW.id, 'in_progress', 'In progress', 1, FALSE),
+                (NEW.id, 'done', 'Done', 2, TRUE);
+            RETURN NULL;
+        END $$;
+        CREATE TRIGGER trg_projects_default_statuses AFTER INSERT ON projects
+            FOR EACH ROW EXECUTE FUNCTION add_default_statuses();`},
+	// The project feed records task and project changes as they happen. A
+	// status rename updates the tasks before the status row, so NEW.status
+	// is not yet defined for the project and no move is recorded. Purging a
+	// trashed task was already reported when it was trashed, and tasks
+	// removed along with their project have no feed left to join.
+	{6, `CREATE FUNCTION record_task_event() RETURNS trigger LANGUAGE plpgsql AS $$
+        BEGIN
+            IF TG_OP = 'INSERT' THEN
+                INSERT INTO project_events(project_id, type, task_id, task_title, new_value)
+                VALUES (NEW.project_id, 'task.created', NEW.id, NEW.title, NEW.status);
+                RETURN NULL;
+            END IF;
+            IF TG_OP = 'DELETE' THEN
+                IF OLD.deleted_at IS NULL AND EXISTS (SELECT 1 FROM projects WHERE id = OLD.project_id) THEN
+                    INSERT INTO project_events(project_id, type, task_id, task_title)
+                    VALUES (OLD.project_id, 'task.deleted', OLD.id, OLD.title);
+                END IF;
+                RETURN NULL;
+            END IF;
+            IF OLD.title IS DISTINCT FROM NEW.title THEN
+                INSERT INTO project_events(project_id, type, task_id, task_title, old_value, new_value)
+                VALUES (NEW.project_id, 'task.renamed', NEW.id, NEW.title, OLD.title, NEW.title);
+            END IF;
+            IF OLD.status IS DISTINCT FROM NEW.status
+                AND EXISTS (SELECT 1 FROM statuses WHERE project_id = NEW.project_id AND name = NEW.status) THEN
+                INSERT INTO project_events(project_id, type, task_id, task_title, old_value, new_value)
+                VALUES (NEW.project_id, CASE WHEN EXISTS (SELECT 1 FROM statuses WHERE project_id = NEW.project_id AND name = NEW.status AND is_terminal)
+                    THEN 'task.completed' ELSE 'task.moved' END,
+                    NEW.id, NEW.title, OLD.status, NEW.status);
+            END IF;
+            IF (OLD.deleted_at IS NULL) <> (NEW.deleted_at IS NULL) THEN
+                INSERT INTO project_events(project_id, type, task_id, task_title)
+                VALUES (NEW.project_id, CASE WHEN NEW.deleted_at IS NULL THEN 'task.restored' ELSE 'task.deleted' END, NEW.id, NEW.title);
+            END IF;
+            RETURN NULL;
+        END $$;
+        CREATE TRIGGER trg_events_task AFTER INSERT OR UPDATE OF title, status, deleted_at OR DELETE ON tasks
+            FOR EACH ROW EXECUTE FUNCTION record_task_event();
+        CREATE FUNCTION record_project_event() RETURNS trigger LANGUAGE plpgsql AS $$
+        BEGIN
+            IF OLD.name IS DISTINCT FROM NEW.name THEN
+                INSERT INTO project_events(project_id, type, old_value, new_value)
+                VALUES (NEW.id, 'project.renamed', OLD.name, NEW.name);
+            END IF;
+            IF OLD.color IS DISTINCT FROM NEW.color THEN
+                INSERT INTO project_events(project_id, type, old_value, new_value)
+                VALUES (NEW.id, 'project.recolored', OLD.color, NEW.color);
+            END IF;
+            RETURN NULL;
+        END $$;
+        CREATE TRIGGER trg_events_project AFTER UPDATE OF name, color ON projects
+            FOR EACH ROW EXECUTE FUNCTION record_project_event();`},
+	// The audit log is append-only.
+	{7, `CREATE FUNCTION reject_audit_change() RETURNS trigger LANGUAGE plpgsql AS $$
+        BEGIN
+            RAISE EXCEPTION 'audit_log is append-only';
+        END $$;
+        CREATE TRIGGER trg_audit_log_append_only BEFORE UPDATE OR DELETE ON audit_log
+            FOR EACH ROW EXECUTE FUNCTION reject_audit_change();`},
+}
@@ -0,0 +1,180 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// QueryObserver is told how long each database query took, labelled with the
+// store function that issued it.
+type QueryObserver func(operation string, d time.Duration)
+
+// observedDB times queries made through the store's connection pool and
+// rewrites their ? placeholders into the $n PostgreSQL expects; see rebind.
+type observedDB struct {
+	*sql.DB
+	observe QueryObserver
+}
+
+// observedTx times queries made inside a transaction, including the commit.
+type observedTx struct {
+	*sql.Tx
+	observe QueryObserver
+}
+
+// SetQueryObserver registers fn to be called after every query the store
+// runs. It must be set before serving requests.
+func (s *Store) SetQueryObserver(fn QueryObserver) {
+	s.db.observe = fn
+}
+
+func (db *observedDB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	defer db.observe.since(time.Now())
+	return db.DB.QueryContext(ctx, rebind(query), args...)
+}
+
+func (db *observedDB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	defer db.observe.since(time.Now())
+	return db.DB.QueryRowContext(ctx, rebind(query), args...)
+}
+
+func (db *observedDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	defer db.observe.since(time.Now())
+	return db.DB.ExecContext(ctx, rebind(query), args...)
+}
+
+// BeginTx starts a transaction that holds the store's write lock until it
+// ends; see writeLock.
+func (db *observedDB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*observedTx, error) {
+	tx, err := db.DB.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, writeLock); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	return &observedTx{Tx: tx, observe: db.observe}, nil
+}
+
+func (tx *observedTx) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	defer tx.observe.since(time.Now())
+	return tx.Tx.QueryContext(ctx, rebind(query), args...)
+}
+
+func (tx *observedTx) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	defer tx.observe.since(time.Now())
+	return tx.Tx.QueryRowContext(ctx, rebind(query), args...)
+}
+
+func (tx *observedTx) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	defer tx.observe.since(time.Now())
+	return tx.Tx.ExecContext(ctx, rebind(query), args...)
+}
+
+func (tx *observedTx) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return tx.Tx.PrepareContext(ctx, rebind(query))
+}
+
+func (tx *observedTx) Commit() error {
+	defer tx.observe.since(time.Now())
+	return tx.Tx.Commit()
+}
+
+// writeLock is the advisory lock key every transaction takes first. The
+// store reads and then writes inside its transactions, numbering tasks,
+// placing cards and checking WIP limits, and relies on no other transaction
+// changing those rows in between, which the single SQLite writer guarantees
+// and READ COMMITTED does not. Holding one lock per database serializes the
+// transactions of every instance sharing it; statements outside a
+// transaction still run concurrently.
+const writeLock = 5_147_280
+
+// rebind numbers the ? placeholders of query as $1, $2 and so on, skipping
+// those inside quoted strings, quoted identifiers and comments, so that the
+// queries can be built the way the SQLite store builds them.
+func rebind(query string) string {
+	if !strings.Contains(query, "?") {
+		return query
+	}
+	var b strings.Builder
+	b.Grow(len(query) + 8)
+	n := 0
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(query[i+1:], c)
+			if end < 0 {
+				b.WriteString(query[i:])
+				return b.String()
+			}
+			b.WriteString(query[i : i+end+2])
+			i += end + 1
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				b.WriteString(query[i:])
+				return b.String()
+			}
+			b.WriteString(query[i : i+end+1])
+			i += end
+		case c == '?':
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// since reports the time elapsed from start under the name of the function
+// that called the wrapped query method.
+func (fn QueryObserver) since(start time.Time) {
+	if fn == nil {
+		return
+	}
+	d := time.Since(start)
+	// Skip since, the deferred wrapper method and land on its caller.
+	pc, _, _, ok := runtime.Caller(2)
+	if !ok {
+		fn("unknown", d)
+		return
+	}
+	fn(operationName(pc), d)
+}
+
+// operationNames caches the trimmed function name for each caller PC.
+var operationNames sync.Map
+
+// operationName turns a PC into a short label such as "CreateTask", folding
+// closures into their enclosing function.
+func operationName(pc uintptr) string {
+	if name, ok := operationNames.Load(pc); ok {
+		return name.(string)
+	}
+	name := "unknown"
+	if f := runtime.FuncForPC(pc); f != nil {
+		name = f.Name()
+		if i := strings.LastIndex(name, "/"); i >= 0 {
+			name = name[i+1:]
+		}
+		parts := strings.Split(name, ".")
+		// parts is [package, (receiver,) function, (closure...)].
+		for i := 1; i < len(parts); i++ {
+			if part := parts[i]; part != "" && !strings.HasPrefix(part, "(") && !strings.HasPrefix(part, "func") {
+				name = part
+				break
+			}
+		}
+	}
+	operationNames.Store(pc, name)
+	return name
+}
@@ -0,0 +1,158 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+
+	"todo/internal/models"
+)
+
+// Snippet markers chosen by SearchProjectTasks; they cannot appear in escaped
+// text and are replaced by <mark> tags after escaping.
+const (
+	snippetOpen  = "\x02"
+	snippetClose = "\x03"
+)
+
+// taskDocument is the text search document of a task t. It must stay the
+// expression idx_tasks_search indexes, or searches stop using the index.
+const taskDocument = `to_tsvector('simple', t.title || ' ' || t.description)`
+
+// MaxSearchLimit caps how many results of each kind a search returns.
+const MaxSearchLimit = 100
+
+// SearchProjectTasks finds live tasks of a project whose title or
+// description contains every word of query, best matches first.
+func (s *Store) SearchProjectTasks(ctx context.Context, projectID int64, query string) ([]models.TaskSearchResult, error) {
+	ctx, span := tracer.Start(ctx, "store.SearchProjectTasks")
+	defer span.End()
+	if _, err := s.GetProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+	return s.searchTasks(ctx, &projectID, query, MaxSearchLimit)
+}
+
+// SearchTasks finds up to limit live tasks whose title or description
+// contains every word of query, best matches first. When projectID is nil
+// every live project is searched.
+func (s *Store) SearchTasks(ctx context.Context, projectID *int64, query string, limit int) ([]models.TaskSearchResult, error) {
+	ctx, span := tracer.Start(ctx, "store.SearchTasks")
+	defer span.End()
+	return s.searchTasks(ctx, projectID, query, limit)
+}
+
+// SearchProjects finds up to limit live projects whose name contains every
+// word of query, names starting with the query first.
+func (s *Store) SearchProjects(ctx context.Context, query string, limit int) ([]models.Project, error) {
+	ctx, span := tracer.Start(ctx, "store.SearchProjects")
+	defer span.End()
+	terms, err := searchTerms(query, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	scope, args := memberScope(ctx, "id")
+	stmt := `SELECT ` + projectColumns + ` FROM projects WHERE deleted_at IS NULL` + scope
+	for _, term := range terms {
+		stmt += ` AND name ILIKE ? ESCAPE '\'`
+		args = append(args, "%"+escapeLike(term)+"%")
+	}
+	stmt += ` ORDER BY name ILIKE ? ESCAPE '\' DESC, lower(name), id LIMIT ?`
+	args = append(args, escapeLike(strings.TrimSpace(query))+"%", limit)
+
+	rows, err := s.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, fmt.Errorf("search projects: %w", err)
+	}
+	defer rows.Close()
+
+	projects := []models.Project{}
+	for rows.Next() {
+		p, err := scanProject(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan project: %w", err)
+		}
+		projects = append(projects, p)
+	}
+	return projects, rows.Err()
+}
+
+// searchTerms splits query into words and checks the result limit.
+func searchTerms(query string, limit int) ([]string, error) {
+	terms := strings.Fields(query)
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("%w: search query must not be empty", ErrValidation)
+	}
+	if limit < 1 || limit > MaxSearchLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrValidation, MaxSearchLimit)
+	}
+	return terms, nil
+}
+
+// searchTasks matches tasks against the text search index, each word as a
+// prefix. Results carry their project and an HTML-escaped snippet with the
+// matches wrapped in <mark>.
+func (s *Store) searchTasks(ctx context.Context, projectID *int64, query string, limit int) ([]models.TaskSearchResult, error) {
+	terms, err := searchTerms(query, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		quoted[i] = `'` + strings.NewReplacer(`\`, `\\`, `'`, `''`).Replace(term) + `':*`
+	}
+	stmt := `SELECT ` + qualify("t", taskColumns) + `, p.name, p.color,
+            ts_headline('simple', t.title || ' ' || t.description, q, 'StartSel=' || chr(2) || ', StopSel=' || chr(3) || ', MaxWords=16, MinWords=5')
+        FROM tasks t JOIN projects p ON p.id = t.project_id, to_tsquery('simple', ?) q
+        WHERE ` + taskDocument + ` @@ q AND t.deleted_at IS NULL AND p.deleted_at IS NULL`
+	args := []any{strings.Join(quoted, " & ")}
+	if projectID != nil {
+		stmt += ` AND t.project_id = ?`
+		args = append(args, *projectID)
+	}
+	scope, scopeArgs := memberScope(ctx, "t.project_id")
+	stmt += scope + ` ORDER BY ts_rank(` + taskDocument + `, q) DESC, t.id DESC LIMIT ?`
+	args = append(args, scopeArgs...)
+	args = append(args, limit)
+
+	rows, err := s.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, fmt.Errorf("search tasks: %w", err)
+	}
+	defer rows.Close()
+
+	results := []models.TaskSearchResult{}
+	for rows.Next() {
+		var r models.TaskSearchResult
+		r.Task, err = scanTask(rows, &r.ProjectName, &r.ProjectColor, &r.Snippet)
+		if err != nil {
+			return nil, fmt.Errorf("scan task: %w", err)
+		}
+		r.Snippet = highlight(r.Snippet)
+		results = append(results, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	tasks := make([]models.Task, len(results))
+	for i := range results {
+		tasks[i] = results[i].Task
+	}
+	if err := s.hydrateTasks(ctx, tasks); err != nil {
+		return nil, err
+	}
+	for i := range results {
+		results[i].Task = tasks[i]
+	}
+	return results, nil
+}
+
+// highlight escapes a snippet for HTML and turns its markers into <mark> tags.
+func highlight(snippet string) string {
+	return strings.NewReplacer(snippetOpen, "<mark>", snippetClose, "</mark>").Replace(html.EscapeString(snippet))
+}
@@ -0,0 +1,371 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"todo/internal/models"
+)
+
+const (
+	sprintColumns = `id, project_id, name, goal, starts_at, ends_at, status, created_at, updated_at`
+	dateLayout    = "2006-01-02"
+)
+
+func scanSprint(row rowScanner) (models.Sprint, error) {
+	var (
+		sp       models.Sprint
+		startsAt sql.NullTime
+		endsAt   sql.NullTime
+	)
+	if err := row.Scan(&sp.ID, &sp.ProjectID, &sp.Name, &sp.Goal, &startsAt, &endsAt, &sp.Status, &sp.CreatedAt, &sp.UpdatedAt); err != nil {
+		return models.Sprint{}, err
+	}
+	if startsAt.Valid {
+		sp.StartsAt = &startsAt.Time
+	}
+	if endsAt.Valid {
+		sp.EndsAt = &endsAt.Time
+	}
+	return sp, nil
+}
+
+// dateValue converts an optional date to the value stored in DATE columns.
+func dateValue(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.Format(dateLayout)
+}
+
+// ListSprints returns the sprints of a project, newest first.
+func (s *Store) ListSprints(ctx context.Context, projectID int64) ([]models.Sprint, error) {
+	ctx, span := tracer.Start(ctx, "store.ListSprints")
+	defer span.End()
+	if _, err := s.GetProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT `+sprintColumns+` FROM sprints WHERE project_id = ? ORDER BY COALESCE(starts_at, created_at) DESC, id DESC`, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("list sprints: %w", err)
+	}
+	defer rows.Close()
+
+	sprints := []models.Sprint{}
+	for rows.Next() {
+		sp, err := scanSprint(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan sprint: %w", err)
+		}
+		sprints = append(sprints, sp)
+	}
+	return sprints, rows.Err()
+}
+
+// GetSprint fetches a single sprint by id.
+func (s *Store) GetSprint(ctx context.Context, id int64) (models.Sprint, error) {
+	ctx, span := tracer.Start(ctx, "store.GetSprint")
+	defer span.End()
+	sp, err := scanSprint(s.db.QueryRowContext(ctx, `SELECT `+sprintColumns+` FROM sprints WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Sprint{}, fmt.Errorf("sprint not found")
+	}
+	if err != nil {
+		return models.Sprint{}, fmt.Errorf("get sprint: %w", err)
+	}
+	return sp, nil
+}
+
+// CreateSprint plans a new sprint for a project.
+func (s *Store) CreateSprint(ctx context.Context, sp models.Sprint) (models.Sprint, error) {
+	ctx, span := tracer.Start(ctx, "store.CreateSprint")
+	defer span.End()
+	if sp.Status == "" {
+		sp.Status = "planning"
+	}
+	if err := validateSprintFields(&sp); err != nil {
+		return models.Sprint{}, err
+	}
+	if sp.Status == "closed" {
+		return models.Sprint{}, fmt.Errorf("%w: new sprints cannot be closed", ErrValidation)
+	}
+	if _, err := s.GetProject(ctx, sp.ProjectID); err != nil {
+		return models.Sprint{}, err
+	}
+
+	var id int64
+	if err := s.db.QueryRowContext(ctx, `INSERT INTO sprints(project_id, name, goal, starts_at, ends_at, status) VALUES(?, ?, ?, ?, ?, ?) RETURNING id`,
+		sp.ProjectID, sp.Name, sp.Goal, dateValue(sp.StartsAt), dateValue(sp.EndsAt), sp.Status).Scan(&id); err != nil {
+		return models.Sprint{}, fmt.Errorf("insert sprint: %w", err)
+	}
+	return s.GetSprint(ctx, id)
+}
+
+// UpdateSprint replaces the editable fields of a sprint. Closing goes through
+// CloseSprint so unfinished tasks are returned to the backlog.
+func (s *Store) UpdateSprint(ctx context.Context, id int64, sp models.Sprint) (models.Sprint, error) {
+	ctx, span := tracer.Start(ctx, "store.UpdateSprint")
+	defer span.End()
+	current, err := s.GetSprint(ctx, id)
+	if err != nil {
+		return models.Sprint{}, err
+	}
+	if sp.Status == "" {
+		sp.Status = current.Status
+	}
+	if err := validateSprintFields(&sp); err != nil {
+		return models.Sprint{}, err
+	}
+	if sp.Status == "closed" && current.Status != "closed" {
+		return models.Sprint{}, fmt.Errorf("%w: use the close endpoint to close a sprint", ErrValidation)
+	}
+
+	if _, err := s.db.ExecContext(ctx, `UPDATE sprints SET name = ?, goal = ?, starts_at = ?, ends_at = ?, status = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
+		sp.Name, sp.Goal, dateValue(sp.StartsAt), dateValue(sp.EndsAt), sp.Status, id); err != nil {
+		return models.Sprint{}, fmt.Errorf("update sprint: %w", err)
+	}
+	return s.GetSprint(ctx, id)
+}
+
+// CloseSprint marks a sprint closed and moves its unfinished tasks back to
+// the backlog.
+func (s *Store) CloseSprint(ctx context.Context, id int64) (models.Sprint, error) {
+	ctx, span := tracer.Start(ctx, "store.CloseSprint")
+	defer span.End()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Sprint{}, fmt.Errorf("close sprint: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `UPDATE sprints SET status = 'closed', updated_at = CURRENT_TIMESTAMP WHERE id = ? AND status != 'closed'`, id)
+	if err != nil {
+		return models.Sprint{}, fmt.Errorf("close sprint: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return models.Sprint{}, err
+	}
+	if affected == 0 {
+		if _, err := s.GetSprint(ctx, id); err != nil {
+			return models.Sprint{}, err
+		}
+		return models.Sprint{}, fmt.Errorf("%w: sprint already closed", ErrConflict)
+	}
+	// Snapshot the velocity before unfinished tasks leave the sprint.
+	if _, err := tx.ExecContext(ctx, `UPDATE sprints SET
+            planned_points = (SELECT COALESCE(SUM(story_points), 0) FROM tasks WHERE sprint_id = ? AND deleted_at IS NULL),
+            completed_points = (SELECT COALESCE(SUM(story_points), 0) FROM tasks WHERE sprint_id = ? AND deleted_at IS NULL AND status = 'done')
+        WHERE id = ?`, id, id, id); err != nil {
+		return models.Sprint{}, fmt.Errorf("record sprint velocity: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE tasks SET sprint_id = NULL WHERE sprint_id = ? AND status != 'done'`, id); err != nil {
+		return models.Sprint{}, fmt.Errorf("release sprint tasks: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return models.Sprint{}, fmt.Errorf("close sprint: %w", err)
+	}
+	return s.GetSprint(ctx, id)
+}
+
+// DeleteSprint removes a sprint and returns its tasks to the backlog.
+func (s *Store) DeleteSprint(ctx context.Context, id int64) error {
+	ctx, span := tracer.Start(ctx, "store.DeleteSprint")
+	defer span.End()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("delete sprint: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `UPDATE tasks SET sprint_id = NULL WHERE sprint_id = ?`, id); err != nil {
+		return fmt.Errorf("release sprint tasks: %w", err)
+	}
+	res, err := tx.ExecContext(ctx, `DELETE FROM sprints WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("delete sprint: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("sprint not found")
+	}
+	return tx.Commit()
+}
+
+// GetSprintVelocity sums the story points of a sprint's tasks, split into
+// planned (all tasks) and completed (tasks in the done column). Closed sprints
+// report the totals recorded when they were closed.
+func (s *Store) GetSprintVelocity(ctx context.Context, id int64) (models.SprintVelocity, error) {
+	ctx, span := tracer.Start(ctx, "store.GetSprintVelocity")
+	defer span.End()
+	sp, err := s.GetSprint(ctx, id)
+	if err != nil {
+		return models.SprintVelocity{}, err
+	}
+	v := models.SprintVelocity{SprintID: sp.ID, Name: sp.Name}
+	if sp.Status == "closed" {
+		if err := s.db.QueryRowContext(ctx, `SELECT planned_points, completed_points FROM sprints WHERE id = ?`, id).Scan(&v.Planned, &v.Completed); err != nil {
+			return models.SprintVelocity{}, fmt.Errorf("sprint velocity: %w", err)
+		}
+		return v, nil
+	}
+	if err := s.db.QueryRowContext(ctx, `SELECT COALESCE(SUM(story_points), 0), COALESCE(SUM(CASE WHEN status = 'done' THEN story_points END), 0)
+        FROM tasks WHERE sprint_id = ? AND deleted_at IS NULL`, id).Scan(&v.Planned, &v.Completed); err != nil {
+		return models.SprintVelocity{}, fmt.Errorf("sprint velocity: %w", err)
+	}
+	return v, nil
+}
+
+// ListProjectVelocity returns the velocity of the last n closed sprints of a
+// project in chronological order, ready for charting.
+func (s *Store) ListProjectVelocity(ctx context.Context, projectID int64, n int) ([]models.SprintVelocity, error) {
+	ctx, span := tracer.Start(ctx, "store.ListProjectVelocity")
+	defer span.End()
+	if _, err := s.GetProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT id, name, planned_points, completed_points FROM sprints
+        WHERE project_id = ? AND status = 'closed'
+        ORDER BY COALESCE(ends_at, updated_at) DESC, id DESC
+        LIMIT ?`, projectID, n)
+	if err != nil {
+		return nil, fmt.Errorf("project velocity: %w", err)
+	}
+	defer rows.Close()
+
+	velocity := []models.SprintVelocity{}
+	for rows.Next() {
+		var v models.SprintVelocity
+		if err := rows.Scan(&v.SprintID, &v.Name, &v.Planned, &v.Completed); err != nil {
+			return nil, fmt.Errorf("scan velocity: %w", err)
+		}
+		velocity = append(velocity, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(velocity)-1; i < j; i, j = i+1, j-1 {
+		velocity[i], velocity[j] = velocity[j], velocity[i]
+	}
+	return velocity, nil
+}
+
+// GetSprintBurndown returns one point per elapsed sprint day with the story
+// points and tasks still open at the end of that day, based on the
+// completed_at timestamps of the sprint's tasks, next to an ideal line from
+// the total down to zero. Days after today are left out. Closing a sprint
+// returns its unfinished tasks to the backlog, so for closed sprints only the
+// points keep the planned total.
+func (s *Store) GetSprintBurndown(ctx context.Context, id int64) (models.SprintBurndown, error) {
+	ctx, span := tracer.Start(ctx, "store.GetSprintBurndown")
+	defer span.End()
+	velocity, err := s.GetSprintVelocity(ctx, id)
+	if err != nil {
+		return models.SprintBurndown{}, err
+	}
+	sp, err := s.GetSprint(ctx, id)
+	if err != nil {
+		return models.SprintBurndown{}, err
+	}
+	if sp.StartsAt == nil || sp.EndsAt == nil {
+		return models.SprintBurndown{}, fmt.Errorf("%w: sprint needs start and end dates for a burndown", ErrValidation)
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT story_points, completed_at FROM tasks
+        WHERE sprint_id = ? AND deleted_at IS NULL`, id)
+	if err != nil {
+		return models.SprintBurndown{}, fmt.Errorf("sprint burndown: %w", err)
+	}
+	defer rows.Close()
+
+	// Points and tasks completed per day; anything finished before the
+	// sprint started counts against the first day.
+	start := sp.StartsAt.UTC()
+	type dayTotal struct{ points, tasks int }
+	completed := map[string]dayTotal{}
+	tasks := 0
+	for rows.Next() {
+		var (
+			points int
+			at     *time.Time
+		)
+		if err := rows.Scan(&points, &at); err != nil {
+			return models.SprintBurndown{}, fmt.Errorf("scan burndown: %w", err)
+		}
+		tasks++
+		if at == nil {
+			continue
+		}
+		day := at.UTC()
+		if day.Before(start) {
+			day = start
+		}
+		total := completed[day.Format(dateLayout)]
+		total.points += points
+		total.tasks++
+		completed[day.Format(dateLayout)] = total
+	}
+	if err := rows.Err(); err != nil {
+		return models.SprintBurndown{}, err
+	}
+
+	days := int(sp.EndsAt.Sub(start).Hours()/24) + 1
+	today := time.Now().UTC().Format(dateLayout)
+	total := velocity.Planned
+	burndown := models.SprintBurndown{SprintID: id, StartsAt: *sp.StartsAt, EndsAt: *sp.EndsAt, Days: []models.BurndownPoint{}}
+	remainingPoints, remainingTasks := total, tasks
+	for i := 0; i < days; i++ {
+		date := start.AddDate(0, 0, i).Format(dateLayout)
+		if date > today {
+			break
+		}
+		remainingPoints -= completed[date].points
+		remainingTasks -= completed[date].tasks
+		p := models.BurndownPoint{Date: date, RemainingPoints: remainingPoints, RemainingTasks: remainingTasks, Ideal: float64(total)}
+		if days > 1 {
+			p.Ideal = math.Round(float64(total)*float64(days-1-i)/float64(days-1)*100) / 100
+		}
+		burndown.Days = append(burndown.Days, p)
+	}
+	return burndown, nil
+}
+
+// validateSprint checks that a task of projectID may be assigned to sprintID.
+func (s *Store) validateSprint(ctx context.Context, projectID, sprintID int64) error {
+	sp, err := s.GetSprint(ctx, sprintID)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrValidation, err)
+	}
+	if sp.ProjectID != projectID {
+		return fmt.Errorf("%w: sprint belongs to another project", ErrValidation)
+	}
+	if sp.Status == "closed" {
+		return fmt.Errorf("%w: sprint is closed", ErrValidation)
+	}
+	return nil
+}
+
+func validateSprintFields(sp *models.Sprint) error {
+	sp.Name = strings.TrimSpace(sp.Name)
+	sp.Goal = strings.TrimSpace(sp.Goal)
+	if sp.Name == "" {
+		return fmt.Errorf("sprint name must not be empty")
+	}
+	if _, ok := models.ValidSprintStatuses[sp.Status]; !ok {
+		return fmt.Errorf("%w: invalid sprint status %q", ErrValidation, sp.Status)
+	}
+	if sp.StartsAt != nil && sp.EndsAt != nil && sp.EndsAt.Before(*sp.StartsAt) {
+		return fmt.Errorf("%w: sprint must end after it starts", ErrValidation)
+	}
+	return nil
+}
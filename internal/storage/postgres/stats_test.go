@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"todo/internal/models"
+)
+
+func TestGetProjectStats(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	p, err := s.CreateProject(ctx, models.Project{Name: "P"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := s.CreateProject(ctx, models.Project{Name: "Other"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	create := func(projectID int64, title, status string) models.Task {
+		t.Helper()
+		task, err := s.CreateTask(ctx, models.Task{ProjectID: projectID, Title: title, Status: status})
+		if err != nil {
+			t.Fatalf("create %s: %v", title, err)
+		}
+		return task
+	}
+	backdate := func(task models.Task, column, modifier string) {
+		t.Helper()
+		if _, err := s.db.ExecContext(ctx, `UPDATE tasks SET `+column+` = now() + CAST(? AS interval) WHERE id = ?`, modifier, task.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	old := create(p.ID, "old", "todo")
+	backdate(old, "created_at", "-10 days")
+	create(p.ID, "new", "todo")
+	create(p.ID, "doing", "in_progress")
+	backdate(create(p.ID, "done lately", "done"), "completed_at", "-3 days")
+	long := create(p.ID, "done long ago", "done")
+	backdate(long, "created_at", "-30 days")
+	backdate(long, "completed_at", "-20 days")
+	trashed := create(p.ID, "trashed", "todo")
+	backdate(trashed, "created_at", "-40 days")
+	if err := s.DeleteTask(ctx, trashed.ID); err != nil {
+		t.Fatal(err)
+	}
+	create(other.ID, "elsewhere", "todo")
+
+	stats, err := s.GetProjectStats(ctx, p.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := models.ProjectStats{ProjectID: p.ID, ProjectName: "P", Todo: 2, InProgress: 1, Done: 2, Total: 5, CompletionPct: 40}
+	if stats.ProjectStats != want {
+		t.Errorf("counts = %+v, want %+v", stats.ProjectStats, want)
+	}
+	if stats.Open != 3 {
+		t.Errorf("open = %d, want 3", stats.Open)
+	}
+	if stats.CreatedLast7Days != 3 {
+		t.Errorf("created last 7 days = %d, want 3", stats.CreatedLast7Days)
+	}
+	if stats.CompletedLast7Days != 1 {
+		t.Errorf("completed last 7 days = %d, want 1", stats.CompletedLast7Days)
+	}
+	if stats.OldestOpenAgeDays == nil || math.Abs(*stats.OldestOpenAgeDays-10) > 0.01 {
+		t.Errorf("oldest open age = %v, want 10 days", stats.OldestOpenAgeDays)
+	}
+
+	empty, err := s.CreateProject(ctx, models.Project{Name: "Empty"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats, err = s.GetProjectStats(ctx, empty.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Total != 0 || stats.Open != 0 || stats.OldestOpenAgeDays != nil {
+		t.Errorf("empty project stats = %+v, want zero", stats)
+	}
+
+	if _, err := s.GetProjectStats(ctx, 999); err == nil {
+		t.Error("stats of unknown project: want error")
+	}
+}
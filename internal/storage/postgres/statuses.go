@@ -0,0 +1,389 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"todo/internal/models"
+)
+
+const statusColumns = `id, project_id, name, title, color, display_order, is_terminal, wip_limit, wip_mode, created_at, updated_at`
+
+// maxStatusNameLength bounds status names, which are stored on every task.
+const maxStatusNameLength = 50
+
+// defaultStatuses mirrors the statuses trg_projects_default_statuses gives
+// every new project.
+var defaultStatuses = []models.TaskStatus{
+	{Name: "todo", Title: "To do", DisplayOrder: 0},
+	{Name: "in_progress", Title: "In progress", DisplayOrder: 1},
+	{Name: "done", Title: "Done", DisplayOrder: 2, IsTerminal: true},
+}
+
+// queryer is satisfied by both the pool and a transaction, so status lookups
+// can run inside the transaction that writes the task.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func scanStatus(row rowScanner) (models.TaskStatus, error) {
+	var st models.TaskStatus
+	err := row.Scan(&st.ID, &st.ProjectID, &st.Name, &st.Title, &st.Color, &st.DisplayOrder, &st.IsTerminal, &st.WIPLimit, &st.WIPMode, &st.CreatedAt, &st.UpdatedAt)
+	return st, err
+}
+
+// projectStatuses returns the statuses of a project in display order.
+func projectStatuses(ctx context.Context, q queryer, projectID int64) ([]models.TaskStatus, error) {
+	rows, err := q.QueryContext(ctx, `SELECT `+statusColumns+` FROM statuses WHERE project_id = ? ORDER BY display_order, id`, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("list statuses: %w", err)
+	}
+	defer rows.Close()
+
+	statuses := []models.TaskStatus{}
+	for rows.Next() {
+		st, err := scanStatus(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan status: %w", err)
+		}
+		statuses = append(statuses, st)
+	}
+	return statuses, rows.Err()
+}
+
+// lookupStatus resolves a status name within a project. An empty name picks
+// the project's first status; an unknown one is a validation error listing
+// the valid names.
+func lookupStatus(ctx context.Context, q queryer, projectID int64, name string) (models.TaskStatus, error) {
+	statuses, err := projectStatuses(ctx, q, projectID)
+	if err != nil {
+		return models.TaskStatus{}, err
+	}
+	if len(statuses) == 0 {
+		return models.TaskStatus{}, fmt.Errorf("%w: project has no statuses", ErrValidation)
+	}
+	if name == "" {
+		return statuses[0], nil
+	}
+	names := make([]string, len(statuses))
+	for i, st := range statuses {
+		if st.Name == name {
+			return st, nil
+		}
+		names[i] = st.Name
+	}
+	return models.TaskStatus{}, fmt.Errorf("%w: invalid status %q; valid statuses are %s", ErrValidation, name, strings.Join(names, ", "))
+}
+
+// validateAnyStatus checks a status used outside a single project, such as a
+// global template or filter: it must be defined by some project or be one of
+// the default statuses.
+func (s *Store) validateAnyStatus(ctx context.Context, name string) error {
+	if _, ok := models.ValidTaskStatuses[name]; ok {
+		return nil
+	}
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM statuses WHERE name = ?)`, name).Scan(&exists); err != nil {
+		return fmt.Errorf("check status: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("%w: invalid status %q", ErrValidation, name)
+	}
+	return nil
+}
+
+// checkWIPLimit refuses to add a task to an enforcing status column that
+// already holds as many live tasks as its WIP limit allows.
+func checkWIPLimit(ctx context.Context, q queryer, st models.TaskStatus) error {
+	if st.WIPLimit <= 0 || st.WIPMode == models.WIPModeWarn {
+		return nil
+	}
+	var n int
+	if err := q.QueryRowContext(ctx, `SELECT COUNT(*) FROM tasks WHERE project_id = ? AND status = ? AND deleted_at IS NULL`, st.ProjectID, st.Name).Scan(&n); err != nil {
+		return fmt.Errorf("check wip limit: %w", err)
+	}
+	if n >= st.WIPLimit {
+		return fmt.Errorf("%w: WIP limit reached for status %s", ErrConflict, st.Name)
+	}
+	return nil
+}
+
+// normalizeStatus trims and checks the editable fields of a status. An empty
+// title falls back to the name.
+func normalizeStatus(st *models.TaskStatus) error {
+	st.Name = strings.TrimSpace(st.Name)
+	if err := validateStatusName(st.Name); err != nil {
+		return err
+	}
+	if st.Title = strings.TrimSpace(st.Title); st.Title == "" {
+		st.Title = st.Name
+	}
+	if len(st.Title) > maxStatusNameLength {
+		return fmt.Errorf("%w: status title must be at most %d characters", ErrValidation, maxStatusNameLength)
+	}
+	if st.Color = normalizeHexColor(strings.TrimSpace(st.Color)); st.Color != "" {
+		if err := validateHexColor(st.Color); err != nil {
+			return err
+		}
+	}
+	if st.WIPLimit < 0 {
+		return fmt.Errorf("%w: wip_limit must not be negative", ErrValidation)
+	}
+	switch st.WIPMode {
+	case "":
+		st.WIPMode = models.WIPModeEnforce
+	case models.WIPModeEnforce, models.WIPModeWarn:
+	default:
+		return fmt.Errorf("%w: wip_mode must be %s or %s", ErrValidation, models.WIPModeEnforce, models.WIPModeWarn)
+	}
+	return nil
+}
+
+func validateStatusName(name string) error {
+	if name == "" {
+		return fmt.Errorf("%w: status name must not be empty", ErrValidation)
+	}
+	if len(name) > maxStatusNameLength {
+		return fmt.Errorf("%w: status name must be at most %d characters", ErrValidation, maxStatusNameLength)
+	}
+	if strings.Contains(name, ",") {
+		return fmt.Errorf("%w: status name must not contain commas", ErrValidation)
+	}
+	return nil
+}
+
+// ListStatuses returns the statuses of a project in display order.
+func (s *Store) ListStatuses(ctx context.Context, projectID int64) ([]models.TaskStatus, error) {
+	ctx, span := tracer.Start(ctx, "store.ListStatuses")
+	defer span.End()
+	if _, err := s.GetProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+	return projectStatuses(ctx, s.db, projectID)
+}
+
+// GetStatus fetches a single status by id.
+func (s *Store) GetStatus(ctx context.Context, id int64) (models.TaskStatus, error) {
+	ctx, span := tracer.Start(ctx, "store.GetStatus")
+	defer span.End()
+	st, err := scanStatus(s.db.QueryRowContext(ctx, `SELECT `+statusColumns+` FROM statuses WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.TaskStatus{}, fmt.Errorf("status not found")
+	}
+	if err != nil {
+		return models.TaskStatus{}, fmt.Errorf("get status: %w", err)
+	}
+	return st, nil
+}
+
+// GetStatusByName fetches a status of a project by name.
+func (s *Store) GetStatusByName(ctx context.Context, projectID int64, name string) (models.TaskStatus, error) {
+	ctx, span := tracer.Start(ctx, "store.GetStatusByName")
+	defer span.End()
+	st, err := scanStatus(s.db.QueryRowContext(ctx, `SELECT `+statusColumns+` FROM statuses WHERE project_id = ? AND name = ?`, projectID, name))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.TaskStatus{}, fmt.Errorf("status not found")
+	}
+	if err != nil {
+		return models.TaskStatus{}, fmt.Errorf("get status: %w", err)
+	}
+	return st, nil
+}
+
+// CreateStatus adds a status column to a project.
+func (s *Store) CreateStatus(ctx context.Context, projectID int64, in StatusInput) (models.TaskStatus, error) {
+	ctx, span := tracer.Start(ctx, "store.CreateStatus")
+	defer span.End()
+	st := models.TaskStatus{Name: in.Name, Title: in.Title, Color: in.Color, IsTerminal: in.IsTerminal, WIPLimit: in.WIPLimit, WIPMode: in.WIPMode}
+	if err := normalizeStatus(&st); err != nil {
+		return models.TaskStatus{}, err
+	}
+	if _, err := s.GetProject(ctx, projectID); err != nil {
+		return models.TaskStatus{}, err
+	}
+
+	var id int64
+	err := transaction(ctx, s.db, "create status", func(tx *observedTx) error {
+		var exists bool
+		if err := tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM statuses WHERE project_id = ? AND name = ?)`, projectID, st.Name).Scan(&exists); err != nil {
+			return fmt.Errorf("create status: %w", err)
+		}
+		if exists {
+			return fmt.Errorf("%w: status %q already exists", ErrConflict, st.Name)
+		}
+		if in.DisplayOrder != nil {
+			st.DisplayOrder = *in.DisplayOrder
+		} else if err := tx.QueryRowContext(ctx, `SELECT COALESCE(MAX(display_order) + 1, 0) FROM statuses WHERE project_id = ?`, projectID).Scan(&st.DisplayOrder); err != nil {
+			return fmt.Errorf("create status: %w", err)
+		}
+		if err := tx.QueryRowContext(ctx, `INSERT INTO statuses(project_id, name, title, color, display_order, is_terminal, wip_limit, wip_mode) VALUES(?, ?, ?, ?, ?, ?, ?, ?) RETURNING id`,
+			projectID, st.Name, st.Title, st.Color, st.DisplayOrder, st.IsTerminal, st.WIPLimit, st.WIPMode).Scan(&id); err != nil {
+			return fmt.Errorf("insert status: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return models.TaskStatus{}, err
+	}
+	return s.GetStatus(ctx, id)
+}
+
+// UpdateStatus renames, retitles, recolors or reorders a status or changes
+// its terminal flag or WIP limit. Renaming moves the project's tasks, trashed
+// ones included, and their status history to the new name; a title that
+// showed the old name follows it. A limit below the column's current count
+// only blocks new tasks.
+func (s *Store) UpdateStatus(ctx context.Context, id int64, in StatusUpdate) (models.TaskStatus, error) {
+	ctx, span := tracer.Start(ctx, "store.UpdateStatus")
+	defer span.End()
+	current, err := s.GetStatus(ctx, id)
+	if err != nil {
+		return models.TaskStatus{}, err
+	}
+	updated := current
+	if in.Name != nil {
+		updated.Name = *in.Name
+		if in.Title == nil && current.Title == current.Name {
+			updated.Title = ""
+		}
+	}
+	if in.Title != nil {
+		updated.Title = *in.Title
+	}
+	if in.Color != nil {
+		updated.Color = *in.Color
+	}
+	if in.DisplayOrder != nil {
+		updated.DisplayOrder = *in.DisplayOrder
+	}
+	if in.IsTerminal != nil {
+		updated.IsTerminal = *in.IsTerminal
+	}
+	if in.WIPLimit != nil {
+		updated.WIPLimit = *in.WIPLimit
+	}
+	if in.WIPMode != nil {
+		updated.WIPMode = *in.WIPMode
+	}
+	if err := normalizeStatus(&updated); err != nil {
+		return models.TaskStatus{}, err
+	}
+
+	err = transaction(ctx, s.db, "update status", func(tx *observedTx) error {
+		if updated.Name != current.Name {
+			var exists bool
+			if err := tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM statuses WHERE project_id = ? AND name = ?)`, current.ProjectID, updated.Name).Scan(&exists); err != nil {
+				return fmt.Errorf("update status: %w", err)
+			}
+			if exists {
+				return fmt.Errorf("%w: status %q already exists", ErrConflict, updated.Name)
+			}
+			if _, err := tx.ExecContext(ctx, `UPDATE tasks SET status = ? WHERE project_id = ? AND status = ?`, updated.Name, current.ProjectID, current.Name); err != nil {
+				return fmt.Errorf("rename task statuses: %w", err)
+			}
+			if _, err := tx.ExecContext(ctx, `UPDATE task_status_log SET status = ? WHERE status = ? AND task_id IN (SELECT id FROM tasks WHERE project_id = ?)`, updated.Name, current.Name, current.ProjectID); err != nil {
+				return fmt.Errorf("rename status log: %w", err)
+			}
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE statuses SET name = ?, title = ?, color = ?, display_order = ?, is_terminal = ?, wip_limit = ?, wip_mode = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
+			updated.Name, updated.Title, updated.Color, updated.DisplayOrder, updated.IsTerminal, updated.WIPLimit, updated.WIPMode, id); err != nil {
+			return fmt.Errorf("update status: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return models.TaskStatus{}, err
+	}
+	return s.GetStatus(ctx, id)
+}
+
+// DeleteStatus removes a status. With an empty moveTo it is refused while any
+// task of the project, trashed ones included, still uses it; otherwise those
+// tasks first move to the end of the moveTo column, which must have room for
+// them under its WIP limit. The project's last status cannot be deleted.
+func (s *Store) DeleteStatus(ctx context.Context, id int64, moveTo string) error {
+	ctx, span := tracer.Start(ctx, "store.DeleteStatus")
+	defer span.End()
+	current, err := s.GetStatus(ctx, id)
+	if err != nil {
+		return err
+	}
+	return transaction(ctx, s.db, "delete status", func(tx *observedTx) error {
+		var inUse int
+		if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM tasks WHERE project_id = ? AND status = ?`, current.ProjectID, current.Name).Scan(&inUse); err != nil {
+			return fmt.Errorf("delete status: %w", err)
+		}
+		if inUse > 0 && moveTo == "" {
+			return fmt.Errorf("%w: status %q is used by %d tasks", ErrConflict, current.Name, inUse)
+		}
+		var remaining int
+		if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM statuses WHERE project_id = ?`, current.ProjectID).Scan(&remaining); err != nil {
+			return fmt.Errorf("delete status: %w", err)
+		}
+		if remaining <= 1 {
+			return fmt.Errorf("%w: a project needs at least one status", ErrConflict)
+		}
+		if inUse > 0 {
+			if err := moveStatusTasks(ctx, tx, current, moveTo); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM statuses WHERE id = ?`, id); err != nil {
+			return fmt.Errorf("delete status: %w", err)
+		}
+		return nil
+	})
+}
+
+// moveStatusTasks moves every task of a status to the end of the target
+// status of the same project, keeping their relative order.
+func moveStatusTasks(ctx context.Context, tx *observedTx, from models.TaskStatus, target string) error {
+	if target == from.Name {
+		return fmt.Errorf("%w: cannot move tasks to the status being deleted", ErrValidation)
+	}
+	to, err := lookupStatus(ctx, tx, from.ProjectID, target)
+	if err != nil {
+		return err
+	}
+	if to.WIPLimit > 0 && to.WIPMode != models.WIPModeWarn {
+		var live, moving int
+		if err := tx.QueryRowContext(ctx, `SELECT
+                COUNT(*) FILTER (WHERE status = ?), COUNT(*) FILTER (WHERE status = ?)
+            FROM tasks WHERE project_id = ? AND deleted_at IS NULL`, to.Name, from.Name, from.ProjectID).Scan(&live, &moving); err != nil {
+			return fmt.Errorf("check wip limit: %w", err)
+		}
+		if live+moving > to.WIPLimit {
+			return fmt.Errorf("%w: WIP limit reached for status %s", ErrConflict, to.Name)
+		}
+	}
+	var offset int
+	if err := tx.QueryRowContext(ctx, `SELECT COALESCE(MAX(position) + 1, 0) FROM tasks WHERE project_id = ? AND status = ?`, from.ProjectID, to.Name).Scan(&offset); err != nil {
+		return fmt.Errorf("move tasks: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE tasks SET status = ?, position = position + ?,
+            completed_at = `+completedAtExpr+`, updated_at = CURRENT_TIMESTAMP
+        WHERE project_id = ? AND status = ?`, to.Name, offset, to.Name, from.ProjectID, from.Name); err != nil {
+		return fmt.Errorf("move tasks: %w", err)
+	}
+	return nil
+}
+
+// WIPExceeded reports whether a status column of a project holds more live
+// tasks than its WIP limit allows, as warn mode columns may.
+func (s *Store) WIPExceeded(ctx context.Context, projectID int64, status string) (bool, error) {
+	ctx, span := tracer.Start(ctx, "store.WIPExceeded")
+	defer span.End()
+	st, err := s.GetStatusByName(ctx, projectID, status)
+	if err != nil || st.WIPLimit <= 0 {
+		return false, err
+	}
+	var n int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM tasks WHERE project_id = ? AND status = ? AND deleted_at IS NULL`, projectID, status).Scan(&n); err != nil {
+		return false, fmt.Errorf("count tasks: %w", err)
+	}
+	return n > st.WIPLimit, nil
+}
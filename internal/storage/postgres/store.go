@@ -0,0 +1,1765 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/lib/pq"
+
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+// The errors, inputs and limits shared by every backend are defined in
+// package storage; the names below keep them reachable from this one.
+var (
+	ErrValidation         = storage.ErrValidation
+	ErrConflict           = storage.ErrConflict
+	ErrInvalidAPIKey      = storage.ErrInvalidAPIKey
+	ErrAPIKeyExpired      = storage.ErrAPIKeyExpired
+	ErrInvalidCredentials = storage.ErrInvalidCredentials
+	ErrUserNotFound       = storage.ErrUserNotFound
+	ErrSetupCompleted     = storage.ErrSetupCompleted
+)
+
+type (
+	PoolConfig       = storage.PoolConfig
+	SetupInput       = storage.SetupInput
+	StatusInput      = storage.StatusInput
+	StatusUpdate     = storage.StatusUpdate
+	UserUpdate       = storage.UserUpdate
+	DuplicateOptions = storage.DuplicateOptions
+)
+
+// timestampLayout formats the times bound as text, which the session time
+// zone of UTC reads the way they are meant; see connString.
+const timestampLayout = "2006-01-02 15:04:05"
+
+// Store wraps access to the PostgreSQL database and exposes high level
+// helpers.
+type Store struct {
+	db     *observedDB
+	logger *slog.Logger
+
+	// maxRevisions caps the title/description history kept per task.
+	maxRevisions int
+
+	// Text length limits in characters; see SetLengthLimits.
+	maxTitleLength       int
+	maxDescriptionLength int
+	maxProjectNameLength int
+
+	// listener is told about every emitted event; see SetEventListener.
+	listener func(event string, projectID int64, data any)
+
+	// Webhook delivery workers; see StartWebhookWorkers.
+	queue   chan int64
+	stop    chan struct{}
+	workers sync.WaitGroup
+}
+
+// DefaultPoolConfig returns a pool of ten connections that are never
+// recycled. Transactions still run one at a time; see writeLock.
+func DefaultPoolConfig() PoolConfig {
+	return PoolConfig{MaxOpenConns: 10, MaxIdleConns: 10}
+}
+
+// Open connects to the PostgreSQL database at dsn, a URL or a list of
+// key=value settings as lib/pq accepts them, with the given connection pool
+// and runs the required migrations.
+func Open(dsn string, logger *slog.Logger, pool PoolConfig) (*Store, error) {
+	if dsn == "" {
+		return nil, fmt.Errorf("empty database DSN")
+	}
+
+	if logger == nil {
+		logger = slog.New(slog.NewTextHandler(nil, nil))
+	}
+
+	if pool.MaxOpenConns < 1 {
+		return nil, fmt.Errorf("max open connections must be at least 1")
+	}
+	if pool.MaxIdleConns < 0 || pool.ConnMaxLifetime < 0 || pool.ConnMaxIdleTime < 0 {
+		return nil, fmt.Errorf("connection pool settings must not be negative")
+	}
+
+	conninfo, err := connString(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("parse postgres dsn: %w", err)
+	}
+	conn, err := sql.Open("postgres", conninfo)
+	if err != nil {
+		return nil, fmt.Errorf("open postgres: %w", err)
+	}
+	conn.SetMaxOpenConns(pool.MaxOpenConns)
+	conn.SetMaxIdleConns(pool.MaxIdleConns)
+	conn.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	conn.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
+
+	s := &Store{
+		db:                   &observedDB{DB: conn},
+		logger:               logger,
+		maxRevisions:         DefaultMaxRevisions,
+		maxTitleLength:       DefaultMaxTitleLength,
+		maxDescriptionLength: DefaultMaxDescriptionLength,
+		maxProjectNameLength: DefaultMaxProjectNameLength,
+	}
+	if err := s.migrate(); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// connString turns dsn into key=value settings and sets the session time
+// zone to UTC, overriding any the DSN gives. Timestamps are then scanned in
+// UTC, as the SQLite store returns them, and times bound as text without a
+// zone are read as UTC.
+func connString(dsn string) (string, error) {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		var err error
+		if dsn, err = pq.ParseURL(dsn); err != nil {
+			return "", err
+		}
+	}
+	return dsn + " timezone=UTC", nil
+}
+
+// Close releases the database resources.
+func (s *Store) Close() error {
+	if s.db == nil {
+		return nil
+	}
+	s.stopWebhookWorkers()
+	return s.db.Close()
+}
+
+// Ping checks that the database answers queries.
+func (s *Store) Ping(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "store.Ping")
+	defer span.End()
+	var one int
+	if err := s.db.QueryRowContext(ctx, `SELECT 1`).Scan(&one); err != nil {
+		return fmt.Errorf("ping database: %w", err)
+	}
+	return nil
+}
+
+// migrate applies the migrations not yet recorded in schema_migrations.
+// Each runs in a transaction holding the write lock and is skipped when
+// another instance sharing the database applied it first.
+func (s *Store) migrate() error {
+	ctx := context.Background()
+	err := transaction(ctx, s.db, "migration", func(tx *observedTx) error {
+		_, err := tx.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+            version INTEGER PRIMARY KEY,
+            applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+        )`)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+
+	for _, m := range migrations {
+		if err := s.applyMigration(ctx, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyMigration runs m and records its version in one transaction unless
+// the version is recorded already.
+func (s *Store) applyMigration(ctx context.Context, m Migration) error {
+	return transaction(ctx, s.db, fmt.Sprintf("migration %d", m.Version), func(tx *observedTx) error {
+		var applied bool
+		if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = ?)`, m.Version).Scan(&applied); err != nil {
+			return fmt.Errorf("migration %d: %w", m.Version, err)
+		}
+		if applied {
+			return nil
+		}
+		if _, err := tx.ExecContext(ctx, m.Up); err != nil {
+			return fmt.Errorf("migration %d failed: %w", m.Version, err)
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations(version) VALUES(?)`, m.Version); err != nil {
+			return fmt.Errorf("migration %d: %w", m.Version, err)
+		}
+		return nil
+	})
+}
+
+// MigrationVersion returns the highest applied migration version.
+func (s *Store) MigrationVersion(ctx context.Context) (int, error) {
+	ctx, span := tracer.Start(ctx, "store.MigrationVersion")
+	defer span.End()
+	var version int
+	if err := s.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("migration version: %w", err)
+	}
+	return version, nil
+}
+
+const projectColumns = `id, name, color, description, deadline, position, created_at, updated_at, deleted_at`
+
+// nextProjectPosition places a new project after all others.
+const nextProjectPosition = `(SELECT COALESCE(MAX(position), -1) + 1 FROM projects)`
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanProject reads the projectColumns of a row; extra receives any columns
+// selected after them.
+func scanProject(row rowScanner, extra ...any) (models.Project, error) {
+	var (
+		p         models.Project
+		deadline  sql.NullTime
+		deletedAt sql.NullTime
+	)
+	dest := []any{&p.ID, &p.Name, &p.Color, &p.Description, &deadline, &p.Position, &p.CreatedAt, &p.UpdatedAt, &deletedAt}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return models.Project{}, err
+	}
+	if deadline.Valid {
+		p.Deadline = &deadline.Time
+	}
+	if deletedAt.Valid {
+		p.DeletedAt = &deletedAt.Time
+	}
+	return p, nil
+}
+
+// ListProjects retrieves all projects in their manual order, then by
+// creation date.
+func (s *Store) ListProjects(ctx context.Context) ([]models.Project, error) {
+	ctx, span := tracer.Start(ctx, "store.ListProjects")
+	defer span.End()
+	scope, args := memberScope(ctx, "id")
+	rows, err := s.db.QueryContext(ctx, `SELECT `+projectColumns+` FROM projects WHERE deleted_at IS NULL`+scope+` ORDER BY position, created_at, id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list projects: %w", err)
+	}
+	defer rows.Close()
+
+	projects := []models.Project{}
+	for rows.Next() {
+		p, err := scanProject(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan project: %w", err)
+		}
+		projects = append(projects, p)
+	}
+	return projects, rows.Err()
+}
+
+// ListProjectsWithTaskCounts returns live projects with their live task
+// counts per status.
+func (s *Store) ListProjectsWithTaskCounts(ctx context.Context) ([]models.ProjectWithCounts, error) {
+	ctx, span := tracer.Start(ctx, "store.ListProjectsWithTaskCounts")
+	defer span.End()
+	scope, args := memberScope(ctx, "p.id")
+	rows, err := s.db.QueryContext(ctx, `SELECT `+qualify("p", projectColumns)+`,
+            COALESCE(SUM(CASE WHEN st.is_terminal THEN 1 ELSE 0 END), 0),
+            COUNT(t.id)
+        FROM projects p
+        LEFT JOIN tasks t ON t.project_id = p.id AND t.deleted_at IS NULL
+        LEFT JOIN statuses st ON st.project_id = t.project_id AND st.name = t.status
+        WHERE p.deleted_at IS NULL`+scope+`
+        GROUP BY p.id
+        ORDER BY p.position, p.created_at, p.id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list projects: %w", err)
+	}
+	defer rows.Close()
+
+	projects := []models.ProjectWithCounts{}
+	index := make(map[int64]int)
+	for rows.Next() {
+		pc := models.ProjectWithCounts{StatusCounts: map[string]int{}}
+		pc.Project, err = scanProject(rows, &pc.DoneCount, &pc.TotalCount)
+		if err != nil {
+			return nil, fmt.Errorf("scan project: %w", err)
+		}
+		index[pc.ID] = len(projects)
+		projects = append(projects, pc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list projects: %w", err)
+	}
+	rows.Close()
+
+	rows, err = s.db.QueryContext(ctx, `SELECT st.project_id, st.name, COUNT(t.id)
+        FROM statuses st
+        JOIN projects p ON p.id = st.project_id
+        LEFT JOIN tasks t ON t.project_id = st.project_id AND t.status = st.name AND t.deleted_at IS NULL
+        WHERE p.deleted_at IS NULL`+scope+`
+        GROUP BY st.id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("count tasks: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var projectID int64
+		var status string
+		var count int
+		if err := rows.Scan(&projectID, &status, &count); err != nil {
+			return nil, fmt.Errorf("scan task count: %w", err)
+		}
+		if i, ok := index[projectID]; ok {
+			projects[i].StatusCounts[status] = count
+		}
+	}
+	return projects, rows.Err()
+}
+
+// ValidateProject checks the name and color accepted by CreateProject and
+// UpdateProject. An empty color is allowed and later replaced by a palette
+// color; three digit shorthand is expanded before the check.
+func (s *Store) ValidateProject(name, color string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("%w: project name must not be empty", ErrValidation)
+	}
+	if err := validateLength("project name", strings.TrimSpace(name), s.maxProjectNameLength); err != nil {
+		return err
+	}
+	if color == "" {
+		return nil
+	}
+	return validateHexColor(normalizeHexColor(color))
+}
+
+// CreateProject persists a new project; an empty color picks one from the
+// palette.
+func (s *Store) CreateProject(ctx context.Context, p models.Project) (models.Project, error) {
+	ctx, span := tracer.Start(ctx, "store.CreateProject")
+	defer span.End()
+	if err := s.ValidateProject(p.Name, p.Color); err != nil {
+		return models.Project{}, err
+	}
+	p.Color = normalizeHexColor(p.Color)
+	if p.Color == "" {
+		p.Color = randomPaletteColor()
+	}
+	if err := validateDeadline(p.Deadline, nil); err != nil {
+		return models.Project{}, err
+	}
+	if err := checkProjectName(ctx, s.db, strings.TrimSpace(p.Name), 0); err != nil {
+		return models.Project{}, err
+	}
+
+	var id int64
+	err := s.db.QueryRowContext(ctx, `INSERT INTO projects(name, color, description, deadline, position) VALUES(?, ?, ?, ?, `+nextProjectPosition+`) RETURNING id`,
+		strings.TrimSpace(p.Name), p.Color, strings.TrimSpace(p.Description), dueDateValue(p.Deadline)).Scan(&id)
+	if err != nil {
+		return models.Project{}, fmt.Errorf("insert project: %w", err)
+	}
+	project, err := s.GetProject(ctx, id)
+	if err == nil {
+		s.emit(ctx, "project.created", project.ID, project)
+	}
+	return project, err
+}
+
+// GetProject fetches a single project by id.
+func (s *Store) GetProject(ctx context.Context, id int64) (models.Project, error) {
+	ctx, span := tracer.Start(ctx, "store.GetProject")
+	defer span.End()
+	p, err := scanProject(s.db.QueryRowContext(ctx, `SELECT `+projectColumns+` FROM projects WHERE id = ? AND deleted_at IS NULL`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Project{}, fmt.Errorf("project not found")
+	}
+	if err != nil {
+		return models.Project{}, fmt.Errorf("get project: %w", err)
+	}
+	return p, nil
+}
+
+// checkProjectName reports ErrConflict when a live project other than id is
+// named name. Trashed projects do not hold on to their names.
+func checkProjectName(ctx context.Context, q queryer, name string, id int64) error {
+	var taken bool
+	if err := q.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM projects WHERE name = ? AND deleted_at IS NULL AND id != ?)`, name, id).Scan(&taken); err != nil {
+		return fmt.Errorf("check project name: %w", err)
+	}
+	if taken {
+		return fmt.Errorf("%w: a project named %q already exists", ErrConflict, name)
+	}
+	return nil
+}
+
+// FindProjectByName looks up a live project by name, ignoring case.
+// The oldest project wins when several share a name.
+func (s *Store) FindProjectByName(ctx context.Context, name string) (models.Project, error) {
+	ctx, span := tracer.Start(ctx, "store.FindProjectByName")
+	defer span.End()
+	p, err := scanProject(s.db.QueryRowContext(ctx, `SELECT `+projectColumns+` FROM projects
+        WHERE lower(name) = lower(?) AND deleted_at IS NULL ORDER BY id LIMIT 1`, strings.TrimSpace(name)))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Project{}, fmt.Errorf("project %q not found", name)
+	}
+	if err != nil {
+		return models.Project{}, fmt.Errorf("find project: %w", err)
+	}
+	return p, nil
+}
+
+// UpdateProject replaces the name, color, description and deadline of a
+// project; an empty color picks one from the palette and a nil deadline
+// clears it.
+func (s *Store) UpdateProject(ctx context.Context, id int64, p models.Project) (models.Project, error) {
+	ctx, span := tracer.Start(ctx, "store.UpdateProject")
+	defer span.End()
+	if err := s.ValidateProject(p.Name, p.Color); err != nil {
+		return models.Project{}, err
+	}
+	name := strings.TrimSpace(p.Name)
+	color := normalizeHexColor(p.Color)
+	if color == "" {
+		color = randomPaletteColor()
+	}
+	description := strings.TrimSpace(p.Description)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Project{}, fmt.Errorf("update project: %w", err)
+	}
+	defer tx.Rollback()
+
+	var (
+		previous projectState
+		deadline sql.NullTime
+	)
+	err = tx.QueryRowContext(ctx, `SELECT name, color, description, deadline FROM projects WHERE id = ? AND deleted_at IS NULL`, id).
+		Scan(&previous.Name, &previous.Color, &previous.Description, &deadline)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Project{}, fmt.Errorf("project not found")
+	}
+	if err != nil {
+		return models.Project{}, fmt.Errorf("update project: %w", err)
+	}
+	if deadline.Valid {
+		previous.Deadline = &deadline.Time
+	}
+	if err := validateDeadline(p.Deadline, previous.Deadline); err != nil {
+		return models.Project{}, err
+	}
+	if err := checkProjectName(ctx, tx, name, id); err != nil {
+		return models.Project{}, err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE projects SET name = ?, color = ?, description = ?, deadline = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
+		name, color, description, dueDateValue(p.Deadline), id); err != nil {
+		return models.Project{}, fmt.Errorf("update project: %w", err)
+	}
+	if previous.Name != name || previous.Color != color || previous.Description != description || !sameTime(previous.Deadline, p.Deadline) {
+		if err := recordOperation(ctx, tx, opProjectUpdate, id, previous); err != nil {
+			return models.Project{}, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return models.Project{}, fmt.Errorf("update project: %w", err)
+	}
+	project, err := s.GetProject(ctx, id)
+	if err == nil {
+		s.emit(ctx, "project.updated", project.ID, project)
+	}
+	return project, err
+}
+
+// validateDeadline requires a new project deadline to lie in the future. The
+// current deadline may be kept even once it has passed.
+func validateDeadline(deadline, current *time.Time) error {
+	if deadline == nil || sameTime(deadline, current) {
+		return nil
+	}
+	if !deadline.After(time.Now()) {
+		return fmt.Errorf("%w: deadline must be in the future", ErrValidation)
+	}
+	return nil
+}
+
+// sameTime reports whether a and b are both nil or the same instant at the
+// second precision the database stores.
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Truncate(time.Second).Equal(b.Truncate(time.Second))
+}
+
+// ReorderProjects puts the given live projects first, in the given order,
+// followed by the remaining projects in their current order. Unknown and
+// repeated ids are rejected. Under storage.WithProjectMember only the member's
+// projects can be named, and they are rearranged among the places they
+// already hold, leaving the other projects where they are.
+func (s *Store) ReorderProjects(ctx context.Context, ids []int64) ([]models.Project, error) {
+	ctx, span := tracer.Start(ctx, "store.ReorderProjects")
+	defer span.End()
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w: ids must not be empty", ErrValidation)
+	}
+
+	err := transaction(ctx, s.db, "reorder projects", func(tx *observedTx) error {
+		current, err := projectOrder(ctx, tx, "", nil)
+		if err != nil {
+			return err
+		}
+		scope, scopeArgs := memberScope(ctx, "id")
+		visible, err := projectOrder(ctx, tx, scope, scopeArgs)
+		if err != nil {
+			return err
+		}
+
+		placed := make(map[int64]bool, len(visible))
+		for _, id := range visible {
+			placed[id] = false
+		}
+		moved := make([]int64, 0, len(visible))
+		for _, id := range ids {
+			seen, ok := placed[id]
+			if !ok {
+				return fmt.Errorf("%w: project %d not found", ErrValidation, id)
+			}
+			if seen {
+				return fmt.Errorf("%w: project %d appears more than once", ErrValidation, id)
+			}
+			placed[id] = true
+			moved = append(moved, id)
+		}
+		for _, id := range visible {
+			if !placed[id] {
+				moved = append(moved, id)
+			}
+		}
+
+		// Hidden projects keep their places; the visible ones fill theirs in
+		// the new order.
+		next := 0
+		for i, id := range current {
+			if _, ok := placed[id]; ok {
+				id = moved[next]
+				next++
+			}
+			if _, err := tx.ExecContext(ctx, `UPDATE projects SET position = ? WHERE id = ?`, i, id); err != nil {
+				return fmt.Errorf("reorder projects: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.ListProjects(ctx)
+}
+
+// projectOrder returns the ids of the live projects matching scope in their
+// manual order.
+func projectOrder(ctx context.Context, q queryer, scope string, args []any) ([]int64, error) {
+	rows, err := q.QueryContext(ctx, `SELECT id FROM projects WHERE deleted_at IS NULL`+scope+` ORDER BY position, created_at, id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("reorder projects: %w", err)
+	}
+	defer rows.Close()
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan project: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// ListProjectsDueSoon returns live projects whose deadline falls between now
+// and now plus within, soonest first.
+func (s *Store) ListProjectsDueSoon(ctx context.Context, within time.Duration) ([]models.Project, error) {
+	ctx, span := tracer.Start(ctx, "store.ListProjectsDueSoon")
+	defer span.End()
+	now := time.Now()
+	until := now.Add(within)
+	scope, scopeArgs := memberScope(ctx, "id")
+	rows, err := s.db.QueryContext(ctx, `SELECT `+projectColumns+` FROM projects
+        WHERE deleted_at IS NULL AND deadline >= ? AND deadline <= ?`+scope+`
+        ORDER BY deadline, id`, append([]any{dueDateValue(&now), dueDateValue(&until)}, scopeArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("list projects due soon: %w", err)
+	}
+	defer rows.Close()
+
+	projects := []models.Project{}
+	for rows.Next() {
+		p, err := scanProject(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan project: %w", err)
+		}
+		projects = append(projects, p)
+	}
+	return projects, rows.Err()
+}
+
+// DeleteProject moves a project along with its tasks to the trash.
+func (s *Store) DeleteProject(ctx context.Context, id int64) error {
+	ctx, span := tracer.Start(ctx, "store.DeleteProject")
+	defer span.End()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("delete project: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	res, err := tx.ExecContext(ctx, `UPDATE projects SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`, now, id)
+	if err != nil {
+		return fmt.Errorf("delete project: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("project not found")
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE tasks SET deleted_at = ? WHERE project_id = ? AND deleted_at IS NULL`, now, id); err != nil {
+		return fmt.Errorf("delete project tasks: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("delete project: %w", err)
+	}
+	s.emit(ctx, "project.deleted", id, map[string]int64{"id": id})
+	return nil
+}
+
+const taskColumns = `id, project_id, number, parent_id, sprint_id, milestone_id, title, description, status, priority, assignee, color, story_points, cover_url, due_date, snoozed_until, position, created_at, updated_at, completed_at, deleted_at, version`
+
+// completedAtExpr keeps completed_at in sync with the status bound to its
+// placeholder: stamped when entering a terminal status of the task's project,
+// kept while in one, cleared otherwise.
+const completedAtExpr = `CASE WHEN EXISTS (SELECT 1 FROM statuses WHERE project_id = tasks.project_id AND name = ? AND is_terminal)
+    THEN COALESCE(completed_at, CURRENT_TIMESTAMP) END`
+
+// scanTask reads the taskColumns of a row; extra receives any columns
+// selected after them.
+func scanTask(row rowScanner, extra ...any) (models.Task, error) {
+	var (
+		t           models.Task
+		parentID    sql.NullInt64
+		sprintID    sql.NullInt64
+		milestoneID sql.NullInt64
+		dueDate     sql.NullTime
+		snoozed     sql.NullTime
+		completedAt sql.NullTime
+		deletedAt   sql.NullTime
+	)
+	dest := []any{&t.ID, &t.ProjectID, &t.Number, &parentID, &sprintID, &milestoneID, &t.Title, &t.Description, &t.Status, &t.Priority, &t.Assignee, &t.Color, &t.StoryPoints, &t.CoverURL, &dueDate, &snoozed, &t.Position, &t.CreatedAt, &t.UpdatedAt, &completedAt, &deletedAt, &t.Version}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return models.Task{}, err
+	}
+	if parentID.Valid {
+		t.ParentID = &parentID.Int64
+	}
+	if sprintID.Valid {
+		t.SprintID = &sprintID.Int64
+	}
+	if milestoneID.Valid {
+		t.MilestoneID = &milestoneID.Int64
+	}
+	if dueDate.Valid {
+		t.DueDate = &dueDate.Time
+	}
+	if snoozed.Valid {
+		t.SnoozedUntil = &snoozed.Time
+	}
+	if completedAt.Valid {
+		t.CompletedAt = &completedAt.Time
+	}
+	if deletedAt.Valid {
+		t.DeletedAt = &deletedAt.Time
+	}
+	return t, nil
+}
+
+func scanTasks(rows *sql.Rows) ([]models.Task, error) {
+	defer rows.Close()
+
+	var tasks []models.Task
+	for rows.Next() {
+		t, err := scanTask(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan task: %w", err)
+		}
+		tasks = append(tasks, t)
+	}
+	return tasks, rows.Err()
+}
+
+// hydrateTasks loads the related data shown on task cards, issuing one query
+// per relation regardless of the number of tasks.
+func (s *Store) hydrateTasks(ctx context.Context, tasks []models.Task) error {
+	if err := s.attachLabels(ctx, tasks); err != nil {
+		return err
+	}
+	if err := s.attachCommentCounts(ctx, tasks); err != nil {
+		return err
+	}
+	if err := s.attachChecklistCounts(ctx, tasks); err != nil {
+		return err
+	}
+	if err := s.attachTrackedMinutes(ctx, tasks); err != nil {
+		return err
+	}
+	if err := s.attachDependencies(ctx, tasks); err != nil {
+		return err
+	}
+	if err := s.attachWatchers(ctx, tasks); err != nil {
+		return err
+	}
+	if err := s.attachMentions(ctx, tasks); err != nil {
+		return err
+	}
+	if err := s.attachFields(ctx, tasks); err != nil {
+		return err
+	}
+	if err := s.attachLinks(ctx, tasks); err != nil {
+		return err
+	}
+	return s.attachReactions(ctx, tasks)
+}
+
+// taskIndex maps task ids to their slice position and returns the ids as
+// bind arguments.
+func taskIndex(tasks []models.Task) (map[int64]int, []any) {
+	index := make(map[int64]int, len(tasks))
+	args := make([]any, 0, len(tasks))
+	for i := range tasks {
+		index[tasks[i].ID] = i
+		args = append(args, tasks[i].ID)
+	}
+	return index, args
+}
+
+// ListTasks returns tasks for the given project in board order: by column
+// position, then position within the column.
+func (s *Store) ListTasks(ctx context.Context, projectID int64) ([]models.Task, error) {
+	ctx, span := tracer.Start(ctx, "store.ListTasks")
+	defer span.End()
+	return s.ListTasksFiltered(ctx, projectID, models.TaskFilter{})
+}
+
+// ListTasksByLabels returns the tasks of a project carrying all given labels.
+func (s *Store) ListTasksByLabels(ctx context.Context, projectID int64, labelIDs []int64) ([]models.Task, error) {
+	ctx, span := tracer.Start(ctx, "store.ListTasksByLabels")
+	defer span.End()
+	return s.ListTasksFiltered(ctx, projectID, models.TaskFilter{LabelIDs: labelIDs})
+}
+
+// ListTasksByAssignee returns the tasks of a project owned by assignee.
+func (s *Store) ListTasksByAssignee(ctx context.Context, projectID int64, assignee string) ([]models.Task, error) {
+	ctx, span := tracer.Start(ctx, "store.ListTasksByAssignee")
+	defer span.End()
+	return s.ListTasksFiltered(ctx, projectID, models.TaskFilter{Assignee: &assignee})
+}
+
+// ListTasksFiltered returns the tasks of a project matching every criterion
+// set in filter; the filtering happens in SQL.
+func (s *Store) ListTasksFiltered(ctx context.Context, projectID int64, filter models.TaskFilter) ([]models.Task, error) {
+	ctx, span := tracer.Start(ctx, "store.ListTasksFiltered")
+	defer span.End()
+	var (
+		clauses []string
+		args    []any
+	)
+	if filter.Assignee != nil {
+		assignee := strings.TrimSpace(*filter.Assignee)
+		if err := validateAssignee(assignee); err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, `assignee = ?`)
+		args = append(args, assignee)
+	}
+	if len(filter.Statuses) > 0 {
+		clauses = append(clauses, `status IN (`+placeholders(len(filter.Statuses))+`)`)
+		for _, status := range filter.Statuses {
+			if _, err := lookupStatus(ctx, s.db, projectID, status); err != nil {
+				return nil, err
+			}
+			args = append(args, status)
+		}
+	}
+	if len(filter.Priorities) > 0 {
+		clauses = append(clauses, `priority IN (`+placeholders(len(filter.Priorities))+`)`)
+		for _, priority := range filter.Priorities {
+			if err := validatePriority(priority); err != nil {
+				return nil, err
+			}
+			args = append(args, priority)
+		}
+	}
+	if len(filter.LabelIDs) > 0 {
+		clauses = append(clauses, `id IN (SELECT task_id FROM task_labels WHERE label_id IN (`+placeholders(len(filter.LabelIDs))+`)
+            GROUP BY task_id HAVING COUNT(DISTINCT label_id) = ?)`)
+		for _, id := range filter.LabelIDs {
+			args = append(args, id)
+		}
+		args = append(args, len(filter.LabelIDs))
+	}
+	if !filter.IncludeSnoozed {
+		clauses = append(clauses, `(snoozed_until IS NULL OR snoozed_until <= CURRENT_TIMESTAMP)`)
+	}
+	if filter.SprintID != nil {
+		if *filter.SprintID == 0 {
+			clauses = append(clauses, `sprint_id IS NULL`)
+		} else {
+			clauses = append(clauses, `sprint_id = ?`)
+			args = append(args, *filter.SprintID)
+		}
+	}
+	if filter.MilestoneID != nil {
+		if *filter.MilestoneID == 0 {
+			clauses = append(clauses, `milestone_id IS NULL`)
+		} else {
+			clauses = append(clauses, `milestone_id = ?`)
+			args = append(args, *filter.MilestoneID)
+		}
+	}
+
+	orderBy, err := taskOrderBy(filter.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	clause := ""
+	for _, c := range clauses {
+		clause += " AND " + c
+	}
+	return s.listProjectTasks(ctx, projectID, clause+" ORDER BY "+orderBy, args...)
+}
+
+// taskSortColumns whitelists the columns task lists may be sorted by.
+var taskSortColumns = map[string]string{
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+	"title":      "lower(title)",
+	"position":   "position",
+}
+
+// boardOrder sorts tasks by the position of their status column, then by
+// their position within it.
+const boardOrder = `(SELECT display_order FROM statuses WHERE project_id = tasks.project_id AND name = tasks.status), status, position, id`
+
+// taskOrderBy turns a sort key such as "-created_at" into an ORDER BY list.
+// An empty key keeps board order: by column position, then position.
+func taskOrderBy(key string) (string, error) {
+	if key == "" {
+		return boardOrder, nil
+	}
+	name, dir := key, "ASC"
+	if strings.HasPrefix(name, "-") {
+		name, dir = name[1:], "DESC"
+	}
+	column, ok := taskSortColumns[name]
+	if !ok {
+		return "", fmt.Errorf("%w: invalid sort %q; use created_at, updated_at, title or position, optionally prefixed with -", ErrValidation, key)
+	}
+	return column + " " + dir + ", id " + dir, nil
+}
+
+// SnoozeTask hides a task from the board until the given time; a nil until
+// wakes it up again. Snoozes end on their own once the time has passed.
+func (s *Store) SnoozeTask(ctx context.Context, id int64, until *time.Time) (models.Task, error) {
+	ctx, span := tracer.Start(ctx, "store.SnoozeTask")
+	defer span.End()
+	current, err := s.GetTask(ctx, id)
+	if err != nil {
+		return models.Task{}, err
+	}
+	if until != nil && !until.After(time.Now()) {
+		return models.Task{}, fmt.Errorf("%w: snooze time must be in the future", ErrValidation)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Task{}, fmt.Errorf("snooze task: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `UPDATE tasks SET snoozed_until = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`, dueDateValue(until), id); err != nil {
+		return models.Task{}, fmt.Errorf("snooze task: %w", err)
+	}
+	if err := recordActivity(ctx, tx, id, []fieldChange{
+		{"snoozed_until", formatTime(current.SnoozedUntil), formatTime(until)},
+	}); err != nil {
+		return models.Task{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return models.Task{}, fmt.Errorf("snooze task: %w", err)
+	}
+	return s.emitTask(ctx, "task.updated", id)
+}
+
+// ListAssignees returns the distinct non-empty assignees of a project.
+func (s *Store) ListAssignees(ctx context.Context, projectID int64) ([]string, error) {
+	ctx, span := tracer.Start(ctx, "store.ListAssignees")
+	defer span.End()
+	if _, err := s.GetProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT DISTINCT assignee FROM tasks
+        WHERE project_id = ? AND deleted_at IS NULL AND assignee != '' ORDER BY assignee`, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("list assignees: %w", err)
+	}
+	defer rows.Close()
+
+	assignees := []string{}
+	for rows.Next() {
+		var a string
+		if err := rows.Scan(&a); err != nil {
+			return nil, fmt.Errorf("scan assignee: %w", err)
+		}
+		assignees = append(assignees, a)
+	}
+	return assignees, rows.Err()
+}
+
+// listProjectTasks runs the board query for a project, appending clause (extra
+// AND conditions and the ORDER BY) to the WHERE, and hydrates the result.
+func (s *Store) listProjectTasks(ctx context.Context, projectID int64, clause string, args ...any) ([]models.Task, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+taskColumns+`
+        FROM tasks WHERE project_id = ? AND deleted_at IS NULL `+clause, append([]any{projectID}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("list tasks: %w", err)
+	}
+	tasks, err := scanTasks(rows)
+	if err != nil {
+		return nil, err
+	}
+	return tasks, s.hydrateTasks(ctx, tasks)
+}
+
+const maxAssigneeLength = 200
+
+func validateAssignee(assignee string) error {
+	if utf8.RuneCountInString(assignee) > maxAssigneeLength {
+		return fmt.Errorf("%w: assignee must be at most %d characters", ErrValidation, maxAssigneeLength)
+	}
+	return nil
+}
+
+// dueDateValue binds a due date or snooze time in the UTC layout used for
+// comparisons so SQL can order and filter on it; nil stores NULL.
+func dueDateValue(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC().Format(timestampLayout)
+}
+
+func validateCoverURL(raw string) error {
+	if raw != "" && !isHTTPURL(raw) {
+		return fmt.Errorf("%w: cover_url must be an absolute http(s) URL", ErrValidation)
+	}
+	return nil
+}
+
+func validatePriority(priority string) error {
+	if _, ok := models.ValidTaskPriorities[priority]; !ok {
+		return fmt.Errorf("%w: priority must be one of low, medium, high or urgent", ErrValidation)
+	}
+	return nil
+}
+
+func validateStoryPoints(points int) error {
+	if points < 0 {
+		return fmt.Errorf("%w: story points must not be negative", ErrValidation)
+	}
+	return nil
+}
+
+// CreateTask inserts a new task for a project.
+func (s *Store) CreateTask(ctx context.Context, t models.Task) (models.Task, error) {
+	ctx, span := tracer.Start(ctx, "store.CreateTask")
+	defer span.End()
+	if strings.TrimSpace(t.Title) == "" {
+		return models.Task{}, fmt.Errorf("task title must not be empty")
+	}
+	if err := s.validateTaskText(strings.TrimSpace(t.Title), strings.TrimSpace(t.Description)); err != nil {
+		return models.Task{}, err
+	}
+	if _, err := s.GetProject(ctx, t.ProjectID); err != nil {
+		return models.Task{}, err
+	}
+	status, err := lookupStatus(ctx, s.db, t.ProjectID, t.Status)
+	if err != nil {
+		return models.Task{}, err
+	}
+	t.Status = status.Name
+	if t.ParentID != nil {
+		if err := s.validateParent(ctx, 0, t.ProjectID, *t.ParentID); err != nil {
+			return models.Task{}, err
+		}
+	}
+	t.Assignee = strings.TrimSpace(t.Assignee)
+	if err := validateAssignee(t.Assignee); err != nil {
+		return models.Task{}, err
+	}
+	t.Color = strings.TrimSpace(t.Color)
+	if t.Color != "" {
+		if err := validateHexColor(t.Color); err != nil {
+			return models.Task{}, err
+		}
+	}
+	if t.Priority == "" {
+		t.Priority = models.DefaultTaskPriority
+	}
+	if err := validatePriority(t.Priority); err != nil {
+		return models.Task{}, err
+	}
+	if err := validateStoryPoints(t.StoryPoints); err != nil {
+		return models.Task{}, err
+	}
+	t.CoverURL = strings.TrimSpace(t.CoverURL)
+	if err := validateCoverURL(t.CoverURL); err != nil {
+		return models.Task{}, err
+	}
+	if t.SprintID != nil {
+		if err := s.validateSprint(ctx, t.ProjectID, *t.SprintID); err != nil {
+			return models.Task{}, err
+		}
+	}
+	if t.MilestoneID != nil {
+		if err := s.validateMilestone(ctx, t.ProjectID, *t.MilestoneID); err != nil {
+			return models.Task{}, err
+		}
+	}
+	fields := make(map[string]*string, len(t.Fields))
+	for k, v := range t.Fields {
+		fields[k] = &v
+	}
+	if _, err := mergeFields(nil, fields); err != nil {
+		return models.Task{}, err
+	}
+	links, err := normalizeLinks(t.Links)
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Task{}, fmt.Errorf("insert task: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := checkWIPLimit(ctx, tx, status); err != nil {
+		return models.Task{}, err
+	}
+	pos, err := nextPosition(ctx, tx, t.ProjectID, t.Status)
+	if err != nil {
+		return models.Task{}, err
+	}
+	// The number is computed inside the INSERT so it is assigned atomically;
+	// the unique (project_id, number) index rejects any duplicate.
+	var id int64
+	err = tx.QueryRowContext(ctx, `INSERT INTO tasks(project_id, number, parent_id, sprint_id, milestone_id, title, description, status, priority, assignee, color, story_points, cover_url, due_date, position, completed_at)
+        VALUES(?, (SELECT COALESCE(MAX(number), 0) + 1 FROM tasks WHERE project_id = ?), ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CASE WHEN ? THEN CURRENT_TIMESTAMP END)
+        RETURNING id`,
+		t.ProjectID, t.ProjectID, t.ParentID, t.SprintID, t.MilestoneID, strings.TrimSpace(t.Title), strings.TrimSpace(t.Description), t.Status, t.Priority, t.Assignee, t.Color, t.StoryPoints, t.CoverURL, dueDateValue(t.DueDate), pos, status.IsTerminal).Scan(&id)
+	if err != nil {
+		return models.Task{}, fmt.Errorf("insert task: %w", err)
+	}
+	if _, err := saveFields(ctx, tx, id, nil, fields); err != nil {
+		return models.Task{}, err
+	}
+	if err := replaceLinks(ctx, tx, id, links); err != nil {
+		return models.Task{}, err
+	}
+	if err := syncMentions(ctx, tx, id, t.Description); err != nil {
+		return models.Task{}, err
+	}
+	if err := logStatus(ctx, tx, id, t.Status); err != nil {
+		return models.Task{}, err
+	}
+	if err := recordOperation(ctx, tx, opTaskCreate, id, nil); err != nil {
+		return models.Task{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return models.Task{}, fmt.Errorf("insert task: %w", err)
+	}
+	return s.emitTask(ctx, "task.created", id)
+}
+
+// GetTask retrieves a task by id.
+func (s *Store) GetTask(ctx context.Context, id int64) (models.Task, error) {
+	ctx, span := tracer.Start(ctx, "store.GetTask")
+	defer span.End()
+	t, err := scanTask(s.db.QueryRowContext(ctx, `SELECT `+taskColumns+` FROM tasks WHERE id = ? AND deleted_at IS NULL`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Task{}, fmt.Errorf("task not found")
+	}
+	if err != nil {
+		return models.Task{}, fmt.Errorf("get task: %w", err)
+	}
+	tasks := []models.Task{t}
+	if err := s.hydrateTasks(ctx, tasks); err != nil {
+		return models.Task{}, err
+	}
+	if tasks[0].TimeInStatus, err = s.timeInStatus(ctx, id); err != nil {
+		return models.Task{}, err
+	}
+	return tasks[0], nil
+}
+
+// GetTaskByNumber retrieves a task by its per-project number.
+func (s *Store) GetTaskByNumber(ctx context.Context, projectID, number int64) (models.Task, error) {
+	ctx, span := tracer.Start(ctx, "store.GetTaskByNumber")
+	defer span.End()
+	var id int64
+	err := s.db.QueryRowContext(ctx, `SELECT id FROM tasks WHERE project_id = ? AND number = ? AND deleted_at IS NULL`, projectID, number).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Task{}, fmt.Errorf("task not found")
+	}
+	if err != nil {
+		return models.Task{}, fmt.Errorf("get task: %w", err)
+	}
+	return s.GetTask(ctx, id)
+}
+
+// UpdateTask updates task fields and moves the task between columns when needed.
+// A "version" in changes must match the task's current version, or the
+// update fails with ErrConflict.
+func (s *Store) UpdateTask(ctx context.Context, id int64, changes map[string]any) (models.Task, error) {
+	ctx, span := tracer.Start(ctx, "store.UpdateTask")
+	defer span.End()
+	newTitle, _ := changes["title"].(string)
+	newDescription, _ := changes["description"].(string)
+	if err := s.validateTaskText(strings.TrimSpace(newTitle), strings.TrimSpace(newDescription)); err != nil {
+		return models.Task{}, err
+	}
+	current, err := s.GetTask(ctx, id)
+	if err != nil {
+		return models.Task{}, err
+	}
+	// A version given by the client must still be current, so edits based
+	// on a stale copy are refused rather than overwriting newer changes. The
+	// update repeats the check in case another write lands in between.
+	version, checkVersion := changes["version"].(int)
+	if checkVersion && version != current.Version {
+		return models.Task{}, fmt.Errorf("%w: task was modified by another client", ErrConflict)
+	}
+
+	title := current.Title
+	description := current.Description
+	status := current.Status
+	priority := current.Priority
+	position := current.Position
+	parentID := current.ParentID
+	assignee := current.Assignee
+	color := current.Color
+	sprintID := current.SprintID
+	milestoneID := current.MilestoneID
+	storyPoints := current.StoryPoints
+	coverURL := current.CoverURL
+	dueDate := current.DueDate
+
+	if v, ok := changes["title"].(string); ok && strings.TrimSpace(v) != "" {
+		title = strings.TrimSpace(v)
+	}
+	if v, ok := changes["description"].(string); ok {
+		description = strings.TrimSpace(v)
+	}
+	var target models.TaskStatus
+	if v, ok := changes["status"].(string); ok && v != "" {
+		if target, err = lookupStatus(ctx, s.db, current.ProjectID, v); err != nil {
+			return models.Task{}, err
+		}
+		status = target.Name
+	}
+
+	if v, ok := changes["priority"].(string); ok {
+		if err := validatePriority(v); err != nil {
+			return models.Task{}, err
+		}
+		priority = v
+	}
+	if v, ok := changes["assignee"].(string); ok {
+		v = strings.TrimSpace(v)
+		if err := validateAssignee(v); err != nil {
+			return models.Task{}, err
+		}
+		assignee = v
+	}
+	if v, ok := changes["color"].(string); ok {
+		// An empty color removes the highlight.
+		v = strings.TrimSpace(v)
+		if v != "" {
+			if err := validateHexColor(v); err != nil {
+				return models.Task{}, err
+			}
+		}
+		color = v
+	}
+	if v, ok := changes["story_points"].(int); ok {
+		if err := validateStoryPoints(v); err != nil {
+			return models.Task{}, err
+		}
+		storyPoints = v
+	}
+	if v, ok := changes["cover_url"].(string); ok {
+		// An empty cover_url removes the cover image.
+		v = strings.TrimSpace(v)
+		if err := validateCoverURL(v); err != nil {
+			return models.Task{}, err
+		}
+		coverURL = v
+	}
+	if v, ok := changes["due_date"]; ok {
+		// A nil due_date clears the deadline.
+		dueDate, _ = v.(*time.Time)
+	}
+	if v, ok := changes["sprint_id"].(int64); ok {
+		// sprint_id 0 moves the task back to the backlog.
+		if v == 0 {
+			sprintID = nil
+		} else {
+			if err := s.validateSprint(ctx, current.ProjectID, v); err != nil {
+				return models.Task{}, err
+			}
+			sprintID = &v
+		}
+	}
+	if v, ok := changes["milestone_id"].(int64); ok {
+		// milestone_id 0 detaches the task from its milestone.
+		if v == 0 {
+			milestoneID = nil
+		} else {
+			if err := s.validateMilestone(ctx, current.ProjectID, v); err != nil {
+				return models.Task{}, err
+			}
+			milestoneID = &v
+		}
+	}
+	fieldChanges, _ := changes["fields"].(map[string]*string)
+	if _, err := mergeFields(current.Fields, fieldChanges); err != nil {
+		return models.Task{}, err
+	}
+	// links replaces the whole list when present.
+	links, replaceTaskLinks := changes["links"].([]models.TaskLink)
+	if replaceTaskLinks {
+		if links, err = normalizeLinks(links); err != nil {
+			return models.Task{}, err
+		}
+	}
+	if v, ok := changes["parent_id"].(int64); ok {
+		if v == 0 {
+			parentID = nil
+		} else {
+			if err := s.validateParent(ctx, id, current.ProjectID, v); err != nil {
+				return models.Task{}, err
+			}
+			parentID = &v
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Task{}, fmt.Errorf("update task: %w", err)
+	}
+	defer tx.Rollback()
+
+	if status != current.Status {
+		if err := checkWIPLimit(ctx, tx, target); err != nil {
+			return models.Task{}, err
+		}
+		if position, err = nextPosition(ctx, tx, current.ProjectID, status); err != nil {
+			return models.Task{}, err
+		}
+	}
+	stmt := `UPDATE tasks SET parent_id = ?, sprint_id = ?, milestone_id = ?, title = ?, description = ?, status = ?, priority = ?, assignee = ?, color = ?, story_points = ?, cover_url = ?, due_date = ?, position = ?, completed_at = ` + completedAtExpr + `, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
+	args := []any{parentID, sprintID, milestoneID, title, description, status, priority, assignee, color, storyPoints, coverURL, dueDateValue(dueDate), position, status, id}
+	if checkVersion {
+		stmt += ` AND version = ?`
+		args = append(args, version)
+	}
+	res, err := tx.ExecContext(ctx, stmt, args...)
+	if err != nil {
+		return models.Task{}, fmt.Errorf("update task: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return models.Task{}, fmt.Errorf("update task: %w", err)
+	}
+	if affected == 0 {
+		var exists bool
+		if err := tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM tasks WHERE id = ?)`, id).Scan(&exists); err != nil {
+			return models.Task{}, fmt.Errorf("update task: %w", err)
+		}
+		if !exists {
+			return models.Task{}, fmt.Errorf("task not found")
+		}
+		return models.Task{}, fmt.Errorf("%w: task was modified by another client", ErrConflict)
+	}
+	if title != current.Title || description != current.Description {
+		if err := s.saveRevision(ctx, tx, current); err != nil {
+			return models.Task{}, err
+		}
+	}
+	if description != current.Description {
+		if err := syncMentions(ctx, tx, id, description); err != nil {
+			return models.Task{}, err
+		}
+	}
+	if status != current.Status {
+		moved := taskPlacement{ID: id, Status: current.Status, Position: current.Position, CompletedAt: current.CompletedAt}
+		if err := recordOperation(ctx, tx, opTaskMove, id, []taskPlacement{moved}); err != nil {
+			return models.Task{}, err
+		}
+		if err := logStatus(ctx, tx, id, status); err != nil {
+			return models.Task{}, err
+		}
+	}
+	customChanges, err := saveFields(ctx, tx, id, current.Fields, fieldChanges)
+	if err != nil {
+		return models.Task{}, err
+	}
+	if replaceTaskLinks {
+		if err := replaceLinks(ctx, tx, id, links); err != nil {
+			return models.Task{}, err
+		}
+	}
+	if err := recordActivity(ctx, tx, id, append([]fieldChange{
+		{"title", current.Title, title},
+		{"description", current.Description, description},
+		{"status", current.Status, status},
+		{"priority", current.Priority, priority},
+		{"assignee", current.Assignee, assignee},
+		{"color", current.Color, color},
+		{"cover_url", current.CoverURL, coverURL},
+		{"due_date", formatTime(current.DueDate), formatTime(dueDate)},
+		{"story_points", strconv.Itoa(current.StoryPoints), strconv.Itoa(storyPoints)},
+		{"sprint_id", formatID(current.SprintID), formatID(sprintID)},
+		{"milestone_id", formatID(current.MilestoneID), formatID(milestoneID)},
+		{"parent_id", formatID(current.ParentID), formatID(parentID)},
+	}, customChanges...)); err != nil {
+		return models.Task{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return models.Task{}, fmt.Errorf("update task: %w", err)
+	}
+	return s.emitTask(ctx, "task.updated", id)
+}
+
+// MoveTask places a task at the given index inside the target column and
+// renumbers the affected columns so positions stay contiguous. Positions past
+// the end of the column are clamped to the end.
+func (s *Store) MoveTask(ctx context.Context, id int64, status string, position int64) (models.Task, error) {
+	ctx, span := tracer.Start(ctx, "store.MoveTask")
+	defer span.End()
+	if status == "" {
+		return models.Task{}, fmt.Errorf("%w: status must not be empty", ErrValidation)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Task{}, fmt.Errorf("move task: %w", err)
+	}
+	defer tx.Rollback()
+
+	var projectID int64
+	previous := taskPlacement{ID: id}
+	err = tx.QueryRowContext(ctx, `SELECT project_id, status, position, completed_at FROM tasks WHERE id = ? AND deleted_at IS NULL`, id).
+		Scan(&projectID, &previous.Status, &previous.Position, &previous.CompletedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Task{}, fmt.Errorf("task not found")
+	}
+	if err != nil {
+		return models.Task{}, fmt.Errorf("move task: %w", err)
+	}
+	currentStatus := previous.Status
+	target, err := lookupStatus(ctx, tx, projectID, status)
+	if err != nil {
+		return models.Task{}, err
+	}
+	if currentStatus != status {
+		if err := checkWIPLimit(ctx, tx, target); err != nil {
+			return models.Task{}, err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE tasks SET status = ?, completed_at = `+completedAtExpr+`, version = version + 1 WHERE id = ?`, status, status, id); err != nil {
+		return models.Task{}, fmt.Errorf("move task: %w", err)
+	}
+	if err := placeInColumn(ctx, tx, id, projectID, currentStatus, status, position); err != nil {
+		return models.Task{}, err
+	}
+	if err := recordActivity(ctx, tx, id, []fieldChange{{"status", currentStatus, status}}); err != nil {
+		return models.Task{}, err
+	}
+	if currentStatus != status {
+		if err := logStatus(ctx, tx, id, status); err != nil {
+			return models.Task{}, err
+		}
+	}
+	if err := recordOperation(ctx, tx, opTaskMove, id, []taskPlacement{previous}); err != nil {
+		return models.Task{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Task{}, fmt.Errorf("move task: %w", err)
+	}
+	return s.emitTask(ctx, "task.updated", id)
+}
+
+// placeInColumn inserts task id at position of the to column, clamping the
+// index, and closes the gap it left in the from column.
+func placeInColumn(ctx context.Context, tx *observedTx, id, projectID int64, from, to string, position int64) error {
+	siblings, err := columnTaskIDs(ctx, tx, projectID, to, id)
+	if err != nil {
+		return err
+	}
+	if position < 0 {
+		position = 0
+	}
+	if position > int64(len(siblings)) {
+		position = int64(len(siblings))
+	}
+	ordered := make([]int64, 0, len(siblings)+1)
+	ordered = append(ordered, siblings[:position]...)
+	ordered = append(ordered, id)
+	ordered = append(ordered, siblings[position:]...)
+	if err := renumberTasks(ctx, tx, ordered); err != nil {
+		return err
+	}
+
+	if from != to {
+		previous, err := columnTaskIDs(ctx, tx, projectID, from, id)
+		if err != nil {
+			return err
+		}
+		if err := renumberTasks(ctx, tx, previous); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdateTasksStatus moves many tasks into the status column at once, appending
+// them in the given order. Ids that do not refer to live tasks, or to tasks
+// whose project has no such status, are returned as invalid; in strict mode
+// any invalid id aborts the whole update.
+func (s *Store) UpdateTasksStatus(ctx context.Context, ids []int64, status string, strict bool) ([]models.Task, []int64, error) {
+	ctx, span := tracer.Start(ctx, "store.UpdateTasksStatus")
+	defer span.End()
+	if status == "" {
+		return nil, nil, fmt.Errorf("%w: status must not be empty", ErrValidation)
+	}
+	if len(ids) == 0 {
+		return nil, nil, fmt.Errorf("%w: ids must not be empty", ErrValidation)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("bulk update: %w", err)
+	}
+	defer tx.Rollback()
+
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	rows, err := tx.QueryContext(ctx, `SELECT id, project_id, status, position, completed_at FROM tasks WHERE id IN (`+placeholders(len(ids))+`) AND deleted_at IS NULL`, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("bulk update: %w", err)
+	}
+	type taskRef struct {
+		projectID int64
+		status    string
+		position  int64
+		completed *time.Time
+	}
+	found := map[int64]taskRef{}
+	for rows.Next() {
+		var id int64
+		var ref taskRef
+		if err := rows.Scan(&id, &ref.projectID, &ref.status, &ref.position, &ref.completed); err != nil {
+			rows.Close()
+			return nil, nil, fmt.Errorf("scan task: %w", err)
+		}
+		found[id] = ref
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return nil, nil, err
+	}
+	rows.Close()
+
+	targets := map[int64]*models.TaskStatus{}
+	for _, ref := range found {
+		if _, ok := targets[ref.projectID]; ok {
+			continue
+		}
+		st, err := scanStatus(tx.QueryRowContext(ctx, `SELECT `+statusColumns+` FROM statuses WHERE project_id = ? AND name = ?`, ref.projectID, status))
+		if errors.Is(err, sql.ErrNoRows) {
+			targets[ref.projectID] = nil
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("bulk update: %w", err)
+		}
+		targets[ref.projectID] = &st
+	}
+
+	var valid, invalid []int64
+	seen := map[int64]bool{}
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if ref, ok := found[id]; ok && targets[ref.projectID] != nil {
+			valid = append(valid, id)
+		} else {
+			invalid = append(invalid, id)
+		}
+	}
+	if strict && len(invalid) > 0 {
+		return nil, invalid, fmt.Errorf("unknown task ids: %v", invalid)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `UPDATE tasks SET status = ?, position = ?, completed_at = `+completedAtExpr+`, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = ?`)
+	if err != nil {
+		return nil, nil, fmt.Errorf("bulk update: %w", err)
+	}
+	defer stmt.Close()
+
+	next := map[int64]int64{}
+	var moved []taskPlacement
+	for _, id := range valid {
+		ref := found[id]
+		if ref.status == status {
+			continue
+		}
+		// Each moved task counts towards the limit for the next one.
+		if err := checkWIPLimit(ctx, tx, *targets[ref.projectID]); err != nil {
+			return nil, nil, err
+		}
+		moved = append(moved, taskPlacement{ID: id, Status: ref.status, Position: ref.position, CompletedAt: ref.completed})
+		pos, ok := next[ref.projectID]
+		if !ok {
+			var max sql.NullInt64
+			if err := tx.QueryRowContext(ctx, `SELECT MAX(position) FROM tasks WHERE project_id = ? AND status = ? AND deleted_at IS NULL`, ref.projectID, status).Scan(&max); err != nil {
+				return nil, nil, fmt.Errorf("select position: %w", err)
+			}
+			if max.Valid {
+				pos = max.Int64 + 1
+			}
+		}
+		if _, err := stmt.ExecContext(ctx, status, pos, status, id); err != nil {
+			return nil, nil, fmt.Errorf("bulk update: %w", err)
+		}
+		if err := recordActivity(ctx, tx, id, []fieldChange{{"status", ref.status, status}}); err != nil {
+			return nil, nil, err
+		}
+		if err := logStatus(ctx, tx, id, status); err != nil {
+			return nil, nil, err
+		}
+		next[ref.projectID] = pos + 1
+	}
+	if len(moved) > 0 {
+		if err := recordOperation(ctx, tx, opTaskMove, 0, moved); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("bulk update: %w", err)
+	}
+
+	updated := []models.Task{}
+	if len(valid) > 0 {
+		args := make([]any, len(valid))
+		for i, id := range valid {
+			args[i] = id
+		}
+		rows, err := s.db.QueryContext(ctx, `SELECT `+taskColumns+` FROM tasks WHERE id IN (`+placeholders(len(valid))+`) ORDER BY project_id, position, id`, args...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("load tasks: %w", err)
+		}
+		if updated, err = scanTasks(rows); err != nil {
+			return nil, nil, err
+		}
+		if err := s.hydrateTasks(ctx, updated); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, t := range updated {
+		s.emit(ctx, "task.updated", t.ProjectID, t)
+	}
+	if invalid == nil {
+		invalid = []int64{}
+	}
+	return updated, invalid, nil
+}
+
+// columnTaskIDs lists live task ids of a column in board order, skipping exclude.
+func columnTaskIDs(ctx context.Context, tx *observedTx, projectID int64, status string, exclude int64) ([]int64, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM tasks WHERE project_id = ? AND status = ? AND id != ? AND deleted_at IS NULL ORDER BY position, id`, projectID, status, exclude)
+	if err != nil {
+		return nil, fmt.Errorf("list column: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan column: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// renumberTasks assigns positions 0..n-1 following the order of ids.
+func renumberTasks(ctx context.Context, tx *observedTx, ids []int64) error {
+	for i, taskID := range ids {
+		if _, err := tx.ExecContext(ctx, `UPDATE tasks SET position = ? WHERE id = ? AND position != ?`, i, taskID, i); err != nil {
+			return fmt.Errorf("update position: %w", err)
+		}
+	}
+	return nil
+}
+
+// DeleteTask moves a task and its sub-tasks to the trash.
+func (s *Store) DeleteTask(ctx context.Context, id int64) error {
+	ctx, span := tracer.Start(ctx, "store.DeleteTask")
+	defer span.End()
+	task, err := s.GetTask(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("delete task: %w", err)
+	}
+	defer tx.Rollback()
+
+	// The journaled stamp must equal the stored one, which keeps microseconds.
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	res, err := tx.ExecContext(ctx, `UPDATE tasks SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`, now, id)
+	if err != nil {
+		return fmt.Errorf("delete task: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("task not found")
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE tasks SET deleted_at = ? WHERE parent_id = ? AND deleted_at IS NULL`, now, id); err != nil {
+		return fmt.Errorf("delete sub-tasks: %w", err)
+	}
+	if err := recordOperation(ctx, tx, opTaskDelete, id, trashedTask{DeletedAt: now, Position: task.Position}); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("delete task: %w", err)
+	}
+	s.emit(ctx, "task.deleted", task.ProjectID, task)
+	return nil
+}
+
+// ListSubTasks returns the direct children of a task.
+func (s *Store) ListSubTasks(ctx context.Context, parentID int64) ([]models.Task, error) {
+	ctx, span := tracer.Start(ctx, "store.ListSubTasks")
+	defer span.End()
+	if _, err := s.GetTask(ctx, parentID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT `+taskColumns+`
+        FROM tasks WHERE parent_id = ? AND deleted_at IS NULL ORDER BY status, position, id`, parentID)
+	if err != nil {
+		return nil, fmt.Errorf("list sub-tasks: %w", err)
+	}
+	tasks, err := scanTasks(rows)
+	if err != nil {
+		return nil, err
+	}
+	return tasks, s.hydrateTasks(ctx, tasks)
+}
+
+// validateParent checks that parentID may become the parent of task id
+// (0 for a task not created yet). Sub-tasks are limited to one level.
+func (s *Store) validateParent(ctx context.Context, id, projectID, parentID int64) error {
+	if parentID == id {
+		return fmt.Errorf("%w: task cannot be its own parent", ErrValidation)
+	}
+	parent, err := s.GetTask(ctx, parentID)
+	if err != nil {
+		return fmt.Errorf("%w: parent %v", ErrValidation, err)
+	}
+	if parent.ProjectID != projectID {
+		return fmt.Errorf("%w: parent task belongs to another project", ErrValidation)
+	}
+	if parent.ParentID != nil {
+		return fmt.Errorf("%w: sub-tasks cannot have sub-tasks", ErrValidation)
+	}
+	if id != 0 {
+		var children int64
+		if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM tasks WHERE parent_id = ? AND deleted_at IS NULL`, id).Scan(&children); err != nil {
+			return fmt.Errorf("count sub-tasks: %w", err)
+		}
+		if children > 0 {
+			return fmt.Errorf("%w: task with sub-tasks cannot become a sub-task", ErrValidation)
+		}
+	}
+	return nil
+}
+
+func nextPosition(ctx context.Context, q queryer, projectID int64, status string) (int64, error) {
+	var position sql.NullInt64
+	err := q.QueryRowContext(ctx, `SELECT MAX(position) FROM tasks WHERE project_id = ? AND status = ? AND deleted_at IS NULL`, projectID, status).Scan(&position)
+	if err != nil {
+		return 0, fmt.Errorf("select position: %w", err)
+	}
+	if position.Valid {
+		return position.Int64 + 1, nil
+	}
+	return 0, nil
+}
+
+// escapeLike escapes LIKE wildcards so user input is matched literally.
+func escapeLike(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(v)
+}
+
+// placeholders returns n comma separated bind parameters.
+func placeholders(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strings.Repeat("?, ", n-1) + "?"
+}
+
+// qualify prefixes every column of a comma separated list with a table alias.
+func qualify(alias, columns string) string {
+	parts := strings.Split(columns, ",")
+	for i, part := range parts {
+		parts[i] = alias + "." + strings.TrimSpace(part)
+	}
+	return strings.Join(parts, ", ")
+}
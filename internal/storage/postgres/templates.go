@@ -0,0 +1,159 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"todo/internal/models"
+)
+
+const templateColumns = `id, project_id, title, description, status, created_at, updated_at`
+
+func scanTemplate(row rowScanner) (models.TaskTemplate, error) {
+	var (
+		t         models.TaskTemplate
+		projectID sql.NullInt64
+	)
+	if err := row.Scan(&t.ID, &projectID, &t.Title, &t.Description, &t.Status, &t.CreatedAt, &t.UpdatedAt); err != nil {
+		return models.TaskTemplate{}, err
+	}
+	if projectID.Valid {
+		t.ProjectID = &projectID.Int64
+	}
+	return t, nil
+}
+
+// ListTemplates returns global templates plus, when projectID is given, the
+// templates scoped to that project.
+func (s *Store) ListTemplates(ctx context.Context, projectID *int64) ([]models.TaskTemplate, error) {
+	ctx, span := tracer.Start(ctx, "store.ListTemplates")
+	defer span.End()
+	query := `SELECT ` + templateColumns + ` FROM task_templates WHERE project_id IS NULL`
+	var args []any
+	if projectID != nil {
+		query += ` OR project_id = ?`
+		args = append(args, *projectID)
+	}
+	query += ` ORDER BY title, id`
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list templates: %w", err)
+	}
+	defer rows.Close()
+
+	templates := []models.TaskTemplate{}
+	for rows.Next() {
+		t, err := scanTemplate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan template: %w", err)
+		}
+		templates = append(templates, t)
+	}
+	return templates, rows.Err()
+}
+
+// GetTemplate fetches a single template by id.
+func (s *Store) GetTemplate(ctx context.Context, id int64) (models.TaskTemplate, error) {
+	ctx, span := tracer.Start(ctx, "store.GetTemplate")
+	defer span.End()
+	t, err := scanTemplate(s.db.QueryRowContext(ctx, `SELECT `+templateColumns+` FROM task_templates WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.TaskTemplate{}, fmt.Errorf("template not found")
+	}
+	if err != nil {
+		return models.TaskTemplate{}, fmt.Errorf("get template: %w", err)
+	}
+	return t, nil
+}
+
+// CreateTemplate stores a new task template.
+func (s *Store) CreateTemplate(ctx context.Context, t models.TaskTemplate) (models.TaskTemplate, error) {
+	ctx, span := tracer.Start(ctx, "store.CreateTemplate")
+	defer span.End()
+	if err := s.normalizeTemplate(ctx, &t); err != nil {
+		return models.TaskTemplate{}, err
+	}
+
+	var id int64
+	if err := s.db.QueryRowContext(ctx, `INSERT INTO task_templates(project_id, title, description, status) VALUES(?, ?, ?, ?) RETURNING id`, t.ProjectID, t.Title, t.Description, t.Status).Scan(&id); err != nil {
+		return models.TaskTemplate{}, fmt.Errorf("insert template: %w", err)
+	}
+	return s.GetTemplate(ctx, id)
+}
+
+// UpdateTemplate replaces the fields of an existing template.
+func (s *Store) UpdateTemplate(ctx context.Context, id int64, t models.TaskTemplate) (models.TaskTemplate, error) {
+	ctx, span := tracer.Start(ctx, "store.UpdateTemplate")
+	defer span.End()
+	if _, err := s.GetTemplate(ctx, id); err != nil {
+		return models.TaskTemplate{}, err
+	}
+	if err := s.normalizeTemplate(ctx, &t); err != nil {
+		return models.TaskTemplate{}, err
+	}
+
+	if _, err := s.db.ExecContext(ctx, `UPDATE task_templates SET project_id = ?, title = ?, description = ?, status = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`, t.ProjectID, t.Title, t.Description, t.Status, id); err != nil {
+		return models.TaskTemplate{}, fmt.Errorf("update template: %w", err)
+	}
+	return s.GetTemplate(ctx, id)
+}
+
+// DeleteTemplate removes a template; tasks created from it are untouched.
+func (s *Store) DeleteTemplate(ctx context.Context, id int64) error {
+	ctx, span := tracer.Start(ctx, "store.DeleteTemplate")
+	defer span.End()
+	res, err := s.db.ExecContext(ctx, `DELETE FROM task_templates WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("delete template: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("template not found")
+	}
+	return nil
+}
+
+// CreateTaskFromTemplate instantiates a template as a new task in a project.
+func (s *Store) CreateTaskFromTemplate(ctx context.Context, projectID, templateID int64) (models.Task, error) {
+	ctx, span := tracer.Start(ctx, "store.CreateTaskFromTemplate")
+	defer span.End()
+	tpl, err := s.GetTemplate(ctx, templateID)
+	if err != nil {
+		return models.Task{}, err
+	}
+	if tpl.ProjectID != nil && *tpl.ProjectID != projectID {
+		return models.Task{}, fmt.Errorf("%w: template belongs to another project", ErrValidation)
+	}
+	return s.CreateTask(ctx, models.Task{
+		ProjectID:   projectID,
+		Title:       tpl.Title,
+		Description: tpl.Description,
+		Status:      tpl.Status,
+	})
+}
+
+func (s *Store) normalizeTemplate(ctx context.Context, t *models.TaskTemplate) error {
+	t.Title = strings.TrimSpace(t.Title)
+	t.Description = strings.TrimSpace(t.Description)
+	if t.Title == "" {
+		return fmt.Errorf("template title must not be empty")
+	}
+	if t.Status == "" {
+		t.Status = "todo"
+	}
+	if t.ProjectID == nil {
+		return s.validateAnyStatus(ctx, t.Status)
+	}
+	if _, err := s.GetProject(ctx, *t.ProjectID); err != nil {
+		return err
+	}
+	_, err := lookupStatus(ctx, s.db, *t.ProjectID, t.Status)
+	return err
+}
@@ -0,0 +1,7 @@
+package postgres
+
+import "go.opentelemetry.io/otel"
+
+// tracer starts a span for every exported store method. It follows the
+// global provider, which is a no-op unless tracing is enabled.
+var tracer = otel.Tracer("todo/store")
@@ -0,0 +1,261 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+// undoDepth is how many operations are kept per session for undo.
+const undoDepth = 20
+
+// Journaled operation kinds.
+const (
+	opTaskCreate    = "task.create"
+	opTaskDelete    = "task.delete"
+	opTaskMove      = "task.move"
+	opProjectUpdate = "project.update"
+)
+
+// taskPlacement is where a task sat before a move.
+type taskPlacement struct {
+	ID          int64      `json:"id"`
+	Status      string     `json:"status"`
+	Position    int64      `json:"position"`
+	CompletedAt *time.Time `json:"completed_at"`
+}
+
+// trashedTask is what undoing a delete needs to put a task back.
+type trashedTask struct {
+	DeletedAt time.Time `json:"deleted_at"`
+	Position  int64     `json:"position"`
+}
+
+// projectState is a project's details before an update.
+type projectState struct {
+	Name        string     `json:"name"`
+	Color       string     `json:"color"`
+	Description string     `json:"description"`
+	Deadline    *time.Time `json:"deadline"`
+}
+
+// recordOperation journals an undoable operation inside tx and drops the
+// session's entries beyond undoDepth.
+func recordOperation(ctx context.Context, tx *observedTx, kind string, entityID int64, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("encode operation: %w", err)
+	}
+	session := storage.Session(ctx)
+	if _, err := tx.ExecContext(ctx, `INSERT INTO operations(session, kind, entity_id, data) VALUES(?, ?, ?, ?)`, session, kind, entityID, string(payload)); err != nil {
+		return fmt.Errorf("record operation: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM operations WHERE session = ? AND id NOT IN (
+            SELECT id FROM operations WHERE session = ? ORDER BY id DESC LIMIT ?)`, session, session, undoDepth); err != nil {
+		return fmt.Errorf("prune operations: %w", err)
+	}
+	return nil
+}
+
+// Undo reverses the most recent journaled operation of the session in ctx:
+// a created task goes to the trash, a deleted task comes back with its id and
+// timestamps, moved tasks return to their column and position, and a project
+// gets its previous name and color back.
+func (s *Store) Undo(ctx context.Context) (models.UndoResult, error) {
+	ctx, span := tracer.Start(ctx, "store.Undo")
+	defer span.End()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.UndoResult{}, fmt.Errorf("undo: %w", err)
+	}
+	defer tx.Rollback()
+
+	var (
+		opID int64
+		res  models.UndoResult
+		data string
+	)
+	err = tx.QueryRowContext(ctx, `SELECT id, kind, entity_id, data, created_at FROM operations WHERE session = ? ORDER BY id DESC LIMIT 1`, storage.Session(ctx)).
+		Scan(&opID, &res.Operation, &res.EntityID, &data, &res.PerformedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.UndoResult{}, fmt.Errorf("%w: nothing to undo", ErrConflict)
+	}
+	if err != nil {
+		return models.UndoResult{}, fmt.Errorf("undo: %w", err)
+	}
+	// The entry is consumed even when it can no longer be applied, so a stale
+	// operation does not block older ones.
+	if _, err := tx.ExecContext(ctx, `DELETE FROM operations WHERE id = ?`, opID); err != nil {
+		return models.UndoResult{}, fmt.Errorf("undo: %w", err)
+	}
+
+	var events []func()
+	switch res.Operation {
+	case opTaskCreate:
+		task, err := s.undoTaskCreate(ctx, tx, res.EntityID)
+		if err != nil {
+			return models.UndoResult{}, err
+		}
+		res.Description = fmt.Sprintf("moved task %q to the trash", task.Title)
+		events = append(events, func() { s.emit(ctx, "task.deleted", task.ProjectID, task) })
+
+	case opTaskDelete:
+		var trashed trashedTask
+		if err := json.Unmarshal([]byte(data), &trashed); err != nil {
+			return models.UndoResult{}, fmt.Errorf("decode operation: %w", err)
+		}
+		if err := undoTaskDelete(ctx, tx, res.EntityID, trashed); err != nil {
+			return models.UndoResult{}, err
+		}
+		res.Description = fmt.Sprintf("restored task %d from the trash", res.EntityID)
+		res.TaskIDs = []int64{res.EntityID}
+
+	case opTaskMove:
+		var moves []taskPlacement
+		if err := json.Unmarshal([]byte(data), &moves); err != nil {
+			return models.UndoResult{}, fmt.Errorf("decode operation: %w", err)
+		}
+		if err := undoTaskMove(ctx, tx, moves); err != nil {
+			return models.UndoResult{}, err
+		}
+		for _, m := range moves {
+			res.TaskIDs = append(res.TaskIDs, m.ID)
+		}
+		if len(moves) == 1 {
+			res.Description = fmt.Sprintf("moved task %d back to %s", moves[0].ID, moves[0].Status)
+		} else {
+			res.Description = fmt.Sprintf("moved %d tasks back to their columns", len(moves))
+		}
+
+	case opProjectUpdate:
+		var previous projectState
+		if err := json.Unmarshal([]byte(data), &previous); err != nil {
+			return models.UndoResult{}, fmt.Errorf("decode operation: %w", err)
+		}
+		if err := checkProjectName(ctx, tx, previous.Name, res.EntityID); err != nil {
+			return models.UndoResult{}, err
+		}
+		result, err := tx.ExecContext(ctx, `UPDATE projects SET name = ?, color = ?, description = ?, deadline = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND deleted_at IS NULL`,
+			previous.Name, previous.Color, previous.Description, dueDateValue(previous.Deadline), res.EntityID)
+		if err != nil {
+			return models.UndoResult{}, fmt.Errorf("undo: %w", err)
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return models.UndoResult{}, fmt.Errorf("%w: project %d no longer exists", ErrConflict, res.EntityID)
+		}
+		res.Description = fmt.Sprintf("restored the previous details of project %q", previous.Name)
+
+	default:
+		return models.UndoResult{}, fmt.Errorf("undo: unknown operation %q", res.Operation)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.UndoResult{}, fmt.Errorf("undo: %w", err)
+	}
+
+	if res.Operation == opProjectUpdate {
+		project, err := s.GetProject(ctx, res.EntityID)
+		if err != nil {
+			return models.UndoResult{}, err
+		}
+		s.emit(ctx, "project.updated", project.ID, project)
+		res.Project = &project
+	}
+	for _, id := range res.TaskIDs {
+		task, err := s.emitTask(ctx, "task.updated", id)
+		if err != nil {
+			return models.UndoResult{}, err
+		}
+		res.Tasks = append(res.Tasks, task)
+	}
+	for _, fire := range events {
+		fire()
+	}
+	return res, nil
+}
+
+// undoTaskCreate trashes a task created by the undone operation.
+func (s *Store) undoTaskCreate(ctx context.Context, tx *observedTx, id int64) (models.Task, error) {
+	task, err := scanTask(tx.QueryRowContext(ctx, `SELECT `+taskColumns+` FROM tasks WHERE id = ? AND deleted_at IS NULL`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Task{}, fmt.Errorf("%w: task %d no longer exists", ErrConflict, id)
+	}
+	if err != nil {
+		return models.Task{}, fmt.Errorf("undo: %w", err)
+	}
+	now := time.Now().UTC()
+	if _, err := tx.ExecContext(ctx, `UPDATE tasks SET deleted_at = ? WHERE id = ? OR (parent_id = ? AND deleted_at IS NULL)`, now, id, id); err != nil {
+		return models.Task{}, fmt.Errorf("undo: %w", err)
+	}
+	remaining, err := columnTaskIDs(ctx, tx, task.ProjectID, task.Status, id)
+	if err != nil {
+		return models.Task{}, err
+	}
+	return task, renumberTasks(ctx, tx, remaining)
+}
+
+// undoTaskDelete takes a task and the sub-tasks trashed with it out of the
+// trash, putting the task back at its former position.
+func undoTaskDelete(ctx context.Context, tx *observedTx, id int64, trashed trashedTask) error {
+	var (
+		projectID int64
+		status    string
+	)
+	err := tx.QueryRowContext(ctx, `SELECT project_id, status FROM tasks WHERE id = ? AND deleted_at = ?`, id, trashed.DeletedAt).Scan(&projectID, &status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: task %d is no longer in the trash", ErrConflict, id)
+	}
+	if err != nil {
+		return fmt.Errorf("undo: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE tasks SET deleted_at = NULL WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("undo: %w", err)
+	}
+	if err := placeInColumn(ctx, tx, id, projectID, status, status, trashed.Position); err != nil {
+		return err
+	}
+	return restoreSubTasks(ctx, tx, id, trashed.DeletedAt)
+}
+
+// undoTaskMove returns tasks to the column, position and completion time they
+// had before the move. Tasks deleted since are skipped.
+func undoTaskMove(ctx context.Context, tx *observedTx, moves []taskPlacement) error {
+	// Reinserting in ascending position rebuilds each column front to back.
+	sort.SliceStable(moves, func(i, j int) bool { return moves[i].Position < moves[j].Position })
+	for _, m := range moves {
+		var (
+			projectID int64
+			status    string
+		)
+		err := tx.QueryRowContext(ctx, `SELECT project_id, status FROM tasks WHERE id = ? AND deleted_at IS NULL`, m.ID).Scan(&projectID, &status)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("undo: %w", err)
+		}
+		var completedAt any
+		if m.CompletedAt != nil {
+			completedAt = m.CompletedAt.UTC().Format(timestampLayout)
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE tasks SET status = ?, completed_at = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`, m.Status, completedAt, m.ID); err != nil {
+			return fmt.Errorf("undo: %w", err)
+		}
+		if err := placeInColumn(ctx, tx, m.ID, projectID, status, m.Status, m.Position); err != nil {
+			return err
+		}
+		if status != m.Status {
+			if err := logStatus(ctx, tx, m.ID, m.Status); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"todo/internal/models"
+)
+
+// AddWatcher subscribes name to a task. Adding an existing watcher is a no-op.
+func (s *Store) AddWatcher(ctx context.Context, taskID int64, name string) error {
+	ctx, span := tracer.Start(ctx, "store.AddWatcher")
+	defer span.End()
+	name, err := normalizeWatcher(name)
+	if err != nil {
+		return err
+	}
+	if _, err := s.GetTask(ctx, taskID); err != nil {
+		return err
+	}
+
+	if _, err := s.db.ExecContext(ctx, `INSERT INTO task_watchers(task_id, name) VALUES(?, ?) ON CONFLICT DO NOTHING`, taskID, name); err != nil {
+		return fmt.Errorf("add watcher: %w", err)
+	}
+	return nil
+}
+
+// RemoveWatcher unsubscribes name from a task.
+func (s *Store) RemoveWatcher(ctx context.Context, taskID int64, name string) error {
+	ctx, span := tracer.Start(ctx, "store.RemoveWatcher")
+	defer span.End()
+	if _, err := s.GetTask(ctx, taskID); err != nil {
+		return err
+	}
+
+	res, err := s.db.ExecContext(ctx, `DELETE FROM task_watchers WHERE task_id = ? AND name = ?`, taskID, strings.TrimSpace(name))
+	if err != nil {
+		return fmt.Errorf("remove watcher: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("watcher not found")
+	}
+	return nil
+}
+
+// ListWatchers returns the names subscribed to a task in alphabetical order.
+func (s *Store) ListWatchers(ctx context.Context, taskID int64) ([]string, error) {
+	ctx, span := tracer.Start(ctx, "store.ListWatchers")
+	defer span.End()
+	rows, err := s.db.QueryContext(ctx, `SELECT name FROM task_watchers WHERE task_id = ? ORDER BY name`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("list watchers: %w", err)
+	}
+	defer rows.Close()
+
+	watchers := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("scan watcher: %w", err)
+		}
+		watchers = append(watchers, name)
+	}
+	return watchers, rows.Err()
+}
+
+// attachWatchers fills the Watchers field of each task using a single query.
+func (s *Store) attachWatchers(ctx context.Context, tasks []models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	index, args := taskIndex(tasks)
+	for i := range tasks {
+		tasks[i].Watchers = []string{}
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT task_id, name FROM task_watchers WHERE task_id IN (`+placeholders(len(args))+`) ORDER BY name`, args...)
+	if err != nil {
+		return fmt.Errorf("load task watchers: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			taskID int64
+			name   string
+		)
+		if err := rows.Scan(&taskID, &name); err != nil {
+			return fmt.Errorf("scan task watcher: %w", err)
+		}
+		if i, ok := index[taskID]; ok {
+			tasks[i].Watchers = append(tasks[i].Watchers, name)
+		}
+	}
+	return rows.Err()
+}
+
+func normalizeWatcher(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("%w: watcher name must not be empty", ErrValidation)
+	}
+	if utf8.RuneCountInString(name) > maxAssigneeLength {
+		return "", fmt.Errorf("%w: watcher name must be at most %d characters", ErrValidation, maxAssigneeLength)
+	}
+	return name, nil
+}
@@ -0,0 +1,260 @@
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"todo/internal/models"
+	"todo/internal/webhook"
+)
+
+const (
+	// webhookTimeout bounds a single delivery attempt.
+	webhookTimeout = 5 * time.Second
+	// maxDeliveryRetries is how often a failed delivery is retried, backing
+	// off 1s, 2s, 4s, 8s.
+	maxDeliveryRetries = 4
+	// deliveryPollInterval is how often workers look for due retries.
+	deliveryPollInterval = time.Second
+)
+
+// StartWebhookWorkers starts n goroutines delivering queued webhook events
+// until Close is called. Deliveries left pending by a previous run are picked
+// up by the first poll.
+func (s *Store) StartWebhookWorkers(n int) {
+	if n < 1 || s.queue != nil {
+		return
+	}
+	s.queue = make(chan int64, 256)
+	s.stop = make(chan struct{})
+
+	for i := 0; i < n; i++ {
+		s.workers.Add(1)
+		go func() {
+			defer s.workers.Done()
+			for {
+				select {
+				case <-s.stop:
+					return
+				case id := <-s.queue:
+					s.attemptDelivery(id)
+				}
+			}
+		}()
+	}
+
+	s.workers.Add(1)
+	go func() {
+		defer s.workers.Done()
+		ticker := time.NewTicker(deliveryPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				pending, err := s.ListPendingDeliveries(context.Background())
+				if err != nil {
+					s.logger.Error("list pending deliveries", slog.String("error", err.Error()))
+					continue
+				}
+				for _, d := range pending {
+					s.enqueueDelivery(d.ID)
+				}
+			}
+		}
+	}()
+}
+
+// stopWebhookWorkers signals the workers and waits for in-flight attempts.
+func (s *Store) stopWebhookWorkers() {
+	if s.stop == nil {
+		return
+	}
+	close(s.stop)
+	s.workers.Wait()
+}
+
+// enqueueDelivery hands a delivery to the workers without blocking; when the
+// queue is full the next poll picks it up instead.
+func (s *Store) enqueueDelivery(id int64) {
+	if s.queue == nil {
+		return
+	}
+	select {
+	case s.queue <- id:
+	default:
+	}
+}
+
+// ListPendingDeliveries returns the deliveries whose next attempt is due.
+func (s *Store) ListPendingDeliveries(ctx context.Context) ([]models.WebhookDelivery, error) {
+	ctx, span := tracer.Start(ctx, "store.ListPendingDeliveries")
+	defer span.End()
+	rows, err := s.db.QueryContext(ctx, `SELECT `+deliveryColumns+` FROM webhook_deliveries
+        WHERE next_retry_at IS NOT NULL AND next_retry_at <= ? ORDER BY next_retry_at, id LIMIT 100`, time.Now().UTC().Format(timestampLayout))
+	if err != nil {
+		return nil, fmt.Errorf("list pending deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+// TestWebhook queues a ping event to a webhook, even an inactive one.
+func (s *Store) TestWebhook(ctx context.Context, id int64) (models.WebhookDelivery, error) {
+	ctx, span := tracer.Start(ctx, "store.TestWebhook")
+	defer span.End()
+	w, err := s.GetWebhook(ctx, id)
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+	var projectID int64
+	if w.ProjectID != nil {
+		projectID = *w.ProjectID
+	}
+	body, err := json.Marshal(models.WebhookEvent{Event: "ping", ProjectID: projectID, Data: w, SentAt: time.Now().UTC()})
+	if err != nil {
+		return models.WebhookDelivery{}, fmt.Errorf("encode webhook payload: %w", err)
+	}
+	deliveryID, err := s.queueDelivery(ctx, w.ID, "ping", body)
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+	return scanDelivery(s.db.QueryRowContext(ctx, `SELECT `+deliveryColumns+` FROM webhook_deliveries WHERE id = ?`, deliveryID))
+}
+
+// SetEventListener registers fn to be called synchronously for every event
+// emitted after a committed mutation. It must be set before serving requests.
+func (s *Store) SetEventListener(fn func(event string, projectID int64, data any)) {
+	s.listener = fn
+}
+
+// emit notifies the event listener and queues deliveries of event to every
+// active webhook subscribed to it. Failures are logged and never affect the
+// mutation that triggered them.
+func (s *Store) emit(ctx context.Context, event string, projectID int64, data any) {
+	if s.listener != nil {
+		s.listener(event, projectID, data)
+	}
+	rows, err := s.db.QueryContext(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE active AND (project_id IS NULL OR project_id = ?)`, projectID)
+	if err != nil {
+		s.logger.Error("load webhooks", slog.String("error", err.Error()))
+		return
+	}
+	var hooks []models.Webhook
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			s.logger.Error("scan webhook", slog.String("error", err.Error()))
+			continue
+		}
+		if w.Subscribed(event) {
+			hooks = append(hooks, w)
+		}
+	}
+	rows.Close()
+	if len(hooks) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(models.WebhookEvent{Event: event, ProjectID: projectID, Data: data, SentAt: time.Now().UTC()})
+	if err != nil {
+		s.logger.Error("encode webhook payload", slog.String("error", err.Error()))
+		return
+	}
+	for _, w := range hooks {
+		if _, err := s.queueDelivery(ctx, w.ID, event, body); err != nil {
+			s.logger.Error("queue webhook delivery", slog.Int64("webhook_id", w.ID), slog.String("error", err.Error()))
+		}
+	}
+}
+
+// queueDelivery records a delivery due immediately and hands it to the workers.
+func (s *Store) queueDelivery(ctx context.Context, webhookID int64, event string, body []byte) (int64, error) {
+	var id int64
+	if err := s.db.QueryRowContext(ctx, `INSERT INTO webhook_deliveries(webhook_id, event, payload, next_retry_at) VALUES(?, ?, ?, ?) RETURNING id`,
+		webhookID, event, string(body), time.Now().UTC().Format(timestampLayout)).Scan(&id); err != nil {
+		return 0, fmt.Errorf("queue delivery: %w", err)
+	}
+	s.enqueueDelivery(id)
+	return id, nil
+}
+
+// attemptDelivery POSTs a queued delivery and records the outcome, scheduling
+// a retry on network errors and non-2xx responses.
+func (s *Store) attemptDelivery(id int64) {
+	ctx := context.Background()
+	// Claiming the row keeps the poller from handing it to a second worker.
+	res, err := s.db.ExecContext(ctx, `UPDATE webhook_deliveries SET next_retry_at = NULL WHERE id = ? AND next_retry_at IS NOT NULL`, id)
+	if err != nil {
+		s.logger.Error("claim delivery", slog.Int64("delivery_id", id), slog.String("error", err.Error()))
+		return
+	}
+	if claimed, err := res.RowsAffected(); err != nil || claimed == 0 {
+		return
+	}
+
+	var (
+		event, payload, url, secret string
+		retries                     int
+	)
+	if err := s.db.QueryRowContext(ctx, `SELECT d.event, d.payload, d.retry_count, w.url, w.secret FROM webhook_deliveries d
+        JOIN webhooks w ON w.id = d.webhook_id WHERE d.id = ?`, id).Scan(&event, &payload, &retries, &url, &secret); err != nil {
+		s.logger.Error("load delivery", slog.Int64("delivery_id", id), slog.String("error", err.Error()))
+		return
+	}
+
+	statusCode, errText := post(url, event, secret, []byte(payload))
+
+	var nextRetry any
+	if errText != "" && retries < maxDeliveryRetries {
+		nextRetry = time.Now().UTC().Add(time.Second << retries).Format(timestampLayout)
+		retries++
+	} else if errText != "" {
+		// Retries exhausted; the last error stays on the record.
+		retries = maxDeliveryRetries
+	}
+	if _, err := s.db.ExecContext(ctx, `UPDATE webhook_deliveries SET status_code = ?, error = ?, retry_count = ?, next_retry_at = ?, delivered_at = CURRENT_TIMESTAMP WHERE id = ?`,
+		statusCode, errText, retries, nextRetry, id); err != nil {
+		s.logger.Error("record webhook delivery", slog.Int64("delivery_id", id), slog.String("error", err.Error()))
+	}
+}
+
+// post sends one signed delivery attempt; a non-empty errText means failure.
+func post(url, event, secret string, body []byte) (statusCode int, errText string) {
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err.Error()
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Todo-Event", event)
+	if secret != "" {
+		req.Header.Set(webhook.SignatureHeader, webhook.Sign(secret, body))
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err.Error()
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Sprintf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, ""
+}
@@ -0,0 +1,83 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"todo/internal/models"
+)
+
+// WriteAudit appends an entry to the audit log. Entries cannot be changed or
+// deleted afterwards; triggers on audit_log refuse it.
+func (s *Store) WriteAudit(ctx context.Context, entry models.AuditEntry) error {
+	ctx, span := tracer.Start(ctx, "store.WriteAudit")
+	defer span.End()
+	var payload any
+	if len(entry.Payload) > 0 {
+		payload = string(entry.Payload)
+	}
+	if _, err := s.db.ExecContext(ctx, `INSERT INTO audit_log(action, entity_type, entity_id, actor_id, actor_ip, payload) VALUES(?, ?, ?, ?, ?, ?)`,
+		entry.Action, entry.EntityType, entry.EntityID, entry.ActorID, entry.ActorIP, payload); err != nil {
+		return fmt.Errorf("write audit: %w", err)
+	}
+	return nil
+}
+
+// ListAudit returns audit entries matching the filter, newest first.
+func (s *Store) ListAudit(ctx context.Context, f models.AuditFilter) ([]models.AuditEntry, error) {
+	ctx, span := tracer.Start(ctx, "store.ListAudit")
+	defer span.End()
+	var (
+		clauses []string
+		args    []any
+	)
+	if f.EntityType != "" {
+		clauses = append(clauses, "entity_type = ?")
+		args = append(args, f.EntityType)
+	}
+	if f.EntityID != 0 {
+		clauses = append(clauses, "entity_id = ?")
+		args = append(args, f.EntityID)
+	}
+	if f.ActorID != 0 {
+		clauses = append(clauses, "actor_id = ?")
+		args = append(args, f.ActorID)
+	}
+	where := ""
+	if len(clauses) > 0 {
+		where = "WHERE " + strings.Join(clauses, " AND ")
+	}
+	args = append(args, f.Limit)
+
+	rows, err := s.db.QueryContext(ctx, `SELECT id, action, entity_type, entity_id, actor_id, actor_ip, payload, created_at
+        FROM audit_log `+where+` ORDER BY id DESC LIMIT ?`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list audit: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		var (
+			e                 models.AuditEntry
+			entityID, actorID sql.NullInt64
+			payload           sql.NullString
+		)
+		if err := rows.Scan(&e.ID, &e.Action, &e.EntityType, &entityID, &actorID, &e.ActorIP, &payload, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan audit: %w", err)
+		}
+		if entityID.Valid {
+			e.EntityID = &entityID.Int64
+		}
+		if actorID.Valid {
+			e.ActorID = &actorID.Int64
+		}
+		if payload.Valid {
+			e.Payload = []byte(payload.String)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,203 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"todo/internal/models"
+)
+
+const checklistColumns = `id, task_id, text, done, position, created_at`
+
+func scanChecklistItem(row rowScanner) (models.ChecklistItem, error) {
+	var item models.ChecklistItem
+	err := row.Scan(&item.ID, &item.TaskID, &item.Text, &item.Done, &item.Position, &item.CreatedAt)
+	return item, err
+}
+
+// ListChecklistItems returns the checklist of a task in display order.
+func (s *Store) ListChecklistItems(ctx context.Context, taskID int64) ([]models.ChecklistItem, error) {
+	ctx, span := tracer.Start(ctx, "store.ListChecklistItems")
+	defer span.End()
+	if _, err := s.GetTask(ctx, taskID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT `+checklistColumns+` FROM checklist_items WHERE task_id = ? ORDER BY position, id`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("list checklist: %w", err)
+	}
+	defer rows.Close()
+
+	items := []models.ChecklistItem{}
+	for rows.Next() {
+		item, err := scanChecklistItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan checklist item: %w", err)
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// CreateChecklistItem appends a step to the checklist of a task.
+func (s *Store) CreateChecklistItem(ctx context.Context, taskID int64, text string) (models.ChecklistItem, error) {
+	ctx, span := tracer.Start(ctx, "store.CreateChecklistItem")
+	defer span.End()
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return models.ChecklistItem{}, fmt.Errorf("checklist text must not be empty")
+	}
+	if _, err := s.GetTask(ctx, taskID); err != nil {
+		return models.ChecklistItem{}, err
+	}
+
+	var position sql.NullInt64
+	if err := s.db.QueryRowContext(ctx, `SELECT MAX(position) FROM checklist_items WHERE task_id = ?`, taskID).Scan(&position); err != nil {
+		return models.ChecklistItem{}, fmt.Errorf("select position: %w", err)
+	}
+	next := int64(0)
+	if position.Valid {
+		next = position.Int64 + 1
+	}
+
+	res, err := s.db.ExecContext(ctx, `INSERT INTO checklist_items(task_id, text, position) VALUES(?, ?, ?)`, taskID, text, next)
+	if err != nil {
+		return models.ChecklistItem{}, fmt.Errorf("insert checklist item: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return models.ChecklistItem{}, fmt.Errorf("checklist item id: %w", err)
+	}
+	return s.GetChecklistItem(ctx, id)
+}
+
+// GetChecklistItem fetches a single checklist item by id.
+func (s *Store) GetChecklistItem(ctx context.Context, id int64) (models.ChecklistItem, error) {
+	ctx, span := tracer.Start(ctx, "store.GetChecklistItem")
+	defer span.End()
+	item, err := scanChecklistItem(s.db.QueryRowContext(ctx, `SELECT `+checklistColumns+` FROM checklist_items WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.ChecklistItem{}, fmt.Errorf("checklist item not found")
+	}
+	if err != nil {
+		return models.ChecklistItem{}, fmt.Errorf("get checklist item: %w", err)
+	}
+	return item, nil
+}
+
+// UpdateChecklistItem renames an item and/or toggles its done flag; nil
+// arguments leave the field unchanged.
+func (s *Store) UpdateChecklistItem(ctx context.Context, id int64, text *string, done *bool) (models.ChecklistItem, error) {
+	ctx, span := tracer.Start(ctx, "store.UpdateChecklistItem")
+	defer span.End()
+	item, err := s.GetChecklistItem(ctx, id)
+	if err != nil {
+		return models.ChecklistItem{}, err
+	}
+	if text != nil {
+		if strings.TrimSpace(*text) == "" {
+			return models.ChecklistItem{}, fmt.Errorf("checklist text must not be empty")
+		}
+		item.Text = strings.TrimSpace(*text)
+	}
+	if done != nil {
+		item.Done = *done
+	}
+
+	if _, err := s.db.ExecContext(ctx, `UPDATE checklist_items SET text = ?, done = ? WHERE id = ?`, item.Text, item.Done, id); err != nil {
+		return models.ChecklistItem{}, fmt.Errorf("update checklist item: %w", err)
+	}
+	return s.GetChecklistItem(ctx, id)
+}
+
+// DeleteChecklistItem removes an item from a checklist.
+func (s *Store) DeleteChecklistItem(ctx context.Context, id int64) error {
+	ctx, span := tracer.Start(ctx, "store.DeleteChecklistItem")
+	defer span.End()
+	res, err := s.db.ExecContext(ctx, `DELETE FROM checklist_items WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("delete checklist item: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("checklist item not found")
+	}
+	return nil
+}
+
+// ReorderChecklistItems sets the order of a task's checklist. ids must list
+// every item of the task exactly once.
+func (s *Store) ReorderChecklistItems(ctx context.Context, taskID int64, ids []int64) ([]models.ChecklistItem, error) {
+	ctx, span := tracer.Start(ctx, "store.ReorderChecklistItems")
+	defer span.End()
+	current, err := s.ListChecklistItems(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) != len(current) {
+		return nil, fmt.Errorf("expected %d checklist item ids, got %d", len(current), len(ids))
+	}
+	known := make(map[int64]bool, len(current))
+	for _, item := range current {
+		known[item.ID] = true
+	}
+	for _, id := range ids {
+		if !known[id] {
+			return nil, fmt.Errorf("checklist item %d does not belong to task", id)
+		}
+		delete(known, id)
+	}
+
+	err = transaction(ctx, s.db, "reorder checklist", func(tx *observedTx) error {
+		for i, id := range ids {
+			if _, err := tx.ExecContext(ctx, `UPDATE checklist_items SET position = ? WHERE id = ?`, i, id); err != nil {
+				return fmt.Errorf("reorder checklist: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.ListChecklistItems(ctx, taskID)
+}
+
+// attachChecklistCounts fills the checklist progress fields of each task.
+func (s *Store) attachChecklistCounts(ctx context.Context, tasks []models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	index, args := taskIndex(tasks)
+	rows, err := s.db.QueryContext(ctx, `SELECT t.id, COUNT(ci.id), COALESCE(SUM(ci.done), 0) FROM tasks t
+        LEFT JOIN checklist_items ci ON ci.task_id = t.id
+        WHERE t.id IN (`+placeholders(len(args))+`)
+        GROUP BY t.id`, args...)
+	if err != nil {
+		return fmt.Errorf("count checklist: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var taskID int64
+		var total, done int
+		if err := rows.Scan(&taskID, &total, &done); err != nil {
+			return fmt.Errorf("scan checklist count: %w", err)
+		}
+		if i, ok := index[taskID]; ok {
+			tasks[i].ChecklistTotal = total
+			tasks[i].ChecklistDone = done
+			if total > 0 {
+				tasks[i].ChecklistPct = float64(done) * 100 / float64(total)
+			}
+		}
+	}
+	return rows.Err()
+}
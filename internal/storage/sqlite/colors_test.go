@@ -0,0 +1,50 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"todo/internal/models"
+)
+
+func TestProjectColorValidation(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	tests := []struct {
+		color   string
+		want    string
+		invalid bool
+	}{
+		{color: "#gg0000", invalid: true},
+		{color: "#12345", invalid: true},
+		{color: "123456", invalid: true},
+		{color: "#1234567", invalid: true},
+		{color: "#123456", want: "#123456"},
+		{color: "#ABCdef", want: "#ABCdef"},
+		{color: "#2ae", want: "#22aaee"},
+		// An empty color picks one from the palette.
+		{color: ""},
+	}
+	for i, tt := range tests {
+		t.Run(tt.color, func(t *testing.T) {
+			p, err := s.CreateProject(ctx, models.Project{Name: "P" + strconv.Itoa(i), Color: tt.color})
+			if tt.invalid {
+				if !errors.Is(err, ErrValidation) {
+					t.Fatalf("err = %v, want ErrValidation", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.want != "" && p.Color != tt.want {
+				t.Fatalf("color = %q, want %q", p.Color, tt.want)
+			}
+			if err := validateHexColor(p.Color); err != nil {
+				t.Fatalf("stored color %q: %v", p.Color, err)
+			}
+		})
+	}
+}
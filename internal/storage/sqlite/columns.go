@@ -0,0 +1,242 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"todo/internal/models"
+)
+
+// CompleteColumn moves every task of a project's status column to the end of
+// the project's first terminal column in board order and returns the moved
+// tasks. The whole column is undone as one operation.
+func (s *Store) CompleteColumn(ctx context.Context, projectID int64, status string) ([]models.Task, error) {
+	ctx, span := tracer.Start(ctx, "store.CompleteColumn")
+	defer span.End()
+	if _, err := s.GetProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+	source, err := lookupStatus(ctx, s.db, projectID, status)
+	if err != nil {
+		return nil, err
+	}
+	if source.IsTerminal {
+		return []models.Task{}, nil
+	}
+	var done string
+	err = s.db.QueryRowContext(ctx, `SELECT name FROM statuses WHERE project_id = ? AND is_terminal ORDER BY display_order, id LIMIT 1`, projectID).Scan(&done)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: project has no terminal status", ErrValidation)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("complete column: %w", err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("complete column: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT id, position, completed_at FROM tasks WHERE project_id = ? AND status = ? AND deleted_at IS NULL ORDER BY position, id`, projectID, status)
+	if err != nil {
+		return nil, fmt.Errorf("complete column: %w", err)
+	}
+	var moved []taskPlacement
+	for rows.Next() {
+		p := taskPlacement{Status: status}
+		if err := rows.Scan(&p.ID, &p.Position, &p.CompletedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan task: %w", err)
+		}
+		moved = append(moved, p)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return nil, err
+	}
+	rows.Close()
+	if len(moved) == 0 {
+		return []models.Task{}, nil
+	}
+
+	var max sql.NullInt64
+	if err := tx.QueryRowContext(ctx, `SELECT MAX(position) FROM tasks WHERE project_id = ? AND status = ? AND deleted_at IS NULL`, projectID, done).Scan(&max); err != nil {
+		return nil, fmt.Errorf("select position: %w", err)
+	}
+	var pos int64
+	if max.Valid {
+		pos = max.Int64 + 1
+	}
+
+	args := make([]any, 0, len(moved))
+	for _, p := range moved {
+		if _, err := tx.ExecContext(ctx, `UPDATE tasks SET status = ?, position = ?, completed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP WHERE id = ?`, done, pos, p.ID); err != nil {
+			return nil, fmt.Errorf("complete column: %w", err)
+		}
+		if err := recordActivity(ctx, tx, p.ID, []fieldChange{{"status", status, done}}); err != nil {
+			return nil, err
+		}
+		if err := logStatus(ctx, tx, p.ID, done); err != nil {
+			return nil, err
+		}
+		args = append(args, p.ID)
+		pos++
+	}
+	if err := recordOperation(ctx, tx, opTaskMove, 0, moved); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("complete column: %w", err)
+	}
+
+	rows, err = s.db.QueryContext(ctx, `SELECT `+taskColumns+` FROM tasks WHERE id IN (`+placeholders(len(args))+`) ORDER BY position`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("load tasks: %w", err)
+	}
+	tasks, err := scanTasks(rows)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.hydrateTasks(ctx, tasks); err != nil {
+		return nil, err
+	}
+	for _, t := range tasks {
+		s.emit(ctx, "task.updated", t.ProjectID, t)
+	}
+	return tasks, nil
+}
+
+// ClearDoneColumn removes every task in a project's done column together with
+// its sub-tasks and returns how many tasks, sub-tasks included, were removed. Tasks go to the trash
+// unless permanent is set, in which case they are deleted outright.
+func (s *Store) ClearDoneColumn(ctx context.Context, projectID int64, permanent bool) (int64, error) {
+	ctx, span := tracer.Start(ctx, "store.ClearDoneColumn")
+	defer span.End()
+	if _, err := s.GetProject(ctx, projectID); err != nil {
+		return 0, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("clear column: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT `+taskColumns+` FROM tasks WHERE project_id = ? AND status = 'done' AND deleted_at IS NULL ORDER BY position, id`, projectID)
+	if err != nil {
+		return 0, fmt.Errorf("clear column: %w", err)
+	}
+	cleared, err := scanTasks(rows)
+	if err != nil {
+		return 0, err
+	}
+	if len(cleared) == 0 {
+		return 0, nil
+	}
+
+	// Sub-tasks go first so no live row is left pointing at a removed parent.
+	const done = `SELECT id FROM tasks WHERE project_id = ? AND status = 'done' AND deleted_at IS NULL`
+	stmts := []string{
+		`DELETE FROM tasks WHERE parent_id IN (` + done + `)`,
+		`DELETE FROM tasks WHERE id IN (` + done + `)`,
+	}
+	args := []any{projectID}
+	if !permanent {
+		stmts = []string{
+			`UPDATE tasks SET deleted_at = ? WHERE parent_id IN (` + done + `) AND deleted_at IS NULL`,
+			`UPDATE tasks SET deleted_at = ? WHERE id IN (` + done + `)`,
+		}
+		args = []any{time.Now().UTC(), projectID}
+	}
+	var affected int64
+	for _, stmt := range stmts {
+		res, err := tx.ExecContext(ctx, stmt, args...)
+		if err != nil {
+			return 0, fmt.Errorf("clear column: %w", err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		affected += n
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("clear column: %w", err)
+	}
+	for _, t := range cleared {
+		s.emit(ctx, "task.deleted", t.ProjectID, t)
+	}
+	return affected, nil
+}
+
+// GetBoard returns a project with its statuses and its visible tasks grouped
+// by status column. Snoozed tasks are left out, as on the board.
+func (s *Store) GetBoard(ctx context.Context, projectID int64) (models.Board, error) {
+	ctx, span := tracer.Start(ctx, "store.GetBoard")
+	defer span.End()
+	project, err := s.GetProject(ctx, projectID)
+	if err != nil {
+		return models.Board{}, err
+	}
+	statuses, err := projectStatuses(ctx, s.db, projectID)
+	if err != nil {
+		return models.Board{}, err
+	}
+	tasks, err := s.ListTasks(ctx, projectID)
+	if err != nil {
+		return models.Board{}, err
+	}
+	counts, err := s.CountTasksByStatus(ctx, projectID)
+	if err != nil {
+		return models.Board{}, err
+	}
+	board := models.Board{Project: project, Statuses: statuses, Columns: make(map[string][]models.Task, len(statuses)), Counts: counts}
+	for _, st := range statuses {
+		board.Columns[st.Name] = []models.Task{}
+	}
+	for _, task := range tasks {
+		board.Columns[task.Status] = append(board.Columns[task.Status], task)
+	}
+	return board, nil
+}
+
+// CountTasksByStatus returns the number of live tasks of a project in every
+// status column, including snoozed tasks and zero counts.
+func (s *Store) CountTasksByStatus(ctx context.Context, projectID int64) (map[string]int, error) {
+	ctx, span := tracer.Start(ctx, "store.CountTasksByStatus")
+	defer span.End()
+	if _, err := s.GetProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+	statuses, err := projectStatuses(ctx, s.db, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT status, COUNT(*) FROM tasks
+        WHERE project_id = ? AND deleted_at IS NULL GROUP BY status`, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("count tasks: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int, len(statuses))
+	for _, st := range statuses {
+		counts[st.Name] = 0
+	}
+	for rows.Next() {
+		var (
+			status string
+			n      int
+		)
+		if err := rows.Scan(&status, &n); err != nil {
+			return nil, fmt.Errorf("scan task count: %w", err)
+		}
+		counts[status] = n
+	}
+	return counts, rows.Err()
+}
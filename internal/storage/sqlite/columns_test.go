@@ -0,0 +1,59 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	"todo/internal/models"
+)
+
+func TestCountTasksByStatus(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	p, err := s.CreateProject(ctx, models.Project{Name: "P"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CreateStatus(ctx, p.ID, StatusInput{Name: "review"}); err != nil {
+		t.Fatal(err)
+	}
+	check := func(step string, want map[string]int) {
+		t.Helper()
+		got, err := s.CountTasksByStatus(ctx, p.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("%s: counts = %v, want %v", step, got, want)
+		}
+		for status, n := range want {
+			if got[status] != n {
+				t.Fatalf("%s: counts = %v, want %v", step, got, want)
+			}
+		}
+	}
+	check("empty", map[string]int{"todo": 0, "in_progress": 0, "done": 0, "review": 0})
+
+	var tasks []models.Task
+	for i := 0; i < 3; i++ {
+		task, err := s.CreateTask(ctx, models.Task{ProjectID: p.ID, Title: "t"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		tasks = append(tasks, task)
+	}
+	check("created", map[string]int{"todo": 3, "in_progress": 0, "done": 0, "review": 0})
+
+	if _, err := s.MoveTask(ctx, tasks[0].ID, "review", 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.UpdateTask(ctx, tasks[1].ID, map[string]any{"status": "done"}); err != nil {
+		t.Fatal(err)
+	}
+	check("moved", map[string]int{"todo": 1, "in_progress": 0, "done": 1, "review": 1})
+
+	if err := s.DeleteTask(ctx, tasks[2].ID); err != nil {
+		t.Fatal(err)
+	}
+	check("deleted", map[string]int{"todo": 0, "in_progress": 0, "done": 1, "review": 1})
+}
@@ -0,0 +1,138 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+func TestListAllTasks(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	alpha, err := s.CreateProject(ctx, models.Project{Name: "Alpha", Color: "#112233"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	beta, err := s.CreateProject(ctx, models.Project{Name: "Beta"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	gone, err := s.CreateProject(ctx, models.Project{Name: "Gone"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	create := func(projectID int64, title, status string) models.Task {
+		t.Helper()
+		task, err := s.CreateTask(ctx, models.Task{ProjectID: projectID, Title: title, Status: status})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return task
+	}
+	a1 := create(alpha.ID, "Fix login", "todo")
+	a2 := create(alpha.ID, "Write 100% coverage", "done")
+	b1 := create(beta.ID, "Login page copy", "in_progress")
+	trashed := create(beta.ID, "Old login", "todo")
+	create(gone.ID, "Login in trashed project", "todo")
+	if err := s.DeleteTask(ctx, trashed.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteProject(ctx, gone.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		filter    models.GlobalTaskFilter
+		want      []int64
+		wantTotal int
+	}{
+		{"all", models.GlobalTaskFilter{}, []int64{b1.ID, a2.ID, a1.ID}, 3},
+		{"status", models.GlobalTaskFilter{Statuses: []string{"todo", "done"}}, []int64{a2.ID, a1.ID}, 2},
+		{"project", models.GlobalTaskFilter{ProjectID: &beta.ID}, []int64{b1.ID}, 1},
+		{"text", models.GlobalTaskFilter{Query: "LOGIN"}, []int64{b1.ID, a1.ID}, 2},
+		{"literal wildcard", models.GlobalTaskFilter{Query: "100%"}, []int64{a2.ID}, 1},
+		{"first page", models.GlobalTaskFilter{Limit: 2}, []int64{b1.ID, a2.ID}, 3},
+		{"second page", models.GlobalTaskFilter{Limit: 2, Offset: 2}, []int64{a1.ID}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.filter.Limit == 0 {
+				tt.filter.Limit = MaxGlobalTaskPage
+			}
+			tasks, total, err := s.ListAllTasks(ctx, tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []int64
+			for _, task := range tasks {
+				got = append(got, task.ID)
+			}
+			if total != tt.wantTotal || len(got) != len(tt.want) {
+				t.Fatalf("got %v of %d, want %v of %d", got, total, tt.want, tt.wantTotal)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	tasks, _, err := s.ListAllTasks(ctx, models.GlobalTaskFilter{ProjectID: &alpha.ID, Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tasks[0].ProjectName != "Alpha" || tasks[0].ProjectColor != "#112233" {
+		t.Fatalf("annotated with %q %q, want Alpha #112233", tasks[0].ProjectName, tasks[0].ProjectColor)
+	}
+
+	for _, filter := range []models.GlobalTaskFilter{
+		{Limit: 0},
+		{Limit: MaxGlobalTaskPage + 1},
+		{Limit: 1, Offset: -1},
+		{Limit: 1, Statuses: []string{"nope"}},
+	} {
+		if _, _, err := s.ListAllTasks(ctx, filter); !errors.Is(err, ErrValidation) {
+			t.Errorf("filter %+v: err = %v, want ErrValidation", filter, err)
+		}
+	}
+}
+
+func TestListAllTasksOnlyShowsMemberProjects(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	mine, err := s.CreateProject(ctx, models.Project{Name: "Mine"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := s.CreateProject(ctx, models.Project{Name: "Other"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	task, err := s.CreateTask(ctx, models.Task{ProjectID: mine.ID, Title: "visible"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CreateTask(ctx, models.Task{ProjectID: other.ID, Title: "hidden"}); err != nil {
+		t.Fatal(err)
+	}
+	user, err := s.CreateUser(ctx, "bob", "secret123", "", "member")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.AddProjectMember(ctx, mine.ID, user.ID, models.ProjectRoleViewer); err != nil {
+		t.Fatal(err)
+	}
+
+	tasks, total, err := s.ListAllTasks(storage.WithProjectMember(ctx, user.ID), models.GlobalTaskFilter{Limit: MaxGlobalTaskPage})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 || len(tasks) != 1 || tasks[0].ID != task.ID {
+		t.Fatalf("member sees %d of %d tasks, want only task %d", len(tasks), total, task.ID)
+	}
+}
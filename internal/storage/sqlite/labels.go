@@ -0,0 +1,197 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"todo/internal/models"
+)
+
+const labelColumns = `id, project_id, name, color, created_at, updated_at`
+
+func scanLabel(row rowScanner) (models.Label, error) {
+	var l models.Label
+	err := row.Scan(&l.ID, &l.ProjectID, &l.Name, &l.Color, &l.CreatedAt, &l.UpdatedAt)
+	return l, err
+}
+
+// ListLabels returns the labels defined for a project ordered by name.
+func (s *Store) ListLabels(ctx context.Context, projectID int64) ([]models.Label, error) {
+	ctx, span := tracer.Start(ctx, "store.ListLabels")
+	defer span.End()
+	if _, err := s.GetProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT `+labelColumns+` FROM labels WHERE project_id = ? ORDER BY name, id`, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("list labels: %w", err)
+	}
+	defer rows.Close()
+
+	labels := []models.Label{}
+	for rows.Next() {
+		l, err := scanLabel(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan label: %w", err)
+		}
+		labels = append(labels, l)
+	}
+	return labels, rows.Err()
+}
+
+// CreateLabel adds a label to a project with optional color.
+func (s *Store) CreateLabel(ctx context.Context, projectID int64, name, color string) (models.Label, error) {
+	ctx, span := tracer.Start(ctx, "store.CreateLabel")
+	defer span.End()
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return models.Label{}, fmt.Errorf("label name must not be empty")
+	}
+	if color == "" {
+		color = randomPaletteColor()
+	}
+	if _, err := s.GetProject(ctx, projectID); err != nil {
+		return models.Label{}, err
+	}
+
+	res, err := s.db.ExecContext(ctx, `INSERT INTO labels(project_id, name, color) VALUES(?, ?, ?)`, projectID, name, color)
+	if err != nil {
+		return models.Label{}, fmt.Errorf("insert label: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return models.Label{}, fmt.Errorf("label id: %w", err)
+	}
+	return s.GetLabel(ctx, id)
+}
+
+// GetLabel fetches a single label by id.
+func (s *Store) GetLabel(ctx context.Context, id int64) (models.Label, error) {
+	ctx, span := tracer.Start(ctx, "store.GetLabel")
+	defer span.End()
+	l, err := scanLabel(s.db.QueryRowContext(ctx, `SELECT `+labelColumns+` FROM labels WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Label{}, fmt.Errorf("label not found")
+	}
+	if err != nil {
+		return models.Label{}, fmt.Errorf("get label: %w", err)
+	}
+	return l, nil
+}
+
+// UpdateLabel renames a label and optionally changes its color.
+func (s *Store) UpdateLabel(ctx context.Context, id int64, name, color string) (models.Label, error) {
+	ctx, span := tracer.Start(ctx, "store.UpdateLabel")
+	defer span.End()
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return models.Label{}, fmt.Errorf("label name must not be empty")
+	}
+	current, err := s.GetLabel(ctx, id)
+	if err != nil {
+		return models.Label{}, err
+	}
+	if color == "" {
+		color = current.Color
+	}
+
+	if _, err := s.db.ExecContext(ctx, `UPDATE labels SET name = ?, color = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`, name, color, id); err != nil {
+		return models.Label{}, fmt.Errorf("update label: %w", err)
+	}
+	return s.GetLabel(ctx, id)
+}
+
+// DeleteLabel removes a label and detaches it from all tasks.
+func (s *Store) DeleteLabel(ctx context.Context, id int64) error {
+	ctx, span := tracer.Start(ctx, "store.DeleteLabel")
+	defer span.End()
+	res, err := s.db.ExecContext(ctx, `DELETE FROM labels WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("delete label: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("label not found")
+	}
+	return nil
+}
+
+// AddTaskLabel attaches a label of the same project to a task.
+func (s *Store) AddTaskLabel(ctx context.Context, taskID, labelID int64) (models.Task, error) {
+	ctx, span := tracer.Start(ctx, "store.AddTaskLabel")
+	defer span.End()
+	task, err := s.GetTask(ctx, taskID)
+	if err != nil {
+		return models.Task{}, err
+	}
+	label, err := s.GetLabel(ctx, labelID)
+	if err != nil {
+		return models.Task{}, err
+	}
+	if label.ProjectID != task.ProjectID {
+		return models.Task{}, fmt.Errorf("label belongs to another project")
+	}
+
+	if _, err := s.db.ExecContext(ctx, `INSERT OR IGNORE INTO task_labels(task_id, label_id) VALUES(?, ?)`, taskID, labelID); err != nil {
+		return models.Task{}, fmt.Errorf("add task label: %w", err)
+	}
+	return s.GetTask(ctx, taskID)
+}
+
+// RemoveTaskLabel detaches a label from a task.
+func (s *Store) RemoveTaskLabel(ctx context.Context, taskID, labelID int64) (models.Task, error) {
+	ctx, span := tracer.Start(ctx, "store.RemoveTaskLabel")
+	defer span.End()
+	if _, err := s.GetTask(ctx, taskID); err != nil {
+		return models.Task{}, err
+	}
+
+	res, err := s.db.ExecContext(ctx, `DELETE FROM task_labels WHERE task_id = ? AND label_id = ?`, taskID, labelID)
+	if err != nil {
+		return models.Task{}, fmt.Errorf("remove task label: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return models.Task{}, err
+	}
+	if affected == 0 {
+		return models.Task{}, fmt.Errorf("label not attached to task")
+	}
+	return s.GetTask(ctx, taskID)
+}
+
+// attachLabels fills the Labels field of each task using a single query.
+func (s *Store) attachLabels(ctx context.Context, tasks []models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	index, args := taskIndex(tasks)
+	for i := range tasks {
+		tasks[i].Labels = []int64{}
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT task_id, label_id FROM task_labels WHERE task_id IN (`+placeholders(len(args))+`) ORDER BY label_id`, args...)
+	if err != nil {
+		return fmt.Errorf("load task labels: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var taskID, labelID int64
+		if err := rows.Scan(&taskID, &labelID); err != nil {
+			return fmt.Errorf("scan task label: %w", err)
+		}
+		if i, ok := index[taskID]; ok {
+			tasks[i].Labels = append(tasks[i].Labels, labelID)
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,87 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"todo/internal/models"
+)
+
+const (
+	maxTaskLinks       = 50
+	maxLinkTitleLength = 200
+)
+
+// isHTTPURL reports whether raw is an absolute http(s) URL.
+func isHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// normalizeLinks trims and validates the links of a task.
+func normalizeLinks(links []models.TaskLink) ([]models.TaskLink, error) {
+	if len(links) > maxTaskLinks {
+		return nil, fmt.Errorf("%w: a task may have at most %d links", ErrValidation, maxTaskLinks)
+	}
+	out := make([]models.TaskLink, 0, len(links))
+	for _, l := range links {
+		l.URL = strings.TrimSpace(l.URL)
+		l.Title = strings.TrimSpace(l.Title)
+		if !isHTTPURL(l.URL) {
+			return nil, fmt.Errorf("%w: link %q must be an absolute http(s) URL", ErrValidation, l.URL)
+		}
+		if utf8.RuneCountInString(l.Title) > maxLinkTitleLength {
+			return nil, fmt.Errorf("%w: link title must be at most %d characters", ErrValidation, maxLinkTitleLength)
+		}
+		out = append(out, l)
+	}
+	return out, nil
+}
+
+// replaceLinks stores links as the complete, ordered link list of a task.
+func replaceLinks(ctx context.Context, tx *observedTx, taskID int64, links []models.TaskLink) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM task_links WHERE task_id = ?`, taskID); err != nil {
+		return fmt.Errorf("clear links: %w", err)
+	}
+	for i, l := range links {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO task_links(task_id, position, url, title) VALUES(?, ?, ?, ?)`, taskID, i, l.URL, l.Title); err != nil {
+			return fmt.Errorf("save link: %w", err)
+		}
+	}
+	return nil
+}
+
+// attachLinks fills the Links field of each task using a single query.
+func (s *Store) attachLinks(ctx context.Context, tasks []models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	index, args := taskIndex(tasks)
+	for i := range tasks {
+		tasks[i].Links = []models.TaskLink{}
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT task_id, url, title FROM task_links WHERE task_id IN (`+placeholders(len(args))+`) ORDER BY task_id, position`, args...)
+	if err != nil {
+		return fmt.Errorf("load task links: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			taskID int64
+			l      models.TaskLink
+		)
+		if err := rows.Scan(&taskID, &l.URL, &l.Title); err != nil {
+			return fmt.Errorf("scan task link: %w", err)
+		}
+		if i, ok := index[taskID]; ok {
+			tasks[i].Links = append(tasks[i].Links, l)
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,96 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+
+	"todo/internal/models"
+)
+
+// DBStats returns the connection pool statistics of the underlying database.
+func (s *Store) DBStats() sql.DBStats {
+	return s.db.DB.Stats()
+}
+
+// DatabaseSize returns the size of the database file in bytes, computed from
+// the page count and page size.
+func (s *Store) DatabaseSize(ctx context.Context) (int64, error) {
+	ctx, span := tracer.Start(ctx, "store.DatabaseSize")
+	defer span.End()
+	var pages, pageSize int64
+	if err := s.db.QueryRowContext(ctx, `PRAGMA page_count`).Scan(&pages); err != nil {
+		return 0, fmt.Errorf("read page count: %w", err)
+	}
+	if err := s.db.QueryRowContext(ctx, `PRAGMA page_size`).Scan(&pageSize); err != nil {
+		return 0, fmt.Errorf("read page size: %w", err)
+	}
+	return pages * pageSize, nil
+}
+
+// DatabaseFileSize returns the size in bytes of the database file on disk,
+// excluding any journal or WAL file.
+func (s *Store) DatabaseFileSize() (int64, error) {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return 0, fmt.Errorf("stat database: %w", err)
+	}
+	return info.Size(), nil
+}
+
+// GetAdminStats counts the live projects and tasks, the latter per status
+// across all projects, and reports the file size and schema version.
+func (s *Store) GetAdminStats(ctx context.Context) (models.AdminStats, error) {
+	ctx, span := tracer.Start(ctx, "store.GetAdminStats")
+	defer span.End()
+	stats := models.AdminStats{TasksByStatus: map[string]int64{}}
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM projects WHERE deleted_at IS NULL`).Scan(&stats.Projects); err != nil {
+		return models.AdminStats{}, fmt.Errorf("count projects: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT t.status, COUNT(*) FROM tasks t
+        JOIN projects p ON p.id = t.project_id AND p.deleted_at IS NULL
+        WHERE t.deleted_at IS NULL GROUP BY t.status`)
+	if err != nil {
+		return models.AdminStats{}, fmt.Errorf("count tasks: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			status string
+			count  int64
+		)
+		if err := rows.Scan(&status, &count); err != nil {
+			return models.AdminStats{}, fmt.Errorf("scan task count: %w", err)
+		}
+		stats.TasksByStatus[status] = count
+		stats.Tasks += count
+	}
+	if err := rows.Err(); err != nil {
+		return models.AdminStats{}, err
+	}
+
+	if stats.SchemaVersion, err = s.MigrationVersion(ctx); err != nil {
+		return models.AdminStats{}, err
+	}
+	if stats.DatabaseSizeBytes, err = s.DatabaseFileSize(); err != nil {
+		return models.AdminStats{}, err
+	}
+	return stats, nil
+}
+
+// VacuumDB rebuilds the database file to reclaim free pages and then refreshes
+// the query planner statistics. VACUUM cannot run inside a transaction, so it
+// goes straight to the pool.
+func (s *Store) VacuumDB(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "store.VacuumDB")
+	defer span.End()
+	if _, err := s.db.ExecContext(ctx, `VACUUM`); err != nil {
+		return fmt.Errorf("vacuum database: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, `PRAGMA optimize`); err != nil {
+		return fmt.Errorf("optimize database: %w", err)
+	}
+	return nil
+}
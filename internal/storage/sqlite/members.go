@@ -0,0 +1,179 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+// memberScope returns a condition, starting with AND, restricting the
+// project id in column to the projects of the member set by
+// storage.WithProjectMember. It is empty when no member is set.
+func memberScope(ctx context.Context, column string) (string, []any) {
+	userID, ok := storage.ProjectMember(ctx)
+	if !ok {
+		return "", nil
+	}
+	return ` AND ` + column + ` IN (SELECT project_id FROM project_members WHERE user_id = ?)`, []any{userID}
+}
+
+// ListProjectMembers returns the members of a project ordered by username.
+func (s *Store) ListProjectMembers(ctx context.Context, projectID int64) ([]models.ProjectMember, error) {
+	ctx, span := tracer.Start(ctx, "store.ListProjectMembers")
+	defer span.End()
+	if _, err := s.GetProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT m.project_id, m.user_id, u.username, m.role, m.created_at
+        FROM project_members m JOIN users u ON u.id = m.user_id
+        WHERE m.project_id = ? ORDER BY u.username, u.id`, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("list members: %w", err)
+	}
+	defer rows.Close()
+
+	members := []models.ProjectMember{}
+	for rows.Next() {
+		var m models.ProjectMember
+		if err := rows.Scan(&m.ProjectID, &m.UserID, &m.Username, &m.Role, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan member: %w", err)
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
+
+// AddProjectMember gives a user a role in a project, replacing any role the
+// user already had there.
+func (s *Store) AddProjectMember(ctx context.Context, projectID, userID int64, role string) (models.ProjectMember, error) {
+	ctx, span := tracer.Start(ctx, "store.AddProjectMember")
+	defer span.End()
+	if _, ok := models.ValidProjectRoles[role]; !ok {
+		return models.ProjectMember{}, fmt.Errorf("%w: invalid role %q", ErrValidation, role)
+	}
+	if _, err := s.GetProject(ctx, projectID); err != nil {
+		return models.ProjectMember{}, err
+	}
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return models.ProjectMember{}, fmt.Errorf("%w: %v", ErrValidation, err)
+	}
+
+	err = transaction(ctx, s.db, "add member", func(tx *observedTx) error {
+		if role != models.ProjectRoleAdmin {
+			if err := checkOtherProjectAdmin(ctx, tx, projectID, userID); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO project_members(project_id, user_id, role) VALUES(?, ?, ?)
+            ON CONFLICT(project_id, user_id) DO UPDATE SET role = excluded.role`, projectID, userID, role); err != nil {
+			return fmt.Errorf("add member: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return models.ProjectMember{}, err
+	}
+
+	m := models.ProjectMember{ProjectID: projectID, UserID: userID, Username: user.Username}
+	if err := s.db.QueryRowContext(ctx, `SELECT role, created_at FROM project_members WHERE project_id = ? AND user_id = ?`, projectID, userID).
+		Scan(&m.Role, &m.CreatedAt); err != nil {
+		return models.ProjectMember{}, fmt.Errorf("get member: %w", err)
+	}
+	return m, nil
+}
+
+// RemoveProjectMember takes a user out of a project. The project's last
+// admin cannot be removed.
+func (s *Store) RemoveProjectMember(ctx context.Context, projectID, userID int64) error {
+	ctx, span := tracer.Start(ctx, "store.RemoveProjectMember")
+	defer span.End()
+	return transaction(ctx, s.db, "remove member", func(tx *observedTx) error {
+		if err := checkOtherProjectAdmin(ctx, tx, projectID, userID); err != nil {
+			return err
+		}
+		res, err := tx.ExecContext(ctx, `DELETE FROM project_members WHERE project_id = ? AND user_id = ?`, projectID, userID)
+		if err != nil {
+			return fmt.Errorf("remove member: %w", err)
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return fmt.Errorf("member not found")
+		}
+		return nil
+	})
+}
+
+// ProjectRole returns the role of a user in a project, or "" when the user
+// is not a member.
+func (s *Store) ProjectRole(ctx context.Context, projectID, userID int64) (string, error) {
+	ctx, span := tracer.Start(ctx, "store.ProjectRole")
+	defer span.End()
+	var role string
+	err := s.db.QueryRowContext(ctx, `SELECT role FROM project_members WHERE project_id = ? AND user_id = ?`, projectID, userID).Scan(&role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("project role: %w", err)
+	}
+	return role, nil
+}
+
+// resourceProjectQueries look up the project owning a resource by id. Tasks
+// in the trash are included so they can still be restored.
+var resourceProjectQueries = map[string]string{
+	"task":       `SELECT project_id FROM tasks WHERE id = ?`,
+	"label":      `SELECT project_id FROM labels WHERE id = ?`,
+	"status":     `SELECT project_id FROM statuses WHERE id = ?`,
+	"sprint":     `SELECT project_id FROM sprints WHERE id = ?`,
+	"milestone":  `SELECT project_id FROM milestones WHERE id = ?`,
+	"webhook":    `SELECT project_id FROM webhooks WHERE id = ?`,
+	"comment":    `SELECT t.project_id FROM comments c JOIN tasks t ON t.id = c.task_id WHERE c.id = ?`,
+	"checklist":  `SELECT t.project_id FROM checklist_items i JOIN tasks t ON t.id = i.task_id WHERE i.id = ?`,
+	"time-entry": `SELECT t.project_id FROM time_entries e JOIN tasks t ON t.id = e.task_id WHERE e.id = ?`,
+}
+
+// ResourceProjectID returns the project a resource of the given kind
+// belongs to; see resourceProjectQueries for the kinds.
+func (s *Store) ResourceProjectID(ctx context.Context, kind string, id int64) (int64, error) {
+	ctx, span := tracer.Start(ctx, "store.ResourceProjectID")
+	defer span.End()
+	query, ok := resourceProjectQueries[kind]
+	if !ok {
+		return 0, fmt.Errorf("unknown resource %q", kind)
+	}
+	var projectID int64
+	err := s.db.QueryRowContext(ctx, query, id).Scan(&projectID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("%s not found", kind)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("resource project: %w", err)
+	}
+	return projectID, nil
+}
+
+// checkOtherProjectAdmin refuses to take the admin role in a project away
+// from userID when no other member is an admin of it.
+func checkOtherProjectAdmin(ctx context.Context, q queryer, projectID, userID int64) error {
+	var isAdmin, others bool
+	if err := q.QueryRowContext(ctx, `SELECT
+            EXISTS(SELECT 1 FROM project_members WHERE project_id = ? AND user_id = ? AND role = 'admin'),
+            EXISTS(SELECT 1 FROM project_members WHERE project_id = ? AND user_id != ? AND role = 'admin')`,
+		projectID, userID, projectID, userID).Scan(&isAdmin, &others); err != nil {
+		return fmt.Errorf("check project admins: %w", err)
+	}
+	if isAdmin && !others {
+		return fmt.Errorf("%w: a project needs at least one admin", ErrConflict)
+	}
+	return nil
+}
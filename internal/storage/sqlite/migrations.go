@@ -0,0 +1,512 @@
+package sqlite
+
+// Migration is one versioned schema change. Migrations run in order of
+// Version, each in its own transaction, and are recorded in
+// schema_migrations so they are applied only once. Released migrations must
+// never change; append new ones with the next version.
+type Migration struct {
+	Version int
+	Up      string
+}
+
+// migrations lists every schema change in the order it is applied. The
+// statements are written to be harmless on databases that were migrated
+// before versions were tracked.
+var migrations = []Migration{
+	{1, `CREATE TABLE IF NOT EXISTS projects (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            name TEXT NOT NULL UNIQUE,
+            color TEXT NOT NULL DEFAULT '#2563eb',
+            created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+        );`},
+	{2, `CREATE TABLE IF NOT EXISTS tasks (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            project_id INTEGER NOT NULL,
+            title TEXT NOT NULL,
+            description TEXT NOT NULL DEFAULT '',
+            status TEXT NOT NULL DEFAULT 'todo',
+            position INTEGER NOT NULL DEFAULT 0,
+            created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            FOREIGN KEY(project_id) REFERENCES projects(id) ON DELETE CASCADE
+        );`},
+	{3, `CREATE TABLE IF NOT EXISTS users (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            username TEXT NOT NULL UNIQUE,
+            password_hash TEXT NOT NULL,
+            role TEXT NOT NULL DEFAULT 'member',
+            created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+        );`},
+	{4, `CREATE TABLE IF NOT EXISTS settings (
+            key TEXT PRIMARY KEY,
+            value TEXT NOT NULL DEFAULT '',
+            updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+        );`},
+	{5, `CREATE TABLE IF NOT EXISTS labels (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            project_id INTEGER NOT NULL,
+            name TEXT NOT NULL,
+            color TEXT NOT NULL DEFAULT '#2563eb',
+            created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            UNIQUE(project_id, name),
+            FOREIGN KEY(project_id) REFERENCES projects(id) ON DELETE CASCADE
+        );`},
+	{6, `CREATE TABLE IF NOT EXISTS task_labels (
+            task_id INTEGER NOT NULL,
+            label_id INTEGER NOT NULL,
+            PRIMARY KEY(task_id, label_id),
+            FOREIGN KEY(task_id) REFERENCES tasks(id) ON DELETE CASCADE,
+            FOREIGN KEY(label_id) REFERENCES labels(id) ON DELETE CASCADE
+        );`},
+	{7, `CREATE TABLE IF NOT EXISTS comments (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            task_id INTEGER NOT NULL,
+            author TEXT NOT NULL DEFAULT '',
+            body TEXT NOT NULL,
+            created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            FOREIGN KEY(task_id) REFERENCES tasks(id) ON DELETE CASCADE
+        );`},
+	{8, `CREATE TABLE IF NOT EXISTS checklist_items (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            task_id INTEGER NOT NULL,
+            text TEXT NOT NULL,
+            done BOOLEAN NOT NULL DEFAULT 0,
+            position INTEGER NOT NULL DEFAULT 0,
+            created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            FOREIGN KEY(task_id) REFERENCES tasks(id) ON DELETE CASCADE
+        );`},
+	{9, `CREATE TABLE IF NOT EXISTS time_entries (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            task_id INTEGER NOT NULL,
+            started_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            ended_at DATETIME,
+            note TEXT NOT NULL DEFAULT '',
+            FOREIGN KEY(task_id) REFERENCES tasks(id) ON DELETE CASCADE
+        );`},
+	{10, `CREATE TABLE IF NOT EXISTS task_dependencies (
+            blocker_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+            blocked_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+            created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            PRIMARY KEY(blocker_id, blocked_id)
+        );`},
+	{11, `CREATE TABLE IF NOT EXISTS task_templates (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            project_id INTEGER,
+            title TEXT NOT NULL,
+            description TEXT NOT NULL DEFAULT '',
+            status TEXT NOT NULL DEFAULT 'todo',
+            created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            FOREIGN KEY(project_id) REFERENCES projects(id) ON DELETE CASCADE
+        );`},
+	{12, `CREATE TABLE IF NOT EXISTS sprints (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            project_id INTEGER NOT NULL,
+            name TEXT NOT NULL,
+            goal TEXT NOT NULL DEFAULT '',
+            starts_at DATE,
+            ends_at DATE,
+            status TEXT NOT NULL DEFAULT 'planning',
+            created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            FOREIGN KEY(project_id) REFERENCES projects(id) ON DELETE CASCADE
+        );`},
+	{13, `CREATE TABLE IF NOT EXISTS activity_log (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            task_id INTEGER NOT NULL,
+            field TEXT NOT NULL,
+            old_value TEXT NOT NULL DEFAULT '',
+            new_value TEXT NOT NULL DEFAULT '',
+            changed_by TEXT NOT NULL DEFAULT '',
+            changed_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            FOREIGN KEY(task_id) REFERENCES tasks(id) ON DELETE CASCADE
+        );`},
+	{14, `CREATE TABLE IF NOT EXISTS task_watchers (
+            task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+            name TEXT NOT NULL,
+            created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            PRIMARY KEY(task_id, name)
+        );`},
+	{15, `CREATE TABLE IF NOT EXISTS task_mentions (
+            task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+            name TEXT NOT NULL COLLATE NOCASE,
+            created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            PRIMARY KEY(task_id, name)
+        );`},
+	{16, `CREATE TABLE IF NOT EXISTS task_fields (
+            task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+            key TEXT NOT NULL,
+            value TEXT NOT NULL DEFAULT '',
+            PRIMARY KEY(task_id, key)
+        );`},
+	{17, `CREATE TABLE IF NOT EXISTS webhooks (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            project_id INTEGER REFERENCES projects(id) ON DELETE CASCADE,
+            url TEXT NOT NULL,
+            events TEXT NOT NULL DEFAULT '',
+            secret TEXT NOT NULL DEFAULT '',
+            active BOOLEAN NOT NULL DEFAULT 1,
+            created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+        );`},
+	{18, `CREATE TABLE IF NOT EXISTS webhook_deliveries (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            webhook_id INTEGER NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+            event TEXT NOT NULL,
+            status_code INTEGER NOT NULL DEFAULT 0,
+            error TEXT NOT NULL DEFAULT '',
+            delivered_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+        );`},
+	{19, `CREATE TABLE IF NOT EXISTS task_links (
+            task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+            position INTEGER NOT NULL,
+            url TEXT NOT NULL,
+            title TEXT NOT NULL DEFAULT '',
+            PRIMARY KEY(task_id, position)
+        );`},
+	{20, `CREATE TABLE IF NOT EXISTS task_revisions (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+            title TEXT NOT NULL,
+            description TEXT NOT NULL DEFAULT '',
+            created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+        );`},
+	{21, `CREATE TABLE IF NOT EXISTS task_reactions (
+            task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+            emoji TEXT NOT NULL,
+            author TEXT NOT NULL,
+            created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            PRIMARY KEY(task_id, emoji, author)
+        );`},
+	{22, `CREATE TABLE IF NOT EXISTS operations (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            session TEXT NOT NULL DEFAULT '',
+            kind TEXT NOT NULL,
+            entity_id INTEGER NOT NULL,
+            data TEXT NOT NULL DEFAULT '',
+            created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+        );`},
+	{23, `CREATE INDEX IF NOT EXISTS idx_operations_session ON operations(session, id);`},
+	{24, `CREATE TABLE IF NOT EXISTS api_keys (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            key_hash TEXT NOT NULL UNIQUE,
+            name TEXT NOT NULL,
+            created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            last_used_at DATETIME,
+            expires_at DATETIME
+        );`},
+	{25, `CREATE TABLE IF NOT EXISTS task_status_log (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+            status TEXT NOT NULL,
+            entered_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+        );`},
+	{26, `CREATE INDEX IF NOT EXISTS idx_tasks_project ON tasks(project_id);`},
+	{27, `CREATE INDEX IF NOT EXISTS idx_tasks_project_status ON tasks(project_id, status);`},
+	{28, `CREATE INDEX IF NOT EXISTS idx_task_labels_label ON task_labels(label_id);`},
+	{29, `CREATE INDEX IF NOT EXISTS idx_comments_task ON comments(task_id);`},
+	{30, `CREATE INDEX IF NOT EXISTS idx_checklist_items_task ON checklist_items(task_id, position);`},
+	{31, `CREATE INDEX IF NOT EXISTS idx_task_dependencies_blocked ON task_dependencies(blocked_id);`},
+	{32, `CREATE UNIQUE INDEX IF NOT EXISTS idx_time_entries_open ON time_entries(task_id) WHERE ended_at IS NULL;`},
+	{33, `CREATE INDEX IF NOT EXISTS idx_activity_log_task ON activity_log(task_id, changed_at);`},
+	{34, `CREATE INDEX IF NOT EXISTS idx_task_revisions_task ON task_revisions(task_id, id);`},
+	{35, `CREATE INDEX IF NOT EXISTS idx_task_status_log_task ON task_status_log(task_id, id);`},
+	{36, `CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook ON webhook_deliveries(webhook_id, delivered_at);`},
+	{37, `CREATE TRIGGER IF NOT EXISTS trg_projects_updated
+            AFTER UPDATE ON projects
+            FOR EACH ROW BEGIN
+                UPDATE projects SET updated_at = CURRENT_TIMESTAMP WHERE id = OLD.id;
+            END;`},
+	{38, `CREATE TRIGGER IF NOT EXISTS trg_tasks_updated
+            AFTER UPDATE ON tasks
+            FOR EACH ROW BEGIN
+                UPDATE tasks SET updated_at = CURRENT_TIMESTAMP WHERE id = OLD.id;
+            END;`},
+	{39, `ALTER TABLE projects ADD COLUMN deleted_at DATETIME;`},
+	{40, `ALTER TABLE projects ADD COLUMN description TEXT NOT NULL DEFAULT '';`},
+	{41, `ALTER TABLE projects ADD COLUMN deadline DATETIME;`},
+	{42, `ALTER TABLE tasks ADD COLUMN deleted_at DATETIME;`},
+	{43, `ALTER TABLE tasks ADD COLUMN parent_id INTEGER REFERENCES tasks(id);`},
+	{44, `ALTER TABLE tasks ADD COLUMN number INTEGER;`},
+	{45, `ALTER TABLE tasks ADD COLUMN completed_at DATETIME;`},
+	{46, `ALTER TABLE tasks ADD COLUMN assignee TEXT NOT NULL DEFAULT '';`},
+	{47, `ALTER TABLE tasks ADD COLUMN color TEXT NOT NULL DEFAULT '';`},
+	{48, `ALTER TABLE tasks ADD COLUMN sprint_id INTEGER REFERENCES sprints(id) ON DELETE SET NULL;`},
+	{49, `ALTER TABLE tasks ADD COLUMN story_points INTEGER NOT NULL DEFAULT 0;`},
+	{50, `ALTER TABLE tasks ADD COLUMN cover_url TEXT NOT NULL DEFAULT '';`},
+	{51, `ALTER TABLE tasks ADD COLUMN due_date DATETIME;`},
+	{52, `ALTER TABLE tasks ADD COLUMN priority TEXT NOT NULL DEFAULT 'medium';`},
+	{53, `ALTER TABLE tasks ADD COLUMN snoozed_until DATETIME;`},
+	{54, `ALTER TABLE sprints ADD COLUMN planned_points INTEGER NOT NULL DEFAULT 0;`},
+	{55, `ALTER TABLE sprints ADD COLUMN completed_points INTEGER NOT NULL DEFAULT 0;`},
+	{56, `ALTER TABLE webhook_deliveries ADD COLUMN payload TEXT NOT NULL DEFAULT '';`},
+	{57, `ALTER TABLE webhook_deliveries ADD COLUMN retry_count INTEGER NOT NULL DEFAULT 0;`},
+	{58, `ALTER TABLE webhook_deliveries ADD COLUMN next_retry_at DATETIME;`},
+	{59, `-- Number tasks created before the number column existed, continuing
+        -- after the highest number in use in their project.
+        UPDATE tasks SET number = numbered.number
+        FROM (
+            SELECT id, ROW_NUMBER() OVER (PARTITION BY project_id ORDER BY id)
+                + (SELECT COALESCE(MAX(number), 0) FROM tasks m WHERE m.project_id = t.project_id) AS number
+            FROM tasks t WHERE number IS NULL
+        ) AS numbered
+        WHERE tasks.id = numbered.id;`},
+	{60, `UPDATE tasks SET completed_at = updated_at WHERE status = 'done' AND completed_at IS NULL;`},
+	{61, `-- Tasks older than the status log start with their current status.
+        INSERT INTO task_status_log(task_id, status, entered_at)
+        SELECT id, status, COALESCE(completed_at, updated_at) FROM tasks
+        WHERE NOT EXISTS (SELECT 1 FROM task_status_log l WHERE l.task_id = tasks.id);`},
+	{62, `CREATE INDEX IF NOT EXISTS idx_tasks_parent ON tasks(parent_id);`},
+	{63, `CREATE INDEX IF NOT EXISTS idx_tasks_sprint ON tasks(sprint_id);`},
+	{64, `CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_pending ON webhook_deliveries(next_retry_at) WHERE next_retry_at IS NOT NULL;`},
+	{65, `CREATE UNIQUE INDEX IF NOT EXISTS idx_tasks_project_number ON tasks(project_id, number);`},
+	{66, `CREATE TABLE IF NOT EXISTS saved_filters (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            project_id INTEGER REFERENCES projects(id) ON DELETE CASCADE,
+            name TEXT NOT NULL,
+            definition TEXT NOT NULL DEFAULT '{}',
+            created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+        );`},
+	{67, `CREATE INDEX IF NOT EXISTS idx_tasks_updated ON tasks(updated_at);`},
+	// project_events keeps task_id without a foreign key so events of
+	// deleted tasks survive with the title they had.
+	{68, `CREATE TABLE IF NOT EXISTS project_events (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            project_id INTEGER NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+            type TEXT NOT NULL,
+            task_id INTEGER,
+            task_title TEXT NOT NULL DEFAULT '',
+            old_value TEXT NOT NULL DEFAULT '',
+            new_value TEXT NOT NULL DEFAULT '',
+            created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+        );`},
+	{69, `CREATE INDEX IF NOT EXISTS idx_project_events_project ON project_events(project_id, id);`},
+	{70, `INSERT INTO project_events(project_id, type, task_id, task_title, new_value, created_at)
+        SELECT project_id, 'task.created', id, title, status, created_at FROM tasks ORDER BY created_at, id;`},
+	{71, `CREATE TRIGGER IF NOT EXISTS trg_events_task_created
+            AFTER INSERT ON tasks
+            FOR EACH ROW BEGIN
+                INSERT INTO project_events(project_id, type, task_id, task_title, new_value)
+                VALUES (NEW.project_id, 'task.created', NEW.id, NEW.title, NEW.status);
+            END;`},
+	{72, `CREATE TRIGGER IF NOT EXISTS trg_events_task_renamed
+            AFTER UPDATE OF title ON tasks
+            FOR EACH ROW WHEN OLD.title IS NOT NEW.title BEGIN
+                INSERT INTO project_events(project_id, type, task_id, task_title, old_value, new_value)
+                VALUES (NEW.project_id, 'task.renamed', NEW.id, NEW.title, OLD.title, NEW.title);
+            END;`},
+	{73, `CREATE TRIGGER IF NOT EXISTS trg_events_task_moved
+            AFTER UPDATE OF status ON tasks
+            FOR EACH ROW WHEN OLD.status IS NOT NEW.status BEGIN
+                INSERT INTO project_events(project_id, type, task_id, task_title, old_value, new_value)
+                VALUES (NEW.project_id, CASE WHEN NEW.status = 'done' THEN 'task.completed' ELSE 'task.moved' END,
+                    NEW.id, NEW.title, OLD.status, NEW.status);
+            END;`},
+	{74, `CREATE TRIGGER IF NOT EXISTS trg_events_task_trashed
+            AFTER UPDATE OF deleted_at ON tasks
+            FOR EACH ROW WHEN (OLD.deleted_at IS NULL) <> (NEW.deleted_at IS NULL) BEGIN
+                INSERT INTO project_events(project_id, type, task_id, task_title)
+                VALUES (NEW.project_id, CASE WHEN NEW.deleted_at IS NULL THEN 'task.restored' ELSE 'task.deleted' END, NEW.id, NEW.title);
+            END;`},
+	// Purging a trashed task was already reported when it was trashed, and
+	// tasks removed along with their project have no feed left to join.
+	{75, `CREATE TRIGGER IF NOT EXISTS trg_events_task_deleted
+            AFTER DELETE ON tasks
+            FOR EACH ROW WHEN OLD.deleted_at IS NULL AND EXISTS (SELECT 1 FROM projects WHERE id = OLD.project_id) BEGIN
+                INSERT INTO project_events(project_id, type, task_id, task_title)
+                VALUES (OLD.project_id, 'task.deleted', OLD.id, OLD.title);
+            END;`},
+	{76, `CREATE TRIGGER IF NOT EXISTS trg_events_project_renamed
+            AFTER UPDATE OF name ON projects
+            FOR EACH ROW WHEN OLD.name IS NOT NEW.name BEGIN
+                INSERT INTO project_events(project_id, type, old_value, new_value)
+                VALUES (NEW.id, 'project.renamed', OLD.name, NEW.name);
+            END;`},
+	{77, `CREATE TRIGGER IF NOT EXISTS trg_events_project_recolored
+            AFTER UPDATE OF color ON projects
+            FOR EACH ROW WHEN OLD.color IS NOT NEW.color BEGIN
+                INSERT INTO project_events(project_id, type, old_value, new_value)
+                VALUES (NEW.id, 'project.recolored', OLD.color, NEW.color);
+            END;`},
+	{78, `CREATE TABLE IF NOT EXISTS statuses (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            project_id INTEGER NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+            name TEXT NOT NULL,
+            display_order INTEGER NOT NULL DEFAULT 0,
+            is_terminal BOOLEAN NOT NULL DEFAULT 0,
+            created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            UNIQUE(project_id, name)
+        );`},
+	{79, `INSERT OR IGNORE INTO statuses(project_id, name, display_order, is_terminal)
+        SELECT p.id, d.name, d.display_order, d.is_terminal FROM projects p,
+            (SELECT 'todo' AS name, 0 AS display_order, 0 AS is_terminal
+             UNION ALL SELECT 'in_progress', 1, 0
+             UNION ALL SELECT 'done', 2, 1) d;`},
+	{80, `CREATE TRIGGER IF NOT EXISTS trg_projects_default_statuses
+            AFTER INSERT ON projects
+            FOR EACH ROW BEGIN
+                INSERT INTO statuses(project_id, name, display_order, is_terminal) VALUES
+                    (NEW.id, 'todo', 0, 0),
+                    (NEW.id, 'in_progress', 1, 0),
+                    (NEW.id, 'done', 2, 1);
+            END;`},
+	{81, `DROP TRIGGER IF EXISTS trg_events_task_moved;`},
+	// A status rename updates the tasks before the status row, so NEW.status
+	// is not yet defined for the project and no move is recorded.
+	{82, `CREATE TRIGGER IF NOT EXISTS trg_events_task_moved
+            AFTER UPDATE OF status ON tasks
+            FOR EACH ROW WHEN OLD.status IS NOT NEW.status
+                AND EXISTS (SELECT 1 FROM statuses WHERE project_id = NEW.project_id AND name = NEW.status) BEGIN
+                INSERT INTO project_events(project_id, type, task_id, task_title, old_value, new_value)
+                VALUES (NEW.project_id, CASE WHEN EXISTS (SELECT 1 FROM statuses WHERE project_id = NEW.project_id AND name = NEW.status AND is_terminal)
+                    THEN 'task.completed' ELSE 'task.moved' END,
+                    NEW.id, NEW.title, OLD.status, NEW.status);
+            END;`},
+	{83, `ALTER TABLE statuses ADD COLUMN wip_limit INTEGER NOT NULL DEFAULT 0;`},
+	{84, `CREATE TABLE IF NOT EXISTS milestones (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            project_id INTEGER NOT NULL,
+            title TEXT NOT NULL,
+            description TEXT NOT NULL DEFAULT '',
+            due_date DATE,
+            status TEXT NOT NULL DEFAULT 'open',
+            created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            FOREIGN KEY(project_id) REFERENCES projects(id) ON DELETE CASCADE
+        );`},
+	{85, `ALTER TABLE tasks ADD COLUMN milestone_id INTEGER REFERENCES milestones(id) ON DELETE SET NULL;`},
+	{86, `CREATE INDEX IF NOT EXISTS idx_tasks_milestone ON tasks(milestone_id);`},
+	// ALTER TABLE cannot add a UNIQUE column; the partial index enforces it
+	// for users that have an email.
+	{87, `ALTER TABLE users ADD COLUMN email TEXT;`},
+	{88, `CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users(email) WHERE email IS NOT NULL;`},
+	{89, `ALTER TABLE users ADD COLUMN last_login_at DATETIME;`},
+	{90, `CREATE TABLE IF NOT EXISTS project_members (
+            project_id INTEGER NOT NULL,
+            user_id INTEGER NOT NULL,
+            role TEXT NOT NULL DEFAULT 'member',
+            created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            PRIMARY KEY(project_id, user_id),
+            FOREIGN KEY(project_id) REFERENCES projects(id) ON DELETE CASCADE,
+            FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+        );`},
+	{91, `CREATE INDEX IF NOT EXISTS idx_project_members_user ON project_members(user_id);`},
+	{92, `CREATE TABLE IF NOT EXISTS audit_log (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            action TEXT NOT NULL,
+            entity_type TEXT NOT NULL DEFAULT '',
+            entity_id INTEGER,
+            actor_id INTEGER,
+            actor_ip TEXT NOT NULL DEFAULT '',
+            payload JSON,
+            created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+        );`},
+	{93, `CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log(entity_type, entity_id);`},
+	// The audit log is append-only.
+	{94, `CREATE TRIGGER IF NOT EXISTS trg_audit_log_no_update BEFORE UPDATE ON audit_log BEGIN
+                SELECT RAISE(ABORT, 'audit_log is append-only');
+            END;`},
+	{95, `CREATE TRIGGER IF NOT EXISTS trg_audit_log_no_delete BEFORE DELETE ON audit_log BEGIN
+                SELECT RAISE(ABORT, 'audit_log is append-only');
+            END;`},
+	{96, `ALTER TABLE statuses ADD COLUMN title TEXT NOT NULL DEFAULT '';`},
+	{97, `ALTER TABLE statuses ADD COLUMN color TEXT NOT NULL DEFAULT '';`},
+	{98, `UPDATE statuses SET title = CASE name
+                WHEN 'todo' THEN 'To do'
+                WHEN 'in_progress' THEN 'In progress'
+                WHEN 'done' THEN 'Done'
+                ELSE name END
+            WHERE title = '';`},
+	{99, `DROP TRIGGER IF EXISTS trg_projects_default_statuses;`},
+	{100, `CREATE TRIGGER IF NOT EXISTS trg_projects_default_statuses
+            AFTER INSERT ON projects
+            FOR EACH ROW BEGIN
+                INSERT INTO statuses(project_id, name, title, display_order, is_terminal) VALUES
+                    (NEW.id, 'todo', 'To do', 0, 0),
+                    (NEW.id, 'in_progress', 'In progress', 1, 0),
+                    (NEW.id, 'done', 'Done', 2, 1);
+            END;`},
+	{101, `CREATE INDEX IF NOT EXISTS idx_tasks_due_date ON tasks(due_date);`},
+	{102, `ALTER TABLE statuses ADD COLUMN wip_mode TEXT NOT NULL DEFAULT 'enforce';`},
+	{103, `ALTER TABLE tasks ADD COLUMN version INTEGER NOT NULL DEFAULT 1;`},
+	// Every update of a task bumps its version along with updated_at.
+	{104, `DROP TRIGGER IF EXISTS trg_tasks_updated;`},
+	{105, `CREATE TRIGGER IF NOT EXISTS trg_tasks_updated
+            AFTER UPDATE ON tasks
+            FOR EACH ROW BEGIN
+                UPDATE tasks SET updated_at = CURRENT_TIMESTAMP, version = OLD.version + 1 WHERE id = OLD.id;
+            END;`},
+	{106, `CREATE TABLE IF NOT EXISTS idempotency_keys (
+            key TEXT PRIMARY KEY,
+            response_status INTEGER NOT NULL DEFAULT 0,
+            response_body TEXT NOT NULL DEFAULT '',
+            created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+        );`},
+	{107, `CREATE INDEX IF NOT EXISTS idx_idempotency_keys_created ON idempotency_keys(created_at);`},
+	// Existing projects share position 0 and keep their creation order.
+	{108, `ALTER TABLE projects ADD COLUMN position INTEGER NOT NULL DEFAULT 0;`},
+	{109, `ALTER TABLE api_keys ADD COLUMN user_id INTEGER REFERENCES users(id) ON DELETE CASCADE;`},
+	// Trashed projects give up their name: the column constraint becomes a
+	// unique index over live projects. SQLite cannot drop a constraint, so
+	// the table is rebuilt and its triggers recreated.
+	{110, `CREATE TABLE projects_new (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            name TEXT NOT NULL,
+            color TEXT NOT NULL DEFAULT '#2563eb',
+            created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            deleted_at DATETIME,
+            description TEXT NOT NULL DEFAULT '',
+            deadline DATETIME,
+            position INTEGER NOT NULL DEFAULT 0
+        );
+        INSERT INTO projects_new(id, name, color, created_at, updated_at, deleted_at, description, deadline, position)
+            SELECT id, name, color, created_at, updated_at, deleted_at, description, deadline, position FROM projects;
+        UPDATE sqlite_sequence SET seq = (SELECT seq FROM sqlite_sequence WHERE name = 'projects') WHERE name = 'projects_new';
+        DROP TABLE projects;
+        ALTER TABLE projects_new RENAME TO projects;
+        CREATE UNIQUE INDEX idx_projects_name ON projects(name) WHERE deleted_at IS NULL;
+        CREATE TRIGGER trg_projects_updated
+            AFTER UPDATE ON projects
+            FOR EACH ROW BEGIN
+                UPDATE projects SET updated_at = CURRENT_TIMESTAMP WHERE id = OLD.id;
+            END;
+        CREATE TRIGGER trg_events_project_renamed
+            AFTER UPDATE OF name ON projects
+            FOR EACH ROW WHEN OLD.name IS NOT NEW.name BEGIN
+                INSERT INTO project_events(project_id, type, old_value, new_value)
+                VALUES (NEW.id, 'project.renamed', OLD.name, NEW.name);
+            END;
+        CREATE TRIGGER trg_events_project_recolored
+            AFTER UPDATE OF color ON projects
+            FOR EACH ROW WHEN OLD.color IS NOT NEW.color BEGIN
+                INSERT INTO project_events(project_id, type, old_value, new_value)
+                VALUES (NEW.id, 'project.recolored', OLD.color, NEW.color);
+            END;
+        CREATE TRIGGER trg_projects_default_statuses
+            AFTER INSERT ON projects
+            FOR EACH ROW BEGIN
+                INSERT INTO statuses(project_id, name, title, display_order, is_terminal) VALUES
+                    (NEW.id, 'todo', 'To do', 0, 0),
+                    (NEW.id, 'in_progress', 'In progress', 1, 0),
+                    (NEW.id, 'done', 'Done', 2, 1);
+            END;`},
+	// Versions change only with edits of the task itself, which bump them
+	// explicitly; renumbering the siblings of a moved card must not.
+	{111, `DROP TRIGGER IF EXISTS trg_tasks_updated;`},
+	{112, `CREATE TRIGGER IF NOT EXISTS trg_tasks_updated
+            AFTER UPDATE ON tasks
+            FOR EACH ROW BEGIN
+                UPDATE tasks SET updated_at = CURRENT_TIMESTAMP WHERE id = OLD.id;
+            END;`},
+}
+
+// rebuildMigrations are the versions that rebuild a table other tables
+// reference. They run with foreign keys off, so dropping the old table does
+// not cascade, and must leave every reference valid.
+var rebuildMigrations = map[int]bool{110: true}
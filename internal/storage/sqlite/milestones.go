@@ -0,0 +1,187 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"todo/internal/models"
+)
+
+const milestoneColumns = `id, project_id, title, description, due_date, status, created_at, updated_at`
+
+func scanMilestone(row rowScanner) (models.Milestone, error) {
+	var (
+		m       models.Milestone
+		dueDate sql.NullTime
+	)
+	if err := row.Scan(&m.ID, &m.ProjectID, &m.Title, &m.Description, &dueDate, &m.Status, &m.CreatedAt, &m.UpdatedAt); err != nil {
+		return models.Milestone{}, err
+	}
+	if dueDate.Valid {
+		m.DueDate = &dueDate.Time
+	}
+	return m, nil
+}
+
+// ListMilestones returns the milestones of a project, soonest due first and
+// those without a due date last.
+func (s *Store) ListMilestones(ctx context.Context, projectID int64) ([]models.Milestone, error) {
+	ctx, span := tracer.Start(ctx, "store.ListMilestones")
+	defer span.End()
+	if _, err := s.GetProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT `+milestoneColumns+` FROM milestones WHERE project_id = ? ORDER BY due_date IS NULL, due_date, id`, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("list milestones: %w", err)
+	}
+	defer rows.Close()
+
+	milestones := []models.Milestone{}
+	for rows.Next() {
+		m, err := scanMilestone(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan milestone: %w", err)
+		}
+		milestones = append(milestones, m)
+	}
+	return milestones, rows.Err()
+}
+
+// GetMilestone fetches a single milestone by id.
+func (s *Store) GetMilestone(ctx context.Context, id int64) (models.Milestone, error) {
+	ctx, span := tracer.Start(ctx, "store.GetMilestone")
+	defer span.End()
+	m, err := scanMilestone(s.db.QueryRowContext(ctx, `SELECT `+milestoneColumns+` FROM milestones WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Milestone{}, fmt.Errorf("milestone not found")
+	}
+	if err != nil {
+		return models.Milestone{}, fmt.Errorf("get milestone: %w", err)
+	}
+	return m, nil
+}
+
+// CreateMilestone adds a milestone to a project.
+func (s *Store) CreateMilestone(ctx context.Context, m models.Milestone) (models.Milestone, error) {
+	ctx, span := tracer.Start(ctx, "store.CreateMilestone")
+	defer span.End()
+	if m.Status == "" {
+		m.Status = "open"
+	}
+	if err := validateMilestoneFields(&m); err != nil {
+		return models.Milestone{}, err
+	}
+	if _, err := s.GetProject(ctx, m.ProjectID); err != nil {
+		return models.Milestone{}, err
+	}
+
+	res, err := s.db.ExecContext(ctx, `INSERT INTO milestones(project_id, title, description, due_date, status) VALUES(?, ?, ?, ?, ?)`,
+		m.ProjectID, m.Title, m.Description, dateValue(m.DueDate), m.Status)
+	if err != nil {
+		return models.Milestone{}, fmt.Errorf("insert milestone: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return models.Milestone{}, fmt.Errorf("milestone id: %w", err)
+	}
+	return s.GetMilestone(ctx, id)
+}
+
+// UpdateMilestone replaces the editable fields of a milestone.
+func (s *Store) UpdateMilestone(ctx context.Context, id int64, m models.Milestone) (models.Milestone, error) {
+	ctx, span := tracer.Start(ctx, "store.UpdateMilestone")
+	defer span.End()
+	current, err := s.GetMilestone(ctx, id)
+	if err != nil {
+		return models.Milestone{}, err
+	}
+	if m.Status == "" {
+		m.Status = current.Status
+	}
+	if err := validateMilestoneFields(&m); err != nil {
+		return models.Milestone{}, err
+	}
+
+	if _, err := s.db.ExecContext(ctx, `UPDATE milestones SET title = ?, description = ?, due_date = ?, status = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
+		m.Title, m.Description, dateValue(m.DueDate), m.Status, id); err != nil {
+		return models.Milestone{}, fmt.Errorf("update milestone: %w", err)
+	}
+	return s.GetMilestone(ctx, id)
+}
+
+// DeleteMilestone removes a milestone and detaches its tasks.
+func (s *Store) DeleteMilestone(ctx context.Context, id int64) error {
+	ctx, span := tracer.Start(ctx, "store.DeleteMilestone")
+	defer span.End()
+	return transaction(ctx, s.db, "delete milestone", func(tx *observedTx) error {
+		if _, err := tx.ExecContext(ctx, `UPDATE tasks SET milestone_id = NULL WHERE milestone_id = ?`, id); err != nil {
+			return fmt.Errorf("release milestone tasks: %w", err)
+		}
+		res, err := tx.ExecContext(ctx, `DELETE FROM milestones WHERE id = ?`, id)
+		if err != nil {
+			return fmt.Errorf("delete milestone: %w", err)
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return fmt.Errorf("milestone not found")
+		}
+		return nil
+	})
+}
+
+// GetMilestoneProgress counts the live tasks of a milestone and those
+// completed, that is in a terminal status.
+func (s *Store) GetMilestoneProgress(ctx context.Context, id int64) (models.MilestoneProgress, error) {
+	ctx, span := tracer.Start(ctx, "store.GetMilestoneProgress")
+	defer span.End()
+	if _, err := s.GetMilestone(ctx, id); err != nil {
+		return models.MilestoneProgress{}, err
+	}
+
+	var p models.MilestoneProgress
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*), COUNT(completed_at) FROM tasks
+        WHERE milestone_id = ? AND deleted_at IS NULL`, id).Scan(&p.TotalTasks, &p.Done); err != nil {
+		return models.MilestoneProgress{}, fmt.Errorf("milestone progress: %w", err)
+	}
+	if p.TotalTasks > 0 {
+		p.Pct = math.Round(float64(p.Done)/float64(p.TotalTasks)*1000) / 10
+	}
+	return p, nil
+}
+
+// validateMilestone checks that a task of projectID may be attached to
+// milestoneID.
+func (s *Store) validateMilestone(ctx context.Context, projectID, milestoneID int64) error {
+	m, err := s.GetMilestone(ctx, milestoneID)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrValidation, err)
+	}
+	if m.ProjectID != projectID {
+		return fmt.Errorf("%w: milestone belongs to another project", ErrValidation)
+	}
+	if m.Status == "closed" {
+		return fmt.Errorf("%w: milestone is closed", ErrValidation)
+	}
+	return nil
+}
+
+func validateMilestoneFields(m *models.Milestone) error {
+	m.Title = strings.TrimSpace(m.Title)
+	m.Description = strings.TrimSpace(m.Description)
+	if m.Title == "" {
+		return fmt.Errorf("%w: milestone title must not be empty", ErrValidation)
+	}
+	if _, ok := models.ValidMilestoneStatuses[m.Status]; !ok {
+		return fmt.Errorf("%w: invalid milestone status %q", ErrValidation, m.Status)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// QueryObserver is told how long each database query took, labelled with the
+// store function that issued it.
+type QueryObserver func(operation string, d time.Duration)
+
+// observedDB times queries made through the store's connection pool.
+type observedDB struct {
+	*sql.DB
+	observe QueryObserver
+}
+
+// observedTx times queries made inside a transaction, including the commit.
+type observedTx struct {
+	*sql.Tx
+	observe QueryObserver
+}
+
+// SetQueryObserver registers fn to be called after every query the store
+// runs. It must be set before serving requests.
+func (s *Store) SetQueryObserver(fn QueryObserver) {
+	s.db.observe = fn
+}
+
+func (db *observedDB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	defer db.observe.since(time.Now())
+	return db.DB.QueryContext(ctx, query, args...)
+}
+
+func (db *observedDB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	defer db.observe.since(time.Now())
+	return db.DB.QueryRowContext(ctx, query, args...)
+}
+
+func (db *observedDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	defer db.observe.since(time.Now())
+	return db.DB.ExecContext(ctx, query, args...)
+}
+
+func (db *observedDB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*observedTx, error) {
+	tx, err := db.DB.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &observedTx{Tx: tx, observe: db.observe}, nil
+}
+
+func (tx *observedTx) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	defer tx.observe.since(time.Now())
+	return tx.Tx.QueryContext(ctx, query, args...)
+}
+
+func (tx *observedTx) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	defer tx.observe.since(time.Now())
+	return tx.Tx.QueryRowContext(ctx, query, args...)
+}
+
+func (tx *observedTx) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	defer tx.observe.since(time.Now())
+	return tx.Tx.ExecContext(ctx, query, args...)
+}
+
+func (tx *observedTx) Commit() error {
+	defer tx.observe.since(time.Now())
+	return tx.Tx.Commit()
+}
+
+// since reports the time elapsed from start under the name of the function
+// that called the wrapped query method.
+func (fn QueryObserver) since(start time.Time) {
+	if fn == nil {
+		return
+	}
+	d := time.Since(start)
+	// Skip since, the deferred wrapper method and land on its caller.
+	pc, _, _, ok := runtime.Caller(2)
+	if !ok {
+		fn("unknown", d)
+		return
+	}
+	fn(operationName(pc), d)
+}
+
+// operationNames caches the trimmed function name for each caller PC.
+var operationNames sync.Map
+
+// operationName turns a PC into a short label such as "CreateTask", folding
+// closures into their enclosing function.
+func operationName(pc uintptr) string {
+	if name, ok := operationNames.Load(pc); ok {
+		return name.(string)
+	}
+	name := "unknown"
+	if f := runtime.FuncForPC(pc); f != nil {
+		name = f.Name()
+		if i := strings.LastIndex(name, "/"); i >= 0 {
+			name = name[i+1:]
+		}
+		parts := strings.Split(name, ".")
+		// parts is [package, (receiver,) function, (closure...)].
+		for i := 1; i < len(parts); i++ {
+			if part := parts[i]; part != "" && !strings.HasPrefix(part, "(") && !strings.HasPrefix(part, "func") {
+				name = part
+				break
+			}
+		}
+	}
+	operationNames.Store(pc, name)
+	return name
+}
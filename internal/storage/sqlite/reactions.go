@@ -0,0 +1,127 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"todo/internal/models"
+)
+
+// maxEmojiRunes leaves room for skin tones and ZWJ sequences.
+const maxEmojiRunes = 8
+
+// AddReaction records an emoji reaction by author. Repeating a reaction is a
+// no-op.
+func (s *Store) AddReaction(ctx context.Context, taskID int64, emoji, author string) error {
+	ctx, span := tracer.Start(ctx, "store.AddReaction")
+	defer span.End()
+	emoji, author, err := normalizeReaction(emoji, author)
+	if err != nil {
+		return err
+	}
+	if _, err := s.GetTask(ctx, taskID); err != nil {
+		return err
+	}
+
+	if _, err := s.db.ExecContext(ctx, `INSERT OR IGNORE INTO task_reactions(task_id, emoji, author) VALUES(?, ?, ?)`, taskID, emoji, author); err != nil {
+		return fmt.Errorf("add reaction: %w", err)
+	}
+	return nil
+}
+
+// RemoveReaction withdraws an emoji reaction by author.
+func (s *Store) RemoveReaction(ctx context.Context, taskID int64, emoji, author string) error {
+	ctx, span := tracer.Start(ctx, "store.RemoveReaction")
+	defer span.End()
+	emoji, author, err := normalizeReaction(emoji, author)
+	if err != nil {
+		return err
+	}
+	if _, err := s.GetTask(ctx, taskID); err != nil {
+		return err
+	}
+
+	res, err := s.db.ExecContext(ctx, `DELETE FROM task_reactions WHERE task_id = ? AND emoji = ? AND author = ?`, taskID, emoji, author)
+	if err != nil {
+		return fmt.Errorf("remove reaction: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("reaction not found")
+	}
+	return nil
+}
+
+// normalizeReaction trims and validates a reaction.
+func normalizeReaction(emoji, author string) (string, string, error) {
+	emoji = strings.TrimSpace(emoji)
+	author = strings.TrimSpace(author)
+	if !isEmoji(emoji) {
+		return "", "", fmt.Errorf("%w: reaction must be a single emoji", ErrValidation)
+	}
+	if author == "" {
+		return "", "", fmt.Errorf("%w: reaction author must not be empty", ErrValidation)
+	}
+	if utf8.RuneCountInString(author) > maxAssigneeLength {
+		return "", "", fmt.Errorf("%w: reaction author must be at most %d characters", ErrValidation, maxAssigneeLength)
+	}
+	return emoji, author, nil
+}
+
+// isEmoji accepts short strings of symbols such as "👍" or "👩‍💻" and rejects
+// words and whitespace.
+func isEmoji(s string) bool {
+	n := utf8.RuneCountInString(s)
+	if n == 0 || n > maxEmojiRunes {
+		return false
+	}
+	symbol := false
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsSpace(r) || unicode.IsControl(r) {
+			return false
+		}
+		if r >= 0x2000 {
+			symbol = true
+		}
+	}
+	return symbol
+}
+
+// attachReactions fills the Reactions counts of each task using a single query.
+func (s *Store) attachReactions(ctx context.Context, tasks []models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	index, args := taskIndex(tasks)
+	for i := range tasks {
+		tasks[i].Reactions = map[string]int{}
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT task_id, emoji, COUNT(*) FROM task_reactions WHERE task_id IN (`+placeholders(len(args))+`) GROUP BY task_id, emoji`, args...)
+	if err != nil {
+		return fmt.Errorf("load task reactions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			taskID int64
+			emoji  string
+			count  int
+		)
+		if err := rows.Scan(&taskID, &emoji, &count); err != nil {
+			return fmt.Errorf("scan task reaction: %w", err)
+		}
+		if i, ok := index[taskID]; ok {
+			tasks[i].Reactions[emoji] = count
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,78 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"todo/internal/defaults"
+	"todo/internal/models"
+)
+
+// DefaultMaxRevisions is the number of revisions kept per task unless
+// changed with SetMaxRevisions.
+const DefaultMaxRevisions = defaults.MaxRevisions
+
+// SetMaxRevisions changes how many revisions are kept per task; values below
+// one are ignored.
+func (s *Store) SetMaxRevisions(n int) {
+	if n > 0 {
+		s.maxRevisions = n
+	}
+}
+
+// saveRevision stores the current title and description of t and prunes the
+// oldest revisions beyond the cap.
+func (s *Store) saveRevision(ctx context.Context, tx *observedTx, t models.Task) error {
+	if _, err := tx.ExecContext(ctx, `INSERT INTO task_revisions(task_id, title, description) VALUES(?, ?, ?)`, t.ID, t.Title, t.Description); err != nil {
+		return fmt.Errorf("save revision: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM task_revisions WHERE task_id = ? AND id NOT IN (
+            SELECT id FROM task_revisions WHERE task_id = ? ORDER BY id DESC LIMIT ?)`, t.ID, t.ID, s.maxRevisions); err != nil {
+		return fmt.Errorf("prune revisions: %w", err)
+	}
+	return nil
+}
+
+// ListTaskRevisions returns the stored revisions of a task, newest first.
+func (s *Store) ListTaskRevisions(ctx context.Context, taskID int64) ([]models.TaskRevision, error) {
+	ctx, span := tracer.Start(ctx, "store.ListTaskRevisions")
+	defer span.End()
+	if _, err := s.GetTask(ctx, taskID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT id, task_id, title, description, created_at FROM task_revisions WHERE task_id = ? ORDER BY id DESC`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("list revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := []models.TaskRevision{}
+	for rows.Next() {
+		var r models.TaskRevision
+		if err := rows.Scan(&r.ID, &r.TaskID, &r.Title, &r.Description, &r.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan revision: %w", err)
+		}
+		revisions = append(revisions, r)
+	}
+	return revisions, rows.Err()
+}
+
+// RestoreTaskRevision puts the title and description of a revision back on its
+// task. The values being replaced are saved as a new revision first.
+func (s *Store) RestoreTaskRevision(ctx context.Context, taskID, revisionID int64) (models.Task, error) {
+	ctx, span := tracer.Start(ctx, "store.RestoreTaskRevision")
+	defer span.End()
+	var r models.TaskRevision
+	err := s.db.QueryRowContext(ctx, `SELECT title, description FROM task_revisions WHERE id = ? AND task_id = ?`, revisionID, taskID).
+		Scan(&r.Title, &r.Description)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Task{}, fmt.Errorf("revision not found")
+	}
+	if err != nil {
+		return models.Task{}, fmt.Errorf("get revision: %w", err)
+	}
+	return s.UpdateTask(ctx, taskID, map[string]any{"title": r.Title, "description": r.Description})
+}
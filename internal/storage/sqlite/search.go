@@ -0,0 +1,261 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"unicode/utf8"
+
+	"todo/internal/models"
+)
+
+// Snippet markers chosen by SearchProjectTasks; they cannot appear in escaped
+// text and are replaced by <mark> tags after escaping.
+const (
+	snippetOpen  = "\x02"
+	snippetClose = "\x03"
+)
+
+// snippetContext is how many characters of the surrounding text a fallback
+// snippet keeps on each side of the match.
+const snippetContext = 40
+
+// setupFullText creates the tasks_fts index over task titles and descriptions
+// with the triggers keeping it in sync. SQLite builds without FTS5 fall back
+// to LIKE matching; their triggers are dropped so writes keep working.
+func (s *Store) setupFullText() error {
+	_, err := s.db.Exec(`CREATE VIRTUAL TABLE IF NOT EXISTS tasks_fts USING fts5(title, description, content='tasks', content_rowid='id')`)
+	if err != nil {
+		if !strings.Contains(err.Error(), "no such module: fts5") {
+			return fmt.Errorf("create search index: %w", err)
+		}
+		s.logger.Warn("sqlite lacks FTS5, project search falls back to LIKE matching")
+		for _, trigger := range []string{"tasks_fts_insert", "tasks_fts_delete", "tasks_fts_update"} {
+			if _, err := s.db.Exec(`DROP TRIGGER IF EXISTS ` + trigger); err != nil {
+				return fmt.Errorf("drop search trigger: %w", err)
+			}
+		}
+		return nil
+	}
+
+	// Without the triggers the index is new or missed writes made by a build
+	// lacking FTS5, so it is rebuilt from the tasks table.
+	var synced int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name = 'tasks_fts_insert'`).Scan(&synced); err != nil {
+		return fmt.Errorf("check search index: %w", err)
+	}
+	stmts := []string{
+		`CREATE TRIGGER IF NOT EXISTS tasks_fts_insert AFTER INSERT ON tasks BEGIN
+            INSERT INTO tasks_fts(rowid, title, description) VALUES (NEW.id, NEW.title, NEW.description);
+        END;`,
+		`CREATE TRIGGER IF NOT EXISTS tasks_fts_delete AFTER DELETE ON tasks BEGIN
+            INSERT INTO tasks_fts(tasks_fts, rowid, title, description) VALUES ('delete', OLD.id, OLD.title, OLD.description);
+        END;`,
+		`CREATE TRIGGER IF NOT EXISTS tasks_fts_update AFTER UPDATE OF title, description ON tasks BEGIN
+            INSERT INTO tasks_fts(tasks_fts, rowid, title, description) VALUES ('delete', OLD.id, OLD.title, OLD.description);
+            INSERT INTO tasks_fts(rowid, title, description) VALUES (NEW.id, NEW.title, NEW.description);
+        END;`,
+	}
+	if synced == 0 {
+		stmts = append(stmts, `INSERT INTO tasks_fts(tasks_fts) VALUES ('rebuild')`)
+	}
+	for _, stmt := range stmts {
+		if _, err := s.db.Exec(stmt); err != nil {
+			return fmt.Errorf("migration failed: %w", err)
+		}
+	}
+	s.fullText = true
+	return nil
+}
+
+// MaxSearchLimit caps how many results of each kind a search returns.
+const MaxSearchLimit = 100
+
+// SearchProjectTasks finds live tasks of a project whose title or
+// description contains every word of query, best matches first.
+func (s *Store) SearchProjectTasks(ctx context.Context, projectID int64, query string) ([]models.TaskSearchResult, error) {
+	ctx, span := tracer.Start(ctx, "store.SearchProjectTasks")
+	defer span.End()
+	if _, err := s.GetProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+	return s.searchTasks(ctx, &projectID, query, MaxSearchLimit)
+}
+
+// SearchTasks finds up to limit live tasks whose title or description
+// contains every word of query, best matches first. When projectID is nil
+// every live project is searched.
+func (s *Store) SearchTasks(ctx context.Context, projectID *int64, query string, limit int) ([]models.TaskSearchResult, error) {
+	ctx, span := tracer.Start(ctx, "store.SearchTasks")
+	defer span.End()
+	return s.searchTasks(ctx, projectID, query, limit)
+}
+
+// SearchProjects finds up to limit live projects whose name contains every
+// word of query, names starting with the query first.
+func (s *Store) SearchProjects(ctx context.Context, query string, limit int) ([]models.Project, error) {
+	ctx, span := tracer.Start(ctx, "store.SearchProjects")
+	defer span.End()
+	terms, err := searchTerms(query, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	scope, args := memberScope(ctx, "id")
+	stmt := `SELECT ` + projectColumns + ` FROM projects WHERE deleted_at IS NULL` + scope
+	for _, term := range terms {
+		stmt += ` AND name LIKE ? ESCAPE '\'`
+		args = append(args, "%"+escapeLike(term)+"%")
+	}
+	stmt += ` ORDER BY name LIKE ? ESCAPE '\' DESC, name COLLATE NOCASE, id LIMIT ?`
+	args = append(args, escapeLike(strings.TrimSpace(query))+"%", limit)
+
+	rows, err := s.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, fmt.Errorf("search projects: %w", err)
+	}
+	defer rows.Close()
+
+	projects := []models.Project{}
+	for rows.Next() {
+		p, err := scanProject(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan project: %w", err)
+		}
+		projects = append(projects, p)
+	}
+	return projects, rows.Err()
+}
+
+// searchTerms splits query into words and checks the result limit.
+func searchTerms(query string, limit int) ([]string, error) {
+	terms := strings.Fields(query)
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("%w: search query must not be empty", ErrValidation)
+	}
+	if limit < 1 || limit > MaxSearchLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrValidation, MaxSearchLimit)
+	}
+	return terms, nil
+}
+
+// searchTasks matches tasks against the full-text index when available and
+// with LIKE otherwise. Words match as prefixes with the index. Results carry
+// their project and an HTML-escaped snippet with the matches wrapped in
+// <mark>.
+func (s *Store) searchTasks(ctx context.Context, projectID *int64, query string, limit int) ([]models.TaskSearchResult, error) {
+	terms, err := searchTerms(query, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		stmt string
+		args []any
+	)
+	if s.fullText {
+		quoted := make([]string, len(terms))
+		for i, term := range terms {
+			quoted[i] = `"` + strings.ReplaceAll(term, `"`, `""`) + `"*`
+		}
+		stmt = `SELECT ` + qualify("t", taskColumns) + `, p.name, p.color, snippet(tasks_fts, -1, char(2), char(3), '…', 16)
+            FROM tasks_fts JOIN tasks t ON t.id = tasks_fts.rowid JOIN projects p ON p.id = t.project_id
+            WHERE tasks_fts MATCH ? AND t.deleted_at IS NULL AND p.deleted_at IS NULL`
+		args = []any{strings.Join(quoted, " ")}
+	} else {
+		stmt = `SELECT ` + qualify("t", taskColumns) + `, p.name, p.color, ''
+            FROM tasks t JOIN projects p ON p.id = t.project_id
+            WHERE t.deleted_at IS NULL AND p.deleted_at IS NULL`
+		for _, term := range terms {
+			pattern := "%" + escapeLike(term) + "%"
+			stmt += ` AND (t.title LIKE ? ESCAPE '\' OR t.description LIKE ? ESCAPE '\')`
+			args = append(args, pattern, pattern)
+		}
+	}
+	if projectID != nil {
+		stmt += ` AND t.project_id = ?`
+		args = append(args, *projectID)
+	}
+	scope, scopeArgs := memberScope(ctx, "t.project_id")
+	stmt += scope
+	args = append(args, scopeArgs...)
+	if s.fullText {
+		stmt += ` ORDER BY bm25(tasks_fts), t.id DESC LIMIT ?`
+	} else {
+		// Title hits rank above description-only hits.
+		stmt += ` ORDER BY t.title LIKE ? ESCAPE '\' DESC, t.updated_at DESC, t.id DESC LIMIT ?`
+		args = append(args, "%"+escapeLike(terms[0])+"%")
+	}
+	args = append(args, limit)
+
+	rows, err := s.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, fmt.Errorf("search tasks: %w", err)
+	}
+	defer rows.Close()
+
+	results := []models.TaskSearchResult{}
+	for rows.Next() {
+		var r models.TaskSearchResult
+		r.Task, err = scanTask(rows, &r.ProjectName, &r.ProjectColor, &r.Snippet)
+		if err != nil {
+			return nil, fmt.Errorf("scan task: %w", err)
+		}
+		if !s.fullText {
+			r.Snippet = likeSnippet(r.Task, terms)
+		}
+		r.Snippet = highlight(r.Snippet)
+		results = append(results, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	tasks := make([]models.Task, len(results))
+	for i := range results {
+		tasks[i] = results[i].Task
+	}
+	if err := s.hydrateTasks(ctx, tasks); err != nil {
+		return nil, err
+	}
+	for i := range results {
+		results[i].Task = tasks[i]
+	}
+	return results, nil
+}
+
+// likeSnippet marks the first match of any term in the title, or else in the
+// description, keeping some context around it.
+func likeSnippet(t models.Task, terms []string) string {
+	for _, text := range []string{t.Title, t.Description} {
+		lower := strings.ToLower(text)
+		start, end := -1, -1
+		for _, term := range terms {
+			if i := strings.Index(lower, strings.ToLower(term)); i >= 0 && (start < 0 || i < start) {
+				start, end = i, i+len(term)
+			}
+		}
+		// Lowercasing can change byte lengths; give up on marking then.
+		if start < 0 || len(lower) != len(text) {
+			continue
+		}
+		before, after := text[:start], text[end:]
+		prefix, suffix := "", ""
+		if utf8.RuneCountInString(before) > snippetContext {
+			r := []rune(before)
+			before, prefix = string(r[len(r)-snippetContext:]), "…"
+		}
+		if utf8.RuneCountInString(after) > snippetContext {
+			after, suffix = string([]rune(after)[:snippetContext]), "…"
+		}
+		return prefix + before + snippetOpen + text[start:end] + snippetClose + after + suffix
+	}
+	return t.Title
+}
+
+// highlight escapes a snippet for HTML and turns its markers into <mark> tags.
+func highlight(snippet string) string {
+	return strings.NewReplacer(snippetOpen, "<mark>", snippetClose, "</mark>").Replace(html.EscapeString(snippet))
+}
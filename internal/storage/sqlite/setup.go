@@ -0,0 +1,190 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"todo/internal/models"
+)
+
+const settingSetupCompleted = "setup_completed"
+
+// starterTemplates lists the tasks seeded into the initial project.
+var starterTemplates = map[string][]models.Task{
+	"empty": nil,
+	"getting_started": {
+		{Title: "Explore the board", Description: "Drag cards between columns to change their status.", Status: "todo"},
+		{Title: "Invite your team", Status: "todo"},
+		{Title: "Finish the setup wizard", Status: "done"},
+	},
+	"scrum": {
+		{Title: "Groom the backlog", Status: "todo"},
+		{Title: "Plan the first sprint", Status: "todo"},
+		{Title: "Schedule daily stand-up", Status: "todo"},
+	},
+}
+
+// baselineSettings are written on setup unless overridden by the caller.
+var baselineSettings = map[string]string{
+	"default_task_status": "todo",
+}
+
+// SetupStatus reports whether the first-run wizard is still pending.
+func (s *Store) SetupStatus(ctx context.Context) (models.SetupStatus, error) {
+	ctx, span := tracer.Start(ctx, "store.SetupStatus")
+	defer span.End()
+	var status models.SetupStatus
+	completed, err := s.SetupCompleted(ctx)
+	if err != nil {
+		return status, err
+	}
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users`).Scan(&status.Users); err != nil {
+		return status, fmt.Errorf("count users: %w", err)
+	}
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM projects`).Scan(&status.Projects); err != nil {
+		return status, fmt.Errorf("count projects: %w", err)
+	}
+	status.Required = !completed && status.Users == 0
+	return status, nil
+}
+
+// SetupCompleted reports whether the wizard has already been run. Databases
+// from before the wizard never ran it; they count as set up once they hold
+// any users, projects or tasks, so only an empty database needs the wizard.
+func (s *Store) SetupCompleted(ctx context.Context) (bool, error) {
+	ctx, span := tracer.Start(ctx, "store.SetupCompleted")
+	defer span.End()
+	var value string
+	err := s.db.QueryRowContext(ctx, `SELECT value FROM settings WHERE key = ?`, settingSetupCompleted).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		var used bool
+		if err := s.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM users) OR EXISTS(SELECT 1 FROM projects) OR EXISTS(SELECT 1 FROM tasks)`).Scan(&used); err != nil {
+			return false, fmt.Errorf("check existing data: %w", err)
+		}
+		return used, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("get setting: %w", err)
+	}
+	return value == "true", nil
+}
+
+// CompleteSetup creates the admin user, the initial project and baseline
+// settings atomically, then marks setup as completed.
+func (s *Store) CompleteSetup(ctx context.Context, in SetupInput) (models.User, models.Project, error) {
+	ctx, span := tracer.Start(ctx, "store.CompleteSetup")
+	defer span.End()
+	username := strings.TrimSpace(in.Username)
+	if username == "" {
+		return models.User{}, models.Project{}, fmt.Errorf("username must not be empty")
+	}
+	if len(in.Password) < 8 {
+		return models.User{}, models.Project{}, fmt.Errorf("password must be at least 8 characters")
+	}
+	projectName := strings.TrimSpace(in.ProjectName)
+	if projectName == "" {
+		return models.User{}, models.Project{}, fmt.Errorf("project name must not be empty")
+	}
+	template := in.Template
+	if template == "" {
+		template = "empty"
+	}
+	seed, ok := starterTemplates[template]
+	if !ok {
+		return models.User{}, models.Project{}, fmt.Errorf("unknown template %q", template)
+	}
+	color := in.ProjectColor
+	if color == "" {
+		color = randomPaletteColor()
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(in.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return models.User{}, models.Project{}, fmt.Errorf("hash password: %w", err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.User{}, models.Project{}, fmt.Errorf("begin setup: %w", err)
+	}
+	defer tx.Rollback()
+
+	var done int64
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM settings WHERE key = ? AND value = 'true'`, settingSetupCompleted).Scan(&done); err != nil {
+		return models.User{}, models.Project{}, fmt.Errorf("check setup: %w", err)
+	}
+	var users int64
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM users`).Scan(&users); err != nil {
+		return models.User{}, models.Project{}, fmt.Errorf("count users: %w", err)
+	}
+	if done > 0 || users > 0 {
+		return models.User{}, models.Project{}, ErrSetupCompleted
+	}
+
+	res, err := tx.ExecContext(ctx, `INSERT INTO users(username, password_hash, role) VALUES(?, ?, 'admin')`, username, string(hash))
+	if err != nil {
+		return models.User{}, models.Project{}, fmt.Errorf("insert user: %w", err)
+	}
+	userID, err := res.LastInsertId()
+	if err != nil {
+		return models.User{}, models.Project{}, fmt.Errorf("user id: %w", err)
+	}
+
+	res, err = tx.ExecContext(ctx, `INSERT INTO projects(name, color, position) VALUES(?, ?, `+nextProjectPosition+`)`, projectName, color)
+	if err != nil {
+		return models.User{}, models.Project{}, fmt.Errorf("insert project: %w", err)
+	}
+	projectID, err := res.LastInsertId()
+	if err != nil {
+		return models.User{}, models.Project{}, fmt.Errorf("project id: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO project_members(project_id, user_id, role) VALUES(?, ?, 'admin')`, projectID, userID); err != nil {
+		return models.User{}, models.Project{}, fmt.Errorf("insert member: %w", err)
+	}
+
+	positions := map[string]int64{}
+	for i, t := range seed {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO tasks(project_id, number, title, description, status, position, completed_at)
+            VALUES(?, ?, ?, ?, ?, ?, CASE WHEN ? = 'done' THEN CURRENT_TIMESTAMP END)`, projectID, i+1, t.Title, t.Description, t.Status, positions[t.Status], t.Status); err != nil {
+			return models.User{}, models.Project{}, fmt.Errorf("insert task: %w", err)
+		}
+		positions[t.Status]++
+	}
+
+	settings := map[string]string{}
+	for k, v := range baselineSettings {
+		settings[k] = v
+	}
+	for k, v := range in.Settings {
+		if k == settingSetupCompleted {
+			continue
+		}
+		settings[k] = v
+	}
+	settings[settingSetupCompleted] = "true"
+	for k, v := range settings {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO settings(key, value) VALUES(?, ?)
+            ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_at = CURRENT_TIMESTAMP`, k, v); err != nil {
+			return models.User{}, models.Project{}, fmt.Errorf("save setting: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.User{}, models.Project{}, fmt.Errorf("commit setup: %w", err)
+	}
+
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return models.User{}, models.Project{}, err
+	}
+	project, err := s.GetProject(ctx, projectID)
+	if err != nil {
+		return models.User{}, models.Project{}, err
+	}
+	return user, project, nil
+}
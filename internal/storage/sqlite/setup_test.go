@@ -0,0 +1,46 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	"todo/internal/models"
+)
+
+func TestSetupCompleted(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("empty database", func(t *testing.T) {
+		s := openTestStore(t)
+		done, err := s.SetupCompleted(ctx)
+		if err != nil || done {
+			t.Fatalf("SetupCompleted = %v, %v; want false", done, err)
+		}
+	})
+
+	t.Run("data from before the wizard", func(t *testing.T) {
+		s := openTestStore(t)
+		if _, err := s.CreateProject(ctx, models.Project{Name: "Legacy"}); err != nil {
+			t.Fatal(err)
+		}
+		done, err := s.SetupCompleted(ctx)
+		if err != nil || !done {
+			t.Fatalf("SetupCompleted = %v, %v; want true", done, err)
+		}
+		status, err := s.SetupStatus(ctx)
+		if err != nil || status.Required {
+			t.Fatalf("SetupStatus = %+v, %v; want not required", status, err)
+		}
+	})
+
+	t.Run("wizard run", func(t *testing.T) {
+		s := openTestStore(t)
+		if _, _, err := s.CompleteSetup(ctx, SetupInput{Username: "admin", Password: "secret123", ProjectName: "Main"}); err != nil {
+			t.Fatal(err)
+		}
+		done, err := s.SetupCompleted(ctx)
+		if err != nil || !done {
+			t.Fatalf("SetupCompleted = %v, %v; want true", done, err)
+		}
+	})
+}
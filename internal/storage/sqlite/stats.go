@@ -0,0 +1,435 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"todo/internal/models"
+)
+
+// statsRecentWindow is the span of the recent counts of GetProjectStats.
+const statsRecentWindow = 7 * 24 * time.Hour
+
+// GetProjectStats counts the live tasks of a project per status and
+// summarizes the open ones and the recent activity. A task is open until it
+// enters a terminal status.
+func (s *Store) GetProjectStats(ctx context.Context, projectID int64) (models.ProjectOverview, error) {
+	ctx, span := tracer.Start(ctx, "store.GetProjectStats")
+	defer span.End()
+	project, err := s.GetProject(ctx, projectID)
+	if err != nil {
+		return models.ProjectOverview{}, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT status, COUNT(*) FROM tasks WHERE project_id = ? AND deleted_at IS NULL GROUP BY status`, projectID)
+	if err != nil {
+		return models.ProjectOverview{}, fmt.Errorf("project stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := models.ProjectOverview{ProjectStats: models.ProjectStats{ProjectID: project.ID, ProjectName: project.Name}}
+	for rows.Next() {
+		var (
+			status string
+			count  int64
+		)
+		if err := rows.Scan(&status, &count); err != nil {
+			return models.ProjectOverview{}, fmt.Errorf("scan stats: %w", err)
+		}
+		stats.Add(status, count)
+	}
+	if err := rows.Err(); err != nil {
+		return models.ProjectOverview{}, err
+	}
+
+	now := time.Now().UTC()
+	since := now.Add(-statsRecentWindow).Format(timestampLayout)
+	var oldest sql.NullString
+	if err := s.db.QueryRowContext(ctx, `SELECT COALESCE(SUM(completed_at IS NULL), 0),
+            datetime(MIN(CASE WHEN completed_at IS NULL THEN created_at END)),
+            COALESCE(SUM(created_at >= ?), 0),
+            COALESCE(SUM(completed_at >= ?), 0)
+        FROM tasks WHERE project_id = ? AND deleted_at IS NULL`, since, since, projectID).
+		Scan(&stats.Open, &oldest, &stats.CreatedLast7Days, &stats.CompletedLast7Days); err != nil {
+		return models.ProjectOverview{}, fmt.Errorf("project stats: %w", err)
+	}
+	created, err := parseTimestamp(oldest)
+	if err != nil {
+		return models.ProjectOverview{}, err
+	}
+	if created != nil {
+		days := math.Round(now.Sub(*created).Hours()/24*100) / 100
+		stats.OldestOpenAgeDays = &days
+	}
+	return stats, nil
+}
+
+// GetDashboardStats returns per-project task counts for every live project.
+func (s *Store) GetDashboardStats(ctx context.Context) ([]models.ProjectStats, error) {
+	ctx, span := tracer.Start(ctx, "store.GetDashboardStats")
+	defer span.End()
+	scope, args := memberScope(ctx, "p.id")
+	rows, err := s.db.QueryContext(ctx, `SELECT p.id, p.name, t.status, COUNT(t.id) FROM projects p
+        LEFT JOIN tasks t ON t.project_id = p.id AND t.deleted_at IS NULL
+        WHERE p.deleted_at IS NULL`+scope+`
+        GROUP BY p.id, t.status
+        ORDER BY p.created_at, p.id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("dashboard stats: %w", err)
+	}
+	defer rows.Close()
+
+	result := []models.ProjectStats{}
+	for rows.Next() {
+		var (
+			id     int64
+			name   string
+			status sql.NullString
+			count  int64
+		)
+		if err := rows.Scan(&id, &name, &status, &count); err != nil {
+			return nil, fmt.Errorf("scan stats: %w", err)
+		}
+		if len(result) == 0 || result[len(result)-1].ProjectID != id {
+			result = append(result, models.ProjectStats{ProjectID: id, ProjectName: name})
+		}
+		if status.Valid {
+			result[len(result)-1].Add(status.String, count)
+		}
+	}
+	return result, rows.Err()
+}
+
+// ListCompletedTasksBetween returns the live tasks of a project completed in
+// the half-open window [from, to), oldest completion first.
+func (s *Store) ListCompletedTasksBetween(ctx context.Context, projectID int64, from, to time.Time) ([]models.Task, error) {
+	ctx, span := tracer.Start(ctx, "store.ListCompletedTasksBetween")
+	defer span.End()
+	if _, err := s.GetProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT `+taskColumns+` FROM tasks
+        WHERE project_id = ? AND deleted_at IS NULL AND completed_at >= ? AND completed_at < ?
+        ORDER BY completed_at, id`, projectID, from.UTC().Format(timestampLayout), to.UTC().Format(timestampLayout))
+	if err != nil {
+		return nil, fmt.Errorf("list completed tasks: %w", err)
+	}
+	tasks, err := scanTasks(rows)
+	if err != nil {
+		return nil, err
+	}
+	return tasks, s.hydrateTasks(ctx, tasks)
+}
+
+// GetAccuracyReport compares the story point estimate of each live task with
+// its cycle time, from first entering in_progress (or creation, for tasks
+// that skipped the column) until completion, and with the time tracked on
+// it. Tasks that are not done are reported separately as in flight, timed up
+// to now. Per-task figures are computed in SQL.
+func (s *Store) GetAccuracyReport(ctx context.Context, projectID int64) (models.AccuracyReport, error) {
+	ctx, span := tracer.Start(ctx, "store.GetAccuracyReport")
+	defer span.End()
+	if _, err := s.GetProject(ctx, projectID); err != nil {
+		return models.AccuracyReport{}, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT t.id, t.number, t.title, t.status, t.story_points,
+            datetime(COALESCE(ip.started_at, CASE WHEN t.status = 'done' THEN t.created_at END)),
+            datetime(t.completed_at),
+            CAST((julianday(COALESCE(t.completed_at, CURRENT_TIMESTAMP))
+                - julianday(COALESCE(ip.started_at, CASE WHEN t.status = 'done' THEN t.created_at END))) * 1440 AS INTEGER),
+            COALESCE(te.minutes, 0)
+        FROM tasks t
+        LEFT JOIN (SELECT task_id, MIN(changed_at) AS started_at FROM activity_log
+            WHERE field = 'status' AND new_value = 'in_progress' GROUP BY task_id) ip ON ip.task_id = t.id
+        LEFT JOIN (SELECT task_id,
+                CAST(SUM((julianday(COALESCE(ended_at, CURRENT_TIMESTAMP)) - julianday(started_at)) * 1440) AS INTEGER) AS minutes
+            FROM time_entries GROUP BY task_id) te ON te.task_id = t.id
+        WHERE t.project_id = ? AND t.deleted_at IS NULL
+        ORDER BY t.completed_at, t.status, t.position, t.id`, projectID)
+	if err != nil {
+		return models.AccuracyReport{}, fmt.Errorf("accuracy report: %w", err)
+	}
+	defer rows.Close()
+
+	report := models.AccuracyReport{
+		ProjectID: projectID,
+		Completed: []models.AccuracyRow{},
+		InFlight:  []models.AccuracyRow{},
+	}
+	for rows.Next() {
+		var (
+			r                      models.AccuracyRow
+			startedAt, completedAt sql.NullString
+			elapsed                sql.NullInt64
+		)
+		if err := rows.Scan(&r.TaskID, &r.Number, &r.Title, &r.Status, &r.StoryPoints, &startedAt, &completedAt, &elapsed, &r.TrackedMinutes); err != nil {
+			return models.AccuracyReport{}, fmt.Errorf("scan accuracy: %w", err)
+		}
+		if r.StartedAt, err = parseTimestamp(startedAt); err != nil {
+			return models.AccuracyReport{}, err
+		}
+		if r.CompletedAt, err = parseTimestamp(completedAt); err != nil {
+			return models.AccuracyReport{}, err
+		}
+		if elapsed.Valid {
+			minutes := int(elapsed.Int64)
+			r.ElapsedMinutes = &minutes
+		}
+		if r.Status == "done" {
+			report.Completed = append(report.Completed, r)
+			report.CompletedTotals.Add(r)
+		} else {
+			report.InFlight = append(report.InFlight, r)
+			report.InFlightTotals.Add(r)
+		}
+	}
+	return report, rows.Err()
+}
+
+// parseTimestamp reads a datetime() result; NULL becomes nil.
+func parseTimestamp(v sql.NullString) (*time.Time, error) {
+	if !v.Valid {
+		return nil, nil
+	}
+	t, err := time.ParseInLocation(timestampLayout, v.String, time.UTC)
+	if err != nil {
+		return nil, fmt.Errorf("parse timestamp: %w", err)
+	}
+	return &t, nil
+}
+
+// GetCycleTimeReport reports the lead and cycle time of every live task of a
+// project completed since the given time. Cycle time starts when the task
+// first entered in_progress according to the status log. completed_at is
+// cleared when a task leaves done, so reopened tasks count only their final
+// completion.
+func (s *Store) GetCycleTimeReport(ctx context.Context, projectID int64, since time.Time) (models.CycleTimeReport, error) {
+	ctx, span := tracer.Start(ctx, "store.GetCycleTimeReport")
+	defer span.End()
+	if _, err := s.GetProject(ctx, projectID); err != nil {
+		return models.CycleTimeReport{}, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT t.id, t.number, t.title, datetime(t.created_at), datetime(ip.started_at), datetime(t.completed_at),
+            (julianday(t.completed_at) - julianday(t.created_at)) * 1440,
+            (julianday(t.completed_at) - julianday(ip.started_at)) * 1440
+        FROM tasks t
+        LEFT JOIN (SELECT task_id, MIN(entered_at) AS started_at FROM task_status_log
+            WHERE status = 'in_progress' GROUP BY task_id) ip ON ip.task_id = t.id
+        WHERE t.project_id = ? AND t.deleted_at IS NULL AND t.status = 'done'
+            AND t.completed_at IS NOT NULL AND t.completed_at >= ?
+        ORDER BY t.completed_at, t.id`, projectID, since.UTC().Format(timestampLayout))
+	if err != nil {
+		return models.CycleTimeReport{}, fmt.Errorf("cycle time report: %w", err)
+	}
+	defer rows.Close()
+
+	report := models.CycleTimeReport{ProjectID: projectID, Since: since.UTC(), Tasks: []models.CycleTimeRow{}}
+	var lead, cycle []float64
+	for rows.Next() {
+		var (
+			r                                 models.CycleTimeRow
+			createdAt, startedAt, completedAt sql.NullString
+			cycleMinutes                      sql.NullFloat64
+		)
+		if err := rows.Scan(&r.TaskID, &r.Number, &r.Title, &createdAt, &startedAt, &completedAt, &r.LeadMinutes, &cycleMinutes); err != nil {
+			return models.CycleTimeReport{}, fmt.Errorf("scan cycle time: %w", err)
+		}
+		created, err := parseTimestamp(createdAt)
+		if err != nil {
+			return models.CycleTimeReport{}, err
+		}
+		completed, err := parseTimestamp(completedAt)
+		if err != nil {
+			return models.CycleTimeReport{}, err
+		}
+		r.CreatedAt, r.CompletedAt = *created, *completed
+		if r.StartedAt, err = parseTimestamp(startedAt); err != nil {
+			return models.CycleTimeReport{}, err
+		}
+		lead = append(lead, r.LeadMinutes)
+		if cycleMinutes.Valid {
+			// completed_at has second precision while the status log keeps
+			// milliseconds, so a task finished within a second of starting
+			// can come out fractionally negative.
+			minutes := max(cycleMinutes.Float64, 0)
+			r.CycleMinutes = &minutes
+			cycle = append(cycle, minutes)
+		}
+		report.Tasks = append(report.Tasks, r)
+	}
+	if err := rows.Err(); err != nil {
+		return models.CycleTimeReport{}, err
+	}
+	report.LeadTime = summarizeDurations(lead)
+	report.CycleTime = summarizeDurations(cycle)
+	return report, nil
+}
+
+// MaxVelocityPeriods caps the number of sprints or weeks of a velocity report.
+const MaxVelocityPeriods = 52
+
+// velocityRollingWindow is how many periods the rolling averages span.
+const velocityRollingWindow = 3
+
+// GetVelocityReport returns the story points and tasks completed in each of
+// the last n closed sprints of a project or, for projects without closed
+// sprints, in each of the last n calendar weeks up to the current one. Work
+// counts towards a sprint when the task is still attached to it and towards
+// the week of its completed_at. Periods without completions are included.
+func (s *Store) GetVelocityReport(ctx context.Context, projectID int64, n int) (models.VelocityReport, error) {
+	ctx, span := tracer.Start(ctx, "store.GetVelocityReport")
+	defer span.End()
+	if n < 1 || n > MaxVelocityPeriods {
+		return models.VelocityReport{}, fmt.Errorf("%w: sprints must be between 1 and %d", ErrValidation, MaxVelocityPeriods)
+	}
+	if _, err := s.GetProject(ctx, projectID); err != nil {
+		return models.VelocityReport{}, err
+	}
+
+	report := models.VelocityReport{ProjectID: projectID, RollingWindow: velocityRollingWindow, Periods: []models.VelocityPeriod{}}
+	rows, err := s.db.QueryContext(ctx, `SELECT sp.id, sp.name, datetime(COALESCE(sp.starts_at, sp.created_at)), datetime(COALESCE(sp.ends_at, sp.updated_at)),
+            COALESCE(SUM(t.story_points), 0), COUNT(t.id)
+        FROM sprints sp
+        LEFT JOIN tasks t ON t.sprint_id = sp.id AND t.deleted_at IS NULL AND t.completed_at IS NOT NULL
+        WHERE sp.project_id = ? AND sp.status = 'closed'
+        GROUP BY sp.id
+        ORDER BY COALESCE(sp.ends_at, sp.updated_at) DESC, sp.id DESC
+        LIMIT ?`, projectID, n)
+	if err != nil {
+		return models.VelocityReport{}, fmt.Errorf("velocity report: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			p          models.VelocityPeriod
+			id         int64
+			start, end sql.NullString
+		)
+		if err := rows.Scan(&id, &p.Label, &start, &end, &p.CompletedPoints, &p.CompletedTasks); err != nil {
+			return models.VelocityReport{}, fmt.Errorf("scan velocity: %w", err)
+		}
+		p.SprintID = &id
+		starts, err := parseTimestamp(start)
+		if err != nil {
+			return models.VelocityReport{}, err
+		}
+		ends, err := parseTimestamp(end)
+		if err != nil {
+			return models.VelocityReport{}, err
+		}
+		p.Start, p.End = *starts, *ends
+		report.Periods = append(report.Periods, p)
+	}
+	if err := rows.Err(); err != nil {
+		return models.VelocityReport{}, err
+	}
+
+	if len(report.Periods) > 0 {
+		report.Basis = "sprint"
+		for i, j := 0, len(report.Periods)-1; i < j; i, j = i+1, j-1 {
+			report.Periods[i], report.Periods[j] = report.Periods[j], report.Periods[i]
+		}
+	} else {
+		report.Basis = "week"
+		if report.Periods, err = s.weeklyVelocity(ctx, projectID, n); err != nil {
+			return models.VelocityReport{}, err
+		}
+	}
+	for i := range report.Periods {
+		first := max(0, i-velocityRollingWindow+1)
+		var points, tasks int
+		for _, p := range report.Periods[first : i+1] {
+			points += p.CompletedPoints
+			tasks += p.CompletedTasks
+		}
+		count := float64(i + 1 - first)
+		report.Periods[i].RollingPoints = math.Round(float64(points)/count*100) / 100
+		report.Periods[i].RollingTasks = math.Round(float64(tasks)/count*100) / 100
+	}
+	return report, nil
+}
+
+// weeklyVelocity totals the work completed in each of the last n weeks,
+// Monday to Sunday in UTC, ending with the current one.
+func (s *Store) weeklyVelocity(ctx context.Context, projectID int64, n int) ([]models.VelocityPeriod, error) {
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	monday := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+	first := monday.AddDate(0, 0, -7*(n-1))
+
+	// date(x, 'weekday 0', '-6 days') is the Monday starting x's week.
+	rows, err := s.db.QueryContext(ctx, `SELECT date(completed_at, 'weekday 0', '-6 days') AS week,
+            COALESCE(SUM(story_points), 0), COUNT(*)
+        FROM tasks
+        WHERE project_id = ? AND deleted_at IS NULL AND completed_at IS NOT NULL AND completed_at >= ?
+        GROUP BY week`, projectID, first.Format(timestampLayout))
+	if err != nil {
+		return nil, fmt.Errorf("velocity report: %w", err)
+	}
+	defer rows.Close()
+	type weekTotal struct{ points, tasks int }
+	totals := map[string]weekTotal{}
+	for rows.Next() {
+		var (
+			week string
+			t    weekTotal
+		)
+		if err := rows.Scan(&week, &t.points, &t.tasks); err != nil {
+			return nil, fmt.Errorf("scan velocity: %w", err)
+		}
+		totals[week] = t
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	periods := make([]models.VelocityPeriod, 0, n)
+	for start := first; !start.After(monday); start = start.AddDate(0, 0, 7) {
+		year, week := start.ISOWeek()
+		t := totals[start.Format(dateLayout)]
+		periods = append(periods, models.VelocityPeriod{
+			Label:           fmt.Sprintf("%d-W%02d", year, week),
+			Start:           start,
+			End:             start.AddDate(0, 0, 7),
+			CompletedPoints: t.points,
+			CompletedTasks:  t.tasks,
+		})
+	}
+	return periods, nil
+}
+
+// summarizeDurations computes the mean, median and 85th percentile of values,
+// interpolating linearly between the closest ranks.
+func summarizeDurations(values []float64) models.DurationSummary {
+	summary := models.DurationSummary{Count: len(values)}
+	if len(values) == 0 {
+		return summary
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	var sum float64
+	for _, v := range sorted {
+		sum += v
+	}
+	summary.Mean = sum / float64(len(sorted))
+	summary.Median = percentile(sorted, 0.5)
+	summary.P85 = percentile(sorted, 0.85)
+	return summary
+}
+
+// percentile returns the p-th quantile of sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	rank := p * float64(len(sorted)-1)
+	lower := int(rank)
+	if lower+1 >= len(sorted) {
+		return sorted[lower]
+	}
+	return sorted[lower] + (rank-float64(lower))*(sorted[lower+1]-sorted[lower])
+}
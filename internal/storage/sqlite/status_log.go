@@ -0,0 +1,71 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"todo/internal/models"
+)
+
+// logStatus records that a task entered status now.
+func logStatus(ctx context.Context, tx *observedTx, taskID int64, status string) error {
+	if _, err := tx.ExecContext(ctx, `INSERT INTO task_status_log(task_id, status) VALUES(?, ?)`, taskID, status); err != nil {
+		return fmt.Errorf("log status: %w", err)
+	}
+	return nil
+}
+
+// ListStatusLog returns the status columns a task has passed through, oldest
+// first, with the time spent in each.
+func (s *Store) ListStatusLog(ctx context.Context, taskID int64) ([]models.StatusLogEntry, error) {
+	ctx, span := tracer.Start(ctx, "store.ListStatusLog")
+	defer span.End()
+	if _, err := s.GetTask(ctx, taskID); err != nil {
+		return nil, err
+	}
+	return s.statusLog(ctx, taskID, time.Now())
+}
+
+func (s *Store) statusLog(ctx context.Context, taskID int64, now time.Time) ([]models.StatusLogEntry, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT status, entered_at FROM task_status_log WHERE task_id = ? ORDER BY id`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("list status log: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.StatusLogEntry{}
+	for rows.Next() {
+		var e models.StatusLogEntry
+		if err := rows.Scan(&e.Status, &e.EnteredAt); err != nil {
+			return nil, fmt.Errorf("scan status log: %w", err)
+		}
+		if n := len(entries); n > 0 {
+			prev := &entries[n-1]
+			left := e.EnteredAt
+			prev.LeftAt = &left
+			prev.DurationSeconds = left.Sub(prev.EnteredAt).Seconds()
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if n := len(entries); n > 0 {
+		entries[n-1].DurationSeconds = now.Sub(entries[n-1].EnteredAt).Seconds()
+	}
+	return entries, nil
+}
+
+// timeInStatus totals the seconds a task has spent in each status.
+func (s *Store) timeInStatus(ctx context.Context, taskID int64) (map[string]float64, error) {
+	entries, err := s.statusLog(ctx, taskID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	totals := make(map[string]float64, len(entries))
+	for _, e := range entries {
+		totals[e.Status] += e.DurationSeconds
+	}
+	return totals, nil
+}
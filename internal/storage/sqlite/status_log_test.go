@@ -0,0 +1,70 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	"todo/internal/models"
+)
+
+func TestRapidStatusTransitions(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	p, err := s.CreateProject(ctx, models.Project{Name: "P"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	task, err := s.CreateTask(ctx, models.Task{ProjectID: p.ID, Title: "t"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"todo"}
+	for i, status := range []string{"in_progress", "todo", "in_progress", "in_progress", "done", "todo"} {
+		// Alternate between the two ways a status changes.
+		if i%2 == 0 {
+			_, err = s.UpdateTask(ctx, task.ID, map[string]any{"status": status})
+		} else {
+			_, err = s.MoveTask(ctx, task.ID, status, 0)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status != want[len(want)-1] {
+			want = append(want, status)
+		}
+	}
+
+	entries, err := s.ListStatusLog(ctx, task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d log entries, want %d: %+v", len(entries), len(want), entries)
+	}
+	for i, e := range entries {
+		if e.Status != want[i] {
+			t.Fatalf("entry %d status = %q, want %q", i, e.Status, want[i])
+		}
+		if e.DurationSeconds < 0 {
+			t.Fatalf("entry %d lasted %v seconds", i, e.DurationSeconds)
+		}
+		last := i == len(entries)-1
+		if last != (e.LeftAt == nil) {
+			t.Fatalf("entry %d left_at = %v, want it set on all but the last", i, e.LeftAt)
+		}
+		if !last && !e.LeftAt.Equal(entries[i+1].EnteredAt) {
+			t.Fatalf("entry %d left at %v, next entered at %v", i, e.LeftAt, entries[i+1].EnteredAt)
+		}
+	}
+
+	got, err := s.GetTask(ctx, task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, status := range []string{"todo", "in_progress", "done"} {
+		if _, ok := got.TimeInStatus[status]; !ok {
+			t.Fatalf("time_in_status = %v, missing %q", got.TimeInStatus, status)
+		}
+	}
+}
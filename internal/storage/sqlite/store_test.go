@@ -0,0 +1,411 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"todo/internal/models"
+)
+
+// openTestStore opens a migrated store over a fresh database file.
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s, err := Open(filepath.Join(t.TempDir(), "todo.db"), logger, DefaultPoolConfig())
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestUpdateTaskRejectsStaleVersion(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	p, err := s.CreateProject(ctx, models.Project{Name: "P"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	task, err := s.CreateTask(ctx, models.Task{ProjectID: p.ID, Title: "t"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Two clients editing the same copy: exactly one of them wins.
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = s.UpdateTask(ctx, task.ID, map[string]any{"title": "edit " + strconv.Itoa(i), "version": task.Version})
+		}(i)
+	}
+	wg.Wait()
+	conflicts := 0
+	for _, err := range errs {
+		switch {
+		case errors.Is(err, ErrConflict):
+			conflicts++
+		case err != nil:
+			t.Fatalf("update: %v", err)
+		}
+	}
+	if conflicts != 1 {
+		t.Fatalf("conflicts = %d, want 1 (errors %v)", conflicts, errs)
+	}
+
+	got, err := s.GetTask(ctx, task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Version != task.Version+1 {
+		t.Fatalf("version = %d, want %d", got.Version, task.Version+1)
+	}
+	if _, err := s.UpdateTask(ctx, task.ID, map[string]any{"title": "late", "version": task.Version}); !errors.Is(err, ErrConflict) {
+		t.Fatalf("stale update: err = %v, want ErrConflict", err)
+	}
+}
+
+func TestMoveTaskKeepsSiblingVersions(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	p, err := s.CreateProject(ctx, models.Project{Name: "P"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tasks []models.Task
+	for _, title := range []string{"a", "b", "c"} {
+		task, err := s.CreateTask(ctx, models.Task{ProjectID: p.ID, Title: title})
+		if err != nil {
+			t.Fatal(err)
+		}
+		tasks = append(tasks, task)
+	}
+
+	moved, err := s.MoveTask(ctx, tasks[2].ID, tasks[2].Status, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved.Version != tasks[2].Version+1 {
+		t.Fatalf("moved version = %d, want %d", moved.Version, tasks[2].Version+1)
+	}
+	for _, task := range tasks[:2] {
+		got, err := s.GetTask(ctx, task.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Version != task.Version {
+			t.Fatalf("sibling %q version = %d, want %d", task.Title, got.Version, task.Version)
+		}
+	}
+}
+
+func TestConcurrentCreatesGetDistinctPositions(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	p, err := s.CreateProject(ctx, models.Project{Name: "P"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const n = 8
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := s.CreateTask(ctx, models.Task{ProjectID: p.ID, Title: "t" + strconv.Itoa(i)}); err != nil {
+				t.Errorf("create: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	tasks, err := s.ListTasks(ctx, p.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[int64]bool)
+	for _, task := range tasks {
+		if seen[task.Position] {
+			t.Fatalf("position %d assigned twice", task.Position)
+		}
+		seen[task.Position] = true
+	}
+	if len(seen) != n {
+		t.Fatalf("got %d positions, want %d", len(seen), n)
+	}
+}
+
+func TestProjectCountsCoverCustomStatuses(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	p, err := s.CreateProject(ctx, models.Project{Name: "P"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CreateStatus(ctx, p.ID, StatusInput{Name: "review", Title: "Review"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CreateStatus(ctx, p.ID, StatusInput{Name: "shipped", Title: "Shipped", IsTerminal: true}); err != nil {
+		t.Fatal(err)
+	}
+	for _, status := range []string{"todo", "review", "review", "shipped", "done"} {
+		if _, err := s.CreateTask(ctx, models.Task{ProjectID: p.ID, Title: status, Status: status}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	projects, err := s.ListProjectsWithTaskCounts(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(projects) != 1 {
+		t.Fatalf("got %d projects, want 1", len(projects))
+	}
+	got := projects[0]
+	want := map[string]int{"todo": 1, "in_progress": 0, "done": 1, "review": 2, "shipped": 1}
+	if len(got.StatusCounts) != len(want) {
+		t.Fatalf("status counts = %v, want %v", got.StatusCounts, want)
+	}
+	for status, n := range want {
+		if got.StatusCounts[status] != n {
+			t.Fatalf("status counts = %v, want %v", got.StatusCounts, want)
+		}
+	}
+	if got.DoneCount != 2 || got.TotalCount != 5 {
+		t.Fatalf("done = %d, total = %d, want 2 and 5", got.DoneCount, got.TotalCount)
+	}
+}
+
+func TestListTasksFollowsColumnOrder(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	p, err := s.CreateProject(ctx, models.Project{Name: "P"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, status := range []string{"done", "todo", "in_progress", "todo"} {
+		if _, err := s.CreateTask(ctx, models.Task{ProjectID: p.ID, Title: status, Status: status}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	statusOrder := func() []string {
+		t.Helper()
+		tasks, err := s.ListTasks(ctx, p.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out []string
+		for _, task := range tasks {
+			out = append(out, task.Status)
+		}
+		return out
+	}
+	if got, want := strings.Join(statusOrder(), ","), "todo,todo,in_progress,done"; got != want {
+		t.Fatalf("order = %s, want %s", got, want)
+	}
+
+	// Moving the done column to the front moves its tasks with it.
+	done, err := s.GetStatusByName(ctx, p.ID, "done")
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := -1
+	if _, err := s.UpdateStatus(ctx, done.ID, StatusUpdate{DisplayOrder: &first}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Join(statusOrder(), ","), "done,todo,todo,in_progress"; got != want {
+		t.Fatalf("order after reordering columns = %s, want %s", got, want)
+	}
+}
+
+func TestCompletedAtTransitions(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	p, err := s.CreateProject(ctx, models.Project{Name: "P"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	task, err := s.CreateTask(ctx, models.Task{ProjectID: p.ID, Title: "t"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.CompletedAt != nil {
+		t.Fatalf("new task completed_at = %v, want nil", task.CompletedAt)
+	}
+
+	done, err := s.UpdateTask(ctx, task.ID, map[string]any{"status": "done"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if done.CompletedAt == nil {
+		t.Fatal("todo→done: completed_at not set")
+	}
+
+	// Backdate the completion so a reset by the edit would show.
+	if _, err := s.db.Exec(`UPDATE tasks SET completed_at = '2020-01-02 03:04:05' WHERE id = ?`, task.ID); err != nil {
+		t.Fatal(err)
+	}
+	edited, err := s.UpdateTask(ctx, task.ID, map[string]any{"title": "renamed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC); edited.CompletedAt == nil || !edited.CompletedAt.Equal(want) {
+		t.Fatalf("done→done edit: completed_at = %v, want %v", edited.CompletedAt, want)
+	}
+
+	reopened, err := s.UpdateTask(ctx, task.ID, map[string]any{"status": "in_progress"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reopened.CompletedAt != nil {
+		t.Fatalf("done→in_progress: completed_at = %v, want nil", reopened.CompletedAt)
+	}
+}
+
+func TestSnoozeHidesTaskUntilExpiry(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	p, err := s.CreateProject(ctx, models.Project{Name: "P"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	task, err := s.CreateTask(ctx, models.Task{ProjectID: p.ID, Title: "later"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	listed := func(filter models.TaskFilter) int {
+		t.Helper()
+		tasks, err := s.ListTasksFiltered(ctx, p.ID, filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(tasks)
+	}
+
+	until := time.Now().Add(time.Hour)
+	if _, err := s.SnoozeTask(ctx, task.ID, &until); err != nil {
+		t.Fatal(err)
+	}
+	if n := listed(models.TaskFilter{}); n != 0 {
+		t.Fatalf("snoozed task listed: %d tasks, want 0", n)
+	}
+	if n := listed(models.TaskFilter{IncludeSnoozed: true}); n != 1 {
+		t.Fatalf("include_snoozed: %d tasks, want 1", n)
+	}
+
+	// Snoozes end by themselves once their time has passed.
+	if _, err := s.db.Exec(`UPDATE tasks SET snoozed_until = datetime('now', '-1 minute') WHERE id = ?`, task.ID); err != nil {
+		t.Fatal(err)
+	}
+	if n := listed(models.TaskFilter{}); n != 1 {
+		t.Fatalf("expired snooze: %d tasks, want 1", n)
+	}
+
+	if _, err := s.SnoozeTask(ctx, task.ID, &until); err != nil {
+		t.Fatal(err)
+	}
+	woken, err := s.SnoozeTask(ctx, task.ID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if woken.SnoozedUntil != nil {
+		t.Fatalf("unsnoozed task has snoozed_until %v", woken.SnoozedUntil)
+	}
+	if n := listed(models.TaskFilter{}); n != 1 {
+		t.Fatalf("unsnoozed: %d tasks, want 1", n)
+	}
+
+	past := time.Now().Add(-time.Hour)
+	if _, err := s.SnoozeTask(ctx, task.ID, &past); !errors.Is(err, ErrValidation) {
+		t.Fatalf("snooze into the past: err = %v, want ErrValidation", err)
+	}
+}
+
+func TestOpenAppliesPoolConfig(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ctx := context.Background()
+	open := func(t *testing.T, pool PoolConfig) *Store {
+		t.Helper()
+		s, err := Open(filepath.Join(t.TempDir(), "todo.db"), logger, pool)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { s.Close() })
+		return s
+	}
+
+	t.Run("open and idle limits", func(t *testing.T) {
+		pool := PoolConfig{MaxOpenConns: 3, MaxIdleConns: 1}
+		s := open(t, pool)
+		if got := s.DBStats().MaxOpenConnections; got != pool.MaxOpenConns {
+			t.Fatalf("max open connections = %d, want %d", got, pool.MaxOpenConns)
+		}
+		conns := make([]*sql.Conn, pool.MaxOpenConns)
+		for i := range conns {
+			var err error
+			if conns[i], err = s.db.DB.Conn(ctx); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for _, c := range conns {
+			c.Close()
+		}
+		if stats := s.DBStats(); stats.Idle != pool.MaxIdleConns || stats.MaxIdleClosed != 2 {
+			t.Fatalf("idle = %d, closed for idle limit = %d; want %d and 2", stats.Idle, stats.MaxIdleClosed, pool.MaxIdleConns)
+		}
+	})
+
+	t.Run("lifetime", func(t *testing.T) {
+		pool := PoolConfig{MaxOpenConns: 1, MaxIdleConns: 1, ConnMaxLifetime: 20 * time.Millisecond}
+		s := open(t, pool)
+		time.Sleep(2 * pool.ConnMaxLifetime)
+		if _, err := s.MigrationVersion(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if s.DBStats().MaxLifetimeClosed == 0 {
+			t.Fatal("no connection was closed for exceeding its lifetime")
+		}
+	})
+
+	t.Run("concurrent writers", func(t *testing.T) {
+		s := open(t, PoolConfig{MaxOpenConns: 4, MaxIdleConns: 4})
+		p, err := s.CreateProject(ctx, models.Project{Name: "P"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var wg sync.WaitGroup
+		errs := make([]error, 8)
+		for i := range errs {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				_, errs[i] = s.CreateTask(ctx, models.Task{ProjectID: p.ID, Title: "t" + strconv.Itoa(i)})
+			}(i)
+		}
+		wg.Wait()
+		for _, err := range errs {
+			if err != nil {
+				t.Fatalf("concurrent create: %v", err)
+			}
+		}
+	})
+
+	for _, bad := range []PoolConfig{{MaxOpenConns: 0}, {MaxOpenConns: 1, MaxIdleConns: -1}, {MaxOpenConns: 1, ConnMaxIdleTime: -time.Second}} {
+		if s, err := Open(filepath.Join(t.TempDir(), "todo.db"), logger, bad); err == nil {
+			s.Close()
+			t.Errorf("Open accepted pool %+v", bad)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"todo/internal/models"
+)
+
+const timeEntryColumns = `id, task_id, started_at, ended_at, note`
+
+func scanTimeEntry(row rowScanner) (models.TimeEntry, error) {
+	var (
+		e       models.TimeEntry
+		endedAt sql.NullTime
+	)
+	if err := row.Scan(&e.ID, &e.TaskID, &e.StartedAt, &endedAt, &e.Note); err != nil {
+		return models.TimeEntry{}, err
+	}
+	if endedAt.Valid {
+		e.EndedAt = &endedAt.Time
+	}
+	return e, nil
+}
+
+// StartTimer opens a new time entry for a task. Only one entry per task may
+// be running at a time.
+func (s *Store) StartTimer(ctx context.Context, taskID int64, note string) (models.TimeEntry, error) {
+	ctx, span := tracer.Start(ctx, "store.StartTimer")
+	defer span.End()
+	if _, err := s.GetTask(ctx, taskID); err != nil {
+		return models.TimeEntry{}, err
+	}
+
+	var open int64
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM time_entries WHERE task_id = ? AND ended_at IS NULL`, taskID).Scan(&open); err != nil {
+		return models.TimeEntry{}, fmt.Errorf("check timer: %w", err)
+	}
+	if open > 0 {
+		return models.TimeEntry{}, fmt.Errorf("%w: timer already running", ErrConflict)
+	}
+
+	res, err := s.db.ExecContext(ctx, `INSERT INTO time_entries(task_id, note) VALUES(?, ?)`, taskID, strings.TrimSpace(note))
+	if err != nil {
+		return models.TimeEntry{}, fmt.Errorf("insert time entry: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return models.TimeEntry{}, fmt.Errorf("time entry id: %w", err)
+	}
+	return s.getTimeEntry(ctx, id)
+}
+
+// StopTimer closes the running time entry of a task.
+func (s *Store) StopTimer(ctx context.Context, taskID int64) (models.TimeEntry, error) {
+	ctx, span := tracer.Start(ctx, "store.StopTimer")
+	defer span.End()
+	if _, err := s.GetTask(ctx, taskID); err != nil {
+		return models.TimeEntry{}, err
+	}
+
+	var id int64
+	err := s.db.QueryRowContext(ctx, `SELECT id FROM time_entries WHERE task_id = ? AND ended_at IS NULL ORDER BY started_at DESC, id DESC LIMIT 1`, taskID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.TimeEntry{}, fmt.Errorf("%w: no running timer", ErrConflict)
+	}
+	if err != nil {
+		return models.TimeEntry{}, fmt.Errorf("find timer: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, `UPDATE time_entries SET ended_at = CURRENT_TIMESTAMP WHERE id = ?`, id); err != nil {
+		return models.TimeEntry{}, fmt.Errorf("stop timer: %w", err)
+	}
+	return s.getTimeEntry(ctx, id)
+}
+
+// ListTimeEntries returns the time entries of a task, newest first.
+func (s *Store) ListTimeEntries(ctx context.Context, taskID int64) ([]models.TimeEntry, error) {
+	ctx, span := tracer.Start(ctx, "store.ListTimeEntries")
+	defer span.End()
+	if _, err := s.GetTask(ctx, taskID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT `+timeEntryColumns+` FROM time_entries WHERE task_id = ? ORDER BY started_at DESC, id DESC`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("list time entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.TimeEntry{}
+	for rows.Next() {
+		e, err := scanTimeEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan time entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// DeleteTimeEntry removes a time entry.
+func (s *Store) DeleteTimeEntry(ctx context.Context, id int64) error {
+	ctx, span := tracer.Start(ctx, "store.DeleteTimeEntry")
+	defer span.End()
+	res, err := s.db.ExecContext(ctx, `DELETE FROM time_entries WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("delete time entry: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("time entry not found")
+	}
+	return nil
+}
+
+func (s *Store) getTimeEntry(ctx context.Context, id int64) (models.TimeEntry, error) {
+	e, err := scanTimeEntry(s.db.QueryRowContext(ctx, `SELECT `+timeEntryColumns+` FROM time_entries WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.TimeEntry{}, fmt.Errorf("time entry not found")
+	}
+	if err != nil {
+		return models.TimeEntry{}, fmt.Errorf("get time entry: %w", err)
+	}
+	return e, nil
+}
+
+// attachTrackedMinutes fills TotalMinutes of each task, counting running
+// timers up to now.
+func (s *Store) attachTrackedMinutes(ctx context.Context, tasks []models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	index, args := taskIndex(tasks)
+	rows, err := s.db.QueryContext(ctx, `SELECT task_id,
+            CAST(SUM((julianday(COALESCE(ended_at, CURRENT_TIMESTAMP)) - julianday(started_at)) * 1440) AS INTEGER)
+        FROM time_entries WHERE task_id IN (`+placeholders(len(args))+`)
+        GROUP BY task_id`, args...)
+	if err != nil {
+		return fmt.Errorf("sum time entries: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var taskID int64
+		var minutes int
+		if err := rows.Scan(&taskID, &minutes); err != nil {
+			return fmt.Errorf("scan tracked time: %w", err)
+		}
+		if i, ok := index[taskID]; ok {
+			tasks[i].TotalMinutes = minutes
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,223 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"todo/internal/models"
+)
+
+// ListTrash returns soft-deleted projects and tasks, most recently deleted first.
+func (s *Store) ListTrash(ctx context.Context) (models.Trash, error) {
+	ctx, span := tracer.Start(ctx, "store.ListTrash")
+	defer span.End()
+	trash := models.Trash{Projects: []models.Project{}, Tasks: []models.Task{}}
+
+	scope, args := memberScope(ctx, "id")
+	rows, err := s.db.QueryContext(ctx, `SELECT `+projectColumns+` FROM projects WHERE deleted_at IS NOT NULL`+scope+` ORDER BY deleted_at DESC`, args...)
+	if err != nil {
+		return trash, fmt.Errorf("list deleted projects: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		p, err := scanProject(rows)
+		if err != nil {
+			return trash, fmt.Errorf("scan project: %w", err)
+		}
+		trash.Projects = append(trash.Projects, p)
+	}
+	if err := rows.Err(); err != nil {
+		return trash, err
+	}
+	rows.Close()
+
+	scope, args = memberScope(ctx, "project_id")
+	taskRows, err := s.db.QueryContext(ctx, `SELECT `+taskColumns+` FROM tasks WHERE deleted_at IS NOT NULL`+scope+` ORDER BY deleted_at DESC, id`, args...)
+	if err != nil {
+		return trash, fmt.Errorf("list deleted tasks: %w", err)
+	}
+	tasks, err := scanTasks(taskRows)
+	if err != nil {
+		return trash, err
+	}
+	trash.Tasks = append(trash.Tasks, tasks...)
+	return trash, s.hydrateTasks(ctx, trash.Tasks)
+}
+
+// RestoreTask brings a task back from the trash and appends it to its column.
+// Sub-tasks that were deleted together with it are restored as well. Tasks
+// of a project that is itself in the trash cannot be restored until the
+// project is restored.
+func (s *Store) RestoreTask(ctx context.Context, id int64) (models.Task, error) {
+	ctx, span := tracer.Start(ctx, "store.RestoreTask")
+	defer span.End()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Task{}, fmt.Errorf("restore task: %w", err)
+	}
+	defer tx.Rollback()
+
+	var (
+		projectID        int64
+		status           string
+		deletedAt        time.Time
+		projectDeletedAt sql.NullTime
+	)
+	err = tx.QueryRowContext(ctx, `SELECT t.project_id, t.status, t.deleted_at, p.deleted_at FROM tasks t
+        JOIN projects p ON p.id = t.project_id
+        WHERE t.id = ? AND t.deleted_at IS NOT NULL`, id).Scan(&projectID, &status, &deletedAt, &projectDeletedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Task{}, fmt.Errorf("task not found in trash")
+	}
+	if err != nil {
+		return models.Task{}, fmt.Errorf("restore task: %w", err)
+	}
+	if projectDeletedAt.Valid {
+		return models.Task{}, fmt.Errorf("project is in trash; restore project %d first", projectID)
+	}
+
+	var position sql.NullInt64
+	if err := tx.QueryRowContext(ctx, `SELECT MAX(position) FROM tasks WHERE project_id = ? AND status = ? AND deleted_at IS NULL`, projectID, status).Scan(&position); err != nil {
+		return models.Task{}, fmt.Errorf("select position: %w", err)
+	}
+	next := int64(0)
+	if position.Valid {
+		next = position.Int64 + 1
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE tasks SET deleted_at = NULL, position = ? WHERE id = ?`, next, id); err != nil {
+		return models.Task{}, fmt.Errorf("restore task: %w", err)
+	}
+	if err := restoreSubTasks(ctx, tx, id, deletedAt); err != nil {
+		return models.Task{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return models.Task{}, fmt.Errorf("restore task: %w", err)
+	}
+	return s.GetTask(ctx, id)
+}
+
+// restoreSubTasks restores the children of parentID that were trashed at the
+// same moment as the parent, appending each to the end of its column.
+func restoreSubTasks(ctx context.Context, tx *observedTx, parentID int64, deletedAt time.Time) error {
+	rows, err := tx.QueryContext(ctx, `SELECT id, project_id, status FROM tasks WHERE parent_id = ? AND deleted_at = ? ORDER BY position, id`, parentID, deletedAt)
+	if err != nil {
+		return fmt.Errorf("restore sub-tasks: %w", err)
+	}
+	type child struct {
+		id, projectID int64
+		status        string
+	}
+	var children []child
+	for rows.Next() {
+		var ch child
+		if err := rows.Scan(&ch.id, &ch.projectID, &ch.status); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan sub-task: %w", err)
+		}
+		children = append(children, ch)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return err
+	}
+	rows.Close()
+
+	for _, ch := range children {
+		var position sql.NullInt64
+		if err := tx.QueryRowContext(ctx, `SELECT MAX(position) FROM tasks WHERE project_id = ? AND status = ? AND deleted_at IS NULL`, ch.projectID, ch.status).Scan(&position); err != nil {
+			return fmt.Errorf("select position: %w", err)
+		}
+		next := int64(0)
+		if position.Valid {
+			next = position.Int64 + 1
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE tasks SET deleted_at = NULL, position = ? WHERE id = ?`, next, ch.id); err != nil {
+			return fmt.Errorf("restore sub-task: %w", err)
+		}
+	}
+	return nil
+}
+
+// RestoreProject brings a project back from the trash together with the tasks
+// that were deleted along with it. It fails with ErrConflict when a live
+// project has taken its name in the meantime.
+func (s *Store) RestoreProject(ctx context.Context, id int64) (models.Project, error) {
+	ctx, span := tracer.Start(ctx, "store.RestoreProject")
+	defer span.End()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Project{}, fmt.Errorf("restore project: %w", err)
+	}
+	defer tx.Rollback()
+
+	var name string
+	err = tx.QueryRowContext(ctx, `SELECT name FROM projects WHERE id = ? AND deleted_at IS NOT NULL`, id).Scan(&name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Project{}, fmt.Errorf("project not found in trash")
+	}
+	if err != nil {
+		return models.Project{}, fmt.Errorf("restore project: %w", err)
+	}
+	if err := checkProjectName(ctx, tx, name, id); err != nil {
+		return models.Project{}, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE tasks SET deleted_at = NULL
+        WHERE project_id = ? AND deleted_at = (SELECT deleted_at FROM projects WHERE id = ?)`, id, id); err != nil {
+		return models.Project{}, fmt.Errorf("restore project tasks: %w", err)
+	}
+	res, err := tx.ExecContext(ctx, `UPDATE projects SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL`, id)
+	if err != nil {
+		return models.Project{}, fmt.Errorf("restore project: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return models.Project{}, err
+	}
+	if affected == 0 {
+		return models.Project{}, fmt.Errorf("project not found in trash")
+	}
+	if err := tx.Commit(); err != nil {
+		return models.Project{}, fmt.Errorf("restore project: %w", err)
+	}
+	return s.GetProject(ctx, id)
+}
+
+// PurgeTrash permanently removes items that have been in the trash longer
+// than the given retention period and returns how many rows were deleted.
+func (s *Store) PurgeTrash(ctx context.Context, retention time.Duration) (int64, error) {
+	ctx, span := tracer.Start(ctx, "store.PurgeTrash")
+	defer span.End()
+	cutoff := time.Now().UTC().Add(-retention)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("purge trash: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM tasks WHERE deleted_at IS NOT NULL AND deleted_at <= ?`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("purge tasks: %w", err)
+	}
+	tasks, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	res, err = tx.ExecContext(ctx, `DELETE FROM projects WHERE deleted_at IS NOT NULL AND deleted_at <= ?`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("purge projects: %w", err)
+	}
+	projects, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("purge trash: %w", err)
+	}
+	return tasks + projects, nil
+}
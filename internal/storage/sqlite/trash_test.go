@@ -0,0 +1,121 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"todo/internal/models"
+)
+
+func TestTrashedProjectReleasesItsName(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	old, err := s.CreateProject(ctx, models.Project{Name: "A"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CreateProject(ctx, models.Project{Name: "A"}); !errors.Is(err, ErrConflict) {
+		t.Fatalf("duplicate live name: err = %v, want ErrConflict", err)
+	}
+	if err := s.DeleteProject(ctx, old.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CreateProject(ctx, models.Project{Name: "A"}); err != nil {
+		t.Fatalf("reuse trashed name: %v", err)
+	}
+	if _, err := s.RestoreProject(ctx, old.ID); !errors.Is(err, ErrConflict) {
+		t.Fatalf("restore over reused name: err = %v, want ErrConflict", err)
+	}
+}
+
+func TestProjectTableRebuildKeepsReferences(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	p, err := s.CreateProject(ctx, models.Project{Name: "Before"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	task, err := s.CreateTask(ctx, models.Task{ProjectID: p.ID, Title: "kept"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Run the rebuild again over a database holding data.
+	if _, err := s.db.Exec(`DELETE FROM schema_migrations WHERE version = 110`); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.migrate(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	if _, err := s.GetTask(ctx, task.ID); err != nil {
+		t.Fatalf("task lost in rebuild: %v", err)
+	}
+	statuses, err := s.ListStatuses(ctx, p.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 3 {
+		t.Fatalf("project has %d statuses after rebuild, want 3", len(statuses))
+	}
+	next, err := s.CreateProject(ctx, models.Project{Name: "After"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.ID <= p.ID {
+		t.Fatalf("new project id %d reuses ids up to %d", next.ID, p.ID)
+	}
+	if statuses, err = s.ListStatuses(ctx, next.ID); err != nil || len(statuses) != 3 {
+		t.Fatalf("new project statuses = %d, %v; want the 3 defaults", len(statuses), err)
+	}
+}
+
+func TestListTrashEmpty(t *testing.T) {
+	s := openTestStore(t)
+	trash, err := s.ListTrash(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trash.Projects == nil || trash.Tasks == nil {
+		t.Fatalf("empty trash = %+v, want empty slices", trash)
+	}
+}
+
+func TestRestoreTaskOfTrashedProject(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	p, err := s.CreateProject(ctx, models.Project{Name: "A"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	task, err := s.CreateTask(ctx, models.Task{ProjectID: p.ID, Title: "first"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteTask(ctx, task.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteProject(ctx, p.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.RestoreTask(ctx, task.ID); err == nil {
+		t.Fatal("restored a task of a trashed project")
+	}
+	if _, err := s.RestoreProject(ctx, p.ID); err != nil {
+		t.Fatal(err)
+	}
+	// The task was trashed before its project, so it stays in the trash
+	// until restored on its own.
+	if _, err := s.GetTask(ctx, task.ID); err == nil {
+		t.Fatal("task came back with its project")
+	}
+	restored, err := s.RestoreTask(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("restore after project: %v", err)
+	}
+	if restored.DeletedAt != nil {
+		t.Fatalf("restored task still has deleted_at %v", restored.DeletedAt)
+	}
+}
@@ -0,0 +1,25 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+)
+
+// transaction runs fn inside a transaction on db. It commits when fn returns
+// nil and rolls back otherwise. Errors from fn are returned unchanged; those
+// from beginning or committing are wrapped with op.
+func transaction(ctx context.Context, db *observedDB, op string, fn func(tx *observedTx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
@@ -0,0 +1,293 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"todo/internal/models"
+)
+
+// minPasswordLength is the shortest password accepted for a user.
+const minPasswordLength = 8
+
+const userColumns = `id, username, email, role, created_at, updated_at, last_login_at`
+
+func scanUser(row rowScanner) (models.User, error) {
+	var (
+		u         models.User
+		email     sql.NullString
+		lastLogin sql.NullTime
+	)
+	if err := row.Scan(&u.ID, &u.Username, &email, &u.Role, &u.CreatedAt, &u.UpdatedAt, &lastLogin); err != nil {
+		return models.User{}, err
+	}
+	u.Email = email.String
+	if lastLogin.Valid {
+		u.LastLoginAt = &lastLogin.Time
+	}
+	return u, nil
+}
+
+// Authenticate checks a username and password against the users table and
+// records the login.
+func (s *Store) Authenticate(ctx context.Context, username, password string) (models.User, error) {
+	ctx, span := tracer.Start(ctx, "store.Authenticate")
+	defer span.End()
+	var (
+		id   int64
+		hash string
+	)
+	err := s.db.QueryRowContext(ctx, `SELECT id, password_hash FROM users WHERE username = ?`, strings.TrimSpace(username)).Scan(&id, &hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Hash anyway so unknown usernames take as long as wrong passwords.
+		_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return models.User{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return models.User{}, fmt.Errorf("get user: %w", err)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+		return models.User{}, ErrInvalidCredentials
+	}
+	if _, err := s.db.ExecContext(ctx, `UPDATE users SET last_login_at = CURRENT_TIMESTAMP WHERE id = ?`, id); err != nil {
+		return models.User{}, fmt.Errorf("record login: %w", err)
+	}
+	return s.GetUserByID(ctx, id)
+}
+
+// dummyHash is a bcrypt hash of a random string, compared against when the
+// username is unknown.
+var dummyHash = []byte("$2a$10$7EqJtq98hPqEX7fNZaFWoOhi5BWX4Z4yStR3XKk1yS3hx6hi1s2fG")
+
+// ListUsers returns all users ordered by username.
+func (s *Store) ListUsers(ctx context.Context) ([]models.User, error) {
+	ctx, span := tracer.Start(ctx, "store.ListUsers")
+	defer span.End()
+	rows, err := s.db.QueryContext(ctx, `SELECT `+userColumns+` FROM users ORDER BY username, id`)
+	if err != nil {
+		return nil, fmt.Errorf("list users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan user: %w", err)
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+// GetUserByID fetches a single user by id.
+func (s *Store) GetUserByID(ctx context.Context, id int64) (models.User, error) {
+	ctx, span := tracer.Start(ctx, "store.GetUserByID")
+	defer span.End()
+	u, err := scanUser(s.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.User{}, ErrUserNotFound
+	}
+	if err != nil {
+		return models.User{}, fmt.Errorf("get user: %w", err)
+	}
+	return u, nil
+}
+
+// GetUserByUsername fetches a single user by username.
+func (s *Store) GetUserByUsername(ctx context.Context, username string) (models.User, error) {
+	ctx, span := tracer.Start(ctx, "store.GetUserByUsername")
+	defer span.End()
+	u, err := scanUser(s.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE username = ?`, strings.TrimSpace(username)))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.User{}, fmt.Errorf("user not found")
+	}
+	if err != nil {
+		return models.User{}, fmt.Errorf("get user: %w", err)
+	}
+	return u, nil
+}
+
+// CreateUser adds a user with a bcrypt hash of password. An empty role
+// defaults to member.
+func (s *Store) CreateUser(ctx context.Context, username, password, email, role string) (models.User, error) {
+	ctx, span := tracer.Start(ctx, "store.CreateUser")
+	defer span.End()
+	username = strings.TrimSpace(username)
+	email = strings.TrimSpace(email)
+	if role == "" {
+		role = "member"
+	}
+	if err := validateUserFields(username, email, role); err != nil {
+		return models.User{}, err
+	}
+	hash, err := hashPassword(password)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	var id int64
+	err = transaction(ctx, s.db, "create user", func(tx *observedTx) error {
+		if err := checkUserUnique(ctx, tx, 0, username, email); err != nil {
+			return err
+		}
+		res, err := tx.ExecContext(ctx, `INSERT INTO users(username, password_hash, email, role) VALUES(?, ?, ?, ?)`,
+			username, hash, emailValue(email), role)
+		if err != nil {
+			return fmt.Errorf("insert user: %w", err)
+		}
+		if id, err = res.LastInsertId(); err != nil {
+			return fmt.Errorf("user id: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return models.User{}, err
+	}
+	return s.GetUserByID(ctx, id)
+}
+
+// UpdateUser changes the username, email, password or role of a user. The
+// last admin cannot be demoted.
+func (s *Store) UpdateUser(ctx context.Context, id int64, in UserUpdate) (models.User, error) {
+	ctx, span := tracer.Start(ctx, "store.UpdateUser")
+	defer span.End()
+	current, err := s.GetUserByID(ctx, id)
+	if err != nil {
+		return models.User{}, err
+	}
+	updated := current
+	if in.Username != nil {
+		updated.Username = strings.TrimSpace(*in.Username)
+	}
+	if in.Email != nil {
+		updated.Email = strings.TrimSpace(*in.Email)
+	}
+	if in.Role != nil {
+		updated.Role = *in.Role
+	}
+	if err := validateUserFields(updated.Username, updated.Email, updated.Role); err != nil {
+		return models.User{}, err
+	}
+	var hash string
+	if in.Password != nil {
+		if hash, err = hashPassword(*in.Password); err != nil {
+			return models.User{}, err
+		}
+	}
+
+	err = transaction(ctx, s.db, "update user", func(tx *observedTx) error {
+		if err := checkUserUnique(ctx, tx, id, updated.Username, updated.Email); err != nil {
+			return err
+		}
+		if current.Role == "admin" && updated.Role != "admin" {
+			if err := checkOtherAdmin(ctx, tx, id); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE users SET username = ?, email = ?, role = ?,
+                password_hash = CASE WHEN ? = '' THEN password_hash ELSE ? END,
+                updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
+			updated.Username, emailValue(updated.Email), updated.Role, hash, hash, id); err != nil {
+			return fmt.Errorf("update user: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return models.User{}, err
+	}
+	return s.GetUserByID(ctx, id)
+}
+
+// DeleteUser removes a user. The last admin cannot be deleted.
+func (s *Store) DeleteUser(ctx context.Context, id int64) error {
+	ctx, span := tracer.Start(ctx, "store.DeleteUser")
+	defer span.End()
+	current, err := s.GetUserByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	return transaction(ctx, s.db, "delete user", func(tx *observedTx) error {
+		if current.Role == "admin" {
+			if err := checkOtherAdmin(ctx, tx, id); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM users WHERE id = ?`, id); err != nil {
+			return fmt.Errorf("delete user: %w", err)
+		}
+		return nil
+	})
+}
+
+// emailValue stores an empty email as NULL so the unique index skips it.
+func emailValue(email string) any {
+	if email == "" {
+		return nil
+	}
+	return email
+}
+
+func validateUserFields(username, email, role string) error {
+	if username == "" {
+		return fmt.Errorf("%w: username must not be empty", ErrValidation)
+	}
+	if email != "" && !strings.Contains(email, "@") {
+		return fmt.Errorf("%w: invalid email %q", ErrValidation, email)
+	}
+	if _, ok := models.ValidUserRoles[role]; !ok {
+		return fmt.Errorf("%w: invalid role %q", ErrValidation, role)
+	}
+	return nil
+}
+
+func hashPassword(password string) (string, error) {
+	if len(password) < minPasswordLength {
+		return "", fmt.Errorf("%w: password must be at least %d characters", ErrValidation, minPasswordLength)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// checkUserUnique reports a conflict when another user than id already has
+// the username or email.
+func checkUserUnique(ctx context.Context, q queryer, id int64, username, email string) error {
+	var exists bool
+	if err := q.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM users WHERE username = ? AND id != ?)`, username, id).Scan(&exists); err != nil {
+		return fmt.Errorf("check user: %w", err)
+	}
+	if exists {
+		return fmt.Errorf("%w: username %q is taken", ErrConflict, username)
+	}
+	if email == "" {
+		return nil
+	}
+	if err := q.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM users WHERE email = ? AND id != ?)`, email, id).Scan(&exists); err != nil {
+		return fmt.Errorf("check user: %w", err)
+	}
+	if exists {
+		return fmt.Errorf("%w: email %q is taken", ErrConflict, email)
+	}
+	return nil
+}
+
+// checkOtherAdmin refuses to remove the admin role from id when no other
+// admin remains.
+func checkOtherAdmin(ctx context.Context, q queryer, id int64) error {
+	var exists bool
+	if err := q.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM users WHERE role = 'admin' AND id != ?)`, id).Scan(&exists); err != nil {
+		return fmt.Errorf("check admins: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("%w: at least one admin is required", ErrConflict)
+	}
+	return nil
+}
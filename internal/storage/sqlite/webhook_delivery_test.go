@@ -0,0 +1,66 @@
+package sqlite
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"todo/internal/models"
+	"todo/internal/webhook"
+)
+
+func TestFailedDeliveryIsRetried(t *testing.T) {
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !webhook.Verify("shh", body, r.Header.Get(webhook.SignatureHeader)) {
+			t.Errorf("delivery %d has a bad signature", calls.Load()+1)
+		}
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+
+	s := openTestStore(t)
+	ctx := context.Background()
+	hook, err := s.CreateWebhook(ctx, models.Webhook{URL: receiver.URL, Secret: "shh", Active: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	queued, err := s.TestWebhook(ctx, hook.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	delivery := func() models.WebhookDelivery {
+		t.Helper()
+		d, err := scanDelivery(s.db.QueryRow(`SELECT `+deliveryColumns+` FROM webhook_deliveries WHERE id = ?`, queued.ID))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+
+	s.attemptDelivery(queued.ID)
+	failed := delivery()
+	if failed.StatusCode != http.StatusInternalServerError || failed.RetryCount != 1 || failed.NextRetryAt == nil {
+		t.Fatalf("after 500: %+v, want status 500, one retry scheduled", failed)
+	}
+	if pending, err := s.ListPendingDeliveries(ctx); err != nil || len(pending) != 0 {
+		t.Fatalf("pending right after the failure = %v (%v), want none until the back-off passes", pending, err)
+	}
+
+	s.attemptDelivery(queued.ID)
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("receiver called %d times, want 2", n)
+	}
+	ok := delivery()
+	if ok.StatusCode != http.StatusOK || ok.Error != "" || ok.NextRetryAt != nil {
+		t.Fatalf("after retry: %+v, want status 200 and nothing pending", ok)
+	}
+}
@@ -0,0 +1,224 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"todo/internal/models"
+)
+
+const webhookColumns = `id, project_id, url, events, secret, active, created_at, updated_at`
+
+func scanWebhook(row rowScanner) (models.Webhook, error) {
+	var (
+		w         models.Webhook
+		projectID sql.NullInt64
+		events    string
+	)
+	if err := row.Scan(&w.ID, &projectID, &w.URL, &events, &w.Secret, &w.Active, &w.CreatedAt, &w.UpdatedAt); err != nil {
+		return models.Webhook{}, err
+	}
+	if projectID.Valid {
+		w.ProjectID = &projectID.Int64
+	}
+	w.Events = splitEvents(events)
+	w.HasSecret = w.Secret != ""
+	return w, nil
+}
+
+func splitEvents(raw string) []string {
+	events := []string{}
+	for _, e := range strings.Split(raw, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+// ListWebhooks returns the webhooks registered for a project.
+func (s *Store) ListWebhooks(ctx context.Context, projectID int64) ([]models.Webhook, error) {
+	ctx, span := tracer.Start(ctx, "store.ListWebhooks")
+	defer span.End()
+	if _, err := s.GetProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE project_id = ? ORDER BY id`, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("list webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	hooks := []models.Webhook{}
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan webhook: %w", err)
+		}
+		hooks = append(hooks, w)
+	}
+	return hooks, rows.Err()
+}
+
+// GetWebhook fetches a single webhook by id.
+func (s *Store) GetWebhook(ctx context.Context, id int64) (models.Webhook, error) {
+	ctx, span := tracer.Start(ctx, "store.GetWebhook")
+	defer span.End()
+	w, err := scanWebhook(s.db.QueryRowContext(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Webhook{}, fmt.Errorf("webhook not found")
+	}
+	if err != nil {
+		return models.Webhook{}, fmt.Errorf("get webhook: %w", err)
+	}
+	return w, nil
+}
+
+// CreateWebhook registers a webhook. A nil ProjectID subscribes to events of
+// every project; no events subscribes to all of them.
+func (s *Store) CreateWebhook(ctx context.Context, w models.Webhook) (models.Webhook, error) {
+	ctx, span := tracer.Start(ctx, "store.CreateWebhook")
+	defer span.End()
+	if err := validateWebhook(&w); err != nil {
+		return models.Webhook{}, err
+	}
+	if w.ProjectID != nil {
+		if _, err := s.GetProject(ctx, *w.ProjectID); err != nil {
+			return models.Webhook{}, err
+		}
+	}
+
+	res, err := s.db.ExecContext(ctx, `INSERT INTO webhooks(project_id, url, events, secret, active) VALUES(?, ?, ?, ?, ?)`,
+		w.ProjectID, w.URL, strings.Join(w.Events, ","), w.Secret, w.Active)
+	if err != nil {
+		return models.Webhook{}, fmt.Errorf("insert webhook: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return models.Webhook{}, fmt.Errorf("webhook id: %w", err)
+	}
+	return s.GetWebhook(ctx, id)
+}
+
+// UpdateWebhook applies partial changes: url, events ([]string), secret and
+// active (bool).
+func (s *Store) UpdateWebhook(ctx context.Context, id int64, changes map[string]any) (models.Webhook, error) {
+	ctx, span := tracer.Start(ctx, "store.UpdateWebhook")
+	defer span.End()
+	w, err := s.GetWebhook(ctx, id)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+	if v, ok := changes["url"].(string); ok {
+		w.URL = v
+	}
+	if v, ok := changes["events"].([]string); ok {
+		w.Events = v
+	}
+	if v, ok := changes["secret"].(string); ok {
+		w.Secret = v
+	}
+	if v, ok := changes["active"].(bool); ok {
+		w.Active = v
+	}
+	if err := validateWebhook(&w); err != nil {
+		return models.Webhook{}, err
+	}
+
+	if _, err := s.db.ExecContext(ctx, `UPDATE webhooks SET url = ?, events = ?, secret = ?, active = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
+		w.URL, strings.Join(w.Events, ","), w.Secret, w.Active, id); err != nil {
+		return models.Webhook{}, fmt.Errorf("update webhook: %w", err)
+	}
+	return s.GetWebhook(ctx, id)
+}
+
+// DeleteWebhook removes a webhook together with its delivery log.
+func (s *Store) DeleteWebhook(ctx context.Context, id int64) error {
+	ctx, span := tracer.Start(ctx, "store.DeleteWebhook")
+	defer span.End()
+	res, err := s.db.ExecContext(ctx, `DELETE FROM webhooks WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("delete webhook: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("webhook not found")
+	}
+	return nil
+}
+
+const deliveryColumns = `id, webhook_id, event, status_code, error, retry_count, next_retry_at, delivered_at`
+
+func scanDelivery(row rowScanner) (models.WebhookDelivery, error) {
+	var (
+		d         models.WebhookDelivery
+		nextRetry sql.NullTime
+	)
+	if err := row.Scan(&d.ID, &d.WebhookID, &d.Event, &d.StatusCode, &d.Error, &d.RetryCount, &nextRetry, &d.DeliveredAt); err != nil {
+		return models.WebhookDelivery{}, err
+	}
+	if nextRetry.Valid {
+		d.NextRetryAt = &nextRetry.Time
+	}
+	return d, nil
+}
+
+// ListWebhookDeliveries returns the latest 100 deliveries of a webhook.
+func (s *Store) ListWebhookDeliveries(ctx context.Context, webhookID int64) ([]models.WebhookDelivery, error) {
+	ctx, span := tracer.Start(ctx, "store.ListWebhookDeliveries")
+	defer span.End()
+	if _, err := s.GetWebhook(ctx, webhookID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT `+deliveryColumns+` FROM webhook_deliveries
+        WHERE webhook_id = ? ORDER BY id DESC LIMIT 100`, webhookID)
+	if err != nil {
+		return nil, fmt.Errorf("list deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+func validateWebhook(w *models.Webhook) error {
+	w.URL = strings.TrimSpace(w.URL)
+	if !isHTTPURL(w.URL) {
+		return fmt.Errorf("%w: webhook url must be an absolute http(s) URL", ErrValidation)
+	}
+	events := make([]string, 0, len(w.Events))
+	for _, e := range w.Events {
+		e = strings.TrimSpace(e)
+		if _, ok := models.ValidWebhookEvents[e]; !ok {
+			return fmt.Errorf("%w: unknown webhook event %q", ErrValidation, e)
+		}
+		events = append(events, e)
+	}
+	w.Events = events
+	return nil
+}
+
+// emitTask loads a task after a committed mutation and emits event for it.
+func (s *Store) emitTask(ctx context.Context, event string, id int64) (models.Task, error) {
+	task, err := s.GetTask(ctx, id)
+	if err != nil {
+		return models.Task{}, err
+	}
+	s.emit(ctx, event, task.ProjectID, task)
+	return task, nil
+}
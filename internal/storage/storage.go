@@ -0,0 +1,106 @@
+// Package storage holds what every storage backend shares with the server:
+// the errors handlers classify, the inputs of the store methods that take
+// more than a model and the request context the stores read.
+package storage
+
+import (
+	"errors"
+	"time"
+
+	"todo/internal/defaults"
+)
+
+var (
+	// ErrValidation marks errors caused by input that violates a business rule.
+	ErrValidation = errors.New("validation failed")
+	// ErrConflict marks operations rejected because of the current state.
+	ErrConflict = errors.New("conflict")
+
+	// ErrInvalidAPIKey is returned for keys that are unknown or revoked.
+	ErrInvalidAPIKey = errors.New("invalid API key")
+	// ErrAPIKeyExpired is returned for keys past their expiry.
+	ErrAPIKeyExpired = errors.New("API key expired")
+
+	// ErrInvalidCredentials is returned when a username and password do not
+	// match a user.
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	// ErrUserNotFound is returned by GetUserByID for unknown ids.
+	ErrUserNotFound = errors.New("user not found")
+
+	// ErrSetupCompleted is returned when the first-run wizard is invoked twice.
+	ErrSetupCompleted = errors.New("setup already completed")
+)
+
+// Default text length limits, in characters, unless changed with
+// SetLengthLimits.
+const (
+	DefaultMaxTitleLength       = defaults.MaxTitleLength
+	DefaultMaxDescriptionLength = defaults.MaxDescriptionLength
+	DefaultMaxProjectNameLength = defaults.MaxProjectNameLength
+)
+
+// MaxTaskImportRows caps the number of tasks one ImportTasks call accepts.
+const MaxTaskImportRows = 5000
+
+// PoolConfig tunes the database connection pool. Zero durations mean no
+// limit; each backend has its own defaults.
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// SetupInput carries everything the first-run wizard creates in one go.
+type SetupInput struct {
+	Username     string
+	Password     string
+	ProjectName  string
+	ProjectColor string
+	Template     string
+	Settings     map[string]string
+}
+
+// StatusInput describes a new status column. A nil DisplayOrder appends it
+// after the existing columns, an empty Title shows the name, a zero WIPLimit
+// leaves the column unlimited and an empty WIPMode enforces the limit.
+type StatusInput struct {
+	Name         string
+	Title        string
+	Color        string
+	DisplayOrder *int
+	IsTerminal   bool
+	WIPLimit     int
+	WIPMode      string
+}
+
+// StatusUpdate holds the fields UpdateStatus changes; nil fields are left as
+// they are.
+type StatusUpdate struct {
+	Name         *string
+	Title        *string
+	Color        *string
+	DisplayOrder *int
+	IsTerminal   *bool
+	WIPLimit     *int
+	WIPMode      *string
+}
+
+// UserUpdate holds the fields UpdateUser changes; nil fields are left as
+// they are and an empty Email clears it.
+type UserUpdate struct {
+	Username *string
+	Email    *string
+	Password *string
+	Role     *string
+}
+
+// DuplicateOptions selects what DuplicateProject copies. An empty Name keeps
+// the source's name; Rename resolves a taken name by appending " (copy)"
+// instead of failing with ErrConflict.
+type DuplicateOptions struct {
+	Name         string
+	IncludeTasks bool
+	IncludeDone  bool
+	Rename       bool
+}
@@ -1,6 +1,9 @@
 package util
 
-import "os"
+import (
+	"os"
+	"strconv"
+)
 
 // EnvOrDefault returns the environment variable value or fallback when it is empty.
 func EnvOrDefault(key, fallback string) string {
@@ -9,3 +12,29 @@ func EnvOrDefault(key, fallback string) string {
 	}
 	return fallback
 }
+
+// EnvIntOrDefault returns the environment variable parsed as an integer or
+// fallback when it is empty or not a number.
+func EnvIntOrDefault(key string, fallback int) int {
+	if n, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return n
+	}
+	return fallback
+}
+
+// EnvInt64OrDefault is EnvIntOrDefault for 64-bit values such as byte sizes.
+func EnvInt64OrDefault(key string, fallback int64) int64 {
+	if n, err := strconv.ParseInt(os.Getenv(key), 10, 64); err == nil {
+		return n
+	}
+	return fallback
+}
+
+// EnvBoolOrDefault returns the environment variable parsed as a boolean or
+// fallback when it is empty or not a boolean.
+func EnvBoolOrDefault(key string, fallback bool) bool {
+	if b, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return b
+	}
+	return fallback
+}
@@ -0,0 +1,27 @@
+package util
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"testing"
+)
+
+func TestGenerateSelfSignedCert(t *testing.T) {
+	for _, host := range []string{"localhost", "127.0.0.1", "::1"} {
+		certPEM, keyPEM, err := GenerateSelfSignedCert(host)
+		if err != nil {
+			t.Fatalf("%s: %v", host, err)
+		}
+		pair, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			t.Fatalf("%s: key pair: %v", host, err)
+		}
+		cert, err := x509.ParseCertificate(pair.Certificate[0])
+		if err != nil {
+			t.Fatalf("%s: parse: %v", host, err)
+		}
+		if err := cert.VerifyHostname(host); err != nil {
+			t.Fatalf("%s: %v", host, err)
+		}
+	}
+}
@@ -0,0 +1,9 @@
+// Package version reports the version of the running build.
+package version
+
+// Version is the release of this build. Release builds override it with
+//
+//	go build -ldflags "-X todo/internal/version.Version=1.2.3"
+//
+// It is a variable rather than a constant because -X can only set variables.
+var Version = "1.0.0"
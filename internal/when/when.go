@@ -0,0 +1,307 @@
+// Package when turns short natural-language phrases such as "tomorrow",
+// "next friday 17:00" or "in 3 days" into absolute times.
+package when
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Error explains why a phrase could not be resolved. Interpretation describes
+// what the parser made of the input, so a client can show the user what went
+// wrong.
+type Error struct {
+	Input          string
+	Interpretation string
+	Ambiguous      bool
+}
+
+func (e *Error) Error() string {
+	if e.Ambiguous {
+		return fmt.Sprintf("ambiguous date %q: %s", e.Input, e.Interpretation)
+	}
+	return fmt.Sprintf("cannot parse date %q: %s", e.Input, e.Interpretation)
+}
+
+var (
+	clockRe    = regexp.MustCompile(`^(?:at\s+)?(\d{1,2})(?::(\d{2}))?\s*(am|pm)?$`)
+	relativeRe = regexp.MustCompile(`^in\s+(\d+|an?)\s+(minute|hour|day|week|month)s?$`)
+	isoDateRe  = regexp.MustCompile(`^(\d{4})-(\d{2})-(\d{2})$`)
+	slashRe    = regexp.MustCompile(`^(\d{1,2})[/.](\d{1,2})(?:[/.](\d{4}))?$`)
+	dayMonthRe = regexp.MustCompile(`^(\d{1,2})(?:st|nd|rd|th)?\s+([a-z]+)(?:\s+(\d{4}))?$`)
+	monthDayRe = regexp.MustCompile(`^([a-z]+)\s+(\d{1,2})(?:st|nd|rd|th)?(?:,?\s+(\d{4}))?$`)
+)
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+var months = map[string]time.Month{
+	"january": time.January, "jan": time.January,
+	"february": time.February, "feb": time.February,
+	"march": time.March, "mar": time.March,
+	"april": time.April, "apr": time.April,
+	"may":  time.May,
+	"june": time.June, "jun": time.June,
+	"july": time.July, "jul": time.July,
+	"august": time.August, "aug": time.August,
+	"september": time.September, "sep": time.September, "sept": time.September,
+	"october": time.October, "oct": time.October,
+	"november": time.November, "nov": time.November,
+	"december": time.December, "dec": time.December,
+}
+
+// Parse resolves input relative to now in loc. Phrases naming only a day
+// resolve to the end of that day (23:59:59); a trailing clock time such as
+// "17:00", "5pm" or "at 9:30am" sets the time of day.
+func Parse(input string, now time.Time, loc *time.Location) (time.Time, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+	now = now.In(loc)
+	phrase := strings.Join(strings.Fields(strings.ToLower(input)), " ")
+	if phrase == "" {
+		return time.Time{}, &Error{Input: input, Interpretation: "empty input"}
+	}
+
+	// Relative offsets carry their own time of day.
+	if m := relativeRe.FindStringSubmatch(phrase); m != nil {
+		n := 1
+		if m[1] != "a" && m[1] != "an" {
+			n, _ = strconv.Atoi(m[1])
+		}
+		switch m[2] {
+		case "minute":
+			return now.Add(time.Duration(n) * time.Minute), nil
+		case "hour":
+			return now.Add(time.Duration(n) * time.Hour), nil
+		case "day":
+			return endOfDay(now.AddDate(0, 0, n)), nil
+		case "week":
+			return endOfDay(now.AddDate(0, 0, 7*n)), nil
+		default:
+			return endOfDay(now.AddDate(0, n, 0)), nil
+		}
+	}
+
+	datePart, hour, minute, hasClock, err := splitClock(phrase)
+	if err != nil {
+		return time.Time{}, &Error{Input: input, Interpretation: err.Error()}
+	}
+
+	day, err := parseDay(datePart, now, loc)
+	if err != nil && !hasClock {
+		if werr := bareHour(datePart, now, loc); werr != nil {
+			werr.Input = input
+			return time.Time{}, werr
+		}
+	}
+	if err != nil {
+		var werr *Error
+		if e, ok := err.(*Error); ok {
+			werr = e
+		} else {
+			werr = &Error{Interpretation: err.Error()}
+		}
+		werr.Input = input
+		return time.Time{}, werr
+	}
+	if !hasClock {
+		return endOfDay(day), nil
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, loc), nil
+}
+
+// splitClock separates a trailing time of day from the date part.
+func splitClock(phrase string) (string, int, int, bool, error) {
+	switch {
+	case strings.HasSuffix(phrase, "noon"):
+		return strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(phrase, "noon"), "at ")), 12, 0, true, nil
+	case strings.HasSuffix(phrase, "midnight"):
+		return strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(phrase, "midnight"), "at ")), 0, 0, true, nil
+	}
+
+	words := strings.Fields(phrase)
+	// Try the last one or two words ("5pm", "5 pm", "at 17:00").
+	for n := 3; n >= 1; n-- {
+		if len(words) < n {
+			continue
+		}
+		tail := strings.Join(words[len(words)-n:], " ")
+		m := clockRe.FindStringSubmatch(tail)
+		if m == nil {
+			continue
+		}
+		if m[2] == "" && m[3] == "" {
+			// A bare number ("friday 5") could be a day or an hour.
+			continue
+		}
+		hour, _ := strconv.Atoi(m[1])
+		minute := 0
+		if m[2] != "" {
+			minute, _ = strconv.Atoi(m[2])
+		}
+		switch m[3] {
+		case "am", "pm":
+			if hour < 1 || hour > 12 {
+				return "", 0, 0, false, fmt.Errorf("%q is not a valid 12-hour time", tail)
+			}
+			if hour == 12 {
+				hour = 0
+			}
+			if m[3] == "pm" {
+				hour += 12
+			}
+		}
+		if hour > 23 || minute > 59 {
+			return "", 0, 0, false, fmt.Errorf("%q is not a valid time of day", tail)
+		}
+		return strings.TrimSpace(strings.Join(words[:len(words)-n], " ")), hour, minute, true, nil
+	}
+	return phrase, 0, 0, false, nil
+}
+
+// parseDay resolves the date part of a phrase to a day in loc.
+func parseDay(phrase string, now time.Time, loc *time.Location) (time.Time, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	phrase = strings.TrimPrefix(strings.TrimPrefix(phrase, "on "), "by ")
+
+	switch phrase {
+	case "", "today", "tonight":
+		return today, nil
+	case "tomorrow", "tmr", "tmrw":
+		return today.AddDate(0, 0, 1), nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	case "next week":
+		// Monday of the following week.
+		offset := (int(time.Monday) - int(today.Weekday()) + 7) % 7
+		if offset == 0 {
+			offset = 7
+		}
+		return today.AddDate(0, 0, offset), nil
+	case "next month":
+		return time.Date(today.Year(), today.Month()+1, 1, 0, 0, 0, 0, loc), nil
+	}
+
+	words := strings.Fields(phrase)
+	if len(words) <= 2 {
+		name := words[len(words)-1]
+		if wd, ok := weekdays[name]; ok {
+			offset := (int(wd) - int(today.Weekday()) + 7) % 7
+			switch {
+			case len(words) == 1 || words[0] == "this":
+				// The coming occurrence, today included.
+			case words[0] == "next":
+				// The next occurrence after today.
+				if offset == 0 {
+					offset = 7
+				}
+			default:
+				return time.Time{}, fmt.Errorf("understood %q as %s but not %q", name, wd, words[0])
+			}
+			return today.AddDate(0, 0, offset), nil
+		}
+	}
+
+	if m := isoDateRe.FindStringSubmatch(phrase); m != nil {
+		y, _ := strconv.Atoi(m[1])
+		mo, _ := strconv.Atoi(m[2])
+		d, _ := strconv.Atoi(m[3])
+		return calendarDay(y, time.Month(mo), d, loc)
+	}
+
+	if m := slashRe.FindStringSubmatch(phrase); m != nil {
+		a, _ := strconv.Atoi(m[1])
+		b, _ := strconv.Atoi(m[2])
+		year := today.Year()
+		if m[3] != "" {
+			year, _ = strconv.Atoi(m[3])
+		}
+		switch {
+		case a > 12 && b <= 12:
+			return calendarDay(year, time.Month(b), a, loc)
+		case b > 12 && a <= 12:
+			return calendarDay(year, time.Month(a), b, loc)
+		case a == b:
+			return calendarDay(year, time.Month(a), b, loc)
+		default:
+			return time.Time{}, &Error{
+				Ambiguous: true,
+				Interpretation: fmt.Sprintf("could be %d %s or %d %s; use YYYY-MM-DD",
+					a, time.Month(b), b, time.Month(a)),
+			}
+		}
+	}
+
+	if m := dayMonthRe.FindStringSubmatch(phrase); m != nil {
+		if mo, ok := months[m[2]]; ok {
+			d, _ := strconv.Atoi(m[1])
+			return namedDay(today, mo, d, m[3], loc)
+		}
+	}
+	if m := monthDayRe.FindStringSubmatch(phrase); m != nil {
+		if mo, ok := months[m[1]]; ok {
+			d, _ := strconv.Atoi(m[2])
+			return namedDay(today, mo, d, m[3], loc)
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("no date recognised in %q; try \"tomorrow\", \"next friday 17:00\", \"in 3 days\" or YYYY-MM-DD", phrase)
+}
+
+// bareHour recognises a day followed by a number without minutes or am/pm,
+// such as "friday 5", which could mean either half of the day.
+func bareHour(phrase string, now time.Time, loc *time.Location) *Error {
+	i := strings.LastIndex(phrase, " ")
+	if i < 0 {
+		return nil
+	}
+	hour, err := strconv.Atoi(phrase[i+1:])
+	if err != nil || hour < 1 || hour > 12 {
+		return nil
+	}
+	if _, err := parseDay(phrase[:i], now, loc); err != nil {
+		return nil
+	}
+	return &Error{
+		Ambiguous:      true,
+		Interpretation: fmt.Sprintf("%d could be %02d:00 or %02d:00; add am/pm or use 24-hour time", hour, hour%12, hour%12+12),
+	}
+}
+
+// namedDay resolves "3 march" to the next such date, rolling into next year
+// once this year's has passed, unless a year is given.
+func namedDay(today time.Time, month time.Month, day int, year string, loc *time.Location) (time.Time, error) {
+	if year != "" {
+		y, _ := strconv.Atoi(year)
+		return calendarDay(y, month, day, loc)
+	}
+	t, err := calendarDay(today.Year(), month, day, loc)
+	if err == nil && t.Before(today) {
+		return calendarDay(today.Year()+1, month, day, loc)
+	}
+	return t, err
+}
+
+// calendarDay rejects dates that time.Date would normalise, like 31 April.
+func calendarDay(year int, month time.Month, day int, loc *time.Location) (time.Time, error) {
+	t := time.Date(year, month, day, 0, 0, 0, 0, loc)
+	if month < time.January || month > time.December || t.Day() != day || t.Month() != month {
+		return time.Time{}, fmt.Errorf("%04d-%02d-%02d is not a calendar date", year, int(month), day)
+	}
+	return t, nil
+}
+
+func endOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 59, 0, t.Location())
+}